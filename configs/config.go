@@ -2,12 +2,12 @@ package config
 
 import (
 	"os"
+	"strconv"
 	"sync"
 
 	"github.com/furqonzt99/snackbox/constants"
 	"github.com/joho/godotenv"
 	"github.com/labstack/gommon/log"
-	"github.com/xendit/xendit-go"
 )
 
 type AppConfig struct {
@@ -20,6 +20,13 @@ type AppConfig struct {
 		Username string
 		Password string
 	}
+	Payment struct {
+		Provider        string
+		XenditSecretKey string
+		CallbackURL     string
+		CallbackToken   string
+		DelaySeconds    int
+	}
 }
 
 var lock = &sync.Mutex{}
@@ -63,9 +70,45 @@ func initConfig() *AppConfig {
 	constants.S3_BUCKET = os.Getenv("S3_BUCKET")
 	constants.LINK_TEMPLATE = os.Getenv("LINK_TEMPLATE")
 
-	xendit.Opt.SecretKey = os.Getenv("XENDIT_SECRET_KEY")
+	constants.CANCELLATION_WINDOW_HOURS = getEnvInt("CANCELLATION_WINDOW_HOURS", 24)
+
+	constants.PAYMENT_TIMEOUT_HOURS = getEnvInt("PAYMENT_TIMEOUT_HOURS", 24)
+	constants.ACCEPT_TIMEOUT_HOURS = getEnvInt("ACCEPT_TIMEOUT_HOURS", 24)
+	constants.AUTO_CONFIRM_DAYS = getEnvInt("AUTO_CONFIRM_DAYS", 3)
+	constants.SCHEDULER_INTERVAL_MINUTES = getEnvInt("SCHEDULER_INTERVAL_MINUTES", 5)
+	constants.INVOICE_DURATION_HOURS = getEnvInt("INVOICE_DURATION_HOURS", 6)
+	constants.EARNING_HOLD_DAYS = getEnvInt("EARNING_HOLD_DAYS", 7)
+	constants.SUBSCRIPTION_ADVANCE_HOURS = getEnvInt("SUBSCRIPTION_ADVANCE_HOURS", 24)
+	constants.WEBHOOK_MAX_ATTEMPTS = getEnvInt("WEBHOOK_MAX_ATTEMPTS", 5)
+	constants.RECONCILIATION_INTERVAL_MINUTES = getEnvInt("RECONCILIATION_INTERVAL_MINUTES", 30)
+	constants.RECONCILIATION_GRACE_MINUTES = getEnvInt("RECONCILIATION_GRACE_MINUTES", 15)
+
+	constants.TOPUP_MIN_AMOUNT = getEnvInt("TOPUP_MIN_AMOUNT", 10000)
+	constants.TOPUP_MAX_AMOUNT = getEnvInt("TOPUP_MAX_AMOUNT", 10000000)
+
+	constants.CASHOUT_MIN_AMOUNT = getEnvInt("CASHOUT_MIN_AMOUNT", 10000)
+	constants.CASHOUT_MAX_AMOUNT = getEnvInt("CASHOUT_MAX_AMOUNT", 50000000)
+	constants.CASHOUT_DAILY_LIMIT = getEnvInt("CASHOUT_DAILY_LIMIT", 50000000)
+	constants.CASHOUT_MONTHLY_LIMIT = getEnvInt("CASHOUT_MONTHLY_LIMIT", 200000000)
+	constants.CASHOUT_FEE = getEnvInt("CASHOUT_FEE", 2500)
+	constants.CASHOUT_APPROVAL_THRESHOLD = getEnvInt("CASHOUT_APPROVAL_THRESHOLD", 5000000)
+
+	defaultConfig.Payment.Provider = os.Getenv("PAYMENT_PROVIDER")
+	defaultConfig.Payment.XenditSecretKey = os.Getenv("XENDIT_SECRET_KEY")
+	defaultConfig.Payment.CallbackURL = os.Getenv("SANDBOX_CALLBACK_URL")
+	defaultConfig.Payment.CallbackToken = constants.XENDIT_CALLBACK_TOKEN
+	defaultConfig.Payment.DelaySeconds = getEnvInt("SANDBOX_PAYMENT_DELAY_SECONDS", 5)
 
 	Mode = os.Getenv("MODE")
 
 	return &defaultConfig
 }
+
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+
+	return value
+}
@@ -0,0 +1,11 @@
+package constants
+
+// cashout statuses. PENDING, COMPLETED and FAILED come from the disbursement; a cashout
+// waiting for an admin is REQUESTED and one the admin turned down is REJECTED.
+const (
+	CASHOUT_REQUESTED = "REQUESTED"
+	CASHOUT_PENDING   = "PENDING"
+	CASHOUT_COMPLETED = "COMPLETED"
+	CASHOUT_FAILED    = "FAILED"
+	CASHOUT_REJECTED  = "REJECTED"
+)
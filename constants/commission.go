@@ -0,0 +1,10 @@
+package constants
+
+// commission rules with this category apply to the shipping cost instead of the products
+const SHIPPING_COMMISSION = "SHIPPING"
+
+// periods the revenue report can be grouped by
+const (
+	DAILY_PERIOD   = "day"
+	MONTHLY_PERIOD = "month"
+)
@@ -6,4 +6,44 @@ var AWS_ACCESS_KEY_ID string
 var AWS_ACCESS_SECRET_KEY string
 var S3_REGION string
 var S3_BUCKET string
-var LINK_TEMPLATE string
\ No newline at end of file
+var LINK_TEMPLATE string
+
+// hours before the event time after which a customer can no longer cancel
+var CANCELLATION_WINDOW_HOURS int
+
+// order timeouts enforced by the background scheduler
+var PAYMENT_TIMEOUT_HOURS int
+var ACCEPT_TIMEOUT_HOURS int
+var AUTO_CONFIRM_DAYS int
+var SCHEDULER_INTERVAL_MINUTES int
+
+// how long a payment invoice can be paid, never past the order's payment timeout. A customer can
+// ask for a new invoice once it expired while the order is still waiting for payment.
+var INVOICE_DURATION_HOURS int
+
+// days a partner's earning from a confirmed order stays pending before it can be cashed out
+var EARNING_HOLD_DAYS int
+
+// subscription orders are placed this many hours before the partner's lead time runs out
+var SUBSCRIPTION_ADVANCE_HOURS int
+
+// limits on the amount of a single SboxPay top-up
+var TOPUP_MIN_AMOUNT int
+var TOPUP_MAX_AMOUNT int
+
+// limits on cashouts: the amount of a single cashout, the total a user can cash out per day
+// and per month, the flat fee charged on each and the amount above which an admin approves it
+var CASHOUT_MIN_AMOUNT int
+var CASHOUT_MAX_AMOUNT int
+var CASHOUT_DAILY_LIMIT int
+var CASHOUT_MONTHLY_LIMIT int
+var CASHOUT_FEE int
+var CASHOUT_APPROVAL_THRESHOLD int
+
+// invoices and disbursements the provider has not called back about are looked up every
+// interval, once they have been waiting for longer than the grace period
+var RECONCILIATION_INTERVAL_MINUTES int
+var RECONCILIATION_GRACE_MINUTES int
+
+// failed webhook events are retried with a growing delay until they were attempted this many times
+var WEBHOOK_MAX_ATTEMPTS int
@@ -0,0 +1,43 @@
+package constants
+
+// ledger accounts. Every user has a WALLET_ACCOUNT and a PENDING_ACCOUNT for earnings that
+// are still on hold, the others are system accounts: money held for orders, money coming in from the payment gateway, cashouts on
+// their way to a bank, balance corrections made by an admin and the platform's commission.
+const (
+	WALLET_ACCOUNT     = "wallet"
+	PENDING_ACCOUNT    = "pending"
+	ESCROW_ACCOUNT     = "escrow"
+	GATEWAY_ACCOUNT    = "gateway"
+	CASHOUT_ACCOUNT    = "cashout"
+	ADJUSTMENT_ACCOUNT = "adjustment"
+	REVENUE_ACCOUNT    = "revenue"
+)
+
+// ledger entry types, one per kind of balance movement
+const (
+	OPENING_BALANCE_ENTRY      = "OPENING_BALANCE"
+	ORDER_PAYMENT_ENTRY        = "ORDER_PAYMENT"
+	INVOICE_PAYMENT_ENTRY      = "INVOICE_PAYMENT"
+	REFUND_ENTRY               = "REFUND"
+	PARTNER_EARNING_ENTRY      = "PARTNER_EARNING"
+	CASHOUT_ENTRY              = "CASHOUT"
+	CASHOUT_REVERSAL_ENTRY     = "CASHOUT_REVERSAL"
+	CASHOUT_FEE_ENTRY          = "CASHOUT_FEE"
+	CASHOUT_FEE_REVERSAL_ENTRY = "CASHOUT_FEE_REVERSAL"
+	ADJUSTMENT_ENTRY           = "ADJUSTMENT"
+	TOPUP_ENTRY                = "TOPUP"
+	COMMISSION_ENTRY           = "COMMISSION"
+	COMMISSION_REVERSAL_ENTRY  = "COMMISSION_REVERSAL"
+	REFUND_DEDUCTION_ENTRY     = "REFUND_DEDUCTION"
+	EARNING_RELEASE_ENTRY      = "EARNING_RELEASE"
+)
+
+// records a ledger entry can point at
+const (
+	USER_SOURCE        = "user"
+	TRANSACTION_SOURCE = "transaction"
+	CASHOUT_SOURCE     = "cashout"
+	ADJUSTMENT_SOURCE  = "adjustment"
+	TOPUP_SOURCE       = "topup"
+	REFUND_SOURCE      = "refund"
+)
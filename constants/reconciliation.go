@@ -0,0 +1,10 @@
+package constants
+
+// what a reconciliation run found about an invoice or a disbursement. A missed callback was
+// applied by the run; the other kinds are left for an admin to look into.
+const (
+	DISCREPANCY_MISSED_CALLBACK = "MISSED_CALLBACK"
+	DISCREPANCY_AMOUNT_MISMATCH = "AMOUNT_MISMATCH"
+	DISCREPANCY_LOOKUP_FAILED   = "LOOKUP_FAILED"
+	DISCREPANCY_APPLY_FAILED    = "APPLY_FAILED"
+)
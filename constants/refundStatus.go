@@ -0,0 +1,11 @@
+package constants
+
+// refund request statuses. A request is open until it is APPROVED or REJECTED: the partner
+// accepts or counters it, the customer takes the counter offer or escalates to an admin.
+const (
+	REFUND_REQUESTED = "REQUESTED"
+	REFUND_COUNTERED = "COUNTERED"
+	REFUND_DISPUTED  = "DISPUTED"
+	REFUND_APPROVED  = "APPROVED"
+	REFUND_REJECTED  = "REJECTED"
+)
@@ -0,0 +1,9 @@
+package constants
+
+// settlement statuses of a paid invoice as the payment gateway reports them. The money of an
+// invoice PENDING settlement was collected but has not reached the platform's account yet.
+const (
+	SETTLEMENT_PENDING       = "PENDING"
+	SETTLEMENT_EARLY_SETTLED = "EARLY_SETTLED"
+	SETTLEMENT_SETTLED       = "SETTLED"
+)
@@ -0,0 +1,9 @@
+package constants
+
+// subscription statuses, only ACTIVE subscriptions place orders
+const (
+	SUBSCRIPTION_ACTIVE    = "ACTIVE"
+	SUBSCRIPTION_PAUSED    = "PAUSED"
+	SUBSCRIPTION_CANCELLED = "CANCELLED"
+	SUBSCRIPTION_ENDED     = "ENDED"
+)
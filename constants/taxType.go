@@ -0,0 +1,7 @@
+package constants
+
+// tax types a partner can charge, restaurant tax (PB1) or value added tax
+const (
+	PB1_TAX = "PB1"
+	VAT_TAX = "VAT"
+)
@@ -0,0 +1,24 @@
+package constants
+
+// transaction statuses, see helper.CanTransitionOrder for the allowed moves
+const (
+	PENDING_STATUS     = "PENDING"
+	PAID_STATUS        = "PAID"
+	EXPIRED_STATUS     = "EXPIRED"
+	ACCEPT_STATUS      = "ACCEPT"
+	REJECT_STATUS      = "REJECT"
+	PREPARING_STATUS   = "PREPARING"
+	READY_STATUS       = "READY"
+	ON_DELIVERY_STATUS = "ON_DELIVERY"
+	SEND_STATUS        = "SEND"
+	CONFIRM_STATUS     = "CONFIRM"
+	CANCEL_STATUS      = "CANCEL"
+)
+
+// actor roles recorded on the status history, next to the jwt roles
+const (
+	USER_ROLE    = "user"
+	PARTNER_ROLE = "partner"
+	ADMIN_ROLE   = "admin"
+	SYSTEM_ROLE  = "system"
+)
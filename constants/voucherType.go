@@ -0,0 +1,8 @@
+package constants
+
+// voucher types, see helper.ApplyVoucher for how each discount is computed
+const (
+	PERCENTAGE_VOUCHER    = "PERCENTAGE"
+	FLAT_VOUCHER          = "FLAT"
+	FREE_SHIPPING_VOUCHER = "FREE_SHIPPING"
+)
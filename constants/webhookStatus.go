@@ -0,0 +1,14 @@
+package constants
+
+const (
+	TRANSACTION_WEBHOOK = "transaction"
+	CASHOUT_WEBHOOK     = "cashout"
+	TOPUP_WEBHOOK       = "topup"
+)
+
+const (
+	WEBHOOK_PENDING    = "PENDING"
+	WEBHOOK_PROCESSING = "PROCESSING"
+	WEBHOOK_PROCESSED  = "PROCESSED"
+	WEBHOOK_FAILED     = "FAILED"
+)
@@ -1,17 +1,27 @@
 package common
 
+import "errors"
+
+var ErrInvalidCallback = errors.New("the callback has no external_id")
+
 type TransactionCallbackRequest struct {
 	ExternalID string `json:"external_id"`
 	PaymentMethod string `json:"payment_method"`
 	PaymentChannel string `json:"payment_channel"`
 	PaidAt string `json:"paid_at"`
+	PaidAmount float64 `json:"paid_amount"`
+	AdjustedReceivedAmount float64 `json:"adjusted_received_amount"`
+	SettlementStatus string `json:"settlement_status"`
 	Status string `json:"status"`
 	Items []XenditItems `json:"items"`
 }
 
+// CashoutCallbackRequest is a disbursement callback. Fee is only sent by the sandbox and the
+// reconciler, a Xendit callback leaves it out and the fee is looked up.
 type CashoutCallbackRequest struct {
 	ExternalID string `json:"external_id"`
 	Amount float64 `json:"amount"`
+	Fee float64 `json:"fee"`
 	Status string `json:"status"`
 }
 
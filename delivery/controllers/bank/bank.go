@@ -1,9 +1,14 @@
 package bank
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/furqonzt99/snackbox/delivery/common"
+	"github.com/furqonzt99/snackbox/delivery/middlewares"
+	"github.com/furqonzt99/snackbox/helper"
+	"github.com/furqonzt99/snackbox/models"
 	"github.com/furqonzt99/snackbox/repositories/bank"
 	"github.com/labstack/echo/v4"
 )
@@ -23,4 +28,124 @@ func (bc BankController) AvailableBanks(c echo.Context) error {
 	}
 
 	return c.JSON(http.StatusOK, common.SuccessResponse(data))
-}
\ No newline at end of file
+}
+
+func (bc BankController) CreateAccount(c echo.Context) error {
+	user, _ := middlewares.ExtractTokenUser(c)
+
+	var accountRequest BankAccountRequest
+
+	if err := c.Bind(&accountRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	if err := c.Validate(&accountRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	account, err := bc.Repo.CreateAccount(models.BankAccount{
+		UserID:            uint(user.UserID),
+		BankCode:          accountRequest.BankCode,
+		AccountHolderName: accountRequest.AccountHolderName,
+		AccountNumber:     accountRequest.AccountNumber,
+		IsDefault:         accountRequest.IsDefault,
+	})
+
+	return bankAccountResult(c, account, err)
+}
+
+func (bc BankController) GetAccounts(c echo.Context) error {
+	user, _ := middlewares.ExtractTokenUser(c)
+
+	accounts, err := bc.Repo.GetAccounts(user.UserID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
+	}
+
+	response := []BankAccountResponse{}
+	for _, account := range accounts {
+		response = append(response, newBankAccountResponse(account))
+	}
+
+	return c.JSON(http.StatusOK, common.SuccessResponse(response))
+}
+
+func (bc BankController) GetAccount(c echo.Context) error {
+	user, _ := middlewares.ExtractTokenUser(c)
+
+	accountID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	account, err := bc.Repo.GetAccount(accountID, user.UserID)
+
+	return bankAccountResult(c, account, err)
+}
+
+func (bc BankController) UpdateAccount(c echo.Context) error {
+	user, _ := middlewares.ExtractTokenUser(c)
+
+	accountID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	var accountRequest BankAccountRequest
+
+	if err := c.Bind(&accountRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	if err := c.Validate(&accountRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	account, err := bc.Repo.UpdateAccount(accountID, user.UserID, models.BankAccount{
+		BankCode:          accountRequest.BankCode,
+		AccountHolderName: accountRequest.AccountHolderName,
+		AccountNumber:     accountRequest.AccountNumber,
+		IsDefault:         accountRequest.IsDefault,
+	})
+
+	return bankAccountResult(c, account, err)
+}
+
+func (bc BankController) DeleteAccount(c echo.Context) error {
+	user, _ := middlewares.ExtractTokenUser(c)
+
+	accountID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	if err := bc.Repo.DeleteAccount(accountID, user.UserID); err != nil {
+		return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
+	}
+
+	return c.JSON(http.StatusOK, common.NewSuccessOperationResponse())
+}
+
+func (bc BankController) SetDefault(c echo.Context) error {
+	user, _ := middlewares.ExtractTokenUser(c)
+
+	accountID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	account, err := bc.Repo.SetDefault(accountID, user.UserID)
+
+	return bankAccountResult(c, account, err)
+}
+
+func bankAccountResult(c echo.Context, account models.BankAccount, err error) error {
+	if errors.Is(err, helper.ErrInvalidBankAccount) {
+		return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	if err != nil {
+		return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
+	}
+
+	return c.JSON(http.StatusOK, common.SuccessResponse(newBankAccountResponse(account)))
+}
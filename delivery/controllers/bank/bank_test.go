@@ -1,19 +1,31 @@
 package bank_test
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/furqonzt99/snackbox/constants"
 	"github.com/furqonzt99/snackbox/delivery/common"
 	"github.com/furqonzt99/snackbox/delivery/controllers/bank"
+	"github.com/furqonzt99/snackbox/delivery/controllers/user"
+	"github.com/furqonzt99/snackbox/helper"
+	"github.com/furqonzt99/snackbox/models"
+	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
 	"github.com/stretchr/testify/assert"
-	"github.com/xendit/xendit-go"
+	"github.com/furqonzt99/snackbox/payment"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
+var JwtToken string
+
 func TestBank(t *testing.T) {
 	t.Run("test GetAvailableBanks success", func(t *testing.T) {
 		e := echo.New()
@@ -52,11 +64,211 @@ func TestBank(t *testing.T) {
 	})
 }
 
+func TestBankAccount(t *testing.T) {
+	t.Run("Test Login", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = &user.UserValidator{Validator: validator.New()}
+
+		requestBody, _ := json.Marshal(map[string]string{
+			"email":    "test@gmail.com",
+			"password": "test1234",
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(requestBody))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		context := e.NewContext(req, res)
+		context.SetPath("/login")
+
+		userController := user.NewUsersControllers(mockUserRepository{})
+		userController.LoginController()(context)
+
+		response := common.ResponseSuccess{}
+		json.Unmarshal([]byte(res.Body.Bytes()), &response)
+
+		JwtToken = response.Data.(string)
+		assert.Equal(t, "Successful Operation", response.Message)
+		assert.NotNil(t, JwtToken)
+	})
+
+	newContext := func(method string, body interface{}, path string, id string) (echo.Context, *httptest.ResponseRecorder) {
+		e := echo.New()
+		e.Validator = &bank.BankValidator{Validator: validator.New()}
+
+		requestBody, _ := json.Marshal(body)
+
+		req := httptest.NewRequest(method, "/", bytes.NewBuffer(requestBody))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath(path)
+		if id != "" {
+			context.SetParamNames("id")
+			context.SetParamValues(id)
+		}
+
+		return context, res
+	}
+
+	t.Run("create bank account", func(t *testing.T) {
+		context, res := newContext(http.MethodPost, bank.BankAccountRequest{
+			BankCode:          "BCA",
+			AccountHolderName: "tester",
+			AccountNumber:     "1234567890",
+		}, "/bank-accounts", "")
+
+		bankController := bank.NewBankController(mockBankRepository{})
+		middleware.JWT([]byte(constants.JWT_SECRET_KEY))(bankController.CreateAccount)(context)
+
+		var response common.ResponseSuccess
+		json.Unmarshal([]byte(res.Body.Bytes()), &response)
+
+		data := response.Data.(map[string]interface{})
+		assert.Equal(t, "Successful Operation", response.Message)
+		assert.Equal(t, true, data["name_matched"])
+		assert.Nil(t, data["warning"])
+	})
+
+	t.Run("create bank account with another holder name", func(t *testing.T) {
+		context, res := newContext(http.MethodPost, bank.BankAccountRequest{
+			BankCode:          "BCA",
+			AccountHolderName: "someone else",
+			AccountNumber:     "1234567890",
+		}, "/bank-accounts", "")
+
+		bankController := bank.NewBankController(mockBankRepository{})
+		middleware.JWT([]byte(constants.JWT_SECRET_KEY))(bankController.CreateAccount)(context)
+
+		var response common.ResponseSuccess
+		json.Unmarshal([]byte(res.Body.Bytes()), &response)
+
+		data := response.Data.(map[string]interface{})
+		assert.Equal(t, "Successful Operation", response.Message)
+		assert.Equal(t, false, data["name_matched"])
+		assert.Equal(t, "the bank knows the account holder as TESTER, not someone else", data["warning"])
+	})
+
+	t.Run("create bank account bad request", func(t *testing.T) {
+		context, res := newContext(http.MethodPost, bank.BankAccountRequest{
+			BankCode:      "BCA",
+			AccountNumber: "1234567890",
+		}, "/bank-accounts", "")
+
+		bankController := bank.NewBankController(mockBankRepository{})
+		middleware.JWT([]byte(constants.JWT_SECRET_KEY))(bankController.CreateAccount)(context)
+
+		var response common.ResponseSuccess
+		json.Unmarshal([]byte(res.Body.Bytes()), &response)
+
+		assert.Equal(t, "Bad Request", response.Message)
+	})
+
+	t.Run("create bank account with an unknown bank", func(t *testing.T) {
+		context, res := newContext(http.MethodPost, bank.BankAccountRequest{
+			BankCode:          "UNKNOWN",
+			AccountHolderName: "tester",
+			AccountNumber:     "1234567890",
+		}, "/bank-accounts", "")
+
+		bankController := bank.NewBankController(mockFalseBankRepository{})
+		middleware.JWT([]byte(constants.JWT_SECRET_KEY))(bankController.CreateAccount)(context)
+
+		var response common.ResponseSuccess
+		json.Unmarshal([]byte(res.Body.Bytes()), &response)
+
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+		assert.Equal(t, "invalid bank account: the bank UNKNOWN is not available for cashouts", response.Message)
+	})
+
+	t.Run("get bank accounts", func(t *testing.T) {
+		context, res := newContext(http.MethodGet, nil, "/bank-accounts", "")
+
+		bankController := bank.NewBankController(mockBankRepository{})
+		middleware.JWT([]byte(constants.JWT_SECRET_KEY))(bankController.GetAccounts)(context)
+
+		var response common.ResponseSuccess
+		json.Unmarshal([]byte(res.Body.Bytes()), &response)
+
+		assert.Equal(t, "Successful Operation", response.Message)
+		assert.Len(t, response.Data, 1)
+	})
+
+	t.Run("get bank account not found", func(t *testing.T) {
+		context, res := newContext(http.MethodGet, nil, "/bank-accounts/:id", "1")
+
+		bankController := bank.NewBankController(mockFalseBankRepository{})
+		middleware.JWT([]byte(constants.JWT_SECRET_KEY))(bankController.GetAccount)(context)
+
+		var response common.ResponseSuccess
+		json.Unmarshal([]byte(res.Body.Bytes()), &response)
+
+		assert.Equal(t, "Not Found", response.Message)
+	})
+
+	t.Run("update bank account", func(t *testing.T) {
+		context, res := newContext(http.MethodPut, bank.BankAccountRequest{
+			BankCode:          "MANDIRI",
+			AccountHolderName: "tester",
+			AccountNumber:     "1234567890",
+		}, "/bank-accounts/:id", "1")
+
+		bankController := bank.NewBankController(mockBankRepository{})
+		middleware.JWT([]byte(constants.JWT_SECRET_KEY))(bankController.UpdateAccount)(context)
+
+		var response common.ResponseSuccess
+		json.Unmarshal([]byte(res.Body.Bytes()), &response)
+
+		assert.Equal(t, "Successful Operation", response.Message)
+		assert.Equal(t, "MANDIRI", response.Data.(map[string]interface{})["bank_code"])
+	})
+
+	t.Run("set default bank account", func(t *testing.T) {
+		context, res := newContext(http.MethodPut, nil, "/bank-accounts/:id/default", "1")
+
+		bankController := bank.NewBankController(mockBankRepository{})
+		middleware.JWT([]byte(constants.JWT_SECRET_KEY))(bankController.SetDefault)(context)
+
+		var response common.ResponseSuccess
+		json.Unmarshal([]byte(res.Body.Bytes()), &response)
+
+		assert.Equal(t, "Successful Operation", response.Message)
+		assert.Equal(t, true, response.Data.(map[string]interface{})["is_default"])
+	})
+
+	t.Run("delete bank account", func(t *testing.T) {
+		context, res := newContext(http.MethodDelete, nil, "/bank-accounts/:id", "1")
+
+		bankController := bank.NewBankController(mockBankRepository{})
+		middleware.JWT([]byte(constants.JWT_SECRET_KEY))(bankController.DeleteAccount)(context)
+
+		var response common.ResponseSuccess
+		json.Unmarshal([]byte(res.Body.Bytes()), &response)
+
+		assert.Equal(t, "Successful Operation", response.Message)
+	})
+
+	t.Run("delete bank account not found", func(t *testing.T) {
+		context, res := newContext(http.MethodDelete, nil, "/bank-accounts/:id", "1")
+
+		bankController := bank.NewBankController(mockFalseBankRepository{})
+		middleware.JWT([]byte(constants.JWT_SECRET_KEY))(bankController.DeleteAccount)(context)
+
+		var response common.ResponseSuccess
+		json.Unmarshal([]byte(res.Body.Bytes()), &response)
+
+		assert.Equal(t, "Not Found", response.Message)
+	})
+}
+
 //MOCK BANK
 type mockBankRepository struct{}
 
-func (m mockBankRepository) GetAvailableBanks() ([]xendit.DisbursementBank, error) {
-	return []xendit.DisbursementBank{{
+func (m mockBankRepository) GetAvailableBanks() ([]payment.Bank, error) {
+	return []payment.Bank{{
 		Name:            "Bank Mandiri",
 		Code:            "MANDIRI",
 		CanDisburse:     true,
@@ -64,13 +276,105 @@ func (m mockBankRepository) GetAvailableBanks() ([]xendit.DisbursementBank, erro
 	}}, nil
 }
 
+// mockBankRepository verifies every account as held by TESTER.
+func (m mockBankRepository) CreateAccount(account models.BankAccount) (models.BankAccount, error) {
+	account.ID = 1
+	account.BankName = "Bank Central Asia (BCA)"
+	account.InquiredName = "TESTER"
+	account.NameMatched = helper.SameHolderName(account.InquiredName, account.AccountHolderName)
+	account.IsDefault = true
+	return account, nil
+}
+
+func (m mockBankRepository) GetAccounts(userID int) ([]models.BankAccount, error) {
+	account, _ := m.GetAccount(1, userID)
+	return []models.BankAccount{account}, nil
+}
+
+func (m mockBankRepository) GetAccount(accountID, userID int) (models.BankAccount, error) {
+	return m.CreateAccount(models.BankAccount{
+		UserID:            uint(userID),
+		BankCode:          "BCA",
+		AccountHolderName: "tester",
+		AccountNumber:     "1234567890",
+	})
+}
+
+func (m mockBankRepository) UpdateAccount(accountID, userID int, account models.BankAccount) (models.BankAccount, error) {
+	return m.CreateAccount(account)
+}
+
+func (m mockBankRepository) DeleteAccount(accountID, userID int) error {
+	return nil
+}
+
+func (m mockBankRepository) SetDefault(accountID, userID int) (models.BankAccount, error) {
+	return m.GetAccount(accountID, userID)
+}
+
 type mockFalseBankRepository struct{}
 
-func (m mockFalseBankRepository) GetAvailableBanks() ([]xendit.DisbursementBank, error) {
-	return []xendit.DisbursementBank{{
+func (m mockFalseBankRepository) GetAvailableBanks() ([]payment.Bank, error) {
+	return []payment.Bank{{
 		Name:            "Bank Mandiri",
 		Code:            "MANDIRI",
 		CanDisburse:     true,
 		CanNameValidate: true,
 	}}, errors.New("failed")
 }
+
+func (m mockFalseBankRepository) CreateAccount(account models.BankAccount) (models.BankAccount, error) {
+	return account, helper.BankAccountError("the bank " + account.BankCode + " is not available for cashouts")
+}
+
+func (m mockFalseBankRepository) GetAccounts(userID int) ([]models.BankAccount, error) {
+	return nil, errors.New("failed")
+}
+
+func (m mockFalseBankRepository) GetAccount(accountID, userID int) (models.BankAccount, error) {
+	return models.BankAccount{}, gorm.ErrRecordNotFound
+}
+
+func (m mockFalseBankRepository) UpdateAccount(accountID, userID int, account models.BankAccount) (models.BankAccount, error) {
+	return models.BankAccount{}, gorm.ErrRecordNotFound
+}
+
+func (m mockFalseBankRepository) DeleteAccount(accountID, userID int) error {
+	return gorm.ErrRecordNotFound
+}
+
+func (m mockFalseBankRepository) SetDefault(accountID, userID int) (models.BankAccount, error) {
+	return models.BankAccount{}, gorm.ErrRecordNotFound
+}
+
+// ======================
+// MOCK USER REPOSITORY
+// ======================
+type mockUserRepository struct{}
+
+func (m mockUserRepository) Register(newUser models.User) (models.User, error) {
+	return newUser, nil
+}
+
+func (m mockUserRepository) Login(email string) (models.User, error) {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("test1234"), 14)
+	return models.User{
+		Email:    "test@gmail.com",
+		Password: string(hash),
+	}, nil
+}
+
+func (m mockUserRepository) Get(userid int) (models.User, error) {
+	return models.User{
+		Email: "test@gmail.com",
+		Name:  "tester",
+	}, nil
+}
+
+func (m mockUserRepository) Update(newUser models.User, userId int) (models.User, error) {
+	return newUser, nil
+}
+
+func (m mockUserRepository) Delete(userId int) (models.User, error) {
+	return models.User{}, nil
+}
@@ -0,0 +1,28 @@
+package bank
+
+import (
+	"net/http"
+
+	"github.com/furqonzt99/snackbox/delivery/common"
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+type BankAccountRequest struct {
+	BankCode          string `json:"bank_code" validate:"required"`
+	AccountHolderName string `json:"account_holder_name" validate:"required"`
+	AccountNumber     string `json:"account_number" validate:"required,numeric"`
+	IsDefault         bool   `json:"is_default"`
+}
+
+type BankValidator struct {
+	Validator *validator.Validate
+}
+
+func (bv *BankValidator) Validate(i interface{}) error {
+	if err := bv.Validator.Struct(i); err != nil {
+		// Optionally, you could return the error to give each route more control over the status code
+		return echo.NewHTTPError(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package bank
+
+import (
+	"fmt"
+
+	"github.com/furqonzt99/snackbox/models"
+)
+
+type BankAccountResponse struct {
+	ID                uint   `json:"id"`
+	BankCode          string `json:"bank_code"`
+	BankName          string `json:"bank_name"`
+	AccountHolderName string `json:"account_holder_name"`
+	AccountNumber     string `json:"account_number"`
+	InquiredName      string `json:"inquired_name"`
+	NameMatched       bool   `json:"name_matched"`
+	IsDefault         bool   `json:"is_default"`
+	Warning           string `json:"warning,omitempty"`
+}
+
+func newBankAccountResponse(account models.BankAccount) BankAccountResponse {
+	response := BankAccountResponse{
+		ID:                account.ID,
+		BankCode:          account.BankCode,
+		BankName:          account.BankName,
+		AccountHolderName: account.AccountHolderName,
+		AccountNumber:     account.AccountNumber,
+		InquiredName:      account.InquiredName,
+		NameMatched:       account.NameMatched,
+		IsDefault:         account.IsDefault,
+	}
+
+	switch {
+	case account.InquiredName == "":
+		response.Warning = "the account holder could not be verified with the bank"
+	case !account.NameMatched:
+		response.Warning = fmt.Sprintf("the bank knows the account holder as %s, not %s", account.InquiredName, account.AccountHolderName)
+	}
+
+	return response
+}
@@ -0,0 +1,299 @@
+package cart
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/delivery/common"
+	"github.com/furqonzt99/snackbox/delivery/controllers/transaction"
+	"github.com/furqonzt99/snackbox/delivery/middlewares"
+	"github.com/furqonzt99/snackbox/helper"
+	"github.com/furqonzt99/snackbox/models"
+	"github.com/furqonzt99/snackbox/repositories/cart"
+	tr "github.com/furqonzt99/snackbox/repositories/transaction"
+	"github.com/labstack/echo/v4"
+)
+
+type CartController struct {
+	Repo            cart.CartInterface
+	TransactionRepo tr.TransactionInterface
+}
+
+func NewCartController(cart cart.CartInterface, transaction tr.TransactionInterface) *CartController {
+	return &CartController{Repo: cart, TransactionRepo: transaction}
+}
+
+func (cc CartController) GetCart(c echo.Context) error {
+	user, _ := middlewares.ExtractTokenUser(c)
+
+	items, err := cc.Repo.GetCart(user.UserID)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	response := CartResponse{Partners: []CartPartnerResponse{}}
+
+	for _, item := range items {
+		var productImage string
+		if item.Product.Image != "" {
+			productImage = fmt.Sprintf(constants.LINK_TEMPLATE, constants.S3_BUCKET, constants.S3_REGION, item.Product.Image)
+		}
+
+		subtotal := item.Product.Price * models.Money(item.Quantity)
+
+		// items are ordered by partner, so a new group starts whenever the partner changes
+		last := len(response.Partners) - 1
+		if last < 0 || response.Partners[last].PartnerID != int(item.PartnerID) {
+			response.Partners = append(response.Partners, CartPartnerResponse{
+				PartnerID:     int(item.PartnerID),
+				BussinessName: item.Partner.BussinessName,
+				Items:         []CartItemResponse{},
+			})
+			last++
+		}
+
+		response.Partners[last].Items = append(response.Partners[last].Items, CartItemResponse{
+			ID:        int(item.ID),
+			ProductID: int(item.ProductID),
+			Title:     item.Product.Title,
+			Image:     productImage,
+			Type:      item.Product.Type,
+			Price:     item.Product.Price,
+			Quantity:  item.Quantity,
+			Notes:     item.Notes,
+			Subtotal:  subtotal,
+		})
+		response.Partners[last].Subtotal += subtotal
+		response.Total += subtotal
+	}
+
+	return c.JSON(http.StatusOK, common.SuccessResponse(response))
+}
+
+func (cc CartController) AddItem(c echo.Context) error {
+	var cartRequest AddCartRequest
+
+	c.Bind(&cartRequest)
+
+	if err := c.Validate(&cartRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	user, _ := middlewares.ExtractTokenUser(c)
+
+	product, err := cc.Repo.GetProduct(cartRequest.ProductID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
+	}
+
+	items, err := cc.Repo.GetCart(user.UserID)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	if !cartRequest.Split {
+		for _, item := range items {
+			if item.PartnerID != product.PartnerID {
+				return c.JSON(http.StatusConflict, common.ErrorResponse(http.StatusConflict, "cart already contains products from another partner, set split to true to checkout one transaction per partner"))
+			}
+		}
+	}
+
+	data := models.CartItem{
+		UserID:    uint(user.UserID),
+		PartnerID: product.PartnerID,
+		ProductID: product.ID,
+		Quantity:  cartRequest.Quantity,
+		Notes:     cartRequest.Notes,
+	}
+
+	if _, err := cc.Repo.AddItem(data); err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	return c.JSON(http.StatusOK, common.NewSuccessOperationResponse())
+}
+
+func (cc CartController) UpdateItem(c echo.Context) error {
+	itemID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	var cartRequest UpdateCartRequest
+
+	c.Bind(&cartRequest)
+
+	if err := c.Validate(&cartRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	user, _ := middlewares.ExtractTokenUser(c)
+
+	data := models.CartItem{
+		Quantity: cartRequest.Quantity,
+		Notes:    cartRequest.Notes,
+	}
+
+	if _, err := cc.Repo.UpdateItem(itemID, user.UserID, data); err != nil {
+		return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
+	}
+
+	return c.JSON(http.StatusOK, common.NewSuccessOperationResponse())
+}
+
+func (cc CartController) RemoveItem(c echo.Context) error {
+	itemID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	user, _ := middlewares.ExtractTokenUser(c)
+
+	if err := cc.Repo.RemoveItem(itemID, user.UserID); err != nil {
+		return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
+	}
+
+	return c.JSON(http.StatusOK, common.NewSuccessOperationResponse())
+}
+
+// Checkout turns the cart into one transaction per partner. The orders are placed together,
+// when one of them cannot be placed none is and the cart is left as it was.
+func (cc CartController) Checkout(c echo.Context) error {
+	var checkoutRequest CheckoutRequest
+
+	c.Bind(&checkoutRequest)
+
+	if err := c.Validate(&checkoutRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	user, _ := middlewares.ExtractTokenUser(c)
+
+	dateTime, _ := helper.ParseEventTime(checkoutRequest.Date, checkoutRequest.Time)
+
+	cartItems, err := cc.Repo.GetCart(user.UserID)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	if len(cartItems) == 0 {
+		return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, "cart is empty"))
+	}
+
+	partnerIDs := []uint{}
+	partners := map[uint]models.Partner{}
+	partnerItems := map[uint][]models.DetailTransaction{}
+
+	for _, item := range cartItems {
+		if _, ok := partnerItems[item.PartnerID]; !ok {
+			partnerIDs = append(partnerIDs, item.PartnerID)
+			partners[item.PartnerID] = item.Partner
+		}
+
+		partnerItems[item.PartnerID] = append(partnerItems[item.PartnerID], models.DetailTransaction{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+			Notes:     item.Notes,
+		})
+	}
+
+	// every partner's rules are checked before the orders are placed, the repository then reserves
+	// the capacity of all partners and places the orders in one database transaction
+	orders := []models.Transaction{}
+	items := [][]models.DetailTransaction{}
+
+	for _, partnerID := range partnerIDs {
+		distance, err := cc.TransactionRepo.GetDistance(int(partnerID), checkoutRequest.Latitude, checkoutRequest.Longtitude)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+		}
+
+		var quantity int
+		for _, item := range partnerItems[partnerID] {
+			quantity += item.Quantity
+		}
+
+		if err := helper.ValidateOrderRules(partners[partnerID], dateTime, quantity, distance); err != nil {
+			return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, fmt.Sprintf("%v: %v", partners[partnerID].BussinessName, err.Error())))
+		}
+
+		orders = append(orders, models.Transaction{
+			UserID:     uint(user.UserID),
+			PartnerID:  partnerID,
+			Buffet:     checkoutRequest.Buffet,
+			Quantity:   quantity,
+			DateTime:   dateTime,
+			Latitude:   checkoutRequest.Latitude,
+			Longtitude: checkoutRequest.Longtitude,
+			Distance:   distance,
+			InvoiceID:  helper.NewInvoiceID(),
+		})
+		items = append(items, partnerItems[partnerID])
+	}
+
+	voucherCode := strings.ToUpper(strings.TrimSpace(checkoutRequest.VoucherCode))
+
+	transactionOrders, err := cc.TransactionRepo.Checkout(orders, user.Email, voucherCode, items)
+	if errors.Is(err, helper.ErrPartnerUnavailable) || errors.Is(err, helper.ErrCapacityExceeded) || errors.Is(err, helper.ErrInvalidVoucher) {
+		return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	response := []transaction.TransactionResponse{}
+
+	for _, transactionOrder := range transactionOrders {
+		productItems := []transaction.TransactionItemResponse{}
+
+		for _, item := range transactionOrder.DetailTransactions {
+			var productImage string
+			if item.Product.Image != "" {
+				productImage = fmt.Sprintf(constants.LINK_TEMPLATE, constants.S3_BUCKET, constants.S3_REGION, item.Product.Image)
+			}
+			productItems = append(productItems, transaction.TransactionItemResponse{
+				ProductID: int(item.ProductID),
+				Title:     item.Title,
+				Image:     productImage,
+				Type:      item.Type,
+				Price:     item.Price,
+				Quantity:  item.Quantity,
+				Notes:     item.Notes,
+				Subtotal:  item.Price * models.Money(item.Quantity),
+			})
+		}
+
+		response = append(response, transaction.TransactionResponse{
+			ID:             int(transactionOrder.ID),
+			UserID:         int(transactionOrder.UserID),
+			UserName:       transactionOrder.User.Name,
+			PartnerID:      int(transactionOrder.PartnerID),
+			InvoiceID:      transactionOrder.InvoiceID,
+			Buffet:         transactionOrder.Buffet,
+			Quantity:       transactionOrder.Quantity,
+			Latitude:       transactionOrder.Latitude,
+			Longtitude:     transactionOrder.Longtitude,
+			DateTime:       fmt.Sprint(transactionOrder.DateTime),
+			Distance:       float32(transactionOrder.Distance),
+			TotalPrice:     transactionOrder.TotalPrice,
+			ShippingCost:   transactionOrder.ShippingCost,
+			VoucherCode:    transactionOrder.VoucherCode,
+			Discount:       transactionOrder.Discount,
+			Tax:            transactionOrder.Tax,
+			PaymentUrl:     transactionOrder.PaymentUrl,
+			PaymentMethod:  transactionOrder.PaymentMethod,
+			PaymentChannel: transactionOrder.PaymentChannel,
+			PaidAt:         fmt.Sprint(transactionOrder.PaidAt),
+			Status:         transactionOrder.Status,
+			Products:       productItems,
+			Taxes:          transaction.TaxResponses(transactionOrder.Taxes),
+		})
+	}
+
+	return c.JSON(http.StatusOK, common.SuccessResponse(response))
+}
@@ -0,0 +1,489 @@
+package cart_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/delivery/common"
+	"github.com/furqonzt99/snackbox/delivery/controllers/cart"
+	"github.com/furqonzt99/snackbox/delivery/controllers/user"
+	"github.com/furqonzt99/snackbox/helper"
+	"github.com/furqonzt99/snackbox/models"
+	"github.com/furqonzt99/snackbox/repositories/transaction"
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var JwtToken string
+
+func TestCart(t *testing.T) {
+	t.Run("Test Login", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = &user.UserValidator{Validator: validator.New()}
+
+		requestBody, _ := json.Marshal(map[string]string{
+			"email":    "test@gmail.com",
+			"password": "test1234",
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(requestBody))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		context := e.NewContext(req, res)
+		context.SetPath("/login")
+
+		userController := user.NewUsersControllers(mockUserRepository{})
+		userController.LoginController()(context)
+
+		response := common.ResponseSuccess{}
+		json.Unmarshal([]byte(res.Body.Bytes()), &response)
+
+		JwtToken = response.Data.(string)
+		assert.Equal(t, "Successful Operation", response.Message)
+		assert.NotNil(t, JwtToken)
+	})
+
+	t.Run("get cart success", func(t *testing.T) {
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/carts")
+
+		cartController := cart.NewCartController(mockCart{}, mockTransaction{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(cartController.GetCart)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Successful Operation", responses.Message)
+	})
+
+	t.Run("add item success", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = &cart.CartValidator{Validator: validator.New()}
+
+		bodyReq, _ := json.Marshal(cart.AddCartRequest{
+			ProductID: 1,
+			Quantity:  10,
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(bodyReq))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/carts")
+
+		cartController := cart.NewCartController(mockCart{}, mockTransaction{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(cartController.AddItem)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Successful Operation", responses.Message)
+	})
+
+	t.Run("add item from another partner", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = &cart.CartValidator{Validator: validator.New()}
+
+		bodyReq, _ := json.Marshal(cart.AddCartRequest{
+			ProductID: 2,
+			Quantity:  10,
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(bodyReq))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/carts")
+
+		cartController := cart.NewCartController(mockCart{}, mockTransaction{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(cartController.AddItem)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, http.StatusConflict, responses.Code)
+	})
+
+	t.Run("add item from another partner with split", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = &cart.CartValidator{Validator: validator.New()}
+
+		bodyReq, _ := json.Marshal(cart.AddCartRequest{
+			ProductID: 2,
+			Quantity:  10,
+			Split:     true,
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(bodyReq))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/carts")
+
+		cartController := cart.NewCartController(mockCart{}, mockTransaction{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(cartController.AddItem)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Successful Operation", responses.Message)
+	})
+
+	t.Run("add item product not found", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = &cart.CartValidator{Validator: validator.New()}
+
+		bodyReq, _ := json.Marshal(cart.AddCartRequest{
+			ProductID: 1,
+			Quantity:  10,
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(bodyReq))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/carts")
+
+		cartController := cart.NewCartController(mockFalseCart{}, mockTransaction{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(cartController.AddItem)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Not Found", responses.Message)
+	})
+
+	t.Run("update item bad request param", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = &cart.CartValidator{Validator: validator.New()}
+
+		req := httptest.NewRequest(http.MethodPut, "/", nil)
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/carts/:id")
+		context.SetParamNames("id")
+		context.SetParamValues("a")
+
+		cartController := cart.NewCartController(mockCart{}, mockTransaction{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(cartController.UpdateItem)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Bad Request", responses.Message)
+	})
+
+	t.Run("remove item not found", func(t *testing.T) {
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodDelete, "/", nil)
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/carts/:id")
+		context.SetParamNames("id")
+		context.SetParamValues("1")
+
+		cartController := cart.NewCartController(mockFalseCart{}, mockTransaction{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(cartController.RemoveItem)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Not Found", responses.Message)
+	})
+
+	t.Run("checkout success", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = &cart.CartValidator{Validator: validator.New()}
+
+		bodyReq, _ := json.Marshal(cart.CheckoutRequest{
+			Date:       time.Now().AddDate(0, 0, 7).Format("2006-01-02"),
+			Time:       "09:00:00",
+			Latitude:   100,
+			Longtitude: 100,
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(bodyReq))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/carts/checkout")
+
+		cartController := cart.NewCartController(mockCart{}, mockTransaction{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(cartController.Checkout)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Successful Operation", responses.Message)
+	})
+
+	t.Run("checkout empty cart", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = &cart.CartValidator{Validator: validator.New()}
+
+		bodyReq, _ := json.Marshal(cart.CheckoutRequest{
+			Date:       time.Now().AddDate(0, 0, 7).Format("2006-01-02"),
+			Time:       "09:00:00",
+			Latitude:   100,
+			Longtitude: 100,
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(bodyReq))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/carts/checkout")
+
+		cartController := cart.NewCartController(mockEmptyCart{}, mockTransaction{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(cartController.Checkout)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "cart is empty", responses.Message)
+	})
+
+	t.Run("checkout with an invalid voucher", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = &cart.CartValidator{Validator: validator.New()}
+
+		bodyReq, _ := json.Marshal(cart.CheckoutRequest{
+			Date:        time.Now().AddDate(0, 0, 7).Format("2006-01-02"),
+			Time:        "09:00:00",
+			Latitude:    100,
+			Longtitude:  100,
+			VoucherCode: "nope",
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(bodyReq))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/carts/checkout")
+
+		cartController := cart.NewCartController(mockCart{}, mockVoucherTransaction{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(cartController.Checkout)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+		assert.Equal(t, "invalid voucher: code does not exist", responses.Message)
+	})
+}
+
+//======================
+//MOCK CART REPOSITORY
+//======================
+type mockCart struct{}
+
+func (m mockCart) GetCart(userID int) ([]models.CartItem, error) {
+	return []models.CartItem{
+		{
+			UserID:    1,
+			PartnerID: 1,
+			ProductID: 1,
+			Quantity:  10,
+			Product: models.Product{
+				Title: "rendang",
+				Price: 1000,
+			},
+		},
+	}, nil
+}
+
+func (m mockCart) AddItem(item models.CartItem) (models.CartItem, error) {
+	return item, nil
+}
+
+func (m mockCart) UpdateItem(itemID, userID int, item models.CartItem) (models.CartItem, error) {
+	return item, nil
+}
+
+func (m mockCart) RemoveItem(itemID, userID int) error {
+	return nil
+}
+
+func (m mockCart) GetProduct(productID int) (models.Product, error) {
+	return models.Product{
+		PartnerID: uint(productID),
+		Title:     "rendang",
+		Price:     1000,
+	}, nil
+}
+
+//======================
+//MOCK FALSE CART REPOSITORY
+//======================
+type mockFalseCart struct{}
+
+func (m mockFalseCart) GetCart(userID int) ([]models.CartItem, error) {
+	return nil, errors.New("FAILED")
+}
+
+func (m mockFalseCart) AddItem(item models.CartItem) (models.CartItem, error) {
+	return item, errors.New("FAILED")
+}
+
+func (m mockFalseCart) UpdateItem(itemID, userID int, item models.CartItem) (models.CartItem, error) {
+	return item, errors.New("FAILED")
+}
+
+func (m mockFalseCart) RemoveItem(itemID, userID int) error {
+	return errors.New("FAILED")
+}
+
+func (m mockFalseCart) GetProduct(productID int) (models.Product, error) {
+	return models.Product{}, errors.New("FAILED")
+}
+
+//======================
+//MOCK EMPTY CART REPOSITORY
+//======================
+type mockEmptyCart struct {
+	mockCart
+}
+
+func (m mockEmptyCart) GetCart(userID int) ([]models.CartItem, error) {
+	return []models.CartItem{}, nil
+}
+
+//======================
+//MOCK TRANSACTION REPOSITORY
+//======================
+type mockTransaction struct {
+	transaction.TransactionInterface
+}
+
+func (m mockTransaction) Order(trx models.Transaction, email string, items []models.DetailTransaction) (models.Transaction, error) {
+	trx.DetailTransactions = items
+	return trx, nil
+}
+
+func (m mockTransaction) Checkout(orders []models.Transaction, email, voucherCode string, items [][]models.DetailTransaction) ([]models.Transaction, error) {
+	for i := range orders {
+		orders[i].DetailTransactions = items[i]
+	}
+	return orders, nil
+}
+
+type mockVoucherTransaction struct {
+	mockTransaction
+}
+
+func (m mockVoucherTransaction) Checkout(orders []models.Transaction, email, voucherCode string, items [][]models.DetailTransaction) ([]models.Transaction, error) {
+	return nil, fmt.Errorf("%w: code does not exist", helper.ErrInvalidVoucher)
+}
+
+func (m mockTransaction) GetDistance(partnerID int, latitude, longtitude float64) (float64, error) {
+	return 1, nil
+}
+
+//======================
+//MOCK USER REPOSITORY
+//======================
+type mockUserRepository struct{}
+
+func (m mockUserRepository) Register(newUser models.User) (models.User, error) {
+	return newUser, nil
+}
+
+func (m mockUserRepository) Login(email string) (models.User, error) {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("test1234"), 14)
+	return models.User{
+		Email:    "test@gmail.com",
+		Password: string(hash),
+	}, nil
+}
+
+func (m mockUserRepository) Get(userid int) (models.User, error) {
+	return models.User{
+		Email: "test@gmail.com",
+		Name:  "tester",
+	}, nil
+}
+
+func (m mockUserRepository) Update(newUser models.User, userId int) (models.User, error) {
+	return newUser, nil
+}
+
+func (m mockUserRepository) Delete(userId int) (models.User, error) {
+	return models.User{}, nil
+}
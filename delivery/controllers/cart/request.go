@@ -0,0 +1,44 @@
+package cart
+
+import (
+	"net/http"
+
+	"github.com/furqonzt99/snackbox/delivery/common"
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+type AddCartRequest struct {
+	ProductID int    `json:"product_id" validate:"required"`
+	Quantity  int    `json:"quantity" validate:"required,min=1"`
+	Notes     string `json:"notes"`
+	// Split allows the cart to hold products of several partners, checkout then creates one transaction per partner
+	Split bool `json:"split"`
+}
+
+type UpdateCartRequest struct {
+	Quantity int    `json:"quantity" validate:"required,min=1"`
+	Notes    string `json:"notes"`
+}
+
+type CheckoutRequest struct {
+	Buffet     bool    `json:"buffet"`
+	Date       string  `json:"date" validate:"required"`
+	Time       string  `json:"time" validate:"required"`
+	Latitude   float64 `json:"latitude" validate:"required"`
+	Longtitude float64 `json:"longtitude" validate:"required"`
+	// VoucherCode is redeemed on the first order of the checkout it can be used on
+	VoucherCode string `json:"voucher_code"`
+}
+
+type CartValidator struct {
+	Validator *validator.Validate
+}
+
+func (cv *CartValidator) Validate(i interface{}) error {
+	if err := cv.Validator.Struct(i); err != nil {
+		// Optionally, you could return the error to give each route more control over the status code
+		return echo.NewHTTPError(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	return nil
+}
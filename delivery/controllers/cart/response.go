@@ -0,0 +1,27 @@
+package cart
+
+import "github.com/furqonzt99/snackbox/models"
+
+type CartItemResponse struct {
+	ID        int          `json:"id"`
+	ProductID int          `json:"product_id"`
+	Title     string       `json:"title"`
+	Image     string       `json:"image"`
+	Type      string       `json:"type"`
+	Price     models.Money `json:"price"`
+	Quantity  int          `json:"quantity"`
+	Notes     string       `json:"notes"`
+	Subtotal  models.Money `json:"subtotal"`
+}
+
+type CartPartnerResponse struct {
+	PartnerID     int                `json:"partner_id"`
+	BussinessName string             `json:"bussiness_name"`
+	Subtotal      models.Money       `json:"subtotal"`
+	Items         []CartItemResponse `json:"items"`
+}
+
+type CartResponse struct {
+	Total    models.Money          `json:"total"`
+	Partners []CartPartnerResponse `json:"partners"`
+}
@@ -1,14 +1,20 @@
 package cashout
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 
+	"github.com/furqonzt99/snackbox/constants"
 	"github.com/furqonzt99/snackbox/delivery/common"
 	"github.com/furqonzt99/snackbox/delivery/middlewares"
 	"github.com/furqonzt99/snackbox/helper"
 	"github.com/furqonzt99/snackbox/models"
 	"github.com/furqonzt99/snackbox/repositories/cashout"
 	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
 )
 
 type CashoutController struct {
@@ -38,46 +44,41 @@ func (cc CashoutController) Cashout(c echo.Context) error {
 
 	}
 
-	if requestCashout.Amount > userData.Balance {
+	if err := helper.ValidateCashoutAmount(requestCashout.Amount); err != nil {
+		return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	// the fee is taken off the balance on top of the amount
+	total := requestCashout.Amount + models.Money(constants.CASHOUT_FEE)
+
+	if total > userData.Balance {
+		// earnings still on hold count towards the balance but cannot be cashed out yet
+		if total <= userData.Balance+userData.PendingBalance {
+			return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, fmt.Sprintf("only Rp%d is available for cashout, Rp%d is still on hold", userData.Balance, userData.PendingBalance)))
+		}
+
 		// return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
 		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
 
 	}
 
 	data := models.Cashout{
-		UserID:            uint(user.UserID),
-		BankCode:          requestCashout.BankCode,
-		AccountHolderName: requestCashout.AccountHolderName,
-		AccountNumber:     requestCashout.AccountNumber,
-		Amount:            requestCashout.Amount,
+		UserID:        uint(user.UserID),
+		BankAccountID: requestCashout.BankAccountID,
+		Amount:        requestCashout.Amount,
 	}
 
-	cashout, err := helper.PaymentCashout(data)
-	if err != nil {
-		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
-		// return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, "ERROR6"))
-
+	cashoutDB, err := cc.Repo.Cashout(data)
+	if errors.Is(err, helper.ErrInvalidCashout) {
+		return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, err.Error()))
 	}
-
-	cashoutDB, err := cc.Repo.Cashout(cashout)
 	if err != nil {
 		// return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, err.Error()))
 		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
 
 	}
 
-	response := CashoutResponse{
-		ID:                int(cashoutDB.ID),
-		UserID:            int(cashoutDB.UserID),
-		IdempotenceKey:    cashoutDB.IdempotenceKey,
-		ExternalID:        cashoutDB.ExternalID,
-		BankCode:          cashoutDB.BankCode,
-		AccountHolderName: cashoutDB.AccountHolderName,
-		AccountNumber:     cashoutDB.AccountNumber,
-		Description:       cashoutDB.Description,
-		Amount:            cashoutDB.Amount,
-		Status:            cashoutDB.Status,
-	}
+	response := newCashoutResponse(cashoutDB)
 
 	return c.JSON(http.StatusOK, common.SuccessResponse(response))
 }
@@ -94,31 +95,94 @@ func (cc CashoutController) History(c echo.Context) error {
 	response := []CashoutResponse{}
 
 	for _, cashout := range cashouts {
-		response = append(response, CashoutResponse{
-			ID:                int(cashout.ID),
-			UserID:            int(cashout.UserID),
-			IdempotenceKey:    cashout.IdempotenceKey,
-			ExternalID:        cashout.ExternalID,
-			BankCode:          cashout.BankCode,
-			AccountHolderName: cashout.AccountHolderName,
-			AccountNumber:     cashout.AccountNumber,
-			Description:       cashout.Description,
-			Amount:            cashout.Amount,
-			Status:            cashout.Status,
-		})
+		response = append(response, newCashoutResponse(cashout))
+	}
+
+	return c.JSON(http.StatusOK, common.SuccessResponse(response))
+}
+
+// Requests lists the cashouts waiting for an admin, or those with the status query param.
+func (cc CashoutController) Requests(c echo.Context) error {
+	status := c.QueryParam("status")
+	if status == "" {
+		status = constants.CASHOUT_REQUESTED
+	}
+
+	cashouts, err := cc.Repo.GetAll(status)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
+	}
+
+	response := []CashoutResponse{}
+	for _, cashout := range cashouts {
+		response = append(response, newCashoutResponse(cashout))
 	}
 
 	return c.JSON(http.StatusOK, common.SuccessResponse(response))
 }
 
-func (cc CashoutController) Callback(c echo.Context) error {
+func (cc CashoutController) Approve(c echo.Context) error {
+	cashoutID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	cashout, err := cc.Repo.Approve(cashoutID)
+	if errors.Is(err, helper.ErrInvalidCashout) {
+		return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
+	}
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, common.SuccessResponse(newCashoutResponse(cashout)))
+}
+
+func (cc CashoutController) Reject(c echo.Context) error {
+	cashoutID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	var rejectRequest RejectRequest
+
+	if err := c.Bind(&rejectRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	if err := c.Validate(&rejectRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	cashout, err := cc.Repo.Reject(cashoutID, rejectRequest.Note)
+	if errors.Is(err, helper.ErrInvalidCashout) {
+		return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	if err != nil {
+		return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
+	}
+
+	return c.JSON(http.StatusOK, common.SuccessResponse(newCashoutResponse(cashout)))
+}
+
+// HandleCallback applies a disbursement callback stored by the webhook controller.
+func (cc CashoutController) HandleCallback(body []byte) error {
 
 	var callbackRequest common.CashoutCallbackRequest
-	c.Bind(&callbackRequest)
+	if err := json.Unmarshal(body, &callbackRequest); err != nil {
+		return err
+	}
+
+	if callbackRequest.ExternalID == "" {
+		return common.ErrInvalidCallback
+	}
 
 	var data models.Cashout
 	data.ExternalID = callbackRequest.ExternalID
-	data.Amount = callbackRequest.Amount
+	data.Amount = models.NewMoney(callbackRequest.Amount)
 	data.Status = callbackRequest.Status
 
 	const STATUS_COMPLETED = "COMPLETED"
@@ -126,14 +190,11 @@ func (cc CashoutController) Callback(c echo.Context) error {
 	var err error
 
 	if callbackRequest.Status == STATUS_COMPLETED {
+		data.GatewayFee = models.NewMoney(callbackRequest.Fee)
 		_, err = cc.Repo.CallbackSuccess(callbackRequest.ExternalID, data)
 	} else {
 		_, err = cc.Repo.CallbackFailed(callbackRequest.ExternalID, data)
 	}
 
-	if err != nil {
-		return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
-	}
-
-	return c.JSON(http.StatusOK, common.NewSuccessOperationResponse())
+	return err
 }
@@ -15,6 +15,7 @@ import (
 	"github.com/furqonzt99/snackbox/delivery/common"
 	"github.com/furqonzt99/snackbox/delivery/controllers/cashout"
 	"github.com/furqonzt99/snackbox/delivery/controllers/user"
+	"github.com/furqonzt99/snackbox/helper"
 	"github.com/furqonzt99/snackbox/models"
 	"github.com/go-playground/validator/v10"
 	"github.com/joho/godotenv"
@@ -28,6 +29,10 @@ import (
 var JwtToken string
 
 func TestCashout(t *testing.T) {
+	constants.CASHOUT_MIN_AMOUNT = 1000
+	constants.CASHOUT_MAX_AMOUNT = 1000000
+	constants.CASHOUT_FEE = 0
+
 	t.Run("Test Login", func(t *testing.T) {
 		e := echo.New()
 		e.Validator = &user.UserValidator{Validator: validator.New()}
@@ -67,10 +72,8 @@ func TestCashout(t *testing.T) {
 		e.Validator = &cashout.CashoutValidator{Validator: validator.New()}
 		bodyReq, _ := json.Marshal(cashout.CashoutRequest{
 
-			BankCode:          "MANDIRI",
-			AccountHolderName: "test",
-			AccountNumber:     "1",
-			Amount:            1000,
+			BankAccountID: 1,
+			Amount:        1000,
 		})
 
 		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(bodyReq))
@@ -102,10 +105,8 @@ func TestCashout(t *testing.T) {
 		e.Validator = &cashout.CashoutValidator{Validator: validator.New()}
 		bodyReq, _ := json.Marshal(cashout.CashoutRequest{
 
-			BankCode:          "MANDIRI",
-			AccountHolderName: "test",
-			AccountNumber:     "1",
-			Amount:            1000,
+			BankAccountID: 1,
+			Amount:        1000,
 		})
 
 		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(bodyReq))
@@ -137,10 +138,7 @@ func TestCashout(t *testing.T) {
 		e.Validator = &cashout.CashoutValidator{Validator: validator.New()}
 		bodyReq, _ := json.Marshal(cashout.CashoutRequest{
 
-			// BankCode:          "MANDIRI",
-			AccountHolderName: "test",
-			AccountNumber:     "1",
-			Amount:            1000,
+			BankAccountID: 1,
 		})
 
 		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(bodyReq))
@@ -172,10 +170,8 @@ func TestCashout(t *testing.T) {
 		e.Validator = &cashout.CashoutValidator{Validator: validator.New()}
 		bodyReq, _ := json.Marshal(cashout.CashoutRequest{
 
-			BankCode:          "MANDIRI",
-			AccountHolderName: "test",
-			AccountNumber:     "1",
-			Amount:            1000,
+			BankAccountID: 1,
+			Amount:        1000,
 		})
 
 		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(bodyReq))
@@ -207,10 +203,8 @@ func TestCashout(t *testing.T) {
 		e.Validator = &cashout.CashoutValidator{Validator: validator.New()}
 		bodyReq, _ := json.Marshal(cashout.CashoutRequest{
 
-			BankCode:          "MANDIRI",
-			AccountHolderName: "test",
-			AccountNumber:     "1",
-			Amount:            1000,
+			BankAccountID: 1,
+			Amount:        1000,
 		})
 
 		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(bodyReq))
@@ -230,6 +224,40 @@ func TestCashout(t *testing.T) {
 		assert.Equal(t, "Bad Request", response.Message)
 	})
 
+	t.Run("cashout balance on hold", func(t *testing.T) {
+		err := godotenv.Load()
+
+		if err != nil {
+			log.Fatal("Error loading .env file")
+		}
+		xendit.Opt.SecretKey = os.Getenv("XENDIT_SECRET_KEY")
+
+		e := echo.New()
+		e.Validator = &cashout.CashoutValidator{Validator: validator.New()}
+		bodyReq, _ := json.Marshal(cashout.CashoutRequest{
+
+			BankAccountID: 1,
+			Amount:        1000,
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(bodyReq))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+		context := e.NewContext(req, res)
+		context.SetPath("/cashouts")
+
+		cashoutController := cashout.NewCashoutController(mockCashout4{})
+		middleware.JWT([]byte(constants.JWT_SECRET_KEY))(cashoutController.Cashout)(context)
+
+		response := common.ResponseSuccess{}
+		json.Unmarshal([]byte(res.Body.Bytes()), &response)
+
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+		assert.Equal(t, "only Rp500 is available for cashout, Rp1500 is still on hold", response.Message)
+	})
+
 	t.Run("cashout bad request 5", func(t *testing.T) {
 		err := godotenv.Load()
 
@@ -242,10 +270,8 @@ func TestCashout(t *testing.T) {
 		e.Validator = &cashout.CashoutValidator{Validator: validator.New()}
 		bodyReq, _ := json.Marshal(cashout.CashoutRequest{
 
-			BankCode:          "MANDIRI",
-			AccountHolderName: "test",
-			AccountNumber:     "1",
-			Amount:            1000,
+			BankAccountID: 1,
+			Amount:        1000,
 		})
 
 		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(bodyReq))
@@ -335,55 +361,153 @@ func TestHistory(t *testing.T) {
 	})
 }
 
-func TestCashoutCallback(t *testing.T) {
-
-	t.Run("test callback success", func(t *testing.T) {
+func TestCashoutApproval(t *testing.T) {
+	t.Run("test cashout requests", func(t *testing.T) {
 		e := echo.New()
 
-		bodyReq, _ := json.Marshal(common.CashoutCallbackRequest{
-			ExternalID: "1",
-			Status:     "COMPLETED",
-		})
-
-		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(bodyReq))
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
 		res := httptest.NewRecorder()
 
-		// req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+		context := e.NewContext(req, res)
+		context.SetPath("/cashouts/requests")
+
+		cashoutController := cashout.NewCashoutController(mockCashout{})
+		cashoutController.Requests(context)
+
+		response := common.ResponseSuccess{}
+		json.Unmarshal([]byte(res.Body.Bytes()), &response)
+
+		assert.Equal(t, "Successful Operation", response.Message)
+		assert.Equal(t, constants.CASHOUT_REQUESTED, response.Data.([]interface{})[0].(map[string]interface{})["status"])
+	})
+
+	t.Run("test approve cashout", func(t *testing.T) {
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodPut, "/", nil)
+		res := httptest.NewRecorder()
 
 		context := e.NewContext(req, res)
-		context.SetPath("/cashouts/callback")
+		context.SetPath("/cashouts/:id/approve")
+		context.SetParamNames("id")
+		context.SetParamValues("1")
 
 		cashoutController := cashout.NewCashoutController(mockCashout{})
-		cashoutController.Callback(context)
+		cashoutController.Approve(context)
 
 		response := common.ResponseSuccess{}
 		json.Unmarshal([]byte(res.Body.Bytes()), &response)
 
 		assert.Equal(t, "Successful Operation", response.Message)
+		assert.Equal(t, constants.CASHOUT_PENDING, response.Data.(map[string]interface{})["status"])
 	})
 
-	t.Run("test callback failed", func(t *testing.T) {
+	t.Run("test approve cashout already sent", func(t *testing.T) {
 		e := echo.New()
 
-		bodyReq, _ := json.Marshal(common.CashoutCallbackRequest{
-			Status: "COMPLETED",
+		req := httptest.NewRequest(http.MethodPut, "/", nil)
+		res := httptest.NewRecorder()
+
+		context := e.NewContext(req, res)
+		context.SetPath("/cashouts/:id/approve")
+		context.SetParamNames("id")
+		context.SetParamValues("1")
+
+		cashoutController := cashout.NewCashoutController(mockFalseCashout{})
+		cashoutController.Approve(context)
+
+		response := common.ResponseSuccess{}
+		json.Unmarshal([]byte(res.Body.Bytes()), &response)
+
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+		assert.Equal(t, "invalid cashout: only a requested cashout can be approved", response.Message)
+	})
+
+	t.Run("test reject cashout", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = &cashout.CashoutValidator{Validator: validator.New()}
+		bodyReq, _ := json.Marshal(cashout.RejectRequest{
+			Note: "the account holder does not match",
 		})
 
-		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(bodyReq))
+		req := httptest.NewRequest(http.MethodPut, "/", bytes.NewBuffer(bodyReq))
 		res := httptest.NewRecorder()
 
-		// req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+		req.Header.Set("Content-Type", "application/json")
+		context := e.NewContext(req, res)
+		context.SetPath("/cashouts/:id/reject")
+		context.SetParamNames("id")
+		context.SetParamValues("1")
 
+		cashoutController := cashout.NewCashoutController(mockCashout{})
+		cashoutController.Reject(context)
+
+		response := common.ResponseSuccess{}
+		json.Unmarshal([]byte(res.Body.Bytes()), &response)
+
+		assert.Equal(t, "Successful Operation", response.Message)
+		assert.Equal(t, constants.CASHOUT_REJECTED, response.Data.(map[string]interface{})["status"])
+	})
+
+	t.Run("test reject cashout without note", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = &cashout.CashoutValidator{Validator: validator.New()}
+		bodyReq, _ := json.Marshal(cashout.RejectRequest{})
+
+		req := httptest.NewRequest(http.MethodPut, "/", bytes.NewBuffer(bodyReq))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
 		context := e.NewContext(req, res)
-		context.SetPath("/cashouts/callback")
+		context.SetPath("/cashouts/:id/reject")
+		context.SetParamNames("id")
+		context.SetParamValues("1")
 
-		cashoutController := cashout.NewCashoutController(mockCashout3{})
-		cashoutController.Callback(context)
+		cashoutController := cashout.NewCashoutController(mockCashout{})
+		cashoutController.Reject(context)
 
 		response := common.ResponseSuccess{}
 		json.Unmarshal([]byte(res.Body.Bytes()), &response)
 
-		assert.Equal(t, "Not Found", response.Message)
+		assert.Equal(t, "Bad Request", response.Message)
+	})
+}
+
+func TestCashoutCallback(t *testing.T) {
+
+	t.Run("test callback success", func(t *testing.T) {
+		bodyReq, _ := json.Marshal(common.CashoutCallbackRequest{
+			ExternalID: "1",
+			Status:     "COMPLETED",
+		})
+
+		cashoutController := cashout.NewCashoutController(mockCashout{})
+		err := cashoutController.HandleCallback(bodyReq)
+
+		assert.Nil(t, err)
+	})
+
+	t.Run("test callback failed", func(t *testing.T) {
+		bodyReq, _ := json.Marshal(common.CashoutCallbackRequest{
+			Status: "COMPLETED",
+		})
+
+		cashoutController := cashout.NewCashoutController(mockCashout3{})
+		err := cashoutController.HandleCallback(bodyReq)
+
+		assert.Equal(t, common.ErrInvalidCallback, err)
+	})
+
+	t.Run("test callback not found", func(t *testing.T) {
+		bodyReq, _ := json.Marshal(common.CashoutCallbackRequest{
+			ExternalID: "1",
+			Status:     "FAILED",
+		})
+
+		cashoutController := cashout.NewCashoutController(mockCashout3{})
+		err := cashoutController.HandleCallback(bodyReq)
+
+		assert.NotNil(t, err)
 	})
 }
 
@@ -426,6 +550,31 @@ func (m mockCashout) CallbackFailed(extID string, cashout models.Cashout) (model
 	}, nil
 }
 
+func (m mockCashout) GetAll(status string) ([]models.Cashout, error) {
+	return []models.Cashout{
+		{
+			UserID: 1,
+			Amount: 6000000,
+			Status: status,
+		},
+	}, nil
+}
+
+func (m mockCashout) Approve(cashoutID int) (models.Cashout, error) {
+	return models.Cashout{
+		UserID: 1,
+		Status: constants.CASHOUT_PENDING,
+	}, nil
+}
+
+func (m mockCashout) Reject(cashoutID int, note string) (models.Cashout, error) {
+	return models.Cashout{
+		UserID: 1,
+		Status: constants.CASHOUT_REJECTED,
+		Note:   note,
+	}, nil
+}
+
 //==========================
 //MOCK CASHOUT2
 //==========================
@@ -464,6 +613,31 @@ func (m mockCashout2) CallbackFailed(extID string, cashout models.Cashout) (mode
 	}, nil
 }
 
+func (m mockCashout2) GetAll(status string) ([]models.Cashout, error) {
+	return []models.Cashout{
+		{
+			UserID: 1,
+			Amount: 6000000,
+			Status: status,
+		},
+	}, nil
+}
+
+func (m mockCashout2) Approve(cashoutID int) (models.Cashout, error) {
+	return models.Cashout{
+		UserID: 1,
+		Status: constants.CASHOUT_PENDING,
+	}, nil
+}
+
+func (m mockCashout2) Reject(cashoutID int, note string) (models.Cashout, error) {
+	return models.Cashout{
+		UserID: 1,
+		Status: constants.CASHOUT_REJECTED,
+		Note:   note,
+	}, nil
+}
+
 //==========================
 //MOCK CASHOUT 3
 //==========================
@@ -504,6 +678,95 @@ func (m mockCashout3) CallbackFailed(extID string, cashout models.Cashout) (mode
 	}, errors.New("FAILED")
 }
 
+func (m mockCashout3) GetAll(status string) ([]models.Cashout, error) {
+	return []models.Cashout{
+		{
+			UserID: 1,
+			Amount: 6000000,
+			Status: status,
+		},
+	}, nil
+}
+
+func (m mockCashout3) Approve(cashoutID int) (models.Cashout, error) {
+	return models.Cashout{
+		UserID: 1,
+		Status: constants.CASHOUT_PENDING,
+	}, nil
+}
+
+func (m mockCashout3) Reject(cashoutID int, note string) (models.Cashout, error) {
+	return models.Cashout{
+		UserID: 1,
+		Status: constants.CASHOUT_REJECTED,
+		Note:   note,
+	}, nil
+}
+
+//==========================
+//MOCK CASHOUT 4
+//==========================
+type mockCashout4 struct{}
+
+func (m mockCashout4) Cashout(cashout models.Cashout) (models.Cashout, error) {
+	return models.Cashout{
+		UserID: 1,
+	}, nil
+}
+
+func (m mockCashout4) History(userID int) ([]models.Cashout, error) {
+	return []models.Cashout{
+		{
+			UserID: 1,
+		},
+	}, nil
+}
+
+func (m mockCashout4) CheckBalance(userID int) (models.User, error) {
+	return models.User{
+		Email:          "test@gmail.com",
+		Balance:        500,
+		PendingBalance: 1500,
+	}, nil
+}
+
+func (m mockCashout4) CallbackSuccess(extID string, cashout models.Cashout) (models.Cashout, error) {
+	return models.Cashout{
+		UserID: 1,
+	}, nil
+}
+
+func (m mockCashout4) CallbackFailed(extID string, cashout models.Cashout) (models.Cashout, error) {
+	return models.Cashout{
+		UserID: 1,
+	}, nil
+}
+
+func (m mockCashout4) GetAll(status string) ([]models.Cashout, error) {
+	return []models.Cashout{
+		{
+			UserID: 1,
+			Amount: 6000000,
+			Status: status,
+		},
+	}, nil
+}
+
+func (m mockCashout4) Approve(cashoutID int) (models.Cashout, error) {
+	return models.Cashout{
+		UserID: 1,
+		Status: constants.CASHOUT_PENDING,
+	}, nil
+}
+
+func (m mockCashout4) Reject(cashoutID int, note string) (models.Cashout, error) {
+	return models.Cashout{
+		UserID: 1,
+		Status: constants.CASHOUT_REJECTED,
+		Note:   note,
+	}, nil
+}
+
 //==========================
 //MOCK FALSE CASHOUT
 //==========================
@@ -542,6 +805,18 @@ func (m mockFalseCashout) CallbackFailed(extID string, cashout models.Cashout) (
 	}, nil
 }
 
+func (m mockFalseCashout) GetAll(status string) ([]models.Cashout, error) {
+	return nil, errors.New("FAILED")
+}
+
+func (m mockFalseCashout) Approve(cashoutID int) (models.Cashout, error) {
+	return models.Cashout{}, helper.CashoutError("only a requested cashout can be approved")
+}
+
+func (m mockFalseCashout) Reject(cashoutID int, note string) (models.Cashout, error) {
+	return models.Cashout{}, errors.New("FAILED")
+}
+
 //==========================
 //MOCK FALSE CASHOUT2
 //==========================
@@ -580,6 +855,18 @@ func (m mockFalseCashout2) CallbackFailed(extID string, cashout models.Cashout)
 	}, nil
 }
 
+func (m mockFalseCashout2) GetAll(status string) ([]models.Cashout, error) {
+	return nil, errors.New("FAILED")
+}
+
+func (m mockFalseCashout2) Approve(cashoutID int) (models.Cashout, error) {
+	return models.Cashout{}, helper.CashoutError("only a requested cashout can be approved")
+}
+
+func (m mockFalseCashout2) Reject(cashoutID int, note string) (models.Cashout, error) {
+	return models.Cashout{}, errors.New("FAILED")
+}
+
 //======================
 //MOCK USER REPOSITORY
 //======================
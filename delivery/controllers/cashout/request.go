@@ -3,15 +3,20 @@ package cashout
 import (
 	"net/http"
 
+	"github.com/furqonzt99/snackbox/models"
 	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
 )
 
+// CashoutRequest pays out to one of the user's saved bank accounts, the default one when
+// BankAccountID is left out.
 type CashoutRequest struct {
-	BankCode string `json:"bank_code" validate:"required"`
-	AccountHolderName string `json:"account_holder_name" validate:"required"`
-	AccountNumber string `json:"account_number" validate:"required"`
-	Amount float64 `json:"amount" validate:"required"`
+	BankAccountID uint `json:"bank_account_id"`
+	Amount models.Money `json:"amount" validate:"required"`
+}
+
+type RejectRequest struct {
+	Note string `json:"note" validate:"required"`
 }
 
 type CashoutValidator struct {
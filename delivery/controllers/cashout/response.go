@@ -1,14 +1,37 @@
 package cashout
 
+import "github.com/furqonzt99/snackbox/models"
+
 type CashoutResponse struct {
 	ID int `json:"id"`
 	UserID int `json:"user_id"`
+	BankAccountID uint `json:"bank_account_id"`
 	IdempotenceKey string `json:"idempotence_key"`
 	ExternalID string `json:"external_id"`
 	BankCode string `json:"bank_code"`
 	AccountHolderName string `json:"account_holder_name"`
 	AccountNumber string `json:"account_number"`
 	Description string `json:"description"`
-	Amount float64 `json:"amount"`
+	Amount models.Money `json:"amount"`
+	Fee models.Money `json:"fee"`
 	Status string `json:"status"`
+	Note string `json:"note"`
+}
+
+func newCashoutResponse(cashout models.Cashout) CashoutResponse {
+	return CashoutResponse{
+		ID:                int(cashout.ID),
+		UserID:            int(cashout.UserID),
+		BankAccountID:     cashout.BankAccountID,
+		IdempotenceKey:    cashout.IdempotenceKey,
+		ExternalID:        cashout.ExternalID,
+		BankCode:          cashout.BankCode,
+		AccountHolderName: cashout.AccountHolderName,
+		AccountNumber:     cashout.AccountNumber,
+		Description:       cashout.Description,
+		Amount:            cashout.Amount,
+		Fee:               cashout.Fee,
+		Status:            cashout.Status,
+		Note:              cashout.Note,
+	}
 }
\ No newline at end of file
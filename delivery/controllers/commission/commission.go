@@ -0,0 +1,181 @@
+package commission
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/delivery/common"
+	"github.com/furqonzt99/snackbox/delivery/middlewares"
+	"github.com/furqonzt99/snackbox/helper"
+	"github.com/furqonzt99/snackbox/models"
+	"github.com/furqonzt99/snackbox/repositories/commission"
+	"github.com/labstack/echo/v4"
+)
+
+type CommissionController struct {
+	Repo commission.CommissionInterface
+}
+
+func NewCommissionController(commission commission.CommissionInterface) *CommissionController {
+	return &CommissionController{Repo: commission}
+}
+
+func (cc CommissionController) Create(c echo.Context) error {
+	var ruleRequest CommissionRuleRequest
+
+	if err := c.Bind(&ruleRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	if err := c.Validate(&ruleRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	rule, err := cc.Repo.Create(models.CommissionRule{
+		PartnerID: ruleRequest.PartnerID,
+		Category:  ruleRequest.Category,
+		Rate:      ruleRequest.Rate,
+	})
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, "a rule for this partner and category already exists"))
+	}
+
+	return c.JSON(http.StatusOK, common.SuccessResponse(newCommissionRuleResponse(rule)))
+}
+
+func (cc CommissionController) GetAll(c echo.Context) error {
+	rules, err := cc.Repo.GetAll()
+	if err != nil {
+		return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
+	}
+
+	response := []CommissionRuleResponse{}
+	for _, rule := range rules {
+		response = append(response, newCommissionRuleResponse(rule))
+	}
+
+	return c.JSON(http.StatusOK, common.SuccessResponse(response))
+}
+
+func (cc CommissionController) Update(c echo.Context) error {
+	ruleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	var rateRequest RateRequest
+
+	if err := c.Bind(&rateRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	if err := c.Validate(&rateRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	rule, err := cc.Repo.Update(ruleID, models.CommissionRule{Rate: rateRequest.Rate})
+	if err != nil {
+		return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
+	}
+
+	return c.JSON(http.StatusOK, common.SuccessResponse(newCommissionRuleResponse(rule)))
+}
+
+func (cc CommissionController) Delete(c echo.Context) error {
+	ruleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	if err := cc.Repo.Delete(ruleID); err != nil {
+		return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
+	}
+
+	return c.JSON(http.StatusOK, common.NewSuccessOperationResponse())
+}
+
+// GetFees pages through the fees of every partner, or of one with the partner_id query param.
+func (cc CommissionController) GetFees(c echo.Context) error {
+	partnerID, _ := strconv.Atoi(c.QueryParam("partner_id"))
+
+	return cc.fees(c, partnerID)
+}
+
+// GetPartnerFees pages through the fees of the signed in partner's orders.
+func (cc CommissionController) GetPartnerFees(c echo.Context) error {
+	user, _ := middlewares.ExtractTokenUser(c)
+
+	return cc.fees(c, user.PartnerID)
+}
+
+func (cc CommissionController) fees(c echo.Context, partnerID int) error {
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	perpage, _ := strconv.Atoi(c.QueryParam("perpage"))
+
+	if page == 0 {
+		page = 1
+	}
+
+	if perpage == 0 {
+		perpage = 10
+	}
+
+	fees, err := cc.Repo.GetFees(partnerID, (page-1)*perpage, perpage)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
+	}
+
+	response := []FeeResponse{}
+	for _, fee := range fees {
+		response = append(response, newFeeResponse(fee))
+	}
+
+	return c.JSON(http.StatusOK, common.PaginationResponse(page, perpage, response))
+}
+
+// Report sums the commission collected from the from date to the to date, both inclusive
+// and this month by default, grouped by the period query param, day or month.
+func (cc CommissionController) Report(c echo.Context) error {
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	to := now
+
+	var err error
+
+	if c.QueryParam("from") != "" {
+		if from, err = time.ParseInLocation(helper.CalendarDateFormat, c.QueryParam("from"), now.Location()); err != nil {
+			return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, "from must be a date like 2006-01-02"))
+		}
+	}
+
+	if c.QueryParam("to") != "" {
+		if to, err = time.ParseInLocation(helper.CalendarDateFormat, c.QueryParam("to"), now.Location()); err != nil {
+			return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, "to must be a date like 2006-01-02"))
+		}
+	}
+
+	to = time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, to.Location()).AddDate(0, 0, 1)
+
+	period := c.QueryParam("period")
+	switch period {
+	case "":
+		period = constants.MONTHLY_PERIOD
+	case constants.DAILY_PERIOD, constants.MONTHLY_PERIOD:
+	default:
+		return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, "period must be day or month"))
+	}
+
+	rows, err := cc.Repo.Report(from, to, period)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
+	}
+
+	response := []RevenueResponse{}
+	for _, row := range rows {
+		response = append(response, newRevenueResponse(row))
+	}
+
+	return c.JSON(http.StatusOK, common.SuccessResponse(response))
+}
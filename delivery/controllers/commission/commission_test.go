@@ -0,0 +1,392 @@
+package commission_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/delivery/common"
+	"github.com/furqonzt99/snackbox/delivery/controllers/commission"
+	"github.com/furqonzt99/snackbox/delivery/controllers/user"
+	"github.com/furqonzt99/snackbox/models"
+	commissionRepo "github.com/furqonzt99/snackbox/repositories/commission"
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+var JwtToken string
+
+func TestCommission(t *testing.T) {
+	t.Run("Test Login", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = &user.UserValidator{Validator: validator.New()}
+
+		requestBody, _ := json.Marshal(map[string]string{
+			"email":    "test@gmail.com",
+			"password": "test1234",
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(requestBody))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		context := e.NewContext(req, res)
+		context.SetPath("/login")
+
+		userController := user.NewUsersControllers(mockUserRepository{})
+		userController.LoginController()(context)
+
+		response := common.ResponseSuccess{}
+		json.Unmarshal([]byte(res.Body.Bytes()), &response)
+
+		JwtToken = response.Data.(string)
+		assert.Equal(t, "Successful Operation", response.Message)
+		assert.NotNil(t, JwtToken)
+	})
+
+	t.Run("create rule", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = &commission.CommissionValidator{Validator: validator.New()}
+
+		requestBody, _ := json.Marshal(commission.CommissionRuleRequest{
+			Category: constants.SHIPPING_COMMISSION,
+			Rate:     2.5,
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(requestBody))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+
+		context := e.NewContext(req, res)
+		context.SetPath("/commissions")
+
+		commissionController := commission.NewCommissionController(mockCommission{})
+		commissionController.Create(context)
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Successful Operation", responses.Message)
+		assert.Equal(t, 2.5, responses.Data.(map[string]interface{})["rate"])
+	})
+
+	t.Run("create rule above 100 percent", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = &commission.CommissionValidator{Validator: validator.New()}
+
+		requestBody, _ := json.Marshal(commission.CommissionRuleRequest{
+			Rate: 120,
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(requestBody))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+
+		context := e.NewContext(req, res)
+		context.SetPath("/commissions")
+
+		commissionController := commission.NewCommissionController(mockCommission{})
+		commissionController.Create(context)
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Bad Request", responses.Message)
+	})
+
+	t.Run("create rule for an existing scope", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = &commission.CommissionValidator{Validator: validator.New()}
+
+		requestBody, _ := json.Marshal(commission.CommissionRuleRequest{
+			Rate: 10,
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(requestBody))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+
+		context := e.NewContext(req, res)
+		context.SetPath("/commissions")
+
+		commissionController := commission.NewCommissionController(mockFalseCommission{})
+		commissionController.Create(context)
+
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+	})
+
+	t.Run("get all rules", func(t *testing.T) {
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		res := httptest.NewRecorder()
+
+		context := e.NewContext(req, res)
+		context.SetPath("/commissions")
+
+		commissionController := commission.NewCommissionController(mockCommission{})
+		commissionController.GetAll(context)
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Successful Operation", responses.Message)
+		assert.Equal(t, 2, len(responses.Data.([]interface{})))
+	})
+
+	t.Run("update rule", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = &commission.CommissionValidator{Validator: validator.New()}
+
+		requestBody, _ := json.Marshal(commission.RateRequest{
+			Rate: 7,
+		})
+
+		req := httptest.NewRequest(http.MethodPut, "/", bytes.NewBuffer(requestBody))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+
+		context := e.NewContext(req, res)
+		context.SetPath("/commissions/:id")
+		context.SetParamNames("id")
+		context.SetParamValues("1")
+
+		commissionController := commission.NewCommissionController(mockCommission{})
+		commissionController.Update(context)
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Successful Operation", responses.Message)
+		assert.Equal(t, float64(7), responses.Data.(map[string]interface{})["rate"])
+	})
+
+	t.Run("delete rule not found", func(t *testing.T) {
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodDelete, "/", nil)
+		res := httptest.NewRecorder()
+
+		context := e.NewContext(req, res)
+		context.SetPath("/commissions/:id")
+		context.SetParamNames("id")
+		context.SetParamValues("9")
+
+		commissionController := commission.NewCommissionController(mockFalseCommission{})
+		commissionController.Delete(context)
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Not Found", responses.Message)
+	})
+
+	t.Run("get fees", func(t *testing.T) {
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodGet, "/?partner_id=1", nil)
+		res := httptest.NewRecorder()
+
+		context := e.NewContext(req, res)
+		context.SetPath("/commissions/fees")
+
+		commissionController := commission.NewCommissionController(mockCommission{})
+		commissionController.GetFees(context)
+
+		var responses common.ResponsePagination
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Successful Operation", responses.Message)
+
+		fee := responses.Data.([]interface{})[0].(map[string]interface{})
+		assert.Equal(t, float64(1), fee["partner_id"])
+		assert.Equal(t, float64(4000), fee["commission"])
+		assert.Equal(t, float64(36000), fee["net"])
+	})
+
+	t.Run("get partner fees", func(t *testing.T) {
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/partners/fees")
+
+		commissionController := commission.NewCommissionController(mockCommission{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(commissionController.GetPartnerFees)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		var responses common.ResponsePagination
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Successful Operation", responses.Message)
+		assert.Equal(t, 1, len(responses.Data.([]interface{})))
+	})
+
+	t.Run("revenue report", func(t *testing.T) {
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodGet, "/?from=2026-01-01&to=2026-02-28&period=month", nil)
+		res := httptest.NewRecorder()
+
+		context := e.NewContext(req, res)
+		context.SetPath("/commissions/report")
+
+		commissionController := commission.NewCommissionController(mockCommission{})
+		commissionController.Report(context)
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Successful Operation", responses.Message)
+		assert.Equal(t, 2, len(responses.Data.([]interface{})))
+		assert.Equal(t, "2026-01", responses.Data.([]interface{})[0].(map[string]interface{})["period"])
+	})
+
+	t.Run("revenue report with an invalid period", func(t *testing.T) {
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodGet, "/?period=week", nil)
+		res := httptest.NewRecorder()
+
+		context := e.NewContext(req, res)
+		context.SetPath("/commissions/report")
+
+		commissionController := commission.NewCommissionController(mockCommission{})
+		commissionController.Report(context)
+
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+	})
+
+	t.Run("revenue report with an invalid date", func(t *testing.T) {
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodGet, "/?from=01-01-2026", nil)
+		res := httptest.NewRecorder()
+
+		context := e.NewContext(req, res)
+		context.SetPath("/commissions/report")
+
+		commissionController := commission.NewCommissionController(mockCommission{})
+		commissionController.Report(context)
+
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+	})
+}
+
+// ======================
+// MOCK COMMISSION REPOSITORY
+// ======================
+type mockCommission struct{}
+
+func (m mockCommission) Create(rule models.CommissionRule) (models.CommissionRule, error) {
+	rule.ID = 1
+	return rule, nil
+}
+
+func (m mockCommission) GetAll() ([]models.CommissionRule, error) {
+	return []models.CommissionRule{
+		{Model: gorm.Model{ID: 1}, Rate: 10},
+		{Model: gorm.Model{ID: 2}, PartnerID: 1, Category: "snack", Rate: 5},
+	}, nil
+}
+
+func (m mockCommission) Update(ruleID int, rule models.CommissionRule) (models.CommissionRule, error) {
+	rule.ID = uint(ruleID)
+	return rule, nil
+}
+
+func (m mockCommission) Delete(ruleID int) error {
+	return nil
+}
+
+func (m mockCommission) GetFees(partnerID, offset, limit int) ([]models.TransactionFee, error) {
+	return []models.TransactionFee{
+		{TransactionID: 1, PartnerID: 1, ProductAmount: 40000, Gross: 40000, ProductCommission: 4000, Commission: 4000, Net: 36000},
+	}, nil
+}
+
+func (m mockCommission) Report(from, to time.Time, period string) ([]commissionRepo.RevenueRow, error) {
+	return []commissionRepo.RevenueRow{
+		{Period: "2026-01", Transactions: 3, Gross: 120000, Commission: 12000, Net: 108000},
+		{Period: "2026-02", Transactions: 1, Gross: 40000, Commission: 4000, Net: 36000},
+	}, nil
+}
+
+type mockFalseCommission struct{}
+
+func (m mockFalseCommission) Create(rule models.CommissionRule) (models.CommissionRule, error) {
+	return rule, errors.New("FAILED")
+}
+
+func (m mockFalseCommission) GetAll() ([]models.CommissionRule, error) {
+	return nil, errors.New("FAILED")
+}
+
+func (m mockFalseCommission) Update(ruleID int, rule models.CommissionRule) (models.CommissionRule, error) {
+	return rule, errors.New("FAILED")
+}
+
+func (m mockFalseCommission) Delete(ruleID int) error {
+	return errors.New("FAILED")
+}
+
+func (m mockFalseCommission) GetFees(partnerID, offset, limit int) ([]models.TransactionFee, error) {
+	return nil, errors.New("FAILED")
+}
+
+func (m mockFalseCommission) Report(from, to time.Time, period string) ([]commissionRepo.RevenueRow, error) {
+	return nil, errors.New("FAILED")
+}
+
+// ======================
+// MOCK USER REPOSITORY
+// ======================
+type mockUserRepository struct{}
+
+func (m mockUserRepository) Register(newUser models.User) (models.User, error) {
+	return newUser, nil
+}
+
+func (m mockUserRepository) Login(email string) (models.User, error) {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("test1234"), 14)
+	return models.User{
+		Email:    "test@gmail.com",
+		Password: string(hash),
+	}, nil
+}
+
+func (m mockUserRepository) Get(userid int) (models.User, error) {
+	return models.User{
+		Email: "test@gmail.com",
+		Name:  "tester",
+	}, nil
+}
+
+func (m mockUserRepository) Update(newUser models.User, userId int) (models.User, error) {
+	return newUser, nil
+}
+
+func (m mockUserRepository) Delete(userId int) (models.User, error) {
+	return models.User{}, nil
+}
@@ -0,0 +1,33 @@
+package commission
+
+import (
+	"net/http"
+
+	"github.com/furqonzt99/snackbox/delivery/common"
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+// CommissionRuleRequest creates a rule. Leave PartnerID at 0 for every partner and Category
+// empty for every product category; use the SHIPPING category for the shipping cost.
+type CommissionRuleRequest struct {
+	PartnerID uint    `json:"partner_id"`
+	Category  string  `json:"category" validate:"max=32"`
+	Rate      float64 `json:"rate" validate:"min=0,max=100"`
+}
+
+type RateRequest struct {
+	Rate float64 `json:"rate" validate:"min=0,max=100"`
+}
+
+type CommissionValidator struct {
+	Validator *validator.Validate
+}
+
+func (cv *CommissionValidator) Validate(i interface{}) error {
+	if err := cv.Validator.Struct(i); err != nil {
+		// Optionally, you could return the error to give each route more control over the status code
+		return echo.NewHTTPError(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	return nil
+}
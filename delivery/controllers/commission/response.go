@@ -0,0 +1,80 @@
+package commission
+
+import (
+	"time"
+
+	"github.com/furqonzt99/snackbox/models"
+	"github.com/furqonzt99/snackbox/repositories/commission"
+)
+
+type CommissionRuleResponse struct {
+	ID        uint    `json:"id"`
+	PartnerID uint    `json:"partner_id"`
+	Category  string  `json:"category"`
+	Rate      float64 `json:"rate"`
+}
+
+type FeeResponse struct {
+	TransactionID      uint         `json:"transaction_id"`
+	InvoiceID          string       `json:"invoice_id"`
+	PartnerID          uint         `json:"partner_id"`
+	ProductAmount      models.Money `json:"product_amount"`
+	ShippingAmount     models.Money `json:"shipping_amount"`
+	Discount           models.Money `json:"discount"`
+	Tax                models.Money `json:"tax"`
+	Gross              models.Money `json:"gross"`
+	Refunded           models.Money `json:"refunded"`
+	ProductCommission  models.Money `json:"product_commission"`
+	ShippingCommission models.Money `json:"shipping_commission"`
+	Commission         models.Money `json:"commission"`
+	Net                models.Money `json:"net"`
+	CreatedAt          time.Time    `json:"created_at"`
+}
+
+type RevenueResponse struct {
+	Period       string       `json:"period"`
+	Transactions int          `json:"transactions"`
+	Gross        models.Money `json:"gross"`
+	Refunded     models.Money `json:"refunded"`
+	Commission   models.Money `json:"commission"`
+	Net          models.Money `json:"net"`
+}
+
+func newCommissionRuleResponse(rule models.CommissionRule) CommissionRuleResponse {
+	return CommissionRuleResponse{
+		ID:        rule.ID,
+		PartnerID: rule.PartnerID,
+		Category:  rule.Category,
+		Rate:      rule.Rate,
+	}
+}
+
+func newFeeResponse(fee models.TransactionFee) FeeResponse {
+	return FeeResponse{
+		TransactionID:      fee.TransactionID,
+		InvoiceID:          fee.Transaction.InvoiceID,
+		PartnerID:          fee.PartnerID,
+		ProductAmount:      fee.ProductAmount,
+		ShippingAmount:     fee.ShippingAmount,
+		Discount:           fee.Discount,
+		Tax:                fee.Tax,
+		Gross:              fee.Gross,
+		Refunded:           fee.Refunded,
+		ProductCommission:  fee.ProductCommission,
+		ShippingCommission: fee.ShippingCommission,
+		Commission:         fee.Commission,
+		Net:                fee.Net,
+		CreatedAt:          fee.CreatedAt,
+	}
+}
+
+func newRevenueResponse(row commission.RevenueRow) RevenueResponse {
+	return RevenueResponse{
+		Period:       row.Period,
+		Transactions: row.Transactions,
+		Gross:        row.Gross,
+		Refunded:     row.Refunded,
+		Commission:   row.Commission,
+		Net:          row.Net,
+	}
+}
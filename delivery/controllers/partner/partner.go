@@ -6,6 +6,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/furqonzt99/snackbox/constants"
 	"github.com/furqonzt99/snackbox/delivery/common"
@@ -232,6 +233,7 @@ func (p PartnerController) GetPartnerProduct() echo.HandlerFunc {
 			Address:       partner.Address,
 			City:          partner.City,
 			Rating:        helper.CalculateRating(partner.Ratings),
+			OrderRules:    NewOrderRulesResponse(partner),
 			Products:      productItems,
 		}
 
@@ -348,11 +350,25 @@ func (pc PartnerController) Upload(c echo.Context) error {
 func (p PartnerController) Report() echo.HandlerFunc {
 	return func(c echo.Context) error {
 
+		period := c.QueryParam("period")
+		switch period {
+		case "":
+			period = constants.MONTHLY_PERIOD
+		case constants.DAILY_PERIOD, constants.MONTHLY_PERIOD:
+		default:
+			return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, "period must be day or month"))
+		}
+
 		userJwt, _ := middlewares.ExtractTokenUser(c)
 		transactions, err := p.Repo.Report(userJwt.PartnerID)
 		if err != nil {
 			return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
 		}
+
+		taxes, err := p.Repo.TaxReport(userJwt.PartnerID, period)
+		if err != nil {
+			return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
+		}
 		////////////////////////////////////////
 
 		contents := [][]string{}
@@ -363,18 +379,20 @@ func (p PartnerController) Report() echo.HandlerFunc {
 			date := fmt.Sprint(transactions[i].CreatedAt)
 			invoice := transactions[i].InvoiceID
 			totalPrice := ac.FormatMoney(transactions[i].TotalPrice)
+			tax := ac.FormatMoney(transactions[i].Tax)
 			quantity := strconv.Itoa(transactions[i].Quantity)
 			paymentChannel := transactions[i].PaymentChannel
 			status := transactions[i].Status
-			var items string
-			for _, item := range transactions[i].Products {
-				items += item.Title + ", "
+			items := []string{}
+			for _, item := range transactions[i].DetailTransactions {
+				items = append(items, fmt.Sprintf("%v x%v", item.Title, item.Quantity))
 			}
-			product := fmt.Sprint(items)[:len(items)-2]
+			product := strings.Join(items, ", ")
 
 			temp = append(temp, date[:16])
 			temp = append(temp, invoice)
 			temp = append(temp, totalPrice)
+			temp = append(temp, tax)
 			temp = append(temp, product)
 			temp = append(temp, quantity)
 			temp = append(temp, paymentChannel)
@@ -400,18 +418,18 @@ func (p PartnerController) Report() echo.HandlerFunc {
 
 		m.SetBackgroundColor(color.NewWhite())
 
-		tableHeadings := []string{"Transaction Date", "Invoice ID", "Total Transaction", "Product", "Quantity", "Payment", "Status"}
+		tableHeadings := []string{"Transaction Date", "Invoice ID", "Total Transaction", "Tax", "Product", "Quantity", "Payment", "Status"}
 
 		m.TableList(tableHeadings, contents, props.TableList{
 			HeaderProp: props.TableListContent{
 				Size:      12,
 				Style:     consts.Bold,
-				GridSizes: []uint{3, 3, 2, 1, 1, 1, 1},
+				GridSizes: []uint{2, 2, 2, 1, 2, 1, 1, 1},
 			},
 
 			ContentProp: props.TableListContent{
 				Size:      10,
-				GridSizes: []uint{3, 3, 2, 1, 1, 1, 1},
+				GridSizes: []uint{2, 2, 2, 1, 2, 1, 1, 1},
 			},
 			Align:                consts.Center,
 			AlternatedBackground: &color.Color{Red: 230, Blue: 230, Green: 230},
@@ -419,6 +437,54 @@ func (p PartnerController) Report() echo.HandlerFunc {
 			Line:                 true,
 		})
 
+		// the taxes to pay over, summed by period; inclusive taxes are part of the totals above
+		taxContents := [][]string{}
+		for _, row := range taxes {
+			pricing := "added"
+			if row.Inclusive {
+				pricing = "included"
+			}
+
+			taxContents = append(taxContents, []string{
+				row.Period,
+				fmt.Sprintf("%v %v%%", row.Type, row.Rate),
+				pricing,
+				strconv.Itoa(row.Orders),
+				ac.FormatMoney(row.Base),
+				ac.FormatMoney(row.Amount),
+			})
+		}
+
+		if len(taxContents) > 0 {
+			m.Row(20, func() {
+				m.Col(12, func() {
+					m.Text("Tax per "+period, props.Text{
+						Top:    8,
+						Size:   14,
+						Align:  consts.Center,
+						Family: consts.Arial,
+					})
+				})
+			})
+
+			m.TableList([]string{"Period", "Tax", "Pricing", "Orders", "Taxable Amount", "Tax Amount"}, taxContents, props.TableList{
+				HeaderProp: props.TableListContent{
+					Size:      12,
+					Style:     consts.Bold,
+					GridSizes: []uint{2, 2, 2, 2, 2, 2},
+				},
+
+				ContentProp: props.TableListContent{
+					Size:      10,
+					GridSizes: []uint{2, 2, 2, 2, 2, 2},
+				},
+				Align:                consts.Center,
+				AlternatedBackground: &color.Color{Red: 230, Blue: 230, Green: 230},
+				HeaderContentSpace:   2,
+				Line:                 true,
+			})
+		}
+
 		prefix := "reports/"
 
 		fileID := strings.ReplaceAll(uuid.New().String(), "-", "")
@@ -448,3 +514,154 @@ func (p PartnerController) Report() echo.HandlerFunc {
 	}
 
 }
+
+func (p PartnerController) UpdateCapacity() echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		var capacityRequest CapacityRequest
+		c.Bind(&capacityRequest)
+
+		if err := c.Validate(capacityRequest); err != nil {
+			return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+		}
+
+		userJwt, _ := middlewares.ExtractTokenUser(c)
+
+		if err := p.Repo.UpdateCapacity(userJwt.PartnerID, capacityRequest.DailyCapacity); err != nil {
+			return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
+		}
+
+		return c.JSON(http.StatusOK, common.NewSuccessOperationResponse())
+	}
+}
+
+func (p PartnerController) UpdateOrderRules() echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		var rulesRequest OrderRulesRequest
+		c.Bind(&rulesRequest)
+
+		if err := c.Validate(rulesRequest); err != nil {
+			return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+		}
+
+		for _, clock := range []string{rulesRequest.OrderOpenTime, rulesRequest.OrderCloseTime} {
+			if _, err := time.Parse(helper.OrderHourFormat, clock); clock != "" && err != nil {
+				return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, "order hours must be formatted as HH:MM"))
+			}
+		}
+
+		userJwt, _ := middlewares.ExtractTokenUser(c)
+
+		rules := models.Partner{
+			LeadTimeHours:  rulesRequest.LeadTimeHours,
+			MinQuantity:    rulesRequest.MinQuantity,
+			MaxQuantity:    rulesRequest.MaxQuantity,
+			MaxDistance:    rulesRequest.MaxDistance,
+			OrderOpenTime:  rulesRequest.OrderOpenTime,
+			OrderCloseTime: rulesRequest.OrderCloseTime,
+		}
+
+		partner, err := p.Repo.UpdateOrderRules(userJwt.PartnerID, rules)
+		if err != nil {
+			return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
+		}
+
+		return c.JSON(http.StatusOK, common.SuccessResponse(NewOrderRulesResponse(partner)))
+	}
+}
+
+func (p PartnerController) SetCalendar() echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		var calendarRequest CalendarRequest
+		c.Bind(&calendarRequest)
+
+		if err := c.Validate(calendarRequest); err != nil {
+			return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+		}
+
+		if _, err := time.Parse(helper.CalendarDateFormat, calendarRequest.Date); err != nil {
+			return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, "date must be formatted as YYYY-MM-DD"))
+		}
+
+		userJwt, _ := middlewares.ExtractTokenUser(c)
+
+		calendar := models.PartnerCalendar{
+			PartnerID: uint(userJwt.PartnerID),
+			Date:      calendarRequest.Date,
+			Capacity:  calendarRequest.Capacity,
+			Blackout:  calendarRequest.Blackout,
+			Note:      calendarRequest.Note,
+		}
+
+		if _, err := p.Repo.SetCalendar(calendar); err != nil {
+			return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+		}
+
+		return c.JSON(http.StatusOK, common.NewSuccessOperationResponse())
+	}
+}
+
+func (p PartnerController) DeleteCalendar() echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		userJwt, _ := middlewares.ExtractTokenUser(c)
+
+		if err := p.Repo.DeleteCalendar(userJwt.PartnerID, c.Param("date")); err != nil {
+			return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
+		}
+
+		return c.JSON(http.StatusOK, common.NewSuccessOperationResponse())
+	}
+}
+
+// GetAvailability lists the remaining capacity per day, for the next 30 days unless from and to are given.
+func (p PartnerController) GetAvailability() echo.HandlerFunc {
+	return func(c echo.Context) error {
+
+		partnerId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+		}
+
+		from := time.Now()
+		if c.QueryParam("from") != "" {
+			if from, err = time.Parse(helper.CalendarDateFormat, c.QueryParam("from")); err != nil {
+				return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+			}
+		}
+
+		to := from.AddDate(0, 0, 30)
+		if c.QueryParam("to") != "" {
+			if to, err = time.Parse(helper.CalendarDateFormat, c.QueryParam("to")); err != nil {
+				return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+			}
+		}
+
+		const MAX_AVAILABILITY_DAYS = 92
+
+		if to.Before(from) || to.Sub(from) > MAX_AVAILABILITY_DAYS*24*time.Hour {
+			return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, "to must be on or after from and at most 92 days later"))
+		}
+
+		days, err := p.Repo.GetAvailability(partnerId, from, to)
+		if err != nil {
+			return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
+		}
+
+		response := []AvailabilityResponse{}
+		for _, day := range days {
+			response = append(response, AvailabilityResponse{
+				Date:      day.Date,
+				Capacity:  day.Capacity,
+				Remaining: day.Remaining,
+				Unlimited: day.Unlimited,
+				Blackout:  day.Blackout,
+				Note:      day.Note,
+			})
+		}
+
+		return c.JSON(http.StatusOK, common.SuccessResponse(response))
+	}
+}
@@ -18,7 +18,9 @@ import (
 	"github.com/furqonzt99/snackbox/delivery/common"
 	"github.com/furqonzt99/snackbox/delivery/controllers/partner"
 	"github.com/furqonzt99/snackbox/delivery/controllers/user"
+	"github.com/furqonzt99/snackbox/helper"
 	"github.com/furqonzt99/snackbox/models"
+	partnerRepo "github.com/furqonzt99/snackbox/repositories/partner"
 	"github.com/go-playground/validator/v10"
 	"github.com/joho/godotenv"
 	"github.com/labstack/echo/v4"
@@ -892,6 +894,30 @@ func TestReport(t *testing.T) {
 
 	})
 
+	t.Run("test report invalid period", func(t *testing.T) {
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodGet, "/?period=year", nil)
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/partners/report")
+
+		partnerController := partner.NewPartnerController(mockPartnerRepository{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(partnerController.Report())(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "period must be day or month", responses.Message)
+
+	})
+
 	t.Run("test report failed", func(t *testing.T) {
 		e := echo.New()
 
@@ -967,6 +993,334 @@ func TestGetPartnerRating(t *testing.T) {
 	})
 }
 
+func TestCalendar(t *testing.T) {
+	t.Run("login", func(t *testing.T) {
+
+		e := echo.New()
+		e.Validator = &user.UserValidator{Validator: validator.New()}
+
+		requestBody, _ := json.Marshal(map[string]string{
+			"email":    "test@gmail.com",
+			"password": "test1234",
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(requestBody))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		context := e.NewContext(req, res)
+		context.SetPath("/login")
+
+		userController := user.NewUsersControllers(mockUserRepository{})
+		userController.LoginController()(context)
+
+		response := common.ResponseSuccess{}
+		json.Unmarshal([]byte(res.Body.Bytes()), &response)
+		JwtToken = response.Data.(string)
+	})
+
+	t.Run("update capacity", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = &partner.PartnerValidator{Validator: validator.New()}
+
+		requestBody, _ := json.Marshal(map[string]int{
+			"daily_capacity": 200,
+		})
+
+		req := httptest.NewRequest(http.MethodPut, "/", bytes.NewBuffer(requestBody))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/partners/capacity")
+
+		partnerController := partner.NewPartnerController(mockPartnerRepository{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(partnerController.UpdateCapacity())(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Successful Operation", responses.Message)
+	})
+
+	t.Run("update capacity negative", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = &partner.PartnerValidator{Validator: validator.New()}
+
+		requestBody, _ := json.Marshal(map[string]int{
+			"daily_capacity": -1,
+		})
+
+		req := httptest.NewRequest(http.MethodPut, "/", bytes.NewBuffer(requestBody))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/partners/capacity")
+
+		partnerController := partner.NewPartnerController(mockPartnerRepository{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(partnerController.UpdateCapacity())(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Bad Request", responses.Message)
+	})
+
+	t.Run("update order rules", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = &partner.PartnerValidator{Validator: validator.New()}
+
+		requestBody, _ := json.Marshal(map[string]interface{}{
+			"lead_time_hours":  48,
+			"min_quantity":     20,
+			"max_quantity":     300,
+			"max_distance":     15,
+			"order_open_time":  "08:00",
+			"order_close_time": "20:00",
+		})
+
+		req := httptest.NewRequest(http.MethodPut, "/", bytes.NewBuffer(requestBody))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/partners/rules")
+
+		partnerController := partner.NewPartnerController(mockPartnerRepository{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(partnerController.UpdateOrderRules())(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Successful Operation", responses.Message)
+		assert.Equal(t, float64(48), responses.Data.(map[string]interface{})["lead_time_hours"])
+	})
+
+	t.Run("update order rules max below min", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = &partner.PartnerValidator{Validator: validator.New()}
+
+		requestBody, _ := json.Marshal(map[string]interface{}{
+			"min_quantity": 20,
+			"max_quantity": 10,
+		})
+
+		req := httptest.NewRequest(http.MethodPut, "/", bytes.NewBuffer(requestBody))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/partners/rules")
+
+		partnerController := partner.NewPartnerController(mockPartnerRepository{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(partnerController.UpdateOrderRules())(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Bad Request", responses.Message)
+	})
+
+	t.Run("update order rules invalid hours", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = &partner.PartnerValidator{Validator: validator.New()}
+
+		requestBody, _ := json.Marshal(map[string]interface{}{
+			"min_quantity":     1,
+			"order_open_time":  "8am",
+			"order_close_time": "20:00",
+		})
+
+		req := httptest.NewRequest(http.MethodPut, "/", bytes.NewBuffer(requestBody))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/partners/rules")
+
+		partnerController := partner.NewPartnerController(mockPartnerRepository{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(partnerController.UpdateOrderRules())(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "order hours must be formatted as HH:MM", responses.Message)
+	})
+
+	t.Run("set calendar blackout", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = &partner.PartnerValidator{Validator: validator.New()}
+
+		requestBody, _ := json.Marshal(map[string]interface{}{
+			"date":     "2026-12-25",
+			"blackout": true,
+			"note":     "christmas",
+		})
+
+		req := httptest.NewRequest(http.MethodPut, "/", bytes.NewBuffer(requestBody))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/partners/calendar")
+
+		partnerController := partner.NewPartnerController(mockPartnerRepository{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(partnerController.SetCalendar())(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Successful Operation", responses.Message)
+	})
+
+	t.Run("set calendar invalid date", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = &partner.PartnerValidator{Validator: validator.New()}
+
+		requestBody, _ := json.Marshal(map[string]interface{}{
+			"date":     "25-12-2026",
+			"capacity": 10,
+		})
+
+		req := httptest.NewRequest(http.MethodPut, "/", bytes.NewBuffer(requestBody))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/partners/calendar")
+
+		partnerController := partner.NewPartnerController(mockPartnerRepository{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(partnerController.SetCalendar())(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "date must be formatted as YYYY-MM-DD", responses.Message)
+	})
+
+	t.Run("delete calendar not found", func(t *testing.T) {
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodDelete, "/", nil)
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/partners/calendar/:date")
+		context.SetParamNames("date")
+		context.SetParamValues("2026-12-25")
+
+		partnerController := partner.NewPartnerController(mockFalsePartnerRepository{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(partnerController.DeleteCalendar())(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Not Found", responses.Message)
+	})
+}
+
+func TestGetAvailability(t *testing.T) {
+	t.Run("get availability", func(t *testing.T) {
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodGet, "/?from=2026-12-01&to=2026-12-07", nil)
+		res := httptest.NewRecorder()
+
+		context := e.NewContext(req, res)
+		context.SetPath("/partners/:id/availability")
+		context.SetParamNames("id")
+		context.SetParamValues("1")
+
+		partnerController := partner.NewPartnerController(mockPartnerRepository{})
+		partnerController.GetAvailability()(context)
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Successful Operation", responses.Message)
+		assert.Equal(t, "2026-12-01", responses.Data.([]interface{})[0].(map[string]interface{})["date"])
+		assert.Equal(t, float64(60), responses.Data.([]interface{})[0].(map[string]interface{})["remaining"])
+	})
+
+	t.Run("get availability invalid range", func(t *testing.T) {
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodGet, "/?from=2026-12-07&to=2026-12-01", nil)
+		res := httptest.NewRecorder()
+
+		context := e.NewContext(req, res)
+		context.SetPath("/partners/:id/availability")
+		context.SetParamNames("id")
+		context.SetParamValues("1")
+
+		partnerController := partner.NewPartnerController(mockPartnerRepository{})
+		partnerController.GetAvailability()(context)
+
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+	})
+
+	t.Run("get availability partner not found", func(t *testing.T) {
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		res := httptest.NewRecorder()
+
+		context := e.NewContext(req, res)
+		context.SetPath("/partners/:id/availability")
+		context.SetParamNames("id")
+		context.SetParamValues("9")
+
+		partnerController := partner.NewPartnerController(mockFalsePartnerRepository{})
+		partnerController.GetAvailability()(context)
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Not Found", responses.Message)
+	})
+}
+
 //======================
 //MOCK PARTNER REPOSITORY
 //======================
@@ -1095,7 +1449,7 @@ func (m mockPartnerRepository) Report(partnerId int) ([]models.Transaction, erro
 			PaymentMethod:  "BANK TRANSFER",
 			PaidAt:         time.Time{},
 			Status:         "PAID",
-			Products: []models.Product{
+			DetailTransactions: []models.DetailTransaction{
 				{
 
 					Title: "udang",
@@ -1107,6 +1461,46 @@ func (m mockPartnerRepository) Report(partnerId int) ([]models.Transaction, erro
 	}, nil
 }
 
+func (m mockPartnerRepository) TaxReport(partnerId int, period string) ([]partnerRepo.TaxRow, error) {
+	return []partnerRepo.TaxRow{
+		{
+			Period: "2026-01",
+			Type:   "PB1",
+			Rate:   10,
+			Orders: 1,
+			Base:   1000,
+			Amount: 100,
+		},
+	}, nil
+}
+
+func (m mockPartnerRepository) UpdateCapacity(partnerId int, capacity int) error {
+	return nil
+}
+
+func (m mockPartnerRepository) UpdateOrderRules(partnerId int, rules models.Partner) (models.Partner, error) {
+	return rules, nil
+}
+
+func (m mockPartnerRepository) SetCalendar(calendar models.PartnerCalendar) (models.PartnerCalendar, error) {
+	return calendar, nil
+}
+
+func (m mockPartnerRepository) DeleteCalendar(partnerId int, date string) error {
+	return nil
+}
+
+func (m mockPartnerRepository) GetAvailability(partnerId int, from, to time.Time) ([]helper.DayAvailability, error) {
+	return []helper.DayAvailability{
+		{
+			Date:      from.Format(helper.CalendarDateFormat),
+			Capacity:  100,
+			Booked:    40,
+			Remaining: 60,
+		},
+	}, nil
+}
+
 //======================
 //MOCK PARTNER REPOSITORY2
 //======================
@@ -1238,6 +1632,37 @@ func (m mockPartnerRepository2) Report(partnerId int) ([]models.Transaction, err
 	}, nil
 }
 
+func (m mockPartnerRepository2) TaxReport(partnerId int, period string) ([]partnerRepo.TaxRow, error) {
+	return []partnerRepo.TaxRow{}, nil
+}
+
+func (m mockPartnerRepository2) UpdateCapacity(partnerId int, capacity int) error {
+	return nil
+}
+
+func (m mockPartnerRepository2) UpdateOrderRules(partnerId int, rules models.Partner) (models.Partner, error) {
+	return rules, nil
+}
+
+func (m mockPartnerRepository2) SetCalendar(calendar models.PartnerCalendar) (models.PartnerCalendar, error) {
+	return calendar, nil
+}
+
+func (m mockPartnerRepository2) DeleteCalendar(partnerId int, date string) error {
+	return nil
+}
+
+func (m mockPartnerRepository2) GetAvailability(partnerId int, from, to time.Time) ([]helper.DayAvailability, error) {
+	return []helper.DayAvailability{
+		{
+			Date:      from.Format(helper.CalendarDateFormat),
+			Capacity:  100,
+			Booked:    40,
+			Remaining: 60,
+		},
+	}, nil
+}
+
 //======================
 //MOCK PARTNER REPOSITORY3
 //======================
@@ -1366,7 +1791,7 @@ func (m mockPartnerRepository3) Report(partnerId int) ([]models.Transaction, err
 			PaymentMethod:  "BANK TRANSFER",
 			PaidAt:         time.Time{},
 			Status:         "PAID",
-			Products: []models.Product{
+			DetailTransactions: []models.DetailTransaction{
 				{
 					Title: "rendang",
 				},
@@ -1378,6 +1803,37 @@ func (m mockPartnerRepository3) Report(partnerId int) ([]models.Transaction, err
 	}, nil
 }
 
+func (m mockPartnerRepository3) TaxReport(partnerId int, period string) ([]partnerRepo.TaxRow, error) {
+	return []partnerRepo.TaxRow{}, nil
+}
+
+func (m mockPartnerRepository3) UpdateCapacity(partnerId int, capacity int) error {
+	return nil
+}
+
+func (m mockPartnerRepository3) UpdateOrderRules(partnerId int, rules models.Partner) (models.Partner, error) {
+	return rules, nil
+}
+
+func (m mockPartnerRepository3) SetCalendar(calendar models.PartnerCalendar) (models.PartnerCalendar, error) {
+	return calendar, nil
+}
+
+func (m mockPartnerRepository3) DeleteCalendar(partnerId int, date string) error {
+	return nil
+}
+
+func (m mockPartnerRepository3) GetAvailability(partnerId int, from, to time.Time) ([]helper.DayAvailability, error) {
+	return []helper.DayAvailability{
+		{
+			Date:      from.Format(helper.CalendarDateFormat),
+			Capacity:  100,
+			Booked:    40,
+			Remaining: 60,
+		},
+	}, nil
+}
+
 //======================
 //MOCK PARTNER REPOSITORY4
 //======================
@@ -1506,7 +1962,7 @@ func (m mockPartnerRepository4) Report(partnerId int) ([]models.Transaction, err
 			PaymentMethod:  "BANK TRANSFER",
 			PaidAt:         time.Time{},
 			Status:         "PAID",
-			Products: []models.Product{
+			DetailTransactions: []models.DetailTransaction{
 				{
 					Title: "rendang",
 				},
@@ -1518,6 +1974,37 @@ func (m mockPartnerRepository4) Report(partnerId int) ([]models.Transaction, err
 	}, nil
 }
 
+func (m mockPartnerRepository4) TaxReport(partnerId int, period string) ([]partnerRepo.TaxRow, error) {
+	return []partnerRepo.TaxRow{}, nil
+}
+
+func (m mockPartnerRepository4) UpdateCapacity(partnerId int, capacity int) error {
+	return nil
+}
+
+func (m mockPartnerRepository4) UpdateOrderRules(partnerId int, rules models.Partner) (models.Partner, error) {
+	return rules, nil
+}
+
+func (m mockPartnerRepository4) SetCalendar(calendar models.PartnerCalendar) (models.PartnerCalendar, error) {
+	return calendar, nil
+}
+
+func (m mockPartnerRepository4) DeleteCalendar(partnerId int, date string) error {
+	return nil
+}
+
+func (m mockPartnerRepository4) GetAvailability(partnerId int, from, to time.Time) ([]helper.DayAvailability, error) {
+	return []helper.DayAvailability{
+		{
+			Date:      from.Format(helper.CalendarDateFormat),
+			Capacity:  100,
+			Booked:    40,
+			Remaining: 60,
+		},
+	}, nil
+}
+
 //======================
 //MOCK PARTNER REPOSITORY 5
 //======================
@@ -1646,7 +2133,7 @@ func (m mockPartnerRepository5) Report(partnerId int) ([]models.Transaction, err
 			PaymentMethod:  "BANK TRANSFER",
 			PaidAt:         time.Time{},
 			Status:         "PAID",
-			Products: []models.Product{
+			DetailTransactions: []models.DetailTransaction{
 				{
 
 					Title: "udang",
@@ -1658,6 +2145,37 @@ func (m mockPartnerRepository5) Report(partnerId int) ([]models.Transaction, err
 	}, nil
 }
 
+func (m mockPartnerRepository5) TaxReport(partnerId int, period string) ([]partnerRepo.TaxRow, error) {
+	return []partnerRepo.TaxRow{}, nil
+}
+
+func (m mockPartnerRepository5) UpdateCapacity(partnerId int, capacity int) error {
+	return nil
+}
+
+func (m mockPartnerRepository5) UpdateOrderRules(partnerId int, rules models.Partner) (models.Partner, error) {
+	return rules, nil
+}
+
+func (m mockPartnerRepository5) SetCalendar(calendar models.PartnerCalendar) (models.PartnerCalendar, error) {
+	return calendar, nil
+}
+
+func (m mockPartnerRepository5) DeleteCalendar(partnerId int, date string) error {
+	return nil
+}
+
+func (m mockPartnerRepository5) GetAvailability(partnerId int, from, to time.Time) ([]helper.DayAvailability, error) {
+	return []helper.DayAvailability{
+		{
+			Date:      from.Format(helper.CalendarDateFormat),
+			Capacity:  100,
+			Booked:    40,
+			Remaining: 60,
+		},
+	}, nil
+}
+
 //======================
 //MOCK FALSE PARTNER  REPOSITORY
 //======================
@@ -1760,6 +2278,30 @@ func (m mockFalsePartnerRepository) Report(partnerId int) ([]models.Transaction,
 	return nil, errors.New("failed")
 }
 
+func (m mockFalsePartnerRepository) TaxReport(partnerId int, period string) ([]partnerRepo.TaxRow, error) {
+	return nil, errors.New("FAILED")
+}
+
+func (m mockFalsePartnerRepository) UpdateCapacity(partnerId int, capacity int) error {
+	return errors.New("FAILED")
+}
+
+func (m mockFalsePartnerRepository) UpdateOrderRules(partnerId int, rules models.Partner) (models.Partner, error) {
+	return models.Partner{}, errors.New("FAILED")
+}
+
+func (m mockFalsePartnerRepository) SetCalendar(calendar models.PartnerCalendar) (models.PartnerCalendar, error) {
+	return calendar, errors.New("FAILED")
+}
+
+func (m mockFalsePartnerRepository) DeleteCalendar(partnerId int, date string) error {
+	return errors.New("FAILED")
+}
+
+func (m mockFalsePartnerRepository) GetAvailability(partnerId int, from, to time.Time) ([]helper.DayAvailability, error) {
+	return nil, errors.New("FAILED")
+}
+
 //======================
 //MOCK USER REPOSITORY
 //======================
@@ -20,6 +20,26 @@ type UploadDocumentRequest struct {
 	LegalDocument string `form:"legal_document" validate:"required"`
 }
 
+type CapacityRequest struct {
+	DailyCapacity int `json:"daily_capacity" form:"daily_capacity" validate:"min=0"`
+}
+
+type CalendarRequest struct {
+	Date     string `json:"date" form:"date" validate:"required"`
+	Capacity *int   `json:"capacity" form:"capacity" validate:"omitempty,min=0"`
+	Blackout bool   `json:"blackout" form:"blackout"`
+	Note     string `json:"note" form:"note"`
+}
+
+type OrderRulesRequest struct {
+	LeadTimeHours  int     `json:"lead_time_hours" form:"lead_time_hours" validate:"min=0"`
+	MinQuantity    int     `json:"min_quantity" form:"min_quantity" validate:"min=1"`
+	MaxQuantity    int     `json:"max_quantity" form:"max_quantity" validate:"omitempty,gtefield=MinQuantity"`
+	MaxDistance    float64 `json:"max_distance" form:"max_distance" validate:"min=0"`
+	OrderOpenTime  string  `json:"order_open_time" form:"order_open_time" validate:"required_with=OrderCloseTime"`
+	OrderCloseTime string  `json:"order_close_time" form:"order_close_time" validate:"required_with=OrderOpenTime"`
+}
+
 type PartnerValidator struct {
 	Validator *validator.Validate
 }
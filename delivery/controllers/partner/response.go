@@ -62,6 +62,7 @@ type GetPartnerProductResponse struct {
 	Address       string  `json:"address"`
 	City          string  `json:"city"`
 	Rating		  float64 `json:"rating"`
+	OrderRules    OrderRulesResponse `json:"order_rules"`
 	Products      []product.ProductResponse
 }
 
@@ -108,3 +109,32 @@ type PartnerData struct {
 	Status        string  `json:"status"`
 	ApplyDate     string  `json:"apply_date"`
 }
+
+type OrderRulesResponse struct {
+	LeadTimeHours  int     `json:"lead_time_hours"`
+	MinQuantity    int     `json:"min_quantity"`
+	MaxQuantity    int     `json:"max_quantity"`
+	MaxDistance    float64 `json:"max_distance"`
+	OrderOpenTime  string  `json:"order_open_time"`
+	OrderCloseTime string  `json:"order_close_time"`
+}
+
+type AvailabilityResponse struct {
+	Date      string `json:"date"`
+	Capacity  int    `json:"capacity"`
+	Remaining int    `json:"remaining"`
+	Unlimited bool   `json:"unlimited"`
+	Blackout  bool   `json:"blackout"`
+	Note      string `json:"note"`
+}
+
+func NewOrderRulesResponse(partner models.Partner) OrderRulesResponse {
+	return OrderRulesResponse{
+		LeadTimeHours:  partner.LeadTimeHours,
+		MinQuantity:    partner.MinQuantity,
+		MaxQuantity:    partner.MaxQuantity,
+		MaxDistance:    partner.MaxDistance,
+		OrderOpenTime:  partner.OrderOpenTime,
+		OrderCloseTime: partner.OrderCloseTime,
+	}
+}
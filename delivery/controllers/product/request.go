@@ -3,6 +3,7 @@ package product
 import (
 	"net/http"
 
+	"github.com/furqonzt99/snackbox/models"
 	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
 )
@@ -11,14 +12,14 @@ type RegisterProductRequestFormat struct {
 	Title       string  `json:"title" form:"title" validate:"required"`
 	Type        string  `json:"type" form:"type" validate:"required"`
 	Description string  `json:"description" form:"description"`
-	Price       float64 `json:"price" form:"price" validate:"required"`
+	Price       models.Money `json:"price" form:"price" validate:"required"`
 }
 
 type UpdateProductRequestFormat struct {
 	Title       string  `json:"title" form:"title" validate:"required"`
 	Type        string  `json:"type" form:"type" validate:"required"`
 	Description string  `json:"description" form:"description"`
-	Price       float64 `json:"price" form:"price" validate:"required"`
+	Price       models.Money `json:"price" form:"price" validate:"required"`
 }
 
 type UploadProductRequestFormat struct {
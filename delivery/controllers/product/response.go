@@ -1,6 +1,7 @@
 package product
 
 import (
+
 	"github.com/furqonzt99/snackbox/models"
 )
 
@@ -23,7 +24,7 @@ type ProductResponse struct {
 	Image		string	`json:"image"`
 	Type        string  `json:"type"`
 	Description string  `json:"description"`
-	Price       float64 `json:"price"`
+	Price       models.Money `json:"price"`
 }
 
 type GetProductWithPartnerResponse struct {
@@ -33,7 +34,7 @@ type GetProductWithPartnerResponse struct {
 	Image		string	`json:"image"`
 	Type        string  `json:"type"`
 	Description string  `json:"description"`
-	Price       float64 `json:"price"`
+	Price       models.Money `json:"price"`
 }
 
 type GetPartnerResponse struct {
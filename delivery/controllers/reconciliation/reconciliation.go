@@ -0,0 +1,65 @@
+package reconciliation
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/furqonzt99/snackbox/delivery/common"
+	"github.com/furqonzt99/snackbox/models"
+	"github.com/furqonzt99/snackbox/repositories/reconciliation"
+	"github.com/labstack/echo/v4"
+)
+
+// Reconciler runs a reconciliation on demand.
+type Reconciler interface {
+	Reconcile(now time.Time) (models.ReconciliationRun, error)
+}
+
+type ReconciliationController struct {
+	Repo       reconciliation.ReconciliationInterface
+	Reconciler Reconciler
+}
+
+func NewReconciliationController(repo reconciliation.ReconciliationInterface, reconciler Reconciler) *ReconciliationController {
+	return &ReconciliationController{Repo: repo, Reconciler: reconciler}
+}
+
+func (rc ReconciliationController) GetAll(c echo.Context) error {
+	runs, err := rc.Repo.GetRuns()
+	if err != nil {
+		return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
+	}
+
+	response := []RunResponse{}
+	for _, run := range runs {
+		response = append(response, newRunResponse(run))
+	}
+
+	return c.JSON(http.StatusOK, common.SuccessResponse(response))
+}
+
+// Get is the discrepancy report of a run.
+func (rc ReconciliationController) Get(c echo.Context) error {
+	runID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	run, err := rc.Repo.GetRun(runID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
+	}
+
+	return c.JSON(http.StatusOK, common.SuccessResponse(newRunResponse(run)))
+}
+
+// Run reconciles right away instead of waiting for the next scheduled run.
+func (rc ReconciliationController) Run(c echo.Context) error {
+	run, err := rc.Reconciler.Reconcile(time.Now())
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, common.ErrorResponse(http.StatusInternalServerError, err.Error()))
+	}
+
+	return c.JSON(http.StatusOK, common.SuccessResponse(newRunResponse(run)))
+}
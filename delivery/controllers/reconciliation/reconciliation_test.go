@@ -0,0 +1,200 @@
+package reconciliation_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/delivery/common"
+	"github.com/furqonzt99/snackbox/delivery/controllers/reconciliation"
+	"github.com/furqonzt99/snackbox/models"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestReconciliation(t *testing.T) {
+	newContext := func(method, path, id string) (echo.Context, *httptest.ResponseRecorder) {
+		e := echo.New()
+
+		req := httptest.NewRequest(method, "/", nil)
+		res := httptest.NewRecorder()
+
+		context := e.NewContext(req, res)
+		context.SetPath(path)
+		if id != "" {
+			context.SetParamNames("id")
+			context.SetParamValues(id)
+		}
+
+		return context, res
+	}
+
+	t.Run("get reconciliation runs", func(t *testing.T) {
+		context, res := newContext(http.MethodGet, "/reconciliations", "")
+
+		reconciliationController := reconciliation.NewReconciliationController(mockReconciliation{}, mockReconciler{})
+		reconciliationController.GetAll(context)
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Successful Operation", responses.Message)
+		assert.Equal(t, float64(3), responses.Data.([]interface{})[0].(map[string]interface{})["checked"])
+	})
+
+	t.Run("get reconciliation report", func(t *testing.T) {
+		context, res := newContext(http.MethodGet, "/reconciliations/:id", "1")
+
+		reconciliationController := reconciliation.NewReconciliationController(mockReconciliation{}, mockReconciler{})
+		reconciliationController.Get(context)
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Successful Operation", responses.Message)
+
+		discrepancy := responses.Data.(map[string]interface{})["discrepancies"].([]interface{})[0].(map[string]interface{})
+		assert.Equal(t, constants.DISCREPANCY_MISSED_CALLBACK, discrepancy["kind"])
+		assert.Equal(t, "INV-1", discrepancy["reference"])
+	})
+
+	t.Run("get reconciliation report not found", func(t *testing.T) {
+		context, res := newContext(http.MethodGet, "/reconciliations/:id", "99")
+
+		reconciliationController := reconciliation.NewReconciliationController(mockFalseReconciliation{}, mockReconciler{})
+		reconciliationController.Get(context)
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Not Found", responses.Message)
+	})
+
+	t.Run("run reconciliation", func(t *testing.T) {
+		context, res := newContext(http.MethodPost, "/reconciliations", "")
+
+		reconciliationController := reconciliation.NewReconciliationController(mockReconciliation{}, mockReconciler{})
+		reconciliationController.Run(context)
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Successful Operation", responses.Message)
+		assert.Equal(t, float64(1), responses.Data.(map[string]interface{})["applied"])
+	})
+
+	t.Run("run reconciliation error", func(t *testing.T) {
+		context, res := newContext(http.MethodPost, "/reconciliations", "")
+
+		reconciliationController := reconciliation.NewReconciliationController(mockReconciliation{}, mockFalseReconciler{})
+		reconciliationController.Run(context)
+
+		assert.Equal(t, http.StatusInternalServerError, res.Code)
+	})
+}
+
+// ======================
+// MOCK RECONCILIATION
+// ======================
+func reconciliationRun() models.ReconciliationRun {
+	return models.ReconciliationRun{
+		Model:   gorm.Model{ID: 1},
+		Checked: 3,
+		Applied: 1,
+		Discrepancies: []models.ReconciliationDiscrepancy{{
+			Kind:           constants.DISCREPANCY_MISSED_CALLBACK,
+			SourceType:     constants.TRANSACTION_SOURCE,
+			SourceID:       1,
+			Reference:      "INV-1",
+			LocalStatus:    constants.PENDING_STATUS,
+			ProviderStatus: "PAID",
+		}},
+	}
+}
+
+type mockReconciliation struct{}
+
+func (m mockReconciliation) GetOpenInvoices(before time.Time) ([]models.TransactionInvoice, error) {
+	return nil, nil
+}
+
+func (m mockReconciliation) GetOpenTopups(before time.Time) ([]models.WalletTopup, error) {
+	return nil, nil
+}
+
+func (m mockReconciliation) GetOpenCashouts(before time.Time) ([]models.Cashout, error) {
+	return nil, nil
+}
+
+func (m mockReconciliation) GetUnsettledInvoices(before time.Time) ([]models.TransactionInvoice, error) {
+	return nil, nil
+}
+
+func (m mockReconciliation) SettleTransaction(trxID uint, status string) error {
+	return nil
+}
+
+func (m mockReconciliation) SaveRun(run models.ReconciliationRun) (models.ReconciliationRun, error) {
+	return run, nil
+}
+
+func (m mockReconciliation) GetRuns() ([]models.ReconciliationRun, error) {
+	run := reconciliationRun()
+	run.Discrepancies = nil
+	return []models.ReconciliationRun{run}, nil
+}
+
+func (m mockReconciliation) GetRun(runID int) (models.ReconciliationRun, error) {
+	return reconciliationRun(), nil
+}
+
+type mockFalseReconciliation struct{}
+
+func (m mockFalseReconciliation) GetOpenInvoices(before time.Time) ([]models.TransactionInvoice, error) {
+	return nil, errors.New("FAILED")
+}
+
+func (m mockFalseReconciliation) GetOpenTopups(before time.Time) ([]models.WalletTopup, error) {
+	return nil, errors.New("FAILED")
+}
+
+func (m mockFalseReconciliation) GetOpenCashouts(before time.Time) ([]models.Cashout, error) {
+	return nil, errors.New("FAILED")
+}
+
+func (m mockFalseReconciliation) GetUnsettledInvoices(before time.Time) ([]models.TransactionInvoice, error) {
+	return nil, errors.New("FAILED")
+}
+
+func (m mockFalseReconciliation) SettleTransaction(trxID uint, status string) error {
+	return errors.New("FAILED")
+}
+
+func (m mockFalseReconciliation) SaveRun(run models.ReconciliationRun) (models.ReconciliationRun, error) {
+	return run, errors.New("FAILED")
+}
+
+func (m mockFalseReconciliation) GetRuns() ([]models.ReconciliationRun, error) {
+	return nil, errors.New("FAILED")
+}
+
+func (m mockFalseReconciliation) GetRun(runID int) (models.ReconciliationRun, error) {
+	return models.ReconciliationRun{}, gorm.ErrRecordNotFound
+}
+
+type mockReconciler struct{}
+
+func (m mockReconciler) Reconcile(now time.Time) (models.ReconciliationRun, error) {
+	return reconciliationRun(), nil
+}
+
+type mockFalseReconciler struct{}
+
+func (m mockFalseReconciler) Reconcile(now time.Time) (models.ReconciliationRun, error) {
+	return models.ReconciliationRun{}, errors.New("FAILED")
+}
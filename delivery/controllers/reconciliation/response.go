@@ -0,0 +1,56 @@
+package reconciliation
+
+import (
+	"time"
+
+	"github.com/furqonzt99/snackbox/models"
+)
+
+type DiscrepancyResponse struct {
+	ID             uint         `json:"id"`
+	Kind           string       `json:"kind"`
+	SourceType     string       `json:"source_type"`
+	SourceID       uint         `json:"source_id"`
+	Reference      string       `json:"reference"`
+	LocalStatus    string       `json:"local_status"`
+	ProviderStatus string       `json:"provider_status"`
+	LocalAmount    models.Money `json:"local_amount"`
+	ProviderAmount models.Money `json:"provider_amount"`
+	Note           string       `json:"note"`
+}
+
+type RunResponse struct {
+	ID            uint                  `json:"id"`
+	Checked       int                   `json:"checked"`
+	Applied       int                   `json:"applied"`
+	Failed        int                   `json:"failed"`
+	CreatedAt     time.Time             `json:"created_at"`
+	Discrepancies []DiscrepancyResponse `json:"discrepancies,omitempty"`
+}
+
+func newRunResponse(run models.ReconciliationRun) RunResponse {
+	response := RunResponse{
+		ID:        run.ID,
+		Checked:   run.Checked,
+		Applied:   run.Applied,
+		Failed:    run.Failed,
+		CreatedAt: run.CreatedAt,
+	}
+
+	for _, discrepancy := range run.Discrepancies {
+		response.Discrepancies = append(response.Discrepancies, DiscrepancyResponse{
+			ID:             discrepancy.ID,
+			Kind:           discrepancy.Kind,
+			SourceType:     discrepancy.SourceType,
+			SourceID:       discrepancy.SourceID,
+			Reference:      discrepancy.Reference,
+			LocalStatus:    discrepancy.LocalStatus,
+			ProviderStatus: discrepancy.ProviderStatus,
+			LocalAmount:    discrepancy.LocalAmount,
+			ProviderAmount: discrepancy.ProviderAmount,
+			Note:           discrepancy.Note,
+		})
+	}
+
+	return response
+}
@@ -0,0 +1,245 @@
+package refund
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/delivery/common"
+	"github.com/furqonzt99/snackbox/delivery/middlewares"
+	"github.com/furqonzt99/snackbox/helper"
+	"github.com/furqonzt99/snackbox/models"
+	"github.com/furqonzt99/snackbox/repositories/refund"
+	"github.com/google/uuid"
+	"github.com/h2non/filetype"
+	"github.com/labstack/echo/v4"
+)
+
+type RefundController struct {
+	Repo refund.RefundInterface
+}
+
+func NewRefundController(refund refund.RefundInterface) *RefundController {
+	return &RefundController{Repo: refund}
+}
+
+func (rc RefundController) Create(c echo.Context) error {
+	user, _ := middlewares.ExtractTokenUser(c)
+
+	trxID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	var refundRequest RefundRequest
+
+	if err := c.Bind(&refundRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	if err := c.Validate(&refundRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	refund, err := rc.Repo.Create(models.RefundRequest{
+		TransactionID: uint(trxID),
+		UserID:        uint(user.UserID),
+		Reason:        refundRequest.Reason,
+		Amount:        refundRequest.Amount,
+	})
+
+	return refundResult(c, refund, err)
+}
+
+func (rc RefundController) UploadPhoto(c echo.Context) error {
+	user, _ := middlewares.ExtractTokenUser(c)
+
+	refundID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	file, err := c.FormFile("photo")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	defer src.Close()
+
+	head := make([]byte, 261)
+	src.Read(head)
+
+	kind, _ := filetype.Match(head)
+
+	if !filetype.IsImage(head) {
+		return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, "file type must an image"))
+	}
+
+	prefix := "refunds/"
+
+	fileID := strings.ReplaceAll(uuid.New().String(), "-", "")
+	file.Filename = fmt.Sprint(prefix, fileID, ".", kind.Extension)
+
+	if _, err := rc.Repo.Get(refundID); err != nil {
+		return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
+	}
+
+	if err := helper.UploadObjectS3(file.Filename, src); err != nil {
+		return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	refund, err := rc.Repo.UploadPhoto(refundID, user.UserID, file.Filename)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
+	}
+
+	return c.JSON(http.StatusOK, common.SuccessResponse(newRefundResponse(refund)))
+}
+
+// GetAll lists the customer's own requests, the partner's requests or, for an admin, every
+// request. Filter by the status query param, e.g. DISPUTED for the arbitration queue.
+func (rc RefundController) GetAll(c echo.Context) error {
+	user, _ := middlewares.ExtractTokenUser(c)
+
+	var userID, partnerID int
+	switch user.Role {
+	case constants.USER_ROLE:
+		userID = user.UserID
+	case constants.PARTNER_ROLE:
+		partnerID = user.PartnerID
+	}
+
+	refunds, err := rc.Repo.GetAll(userID, partnerID, c.QueryParam("status"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
+	}
+
+	response := []RefundResponse{}
+	for _, refund := range refunds {
+		response = append(response, newRefundResponse(refund))
+	}
+
+	return c.JSON(http.StatusOK, common.SuccessResponse(response))
+}
+
+func (rc RefundController) Get(c echo.Context) error {
+	user, _ := middlewares.ExtractTokenUser(c)
+
+	refundID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	refund, err := rc.Repo.Get(refundID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
+	}
+
+	if (user.Role == constants.USER_ROLE && refund.UserID != uint(user.UserID)) ||
+		(user.Role == constants.PARTNER_ROLE && refund.PartnerID != uint(user.PartnerID)) {
+		return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
+	}
+
+	return c.JSON(http.StatusOK, common.SuccessResponse(newRefundResponse(refund)))
+}
+
+func (rc RefundController) Accept(c echo.Context) error {
+	user, _ := middlewares.ExtractTokenUser(c)
+
+	refundID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	refund, err := rc.Repo.Accept(refundID, user.PartnerID)
+
+	return refundResult(c, refund, err)
+}
+
+func (rc RefundController) Counter(c echo.Context) error {
+	user, _ := middlewares.ExtractTokenUser(c)
+
+	refundID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	var counterRequest CounterRequest
+
+	if err := c.Bind(&counterRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	if err := c.Validate(&counterRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	refund, err := rc.Repo.Counter(refundID, user.PartnerID, counterRequest.Amount, counterRequest.Note)
+
+	return refundResult(c, refund, err)
+}
+
+func (rc RefundController) AcceptCounter(c echo.Context) error {
+	user, _ := middlewares.ExtractTokenUser(c)
+
+	refundID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	refund, err := rc.Repo.AcceptCounter(refundID, user.UserID)
+
+	return refundResult(c, refund, err)
+}
+
+func (rc RefundController) Escalate(c echo.Context) error {
+	user, _ := middlewares.ExtractTokenUser(c)
+
+	refundID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	refund, err := rc.Repo.Escalate(refundID, user.UserID)
+
+	return refundResult(c, refund, err)
+}
+
+func (rc RefundController) Resolve(c echo.Context) error {
+	refundID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	var resolveRequest ResolveRequest
+
+	if err := c.Bind(&resolveRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	if err := c.Validate(&resolveRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	refund, err := rc.Repo.Resolve(refundID, resolveRequest.Amount, resolveRequest.Note)
+
+	return refundResult(c, refund, err)
+}
+
+func refundResult(c echo.Context, refund models.RefundRequest, err error) error {
+	if errors.Is(err, helper.ErrInvalidRefund) {
+		return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	if err != nil {
+		return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
+	}
+
+	return c.JSON(http.StatusOK, common.SuccessResponse(newRefundResponse(refund)))
+}
@@ -0,0 +1,457 @@
+package refund_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/delivery/common"
+	"github.com/furqonzt99/snackbox/delivery/controllers/refund"
+	"github.com/furqonzt99/snackbox/delivery/controllers/user"
+	"github.com/furqonzt99/snackbox/helper"
+	"github.com/furqonzt99/snackbox/models"
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+var JwtToken string
+
+func TestRefund(t *testing.T) {
+	t.Run("Test Login", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = &user.UserValidator{Validator: validator.New()}
+
+		requestBody, _ := json.Marshal(map[string]string{
+			"email":    "test@gmail.com",
+			"password": "test1234",
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(requestBody))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		context := e.NewContext(req, res)
+		context.SetPath("/login")
+
+		userController := user.NewUsersControllers(mockUserRepository{})
+		userController.LoginController()(context)
+
+		response := common.ResponseSuccess{}
+		json.Unmarshal([]byte(res.Body.Bytes()), &response)
+
+		JwtToken = response.Data.(string)
+		assert.Equal(t, "Successful Operation", response.Message)
+		assert.NotNil(t, JwtToken)
+	})
+
+	t.Run("create refund request", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = &refund.RefundValidator{Validator: validator.New()}
+
+		requestBody, _ := json.Marshal(refund.RefundRequest{
+			Reason: "two boxes were crushed",
+			Amount: 20000,
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(requestBody))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/transactions/:id/refunds")
+		context.SetParamNames("id")
+		context.SetParamValues("1")
+
+		refundController := refund.NewRefundController(mockRefund{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(refundController.Create)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Successful Operation", responses.Message)
+		assert.Equal(t, constants.REFUND_REQUESTED, responses.Data.(map[string]interface{})["status"])
+		assert.Equal(t, float64(20000), responses.Data.(map[string]interface{})["amount"])
+	})
+
+	t.Run("create refund request without reason", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = &refund.RefundValidator{Validator: validator.New()}
+
+		requestBody, _ := json.Marshal(refund.RefundRequest{
+			Amount: 20000,
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(requestBody))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/transactions/:id/refunds")
+		context.SetParamNames("id")
+		context.SetParamValues("1")
+
+		refundController := refund.NewRefundController(mockRefund{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(refundController.Create)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Bad Request", responses.Message)
+	})
+
+	t.Run("create refund request on an undelivered order", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = &refund.RefundValidator{Validator: validator.New()}
+
+		requestBody, _ := json.Marshal(refund.RefundRequest{
+			Reason: "never arrived",
+			Amount: 20000,
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(requestBody))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/transactions/:id/refunds")
+		context.SetParamNames("id")
+		context.SetParamValues("1")
+
+		refundController := refund.NewRefundController(mockFalseRefund{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(refundController.Create)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+		assert.Contains(t, responses.Message, "only delivered orders can be refunded")
+	})
+
+	t.Run("get all refund requests", func(t *testing.T) {
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodGet, "/?status=DISPUTED", nil)
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/refunds")
+
+		refundController := refund.NewRefundController(mockRefund{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(refundController.GetAll)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Successful Operation", responses.Message)
+		assert.Equal(t, 1, len(responses.Data.([]interface{})))
+	})
+
+	t.Run("get refund request not found", func(t *testing.T) {
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/refunds/:id")
+		context.SetParamNames("id")
+		context.SetParamValues("9")
+
+		refundController := refund.NewRefundController(mockFalseRefund{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(refundController.Get)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Not Found", responses.Message)
+	})
+
+	t.Run("counter refund request", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = &refund.RefundValidator{Validator: validator.New()}
+
+		requestBody, _ := json.Marshal(refund.CounterRequest{
+			Amount: 10000,
+			Note:   "only one box was damaged",
+		})
+
+		req := httptest.NewRequest(http.MethodPut, "/", bytes.NewBuffer(requestBody))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/refunds/:id/counter")
+		context.SetParamNames("id")
+		context.SetParamValues("1")
+
+		refundController := refund.NewRefundController(mockRefund{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(refundController.Counter)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Successful Operation", responses.Message)
+		assert.Equal(t, constants.REFUND_COUNTERED, responses.Data.(map[string]interface{})["status"])
+		assert.Equal(t, float64(10000), responses.Data.(map[string]interface{})["counter_amount"])
+	})
+
+	t.Run("accept counter offer", func(t *testing.T) {
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodPut, "/", nil)
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/refunds/:id/accept-counter")
+		context.SetParamNames("id")
+		context.SetParamValues("1")
+
+		refundController := refund.NewRefundController(mockRefund{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(refundController.AcceptCounter)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Successful Operation", responses.Message)
+		assert.Equal(t, float64(10000), responses.Data.(map[string]interface{})["approved_amount"])
+	})
+
+	t.Run("escalate refund request", func(t *testing.T) {
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodPut, "/", nil)
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/refunds/:id/escalate")
+		context.SetParamNames("id")
+		context.SetParamValues("1")
+
+		refundController := refund.NewRefundController(mockRefund{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(refundController.Escalate)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, constants.REFUND_DISPUTED, responses.Data.(map[string]interface{})["status"])
+	})
+
+	t.Run("resolve refund request", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = &refund.RefundValidator{Validator: validator.New()}
+
+		requestBody, _ := json.Marshal(refund.ResolveRequest{
+			Amount: 15000,
+			Note:   "photos show three damaged boxes",
+		})
+
+		req := httptest.NewRequest(http.MethodPut, "/", bytes.NewBuffer(requestBody))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+
+		context := e.NewContext(req, res)
+		context.SetPath("/refunds/:id/resolve")
+		context.SetParamNames("id")
+		context.SetParamValues("1")
+
+		refundController := refund.NewRefundController(mockRefund{})
+		refundController.Resolve(context)
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Successful Operation", responses.Message)
+		assert.Equal(t, constants.REFUND_APPROVED, responses.Data.(map[string]interface{})["status"])
+		assert.Equal(t, float64(15000), responses.Data.(map[string]interface{})["approved_amount"])
+	})
+
+	t.Run("resolve refund request without note", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = &refund.RefundValidator{Validator: validator.New()}
+
+		requestBody, _ := json.Marshal(refund.ResolveRequest{
+			Amount: 15000,
+		})
+
+		req := httptest.NewRequest(http.MethodPut, "/", bytes.NewBuffer(requestBody))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+
+		context := e.NewContext(req, res)
+		context.SetPath("/refunds/:id/resolve")
+		context.SetParamNames("id")
+		context.SetParamValues("1")
+
+		refundController := refund.NewRefundController(mockRefund{})
+		refundController.Resolve(context)
+
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+	})
+}
+
+// ======================
+// MOCK REFUND REPOSITORY
+// ======================
+type mockRefund struct{}
+
+func (m mockRefund) Create(refund models.RefundRequest) (models.RefundRequest, error) {
+	refund.ID = 1
+	refund.Status = constants.REFUND_REQUESTED
+	return refund, nil
+}
+
+func (m mockRefund) GetAll(userID, partnerID int, status string) ([]models.RefundRequest, error) {
+	return []models.RefundRequest{
+		{Model: gorm.Model{ID: 1}, TransactionID: 1, Amount: 20000, Status: status},
+	}, nil
+}
+
+func (m mockRefund) Get(refundID int) (models.RefundRequest, error) {
+	return models.RefundRequest{Model: gorm.Model{ID: uint(refundID)}, TransactionID: 1, Amount: 20000, Status: constants.REFUND_REQUESTED}, nil
+}
+
+func (m mockRefund) UploadPhoto(refundID, userID int, photo string) (models.RefundRequest, error) {
+	return models.RefundRequest{Model: gorm.Model{ID: uint(refundID)}, Photo: photo}, nil
+}
+
+func (m mockRefund) Accept(refundID, partnerID int) (models.RefundRequest, error) {
+	return models.RefundRequest{Model: gorm.Model{ID: uint(refundID)}, Amount: 20000, ApprovedAmount: 20000, Status: constants.REFUND_APPROVED}, nil
+}
+
+func (m mockRefund) Counter(refundID, partnerID int, amount models.Money, note string) (models.RefundRequest, error) {
+	return models.RefundRequest{Model: gorm.Model{ID: uint(refundID)}, Amount: 20000, CounterAmount: amount, PartnerNote: note, Status: constants.REFUND_COUNTERED}, nil
+}
+
+func (m mockRefund) AcceptCounter(refundID, userID int) (models.RefundRequest, error) {
+	return models.RefundRequest{Model: gorm.Model{ID: uint(refundID)}, Amount: 20000, CounterAmount: 10000, ApprovedAmount: 10000, Status: constants.REFUND_APPROVED}, nil
+}
+
+func (m mockRefund) Escalate(refundID, userID int) (models.RefundRequest, error) {
+	return models.RefundRequest{Model: gorm.Model{ID: uint(refundID)}, Amount: 20000, Status: constants.REFUND_DISPUTED}, nil
+}
+
+func (m mockRefund) Resolve(refundID int, amount models.Money, note string) (models.RefundRequest, error) {
+	return models.RefundRequest{Model: gorm.Model{ID: uint(refundID)}, Amount: 20000, ApprovedAmount: amount, AdminNote: note, Status: constants.REFUND_APPROVED}, nil
+}
+
+type mockFalseRefund struct{}
+
+func (m mockFalseRefund) Create(refund models.RefundRequest) (models.RefundRequest, error) {
+	return refund, helper.RefundError("only delivered orders can be refunded")
+}
+
+func (m mockFalseRefund) GetAll(userID, partnerID int, status string) ([]models.RefundRequest, error) {
+	return nil, errors.New("FAILED")
+}
+
+func (m mockFalseRefund) Get(refundID int) (models.RefundRequest, error) {
+	return models.RefundRequest{}, errors.New("FAILED")
+}
+
+func (m mockFalseRefund) UploadPhoto(refundID, userID int, photo string) (models.RefundRequest, error) {
+	return models.RefundRequest{}, errors.New("FAILED")
+}
+
+func (m mockFalseRefund) Accept(refundID, partnerID int) (models.RefundRequest, error) {
+	return models.RefundRequest{}, errors.New("FAILED")
+}
+
+func (m mockFalseRefund) Counter(refundID, partnerID int, amount models.Money, note string) (models.RefundRequest, error) {
+	return models.RefundRequest{}, errors.New("FAILED")
+}
+
+func (m mockFalseRefund) AcceptCounter(refundID, userID int) (models.RefundRequest, error) {
+	return models.RefundRequest{}, errors.New("FAILED")
+}
+
+func (m mockFalseRefund) Escalate(refundID, userID int) (models.RefundRequest, error) {
+	return models.RefundRequest{}, errors.New("FAILED")
+}
+
+func (m mockFalseRefund) Resolve(refundID int, amount models.Money, note string) (models.RefundRequest, error) {
+	return models.RefundRequest{}, errors.New("FAILED")
+}
+
+// ======================
+// MOCK USER REPOSITORY
+// ======================
+type mockUserRepository struct{}
+
+func (m mockUserRepository) Register(newUser models.User) (models.User, error) {
+	return newUser, nil
+}
+
+func (m mockUserRepository) Login(email string) (models.User, error) {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("test1234"), 14)
+	return models.User{
+		Email:    "test@gmail.com",
+		Password: string(hash),
+	}, nil
+}
+
+func (m mockUserRepository) Get(userid int) (models.User, error) {
+	return models.User{
+		Email: "test@gmail.com",
+		Name:  "tester",
+	}, nil
+}
+
+func (m mockUserRepository) Update(newUser models.User, userId int) (models.User, error) {
+	return newUser, nil
+}
+
+func (m mockUserRepository) Delete(userId int) (models.User, error) {
+	return models.User{}, nil
+}
@@ -0,0 +1,38 @@
+package refund
+
+import (
+	"net/http"
+
+	"github.com/furqonzt99/snackbox/delivery/common"
+	"github.com/furqonzt99/snackbox/models"
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+type RefundRequest struct {
+	Reason string       `json:"reason" validate:"required"`
+	Amount models.Money `json:"amount" validate:"required"`
+}
+
+type CounterRequest struct {
+	Amount models.Money `json:"amount" validate:"required"`
+	Note   string       `json:"note"`
+}
+
+// ResolveRequest is the admin's ruling. An amount of 0 rejects the request.
+type ResolveRequest struct {
+	Amount models.Money `json:"amount" validate:"min=0"`
+	Note   string       `json:"note" validate:"required"`
+}
+
+type RefundValidator struct {
+	Validator *validator.Validate
+}
+
+func (rv *RefundValidator) Validate(i interface{}) error {
+	if err := rv.Validator.Struct(i); err != nil {
+		// Optionally, you could return the error to give each route more control over the status code
+		return echo.NewHTTPError(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	return nil
+}
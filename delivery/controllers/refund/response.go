@@ -0,0 +1,55 @@
+package refund
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/models"
+)
+
+type RefundResponse struct {
+	ID             uint         `json:"id"`
+	TransactionID  uint         `json:"transaction_id"`
+	InvoiceID      string       `json:"invoice_id"`
+	UserID         uint         `json:"user_id"`
+	PartnerID      uint         `json:"partner_id"`
+	Reason         string       `json:"reason"`
+	Photo          string       `json:"photo"`
+	Amount         models.Money `json:"amount"`
+	CounterAmount  models.Money `json:"counter_amount"`
+	ApprovedAmount models.Money `json:"approved_amount"`
+	Status         string       `json:"status"`
+	PartnerNote    string       `json:"partner_note"`
+	AdminNote      string       `json:"admin_note"`
+	CreatedAt      time.Time    `json:"created_at"`
+	ResolvedAt     *time.Time   `json:"resolved_at"`
+}
+
+func newRefundResponse(refund models.RefundRequest) RefundResponse {
+	response := RefundResponse{
+		ID:             refund.ID,
+		TransactionID:  refund.TransactionID,
+		InvoiceID:      refund.Transaction.InvoiceID,
+		UserID:         refund.UserID,
+		PartnerID:      refund.PartnerID,
+		Reason:         refund.Reason,
+		Amount:         refund.Amount,
+		CounterAmount:  refund.CounterAmount,
+		ApprovedAmount: refund.ApprovedAmount,
+		Status:         refund.Status,
+		PartnerNote:    refund.PartnerNote,
+		AdminNote:      refund.AdminNote,
+		CreatedAt:      refund.CreatedAt,
+	}
+
+	if refund.Photo != "" {
+		response.Photo = fmt.Sprintf(constants.LINK_TEMPLATE, constants.S3_BUCKET, constants.S3_REGION, refund.Photo)
+	}
+
+	if !refund.ResolvedAt.IsZero() {
+		response.ResolvedAt = &refund.ResolvedAt
+	}
+
+	return response
+}
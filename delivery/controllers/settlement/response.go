@@ -0,0 +1,62 @@
+package settlement
+
+import (
+	"github.com/furqonzt99/snackbox/models"
+	"github.com/furqonzt99/snackbox/repositories/settlement"
+)
+
+type SettlementResponse struct {
+	Period           string       `json:"period,omitempty"`
+	Transactions     int          `json:"transactions"`
+	GrossCollected   models.Money `json:"gross_collected"`
+	GatewayFees      models.Money `json:"gateway_fees"`
+	Settled          models.Money `json:"settled"`
+	Refunds          models.Money `json:"refunds"`
+	Cashouts         int          `json:"cashouts"`
+	PartnerPayouts   models.Money `json:"partner_payouts"`
+	CustomerCashouts models.Money `json:"customer_cashouts"`
+	CashoutFees      models.Money `json:"cashout_fees"`
+	Commission       models.Money `json:"commission"`
+	PlatformMargin   models.Money `json:"platform_margin"`
+}
+
+type SettlementReportResponse struct {
+	From    string               `json:"from"`
+	To      string               `json:"to"`
+	Total   SettlementResponse   `json:"total"`
+	Periods []SettlementResponse `json:"periods"`
+}
+
+func newSettlementResponse(row settlement.SettlementRow) SettlementResponse {
+	return SettlementResponse{
+		Period:           row.Period,
+		Transactions:     row.Transactions,
+		GrossCollected:   row.GrossCollected,
+		GatewayFees:      row.GatewayFees,
+		Settled:          row.Settled,
+		Refunds:          row.Refunds,
+		Cashouts:         row.Cashouts,
+		PartnerPayouts:   row.PartnerPayouts,
+		CustomerCashouts: row.CustomerCashouts,
+		CashoutFees:      row.CashoutFees,
+		Commission:       row.Commission,
+		PlatformMargin:   row.PlatformMargin,
+	}
+}
+
+// add sums two responses, the period of the sum is left empty.
+func (r SettlementResponse) add(other SettlementResponse) SettlementResponse {
+	return SettlementResponse{
+		Transactions:     r.Transactions + other.Transactions,
+		GrossCollected:   r.GrossCollected + other.GrossCollected,
+		GatewayFees:      r.GatewayFees + other.GatewayFees,
+		Settled:          r.Settled + other.Settled,
+		Refunds:          r.Refunds + other.Refunds,
+		Cashouts:         r.Cashouts + other.Cashouts,
+		PartnerPayouts:   r.PartnerPayouts + other.PartnerPayouts,
+		CustomerCashouts: r.CustomerCashouts + other.CustomerCashouts,
+		CashoutFees:      r.CashoutFees + other.CashoutFees,
+		Commission:       r.Commission + other.Commission,
+		PlatformMargin:   r.PlatformMargin + other.PlatformMargin,
+	}
+}
@@ -0,0 +1,76 @@
+package settlement
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/delivery/common"
+	"github.com/furqonzt99/snackbox/helper"
+	"github.com/furqonzt99/snackbox/repositories/settlement"
+	"github.com/labstack/echo/v4"
+)
+
+type SettlementController struct {
+	Repo settlement.SettlementInterface
+}
+
+func NewSettlementController(settlement settlement.SettlementInterface) *SettlementController {
+	return &SettlementController{Repo: settlement}
+}
+
+// Report sums what went through the payment gateway from the from date to the to date, both
+// inclusive and this month by default, grouped by the period query param, day or month.
+func (sc SettlementController) Report(c echo.Context) error {
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	to := now
+
+	var err error
+
+	if c.QueryParam("from") != "" {
+		if from, err = time.ParseInLocation(helper.CalendarDateFormat, c.QueryParam("from"), now.Location()); err != nil {
+			return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, "from must be a date like 2006-01-02"))
+		}
+	}
+
+	if c.QueryParam("to") != "" {
+		if to, err = time.ParseInLocation(helper.CalendarDateFormat, c.QueryParam("to"), now.Location()); err != nil {
+			return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, "to must be a date like 2006-01-02"))
+		}
+	}
+
+	to = time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, to.Location()).AddDate(0, 0, 1)
+
+	if !from.Before(to) {
+		return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, "from must not be after to"))
+	}
+
+	period := c.QueryParam("period")
+	switch period {
+	case "":
+		period = constants.MONTHLY_PERIOD
+	case constants.DAILY_PERIOD, constants.MONTHLY_PERIOD:
+	default:
+		return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, "period must be day or month"))
+	}
+
+	rows, err := sc.Repo.Report(from, to, period)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
+	}
+
+	response := SettlementReportResponse{
+		From:    from.Format(helper.CalendarDateFormat),
+		To:      to.AddDate(0, 0, -1).Format(helper.CalendarDateFormat),
+		Periods: []SettlementResponse{},
+	}
+
+	for _, row := range rows {
+		period := newSettlementResponse(row)
+		response.Periods = append(response.Periods, period)
+		response.Total = response.Total.add(period)
+	}
+
+	return c.JSON(http.StatusOK, common.SuccessResponse(response))
+}
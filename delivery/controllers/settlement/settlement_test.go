@@ -0,0 +1,128 @@
+package settlement_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/furqonzt99/snackbox/delivery/common"
+	"github.com/furqonzt99/snackbox/delivery/controllers/settlement"
+	settlementRepo "github.com/furqonzt99/snackbox/repositories/settlement"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSettlementReport(t *testing.T) {
+	t.Run("settlement report", func(t *testing.T) {
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodGet, "/?from=2026-01-01&to=2026-02-28&period=month", nil)
+		res := httptest.NewRecorder()
+
+		context := e.NewContext(req, res)
+		context.SetPath("/settlements/report")
+
+		settlementController := settlement.NewSettlementController(mockSettlement{})
+		settlementController.Report(context)
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Successful Operation", responses.Message)
+
+		data := responses.Data.(map[string]interface{})
+		assert.Equal(t, "2026-01-01", data["from"])
+		assert.Equal(t, "2026-02-28", data["to"])
+		assert.Equal(t, 2, len(data["periods"].([]interface{})))
+		assert.Equal(t, "2026-01", data["periods"].([]interface{})[0].(map[string]interface{})["period"])
+
+		total := data["total"].(map[string]interface{})
+		assert.Equal(t, float64(200000), total["gross_collected"])
+		assert.Equal(t, float64(8880), total["gateway_fees"])
+		assert.Equal(t, float64(11120), total["platform_margin"])
+	})
+
+	t.Run("settlement report with an invalid period", func(t *testing.T) {
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodGet, "/?period=week", nil)
+		res := httptest.NewRecorder()
+
+		context := e.NewContext(req, res)
+		context.SetPath("/settlements/report")
+
+		settlementController := settlement.NewSettlementController(mockSettlement{})
+		settlementController.Report(context)
+
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+	})
+
+	t.Run("settlement report with an invalid date", func(t *testing.T) {
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodGet, "/?to=28-02-2026", nil)
+		res := httptest.NewRecorder()
+
+		context := e.NewContext(req, res)
+		context.SetPath("/settlements/report")
+
+		settlementController := settlement.NewSettlementController(mockSettlement{})
+		settlementController.Report(context)
+
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+	})
+
+	t.Run("settlement report with from after to", func(t *testing.T) {
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodGet, "/?from=2026-03-01&to=2026-02-01", nil)
+		res := httptest.NewRecorder()
+
+		context := e.NewContext(req, res)
+		context.SetPath("/settlements/report")
+
+		settlementController := settlement.NewSettlementController(mockSettlement{})
+		settlementController.Report(context)
+
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+	})
+
+	t.Run("settlement report err Repo.Report", func(t *testing.T) {
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		res := httptest.NewRecorder()
+
+		context := e.NewContext(req, res)
+		context.SetPath("/settlements/report")
+
+		settlementController := settlement.NewSettlementController(mockFalseSettlement{})
+		settlementController.Report(context)
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Not Found", responses.Message)
+	})
+}
+
+// ======================
+// MOCK SETTLEMENT REPOSITORY
+// ======================
+type mockSettlement struct{}
+
+func (m mockSettlement) Report(from, to time.Time, period string) ([]settlementRepo.SettlementRow, error) {
+	return []settlementRepo.SettlementRow{
+		{Period: "2026-01", Transactions: 3, GrossCollected: 150000, GatewayFees: 6660, Settled: 143340, Commission: 15000, PlatformMargin: 8340},
+		{Period: "2026-02", Transactions: 1, GrossCollected: 50000, GatewayFees: 2220, Commission: 5000, PlatformMargin: 2780},
+	}, nil
+}
+
+type mockFalseSettlement struct{}
+
+func (m mockFalseSettlement) Report(from, to time.Time, period string) ([]settlementRepo.SettlementRow, error) {
+	return nil, errors.New("FAILED")
+}
@@ -0,0 +1,47 @@
+package shipping
+
+import (
+	"net/http"
+
+	"github.com/furqonzt99/snackbox/delivery/common"
+	"github.com/furqonzt99/snackbox/models"
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+type TariffRequest struct {
+	BaseCost                models.Money       `json:"base_cost" validate:"min=0"`
+	BaseDistance            float64            `json:"base_distance" validate:"min=0"`
+	FreeShippingMinSubtotal models.Money       `json:"free_shipping_min_subtotal" validate:"min=0"`
+	Tiers                   []TierRequest      `json:"tiers" validate:"dive"`
+	Zones                   []ZoneRequest      `json:"zones" validate:"dive"`
+	Surcharges              []SurchargeRequest `json:"surcharges" validate:"dive"`
+}
+
+type TierRequest struct {
+	UpToKm    float64      `json:"up_to_km" validate:"min=0"`
+	CostPerKm models.Money `json:"cost_per_km" validate:"min=0"`
+}
+
+type ZoneRequest struct {
+	Name     string       `json:"name" validate:"required"`
+	Polygon  [][2]float64 `json:"polygon" validate:"min=3"`
+	FlatCost models.Money `json:"flat_cost" validate:"min=0"`
+}
+
+type SurchargeRequest struct {
+	MinQuantity int          `json:"min_quantity" validate:"required,min=1"`
+	Amount      models.Money `json:"amount" validate:"min=0"`
+}
+
+type ShippingValidator struct {
+	Validator *validator.Validate
+}
+
+func (cv *ShippingValidator) Validate(i interface{}) error {
+	if err := cv.Validator.Struct(i); err != nil {
+		// Optionally, you could return the error to give each route more control over the status code
+		return echo.NewHTTPError(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package shipping
+
+import "github.com/furqonzt99/snackbox/models"
+
+type TariffResponse struct {
+	PartnerID               int                 `json:"partner_id"`
+	BaseCost                models.Money        `json:"base_cost"`
+	BaseDistance            float64             `json:"base_distance"`
+	FreeShippingMinSubtotal models.Money        `json:"free_shipping_min_subtotal"`
+	Tiers                   []TierResponse      `json:"tiers"`
+	Zones                   []ZoneResponse      `json:"zones"`
+	Surcharges              []SurchargeResponse `json:"surcharges"`
+}
+
+type TierResponse struct {
+	UpToKm    float64      `json:"up_to_km"`
+	CostPerKm models.Money `json:"cost_per_km"`
+}
+
+type ZoneResponse struct {
+	Name     string       `json:"name"`
+	Polygon  [][2]float64 `json:"polygon"`
+	FlatCost models.Money `json:"flat_cost"`
+}
+
+type SurchargeResponse struct {
+	MinQuantity int          `json:"min_quantity"`
+	Amount      models.Money `json:"amount"`
+}
@@ -0,0 +1,150 @@
+package shipping
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/furqonzt99/snackbox/delivery/common"
+	"github.com/furqonzt99/snackbox/delivery/middlewares"
+	"github.com/furqonzt99/snackbox/helper"
+	"github.com/furqonzt99/snackbox/models"
+	"github.com/furqonzt99/snackbox/repositories/shipping"
+	"github.com/labstack/echo/v4"
+)
+
+// the admin maintained default tariff is stored under partner id 0
+const DEFAULT_TARIFF_PARTNER_ID = 0
+
+type ShippingController struct {
+	Repo shipping.ShippingInterface
+}
+
+func NewShippingController(shipping shipping.ShippingInterface) *ShippingController {
+	return &ShippingController{Repo: shipping}
+}
+
+func (sc ShippingController) GetTariff(c echo.Context) error {
+	user, _ := middlewares.ExtractTokenUser(c)
+
+	return sc.getTariff(c, user.PartnerID)
+}
+
+func (sc ShippingController) SaveTariff(c echo.Context) error {
+	user, _ := middlewares.ExtractTokenUser(c)
+
+	return sc.saveTariff(c, user.PartnerID)
+}
+
+// DeleteTariff makes the partner fall back to the default tariff.
+func (sc ShippingController) DeleteTariff(c echo.Context) error {
+	user, _ := middlewares.ExtractTokenUser(c)
+
+	if err := sc.Repo.DeleteTariff(user.PartnerID); err != nil {
+		return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
+	}
+
+	return c.JSON(http.StatusOK, common.NewSuccessOperationResponse())
+}
+
+func (sc ShippingController) GetDefaultTariff(c echo.Context) error {
+	return sc.getTariff(c, DEFAULT_TARIFF_PARTNER_ID)
+}
+
+func (sc ShippingController) SaveDefaultTariff(c echo.Context) error {
+	return sc.saveTariff(c, DEFAULT_TARIFF_PARTNER_ID)
+}
+
+func (sc ShippingController) getTariff(c echo.Context, partnerID int) error {
+	tariff, err := sc.Repo.GetTariff(partnerID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
+	}
+
+	return c.JSON(http.StatusOK, common.SuccessResponse(newTariffResponse(tariff)))
+}
+
+func (sc ShippingController) saveTariff(c echo.Context, partnerID int) error {
+	var tariffRequest TariffRequest
+
+	c.Bind(&tariffRequest)
+
+	if err := c.Validate(&tariffRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	tariff := models.ShippingTariff{
+		PartnerID:               uint(partnerID),
+		BaseCost:                tariffRequest.BaseCost,
+		BaseDistance:            tariffRequest.BaseDistance,
+		FreeShippingMinSubtotal: tariffRequest.FreeShippingMinSubtotal,
+	}
+
+	for _, tier := range tariffRequest.Tiers {
+		tariff.Tiers = append(tariff.Tiers, models.ShippingTariffTier{
+			UpToKm:    tier.UpToKm,
+			CostPerKm: tier.CostPerKm,
+		})
+	}
+
+	for _, zone := range tariffRequest.Zones {
+		polygon, _ := json.Marshal(zone.Polygon)
+
+		tariff.Zones = append(tariff.Zones, models.ShippingZone{
+			Name:     zone.Name,
+			Polygon:  string(polygon),
+			FlatCost: zone.FlatCost,
+		})
+	}
+
+	for _, surcharge := range tariffRequest.Surcharges {
+		tariff.Surcharges = append(tariff.Surcharges, models.ShippingSurcharge{
+			MinQuantity: surcharge.MinQuantity,
+			Amount:      surcharge.Amount,
+		})
+	}
+
+	tariff, err := sc.Repo.SaveTariff(tariff)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	return c.JSON(http.StatusOK, common.SuccessResponse(newTariffResponse(tariff)))
+}
+
+func newTariffResponse(tariff models.ShippingTariff) TariffResponse {
+	response := TariffResponse{
+		PartnerID:               int(tariff.PartnerID),
+		BaseCost:                tariff.BaseCost,
+		BaseDistance:            tariff.BaseDistance,
+		FreeShippingMinSubtotal: tariff.FreeShippingMinSubtotal,
+		Tiers:                   []TierResponse{},
+		Zones:                   []ZoneResponse{},
+		Surcharges:              []SurchargeResponse{},
+	}
+
+	for _, tier := range tariff.Tiers {
+		response.Tiers = append(response.Tiers, TierResponse{
+			UpToKm:    tier.UpToKm,
+			CostPerKm: tier.CostPerKm,
+		})
+	}
+
+	for _, zone := range tariff.Zones {
+		polygon, _ := helper.ParsePolygon(zone.Polygon)
+
+		response.Zones = append(response.Zones, ZoneResponse{
+			Name:     zone.Name,
+			Polygon:  polygon,
+			FlatCost: zone.FlatCost,
+		})
+	}
+
+	for _, surcharge := range tariff.Surcharges {
+		response.Surcharges = append(response.Surcharges, SurchargeResponse{
+			MinQuantity: surcharge.MinQuantity,
+			Amount:      surcharge.Amount,
+		})
+	}
+
+	return response
+}
@@ -0,0 +1,283 @@
+package shipping_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/delivery/common"
+	"github.com/furqonzt99/snackbox/delivery/controllers/shipping"
+	"github.com/furqonzt99/snackbox/delivery/controllers/user"
+	"github.com/furqonzt99/snackbox/models"
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var JwtToken string
+
+func TestShipping(t *testing.T) {
+	t.Run("Test Login", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = &user.UserValidator{Validator: validator.New()}
+
+		requestBody, _ := json.Marshal(map[string]string{
+			"email":    "test@gmail.com",
+			"password": "test1234",
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(requestBody))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		context := e.NewContext(req, res)
+		context.SetPath("/login")
+
+		userController := user.NewUsersControllers(mockUserRepository{})
+		userController.LoginController()(context)
+
+		response := common.ResponseSuccess{}
+		json.Unmarshal([]byte(res.Body.Bytes()), &response)
+
+		JwtToken = response.Data.(string)
+		assert.Equal(t, "Successful Operation", response.Message)
+		assert.NotNil(t, JwtToken)
+	})
+
+	t.Run("get tariff success", func(t *testing.T) {
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/shipping/tariffs")
+
+		shippingController := shipping.NewShippingController(mockShipping{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(shippingController.GetTariff)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Successful Operation", responses.Message)
+
+		zones := responses.Data.(map[string]interface{})["zones"].([]interface{})
+		assert.Equal(t, 4, len(zones[0].(map[string]interface{})["polygon"].([]interface{})))
+	})
+
+	t.Run("get tariff not found", func(t *testing.T) {
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/shipping/tariffs")
+
+		shippingController := shipping.NewShippingController(mockFalseShipping{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(shippingController.GetTariff)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Not Found", responses.Message)
+	})
+
+	t.Run("save tariff success", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = &shipping.ShippingValidator{Validator: validator.New()}
+
+		requestBody, _ := json.Marshal(shipping.TariffRequest{
+			BaseCost:     20000,
+			BaseDistance: 2,
+			Tiers:        []shipping.TierRequest{{UpToKm: 10, CostPerKm: 5000}, {CostPerKm: 8000}},
+			Zones:        []shipping.ZoneRequest{{Name: "city center", Polygon: [][2]float64{{0, 0}, {0, 1}, {1, 1}}, FlatCost: 10000}},
+			Surcharges:   []shipping.SurchargeRequest{{MinQuantity: 100, Amount: 25000}},
+		})
+
+		req := httptest.NewRequest(http.MethodPut, "/", bytes.NewBuffer(requestBody))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/shipping/tariffs")
+
+		shippingController := shipping.NewShippingController(mockShipping{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(shippingController.SaveTariff)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Successful Operation", responses.Message)
+	})
+
+	t.Run("save tariff zone with too few points", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = &shipping.ShippingValidator{Validator: validator.New()}
+
+		requestBody, _ := json.Marshal(shipping.TariffRequest{
+			BaseCost: 20000,
+			Zones:    []shipping.ZoneRequest{{Name: "line", Polygon: [][2]float64{{0, 0}, {0, 1}}}},
+		})
+
+		req := httptest.NewRequest(http.MethodPut, "/", bytes.NewBuffer(requestBody))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/shipping/tariffs")
+
+		shippingController := shipping.NewShippingController(mockShipping{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(shippingController.SaveTariff)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Bad Request", responses.Message)
+	})
+
+	t.Run("save default tariff failed", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = &shipping.ShippingValidator{Validator: validator.New()}
+
+		requestBody, _ := json.Marshal(shipping.TariffRequest{
+			BaseCost: 20000,
+		})
+
+		req := httptest.NewRequest(http.MethodPut, "/", bytes.NewBuffer(requestBody))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+
+		context := e.NewContext(req, res)
+		context.SetPath("/shipping/tariffs/default")
+
+		shippingController := shipping.NewShippingController(mockFalseShipping{})
+		shippingController.SaveDefaultTariff(context)
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Bad Request", responses.Message)
+	})
+
+	t.Run("delete tariff", func(t *testing.T) {
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodDelete, "/", nil)
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/shipping/tariffs")
+
+		shippingController := shipping.NewShippingController(mockShipping{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(shippingController.DeleteTariff)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Successful Operation", responses.Message)
+	})
+}
+
+// ======================
+// MOCK SHIPPING REPOSITORY
+// ======================
+type mockShipping struct{}
+
+func (m mockShipping) GetTariff(partnerID int) (models.ShippingTariff, error) {
+	return models.ShippingTariff{
+		PartnerID:    uint(partnerID),
+		BaseCost:     20000,
+		BaseDistance: 2,
+		Tiers:        []models.ShippingTariffTier{{CostPerKm: 5000}},
+		Zones:        []models.ShippingZone{{Name: "city center", Polygon: "[[0,0],[0,1],[1,1],[1,0]]", FlatCost: 10000}},
+	}, nil
+}
+
+func (m mockShipping) SaveTariff(tariff models.ShippingTariff) (models.ShippingTariff, error) {
+	return tariff, nil
+}
+
+func (m mockShipping) DeleteTariff(partnerID int) error {
+	return nil
+}
+
+type mockFalseShipping struct{}
+
+func (m mockFalseShipping) GetTariff(partnerID int) (models.ShippingTariff, error) {
+	return models.ShippingTariff{}, errors.New("FAILED")
+}
+
+func (m mockFalseShipping) SaveTariff(tariff models.ShippingTariff) (models.ShippingTariff, error) {
+	return tariff, errors.New("FAILED")
+}
+
+func (m mockFalseShipping) DeleteTariff(partnerID int) error {
+	return errors.New("FAILED")
+}
+
+// ======================
+// MOCK USER REPOSITORY
+// ======================
+type mockUserRepository struct{}
+
+func (m mockUserRepository) Register(newUser models.User) (models.User, error) {
+	return newUser, nil
+}
+
+func (m mockUserRepository) Login(email string) (models.User, error) {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("test1234"), 14)
+	return models.User{
+		Email:    "test@gmail.com",
+		Password: string(hash),
+	}, nil
+}
+
+func (m mockUserRepository) Get(userid int) (models.User, error) {
+	return models.User{
+		Email: "test@gmail.com",
+		Name:  "tester",
+	}, nil
+}
+
+func (m mockUserRepository) Update(newUser models.User, userId int) (models.User, error) {
+	return newUser, nil
+}
+
+func (m mockUserRepository) Delete(userId int) (models.User, error) {
+	return models.User{}, nil
+}
@@ -0,0 +1,40 @@
+package subscription
+
+import (
+	"net/http"
+
+	"github.com/furqonzt99/snackbox/delivery/common"
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+// SubscriptionRequest describes a recurring order. Rule is an RRULE-like schedule,
+// e.g. FREQ=WEEKLY;BYDAY=MO,TH, and EndDate may be left empty to never end.
+type SubscriptionRequest struct {
+	Buffet     bool                      `json:"buffet"`
+	Latitude   float64                   `json:"latitude" validate:"required"`
+	Longtitude float64                   `json:"longtitude" validate:"required"`
+	Time       string                    `json:"time" validate:"required"`
+	Rule       string                    `json:"rule" validate:"required"`
+	StartDate  string                    `json:"start_date" validate:"required"`
+	EndDate    string                    `json:"end_date"`
+	Products   []SubscriptionItemRequest `json:"products" validate:"required,min=1,dive"`
+}
+
+type SubscriptionItemRequest struct {
+	ProductID int    `json:"product_id" validate:"required"`
+	Quantity  int    `json:"quantity" validate:"required,min=1"`
+	Notes     string `json:"notes"`
+}
+
+type SubscriptionValidator struct {
+	Validator *validator.Validate
+}
+
+func (sv *SubscriptionValidator) Validate(i interface{}) error {
+	if err := sv.Validator.Struct(i); err != nil {
+		// Optionally, you could return the error to give each route more control over the status code
+		return echo.NewHTTPError(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	return nil
+}
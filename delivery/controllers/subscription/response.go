@@ -0,0 +1,27 @@
+package subscription
+
+import "github.com/furqonzt99/snackbox/models"
+
+type SubscriptionResponse struct {
+	ID         int                        `json:"id"`
+	PartnerID  int                        `json:"partner_id"`
+	Buffet     bool                       `json:"buffet"`
+	Latitude   float64                    `json:"latitude"`
+	Longtitude float64                    `json:"longtitude"`
+	Time       string                     `json:"time"`
+	Rule       string                     `json:"rule"`
+	StartDate  string                     `json:"start_date"`
+	EndDate    string                     `json:"end_date"`
+	NextDate   string                     `json:"next_date"`
+	Status     string                     `json:"status"`
+	Note       string                     `json:"note"`
+	Products   []SubscriptionItemResponse `json:"products"`
+}
+
+type SubscriptionItemResponse struct {
+	ProductID int          `json:"product_id"`
+	Title     string       `json:"title"`
+	Price     models.Money `json:"price"`
+	Quantity  int          `json:"quantity"`
+	Notes     string       `json:"notes"`
+}
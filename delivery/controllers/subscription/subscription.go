@@ -0,0 +1,175 @@
+package subscription
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/furqonzt99/snackbox/delivery/common"
+	"github.com/furqonzt99/snackbox/delivery/middlewares"
+	"github.com/furqonzt99/snackbox/helper"
+	"github.com/furqonzt99/snackbox/models"
+	"github.com/furqonzt99/snackbox/repositories/subscription"
+	"github.com/labstack/echo/v4"
+)
+
+type SubscriptionController struct {
+	Repo subscription.SubscriptionInterface
+}
+
+func NewSubscriptionController(subscription subscription.SubscriptionInterface) *SubscriptionController {
+	return &SubscriptionController{Repo: subscription}
+}
+
+func (sc SubscriptionController) Create(c echo.Context) error {
+	var subscriptionRequest SubscriptionRequest
+
+	c.Bind(&subscriptionRequest)
+
+	if err := c.Validate(&subscriptionRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	if _, err := time.Parse("15:04:05", subscriptionRequest.Time); err != nil {
+		return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, "time must be formatted as HH:MM:SS"))
+	}
+
+	startDate, err := time.Parse(helper.CalendarDateFormat, subscriptionRequest.StartDate)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, "start_date must be formatted as YYYY-MM-DD"))
+	}
+
+	if startDate.Before(time.Now().UTC().Truncate(24 * time.Hour)) {
+		return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, "start_date cannot be in the past"))
+	}
+
+	if subscriptionRequest.EndDate != "" {
+		endDate, err := time.Parse(helper.CalendarDateFormat, subscriptionRequest.EndDate)
+		if err != nil || endDate.Before(startDate) {
+			return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, "end_date must be formatted as YYYY-MM-DD and not be before start_date"))
+		}
+	}
+
+	rule := strings.ToUpper(strings.TrimSpace(subscriptionRequest.Rule))
+	if _, err := helper.ParseRecurrence(rule); err != nil {
+		return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	user, _ := middlewares.ExtractTokenUser(c)
+
+	subscription := models.Subscription{
+		UserID:     uint(user.UserID),
+		Buffet:     subscriptionRequest.Buffet,
+		Latitude:   subscriptionRequest.Latitude,
+		Longtitude: subscriptionRequest.Longtitude,
+		Rule:       rule,
+		Time:       subscriptionRequest.Time,
+		StartDate:  subscriptionRequest.StartDate,
+		EndDate:    subscriptionRequest.EndDate,
+	}
+
+	for _, item := range subscriptionRequest.Products {
+		subscription.Items = append(subscription.Items, models.SubscriptionItem{
+			ProductID: uint(item.ProductID),
+			Quantity:  item.Quantity,
+			Notes:     item.Notes,
+		})
+	}
+
+	subscription, err = sc.Repo.Create(subscription)
+	if errors.Is(err, helper.ErrSubscriptionPartners) || errors.Is(err, helper.ErrInvalidRecurrence) {
+		return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	if err != nil {
+		return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
+	}
+
+	return c.JSON(http.StatusOK, common.SuccessResponse(newSubscriptionResponse(subscription)))
+}
+
+func (sc SubscriptionController) GetAll(c echo.Context) error {
+	user, _ := middlewares.ExtractTokenUser(c)
+
+	subscriptions, err := sc.Repo.GetAll(user.UserID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
+	}
+
+	response := []SubscriptionResponse{}
+	for _, subscription := range subscriptions {
+		response = append(response, newSubscriptionResponse(subscription))
+	}
+
+	return c.JSON(http.StatusOK, common.SuccessResponse(response))
+}
+
+func (sc SubscriptionController) Get(c echo.Context) error {
+	return sc.handle(c, sc.Repo.Get)
+}
+
+func (sc SubscriptionController) Pause(c echo.Context) error {
+	return sc.handle(c, sc.Repo.Pause)
+}
+
+func (sc SubscriptionController) Resume(c echo.Context) error {
+	return sc.handle(c, sc.Repo.Resume)
+}
+
+func (sc SubscriptionController) SkipNext(c echo.Context) error {
+	return sc.handle(c, sc.Repo.SkipNext)
+}
+
+func (sc SubscriptionController) Cancel(c echo.Context) error {
+	return sc.handle(c, sc.Repo.Cancel)
+}
+
+func (sc SubscriptionController) handle(c echo.Context, action func(subscriptionID, userID int) (models.Subscription, error)) error {
+	subscriptionID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	user, _ := middlewares.ExtractTokenUser(c)
+
+	subscription, err := action(subscriptionID, user.UserID)
+	if errors.Is(err, helper.ErrSubscriptionStatus) {
+		return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	if err != nil {
+		return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
+	}
+
+	return c.JSON(http.StatusOK, common.SuccessResponse(newSubscriptionResponse(subscription)))
+}
+
+func newSubscriptionResponse(subscription models.Subscription) SubscriptionResponse {
+	response := SubscriptionResponse{
+		ID:         int(subscription.ID),
+		PartnerID:  int(subscription.PartnerID),
+		Buffet:     subscription.Buffet,
+		Latitude:   subscription.Latitude,
+		Longtitude: subscription.Longtitude,
+		Time:       subscription.Time,
+		Rule:       subscription.Rule,
+		StartDate:  subscription.StartDate,
+		EndDate:    subscription.EndDate,
+		NextDate:   subscription.NextDate,
+		Status:     subscription.Status,
+		Note:       subscription.Note,
+		Products:   []SubscriptionItemResponse{},
+	}
+
+	for _, item := range subscription.Items {
+		response.Products = append(response.Products, SubscriptionItemResponse{
+			ProductID: int(item.ProductID),
+			Title:     item.Product.Title,
+			Price:     item.Product.Price,
+			Quantity:  item.Quantity,
+			Notes:     item.Notes,
+		})
+	}
+
+	return response
+}
@@ -0,0 +1,443 @@
+package subscription_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/delivery/common"
+	"github.com/furqonzt99/snackbox/delivery/controllers/subscription"
+	"github.com/furqonzt99/snackbox/delivery/controllers/user"
+	"github.com/furqonzt99/snackbox/helper"
+	"github.com/furqonzt99/snackbox/models"
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var JwtToken string
+
+func TestSubscription(t *testing.T) {
+	startDate := time.Now().AddDate(0, 0, 7).Format("2006-01-02")
+
+	t.Run("Test Login", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = &user.UserValidator{Validator: validator.New()}
+
+		requestBody, _ := json.Marshal(map[string]string{
+			"email":    "test@gmail.com",
+			"password": "test1234",
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(requestBody))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		context := e.NewContext(req, res)
+		context.SetPath("/login")
+
+		userController := user.NewUsersControllers(mockUserRepository{})
+		userController.LoginController()(context)
+
+		response := common.ResponseSuccess{}
+		json.Unmarshal([]byte(res.Body.Bytes()), &response)
+
+		JwtToken = response.Data.(string)
+		assert.Equal(t, "Successful Operation", response.Message)
+		assert.NotNil(t, JwtToken)
+	})
+
+	t.Run("create subscription success", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = &subscription.SubscriptionValidator{Validator: validator.New()}
+
+		requestBody, _ := json.Marshal(subscription.SubscriptionRequest{
+			Latitude:   -6.2,
+			Longtitude: 106.8,
+			Time:       "09:00:00",
+			Rule:       "freq=weekly;byday=mo,th",
+			StartDate:  startDate,
+			Products:   []subscription.SubscriptionItemRequest{{ProductID: 1, Quantity: 20}},
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(requestBody))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/subscriptions")
+
+		subscriptionController := subscription.NewSubscriptionController(mockSubscription{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(subscriptionController.Create)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Successful Operation", responses.Message)
+		assert.Equal(t, "FREQ=WEEKLY;BYDAY=MO,TH", responses.Data.(map[string]interface{})["rule"])
+	})
+
+	t.Run("create subscription invalid rule", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = &subscription.SubscriptionValidator{Validator: validator.New()}
+
+		requestBody, _ := json.Marshal(subscription.SubscriptionRequest{
+			Latitude:   -6.2,
+			Longtitude: 106.8,
+			Time:       "09:00:00",
+			Rule:       "FREQ=HOURLY",
+			StartDate:  startDate,
+			Products:   []subscription.SubscriptionItemRequest{{ProductID: 1, Quantity: 20}},
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(requestBody))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/subscriptions")
+
+		subscriptionController := subscription.NewSubscriptionController(mockSubscription{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(subscriptionController.Create)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, helper.ErrInvalidRecurrence.Error(), responses.Message)
+	})
+
+	t.Run("create subscription starting in the past", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = &subscription.SubscriptionValidator{Validator: validator.New()}
+
+		requestBody, _ := json.Marshal(subscription.SubscriptionRequest{
+			Latitude:   -6.2,
+			Longtitude: 106.8,
+			Time:       "09:00:00",
+			Rule:       "FREQ=DAILY",
+			StartDate:  "2022-01-01",
+			Products:   []subscription.SubscriptionItemRequest{{ProductID: 1, Quantity: 20}},
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(requestBody))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/subscriptions")
+
+		subscriptionController := subscription.NewSubscriptionController(mockSubscription{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(subscriptionController.Create)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "start_date cannot be in the past", responses.Message)
+	})
+
+	t.Run("create subscription from several partners", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = &subscription.SubscriptionValidator{Validator: validator.New()}
+
+		requestBody, _ := json.Marshal(subscription.SubscriptionRequest{
+			Latitude:   -6.2,
+			Longtitude: 106.8,
+			Time:       "09:00:00",
+			Rule:       "FREQ=MONTHLY;BYMONTHDAY=1",
+			StartDate:  startDate,
+			Products:   []subscription.SubscriptionItemRequest{{ProductID: 1, Quantity: 20}, {ProductID: 2, Quantity: 20}},
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(requestBody))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/subscriptions")
+
+		subscriptionController := subscription.NewSubscriptionController(mockFalseSubscription{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(subscriptionController.Create)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, helper.ErrSubscriptionPartners.Error(), responses.Message)
+	})
+
+	t.Run("get all subscriptions", func(t *testing.T) {
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/subscriptions")
+
+		subscriptionController := subscription.NewSubscriptionController(mockSubscription{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(subscriptionController.GetAll)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Successful Operation", responses.Message)
+		assert.Equal(t, 1, len(responses.Data.([]interface{})))
+	})
+
+	t.Run("skip next delivery", func(t *testing.T) {
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodPut, "/", nil)
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/subscriptions/:id/skip")
+		context.SetParamNames("id")
+		context.SetParamValues("1")
+
+		subscriptionController := subscription.NewSubscriptionController(mockSubscription{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(subscriptionController.SkipNext)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Successful Operation", responses.Message)
+		assert.Equal(t, "2026-01-08", responses.Data.(map[string]interface{})["next_date"])
+	})
+
+	t.Run("pause subscription badrequest param", func(t *testing.T) {
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodPut, "/", nil)
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/subscriptions/:id/pause")
+		context.SetParamNames("id")
+		context.SetParamValues("abc")
+
+		subscriptionController := subscription.NewSubscriptionController(mockSubscription{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(subscriptionController.Pause)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Bad Request", responses.Message)
+	})
+
+	t.Run("resume subscription that is not paused", func(t *testing.T) {
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodPut, "/", nil)
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/subscriptions/:id/resume")
+		context.SetParamNames("id")
+		context.SetParamValues("1")
+
+		subscriptionController := subscription.NewSubscriptionController(mockFalseSubscription{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(subscriptionController.Resume)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, helper.ErrSubscriptionStatus.Error(), responses.Message)
+	})
+
+	t.Run("cancel subscription not found", func(t *testing.T) {
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodPut, "/", nil)
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/subscriptions/:id/cancel")
+		context.SetParamNames("id")
+		context.SetParamValues("1")
+
+		subscriptionController := subscription.NewSubscriptionController(mockFalseSubscription{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(subscriptionController.Cancel)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Not Found", responses.Message)
+	})
+}
+
+// ======================
+// MOCK SUBSCRIPTION REPOSITORY
+// ======================
+type mockSubscription struct{}
+
+func (m mockSubscription) Create(subscription models.Subscription) (models.Subscription, error) {
+	subscription.ID = 1
+	subscription.PartnerID = 1
+	subscription.NextDate = subscription.StartDate
+	subscription.Status = constants.SUBSCRIPTION_ACTIVE
+	return subscription, nil
+}
+
+func (m mockSubscription) GetAll(userID int) ([]models.Subscription, error) {
+	return []models.Subscription{{Rule: "FREQ=DAILY", Status: constants.SUBSCRIPTION_ACTIVE}}, nil
+}
+
+func (m mockSubscription) Get(subscriptionID, userID int) (models.Subscription, error) {
+	return models.Subscription{Rule: "FREQ=WEEKLY", NextDate: "2026-01-01", Status: constants.SUBSCRIPTION_ACTIVE}, nil
+}
+
+func (m mockSubscription) Pause(subscriptionID, userID int) (models.Subscription, error) {
+	return models.Subscription{Status: constants.SUBSCRIPTION_PAUSED}, nil
+}
+
+func (m mockSubscription) Resume(subscriptionID, userID int) (models.Subscription, error) {
+	return models.Subscription{Status: constants.SUBSCRIPTION_ACTIVE}, nil
+}
+
+func (m mockSubscription) SkipNext(subscriptionID, userID int) (models.Subscription, error) {
+	return models.Subscription{Rule: "FREQ=WEEKLY", NextDate: "2026-01-08", Status: constants.SUBSCRIPTION_ACTIVE}, nil
+}
+
+func (m mockSubscription) Cancel(subscriptionID, userID int) (models.Subscription, error) {
+	return models.Subscription{Status: constants.SUBSCRIPTION_CANCELLED}, nil
+}
+
+func (m mockSubscription) GetActive() ([]models.Subscription, error) {
+	return []models.Subscription{}, nil
+}
+
+func (m mockSubscription) Ordered(subscriptionID int, dateTime time.Time) (bool, error) {
+	return false, nil
+}
+
+func (m mockSubscription) Advance(subscriptionID int, note string) error {
+	return nil
+}
+
+type mockFalseSubscription struct{}
+
+func (m mockFalseSubscription) Create(subscription models.Subscription) (models.Subscription, error) {
+	return subscription, helper.ErrSubscriptionPartners
+}
+
+func (m mockFalseSubscription) GetAll(userID int) ([]models.Subscription, error) {
+	return nil, errors.New("FAILED")
+}
+
+func (m mockFalseSubscription) Get(subscriptionID, userID int) (models.Subscription, error) {
+	return models.Subscription{}, errors.New("FAILED")
+}
+
+func (m mockFalseSubscription) Pause(subscriptionID, userID int) (models.Subscription, error) {
+	return models.Subscription{}, helper.ErrSubscriptionStatus
+}
+
+func (m mockFalseSubscription) Resume(subscriptionID, userID int) (models.Subscription, error) {
+	return models.Subscription{}, helper.ErrSubscriptionStatus
+}
+
+func (m mockFalseSubscription) SkipNext(subscriptionID, userID int) (models.Subscription, error) {
+	return models.Subscription{}, helper.ErrSubscriptionStatus
+}
+
+func (m mockFalseSubscription) Cancel(subscriptionID, userID int) (models.Subscription, error) {
+	return models.Subscription{}, errors.New("FAILED")
+}
+
+func (m mockFalseSubscription) GetActive() ([]models.Subscription, error) {
+	return nil, errors.New("FAILED")
+}
+
+func (m mockFalseSubscription) Ordered(subscriptionID int, dateTime time.Time) (bool, error) {
+	return false, errors.New("FAILED")
+}
+
+func (m mockFalseSubscription) Advance(subscriptionID int, note string) error {
+	return errors.New("FAILED")
+}
+
+// ======================
+// MOCK USER REPOSITORY
+// ======================
+type mockUserRepository struct{}
+
+func (m mockUserRepository) Register(newUser models.User) (models.User, error) {
+	return newUser, nil
+}
+
+func (m mockUserRepository) Login(email string) (models.User, error) {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("test1234"), 14)
+	return models.User{
+		Email:    "test@gmail.com",
+		Password: string(hash),
+	}, nil
+}
+
+func (m mockUserRepository) Get(userid int) (models.User, error) {
+	return models.User{
+		Email: "test@gmail.com",
+		Name:  "tester",
+	}, nil
+}
+
+func (m mockUserRepository) Update(newUser models.User, userId int) (models.User, error) {
+	return newUser, nil
+}
+
+func (m mockUserRepository) Delete(userId int) (models.User, error) {
+	return models.User{}, nil
+}
@@ -0,0 +1,35 @@
+package tax
+
+import (
+	"net/http"
+
+	"github.com/furqonzt99/snackbox/delivery/common"
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+// TaxRuleRequest creates a rule, Type is PB1 for restaurant tax or VAT.
+type TaxRuleRequest struct {
+	Type        string  `json:"type" validate:"required,oneof=PB1 VAT"`
+	Rate        float64 `json:"rate" validate:"gt=0,max=100"`
+	Inclusive   bool    `json:"inclusive"`
+	TaxShipping bool    `json:"tax_shipping"`
+}
+
+type UpdateTaxRuleRequest struct {
+	Rate        float64 `json:"rate" validate:"gt=0,max=100"`
+	Inclusive   bool    `json:"inclusive"`
+	TaxShipping bool    `json:"tax_shipping"`
+}
+
+type TaxValidator struct {
+	Validator *validator.Validate
+}
+
+func (tv *TaxValidator) Validate(i interface{}) error {
+	if err := tv.Validator.Struct(i); err != nil {
+		// Optionally, you could return the error to give each route more control over the status code
+		return echo.NewHTTPError(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	return nil
+}
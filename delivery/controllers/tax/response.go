@@ -0,0 +1,21 @@
+package tax
+
+import "github.com/furqonzt99/snackbox/models"
+
+type TaxRuleResponse struct {
+	ID          uint    `json:"id"`
+	Type        string  `json:"type"`
+	Rate        float64 `json:"rate"`
+	Inclusive   bool    `json:"inclusive"`
+	TaxShipping bool    `json:"tax_shipping"`
+}
+
+func newTaxRuleResponse(rule models.TaxRule) TaxRuleResponse {
+	return TaxRuleResponse{
+		ID:          rule.ID,
+		Type:        rule.Type,
+		Rate:        rule.Rate,
+		Inclusive:   rule.Inclusive,
+		TaxShipping: rule.TaxShipping,
+	}
+}
@@ -0,0 +1,108 @@
+package tax
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/furqonzt99/snackbox/delivery/common"
+	"github.com/furqonzt99/snackbox/delivery/middlewares"
+	"github.com/furqonzt99/snackbox/models"
+	"github.com/furqonzt99/snackbox/repositories/tax"
+	"github.com/labstack/echo/v4"
+)
+
+type TaxController struct {
+	Repo tax.TaxInterface
+}
+
+func NewTaxController(tax tax.TaxInterface) *TaxController {
+	return &TaxController{Repo: tax}
+}
+
+func (tc TaxController) GetAll(c echo.Context) error {
+	user, _ := middlewares.ExtractTokenUser(c)
+
+	rules, err := tc.Repo.GetRules(user.PartnerID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
+	}
+
+	response := []TaxRuleResponse{}
+	for _, rule := range rules {
+		response = append(response, newTaxRuleResponse(rule))
+	}
+
+	return c.JSON(http.StatusOK, common.SuccessResponse(response))
+}
+
+func (tc TaxController) Create(c echo.Context) error {
+	var ruleRequest TaxRuleRequest
+
+	if err := c.Bind(&ruleRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	if err := c.Validate(&ruleRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	user, _ := middlewares.ExtractTokenUser(c)
+
+	rule, err := tc.Repo.Create(models.TaxRule{
+		PartnerID:   uint(user.PartnerID),
+		Type:        ruleRequest.Type,
+		Rate:        ruleRequest.Rate,
+		Inclusive:   ruleRequest.Inclusive,
+		TaxShipping: ruleRequest.TaxShipping,
+	})
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, "a rule for this tax type already exists"))
+	}
+
+	return c.JSON(http.StatusOK, common.SuccessResponse(newTaxRuleResponse(rule)))
+}
+
+func (tc TaxController) Update(c echo.Context) error {
+	ruleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	var ruleRequest UpdateTaxRuleRequest
+
+	if err := c.Bind(&ruleRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	if err := c.Validate(&ruleRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	user, _ := middlewares.ExtractTokenUser(c)
+
+	rule, err := tc.Repo.Update(ruleID, user.PartnerID, models.TaxRule{
+		Rate:        ruleRequest.Rate,
+		Inclusive:   ruleRequest.Inclusive,
+		TaxShipping: ruleRequest.TaxShipping,
+	})
+	if err != nil {
+		return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
+	}
+
+	return c.JSON(http.StatusOK, common.SuccessResponse(newTaxRuleResponse(rule)))
+}
+
+func (tc TaxController) Delete(c echo.Context) error {
+	ruleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	user, _ := middlewares.ExtractTokenUser(c)
+
+	if err := tc.Repo.Delete(ruleID, user.PartnerID); err != nil {
+		return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
+	}
+
+	return c.JSON(http.StatusOK, common.NewSuccessOperationResponse())
+}
@@ -0,0 +1,331 @@
+package tax_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/delivery/common"
+	"github.com/furqonzt99/snackbox/delivery/controllers/tax"
+	"github.com/furqonzt99/snackbox/delivery/controllers/user"
+	"github.com/furqonzt99/snackbox/models"
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var JwtToken string
+
+func TestTax(t *testing.T) {
+	t.Run("Test Login", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = &user.UserValidator{Validator: validator.New()}
+
+		requestBody, _ := json.Marshal(map[string]string{
+			"email":    "test@gmail.com",
+			"password": "test1234",
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(requestBody))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		context := e.NewContext(req, res)
+		context.SetPath("/login")
+
+		userController := user.NewUsersControllers(mockUserRepository{})
+		userController.LoginController()(context)
+
+		response := common.ResponseSuccess{}
+		json.Unmarshal([]byte(res.Body.Bytes()), &response)
+
+		JwtToken = response.Data.(string)
+		assert.Equal(t, "Successful Operation", response.Message)
+		assert.NotNil(t, JwtToken)
+	})
+
+	t.Run("create rule", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = &tax.TaxValidator{Validator: validator.New()}
+
+		requestBody, _ := json.Marshal(tax.TaxRuleRequest{
+			Type:        constants.PB1_TAX,
+			Rate:        10,
+			TaxShipping: true,
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(requestBody))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/taxes")
+
+		taxController := tax.NewTaxController(mockTax{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(taxController.Create)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Successful Operation", responses.Message)
+		assert.Equal(t, constants.PB1_TAX, responses.Data.(map[string]interface{})["type"])
+		assert.Equal(t, true, responses.Data.(map[string]interface{})["tax_shipping"])
+	})
+
+	t.Run("create rule of an unknown type", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = &tax.TaxValidator{Validator: validator.New()}
+
+		requestBody, _ := json.Marshal(tax.TaxRuleRequest{
+			Type: "SALES",
+			Rate: 10,
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(requestBody))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/taxes")
+
+		taxController := tax.NewTaxController(mockTax{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(taxController.Create)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Bad Request", responses.Message)
+	})
+
+	t.Run("create rule for an existing type", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = &tax.TaxValidator{Validator: validator.New()}
+
+		requestBody, _ := json.Marshal(tax.TaxRuleRequest{
+			Type: constants.VAT_TAX,
+			Rate: 11,
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(requestBody))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/taxes")
+
+		taxController := tax.NewTaxController(mockFalseTax{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(taxController.Create)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+	})
+
+	t.Run("get all rules", func(t *testing.T) {
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/taxes")
+
+		taxController := tax.NewTaxController(mockTax{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(taxController.GetAll)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Successful Operation", responses.Message)
+		assert.Len(t, responses.Data, 1)
+	})
+
+	t.Run("update rule", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = &tax.TaxValidator{Validator: validator.New()}
+
+		requestBody, _ := json.Marshal(tax.UpdateTaxRuleRequest{
+			Rate:      11,
+			Inclusive: true,
+		})
+
+		req := httptest.NewRequest(http.MethodPut, "/", bytes.NewBuffer(requestBody))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/taxes/:id")
+		context.SetParamNames("id")
+		context.SetParamValues("1")
+
+		taxController := tax.NewTaxController(mockTax{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(taxController.Update)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Successful Operation", responses.Message)
+		assert.Equal(t, float64(11), responses.Data.(map[string]interface{})["rate"])
+		assert.Equal(t, true, responses.Data.(map[string]interface{})["inclusive"])
+	})
+
+	t.Run("update rule with a zero rate", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = &tax.TaxValidator{Validator: validator.New()}
+
+		requestBody, _ := json.Marshal(tax.UpdateTaxRuleRequest{})
+
+		req := httptest.NewRequest(http.MethodPut, "/", bytes.NewBuffer(requestBody))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/taxes/:id")
+		context.SetParamNames("id")
+		context.SetParamValues("1")
+
+		taxController := tax.NewTaxController(mockTax{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(taxController.Update)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Bad Request", responses.Message)
+	})
+
+	t.Run("delete rule not found", func(t *testing.T) {
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodDelete, "/", nil)
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/taxes/:id")
+		context.SetParamNames("id")
+		context.SetParamValues("1")
+
+		taxController := tax.NewTaxController(mockFalseTax{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(taxController.Delete)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Not Found", responses.Message)
+	})
+}
+
+// ======================
+// MOCK TAX REPOSITORY
+// ======================
+type mockTax struct{}
+
+func (m mockTax) GetRules(partnerID int) ([]models.TaxRule, error) {
+	return []models.TaxRule{{PartnerID: uint(partnerID), Type: constants.PB1_TAX, Rate: 10}}, nil
+}
+
+func (m mockTax) Create(rule models.TaxRule) (models.TaxRule, error) {
+	rule.ID = 1
+	return rule, nil
+}
+
+func (m mockTax) Update(ruleID, partnerID int, rule models.TaxRule) (models.TaxRule, error) {
+	rule.ID = uint(ruleID)
+	rule.PartnerID = uint(partnerID)
+	rule.Type = constants.PB1_TAX
+	return rule, nil
+}
+
+func (m mockTax) Delete(ruleID, partnerID int) error {
+	return nil
+}
+
+type mockFalseTax struct{}
+
+func (m mockFalseTax) GetRules(partnerID int) ([]models.TaxRule, error) {
+	return nil, errors.New("FAILED")
+}
+
+func (m mockFalseTax) Create(rule models.TaxRule) (models.TaxRule, error) {
+	return rule, errors.New("FAILED")
+}
+
+func (m mockFalseTax) Update(ruleID, partnerID int, rule models.TaxRule) (models.TaxRule, error) {
+	return rule, errors.New("FAILED")
+}
+
+func (m mockFalseTax) Delete(ruleID, partnerID int) error {
+	return errors.New("FAILED")
+}
+
+// ======================
+// MOCK USER REPOSITORY
+// ======================
+type mockUserRepository struct{}
+
+func (m mockUserRepository) Register(newUser models.User) (models.User, error) {
+	return newUser, nil
+}
+
+func (m mockUserRepository) Login(email string) (models.User, error) {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("test1234"), 14)
+	return models.User{
+		Email:    "test@gmail.com",
+		Password: string(hash),
+	}, nil
+}
+
+func (m mockUserRepository) Get(userid int) (models.User, error) {
+	return models.User{
+		Email: "test@gmail.com",
+		Name:  "tester",
+	}, nil
+}
+
+func (m mockUserRepository) Update(newUser models.User, userId int) (models.User, error) {
+	return newUser, nil
+}
+
+func (m mockUserRepository) Delete(userId int) (models.User, error) {
+	return models.User{}, nil
+}
@@ -4,24 +4,39 @@ import (
 	"net/http"
 
 	"github.com/furqonzt99/snackbox/delivery/common"
+	"github.com/furqonzt99/snackbox/models"
 	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
 )
 
 type TransactionRequest struct {
 	Buffet bool `json:"buffet"`
-	Quantity int `json:"quantity" validate:"required"`
 	Date string `json:"date" validate:"required"`
 	Time string `json:"time" validate:"required"`
 	Latitude float64 `json:"latitude" validate:"required"`
 	Longtitude float64 `json:"longtitude" validate:"required"`
-	Products []int `json:"products" validate:"required"`
+	Products []TransactionItemRequest `json:"products" validate:"required,min=1,dive"`
+	VoucherCode string `json:"voucher_code"`
+}
+
+type TransactionItemRequest struct {
+	ProductID int `json:"product_id" validate:"required"`
+	Quantity int `json:"quantity" validate:"required,min=1"`
+	Notes string `json:"notes"`
+}
+
+type TransactionStatusRequest struct {
+	Reason string `json:"reason"`
 }
 
 type ShippingCostRequest struct {
 	PartnerID int `json:"partner_id" validate:"required"`
 	Latitude float64 `json:"latitude" validate:"required"`
 	Longtitude float64 `json:"longtitude" validate:"required"`
+	Date string `json:"date"`
+	Time string `json:"time"`
+	Quantity int `json:"quantity" validate:"min=0"`
+	Subtotal models.Money `json:"subtotal" validate:"min=0"`
 }
 
 type TransactionValidator struct {
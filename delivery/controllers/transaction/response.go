@@ -1,6 +1,6 @@
 package transaction
 
-import "github.com/furqonzt99/snackbox/delivery/controllers/product"
+import "github.com/furqonzt99/snackbox/models"
 
 type TransactionResponse struct {
 	ID int `json:"id"`
@@ -14,16 +14,64 @@ type TransactionResponse struct {
 	Longtitude float64 `json:"longtitude"`
 	DateTime string `json:"datetime"`
 	Distance float32 `json:"distance"`
-	TotalPrice float64 `json:"total_price"`
+	TotalPrice models.Money `json:"total_price"`
+	ShippingCost models.Money `json:"shipping_cost"`
+	VoucherCode string `json:"voucher_code"`
+	Discount models.Money `json:"discount"`
+	Tax models.Money `json:"tax"`
 	PaymentUrl string `json:"payment_url"`
 	PaymentMethod string `json:"payment_method"`
 	PaymentChannel string `json:"payment_channel"`
 	PaidAt string `json:"paid_at"`
 	Status string `json:"status"`
-	Products []product.ProductResponse `json:"products"`
+	Products []TransactionItemResponse `json:"products"`
+	Taxes []TaxResponse `json:"taxes,omitempty"`
+	Invoices []InvoiceResponse `json:"invoices,omitempty"`
+}
+
+type TaxResponse struct {
+	Type string `json:"type"`
+	Rate float64 `json:"rate"`
+	Inclusive bool `json:"inclusive"`
+	Base models.Money `json:"base"`
+	Amount models.Money `json:"amount"`
+}
+
+type InvoiceResponse struct {
+	InvoiceID string `json:"invoice_id"`
+	PaymentUrl string `json:"payment_url"`
+	Amount models.Money `json:"amount"`
+	Status string `json:"status"`
+	ExpiresAt string `json:"expires_at"`
+	CreatedAt string `json:"created_at"`
+}
+
+type TransactionItemResponse struct {
+	ProductID int `json:"product_id"`
+	Title string `json:"title"`
+	Image string `json:"image"`
+	Type string `json:"type"`
+	Price models.Money `json:"price"`
+	Quantity int `json:"quantity"`
+	Notes string `json:"notes"`
+	Subtotal models.Money `json:"subtotal"`
+}
+
+type TimelineResponse struct {
+	FromStatus string `json:"from_status"`
+	Status string `json:"status"`
+	ActorID int `json:"actor_id"`
+	ActorRole string `json:"actor_role"`
+	Reason string `json:"reason"`
+	CreatedAt string `json:"created_at"`
 }
 
 type ShippingCostResponse struct {
 	Distance float64 `json:"distance"`
-	Cost float64 `json:"cost"`
+	Cost models.Money `json:"cost"`
+	Zone string `json:"zone"`
+	BaseCost models.Money `json:"base_cost"`
+	DistanceCost models.Money `json:"distance_cost"`
+	Surcharge models.Money `json:"surcharge"`
+	Discount models.Money `json:"discount"`
 }
\ No newline at end of file
@@ -1,6 +1,8 @@
 package transaction
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -9,12 +11,11 @@ import (
 
 	"github.com/furqonzt99/snackbox/constants"
 	"github.com/furqonzt99/snackbox/delivery/common"
-	"github.com/furqonzt99/snackbox/delivery/controllers/product"
 	"github.com/furqonzt99/snackbox/delivery/middlewares"
 	"github.com/furqonzt99/snackbox/helper"
 	"github.com/furqonzt99/snackbox/models"
+	"github.com/furqonzt99/snackbox/payment"
 	"github.com/furqonzt99/snackbox/repositories/transaction"
-	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 )
 
@@ -38,56 +39,85 @@ func (tc *TransactionController) Order(c echo.Context) error {
 	user, _ := middlewares.ExtractTokenUser(c)
 
 	//get partner id from product
-	partner, err := tc.Repo.GetPartnerFromProduct(transactionRequest.Products[0])
+	partner, err := tc.Repo.GetPartnerFromProduct(transactionRequest.Products[0].ProductID)
 	if err != nil {
 		return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
 	}
 
-	// create invoiceID
-	invoiceId := strings.ToUpper(strings.ReplaceAll(uuid.New().String(), "-", ""))
-
-	dateTime, _ := time.Parse(time.RFC3339, fmt.Sprintf("%vT%vZ", transactionRequest.Date, transactionRequest.Time))
-	threeDayLater := time.Now().AddDate(0, 0, 3)
+	// every product of one transaction must come from the same partner
+	for _, item := range transactionRequest.Products[1:] {
+		itemPartner, err := tc.Repo.GetPartnerFromProduct(item.ProductID)
+		if err != nil {
+			return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
+		}
 
-	if threeDayLater.After(dateTime) {
-		return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, "you must reservate 3 days before the event time!"))
+		if itemPartner.ID != partner.ID {
+			return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, "all products must come from the same partner, use the cart to order from several partners"))
+		}
 	}
 
+	// create invoiceID
+	invoiceId := helper.NewInvoiceID()
+
+	dateTime, _ := helper.ParseEventTime(transactionRequest.Date, transactionRequest.Time)
+
 	distance, err := tc.Repo.GetDistance(int(partner.ID), transactionRequest.Latitude, transactionRequest.Longtitude)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
 	}
 
+	items := []models.DetailTransaction{}
+	var quantity int
+	for _, item := range transactionRequest.Products {
+		items = append(items, models.DetailTransaction{
+			ProductID: uint(item.ProductID),
+			Quantity:  item.Quantity,
+			Notes:     item.Notes,
+		})
+		quantity += item.Quantity
+	}
+
+	if err := helper.ValidateOrderRules(partner, dateTime, quantity, distance); err != nil {
+		return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
 	transaction := models.Transaction{
-		UserID:     uint(user.UserID),
-		PartnerID:  uint(partner.ID),
-		Buffet:     transactionRequest.Buffet,
-		Quantity:   transactionRequest.Quantity,
-		DateTime:   dateTime,
-		Latitude:   transactionRequest.Latitude,
-		Longtitude: transactionRequest.Longtitude,
-		Distance:   distance,
-		InvoiceID:  invoiceId,
-	}
-
-	transactionOrder, err := tc.Repo.Order(transaction, user.Email, transactionRequest.Products)
+		UserID:      uint(user.UserID),
+		PartnerID:   uint(partner.ID),
+		Buffet:      transactionRequest.Buffet,
+		Quantity:    quantity,
+		DateTime:    dateTime,
+		Latitude:    transactionRequest.Latitude,
+		Longtitude:  transactionRequest.Longtitude,
+		Distance:    distance,
+		InvoiceID:   invoiceId,
+		VoucherCode: strings.ToUpper(strings.TrimSpace(transactionRequest.VoucherCode)),
+	}
+
+	transactionOrder, err := tc.Repo.Order(transaction, user.Email, items)
+	if errors.Is(err, helper.ErrPartnerUnavailable) || errors.Is(err, helper.ErrCapacityExceeded) || errors.Is(err, helper.ErrInvalidVoucher) {
+		return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
 	}
 
-	productItems := []product.ProductResponse{}
+	productItems := []TransactionItemResponse{}
 
-	for _, data := range transactionOrder.Products {
+	for _, data := range transactionOrder.DetailTransactions {
 		var productImage string
-			if data.Image != "" {
-				productImage = fmt.Sprintf(constants.LINK_TEMPLATE, constants.S3_BUCKET, constants.S3_REGION, data.Image)
-			}
-		productItems = append(productItems, product.ProductResponse{
-			Title:       data.Title,
-			Image:       productImage,
-			Type:        data.Type,
-			Description: data.Description,
-			Price:       data.Price,
+		if data.Product.Image != "" {
+			productImage = fmt.Sprintf(constants.LINK_TEMPLATE, constants.S3_BUCKET, constants.S3_REGION, data.Product.Image)
+		}
+		productItems = append(productItems, TransactionItemResponse{
+			ProductID: int(data.ProductID),
+			Title:     data.Title,
+			Image:     productImage,
+			Type:      data.Type,
+			Price:     data.Price,
+			Quantity:  data.Quantity,
+			Notes:     data.Notes,
+			Subtotal:  data.Price * models.Money(data.Quantity),
 		})
 	}
 
@@ -104,21 +134,33 @@ func (tc *TransactionController) Order(c echo.Context) error {
 		DateTime:       fmt.Sprint(transactionOrder.DateTime),
 		Distance:       float32(transactionOrder.Distance),
 		TotalPrice:     transactionOrder.TotalPrice,
+		ShippingCost:   transactionOrder.ShippingCost,
+		VoucherCode:    transactionOrder.VoucherCode,
+		Discount:       transactionOrder.Discount,
+		Tax:            transactionOrder.Tax,
 		PaymentUrl:     transactionOrder.PaymentUrl,
 		PaymentMethod:  transactionOrder.PaymentMethod,
 		PaymentChannel: transactionOrder.PaymentChannel,
 		PaidAt:         fmt.Sprint(transactionOrder.PaidAt),
 		Status:         transactionOrder.Status,
 		Products:       productItems,
+		Taxes:          TaxResponses(transactionOrder.Taxes),
 	}
 
 	return c.JSON(http.StatusOK, common.SuccessResponse(response))
 }
 
-func (tc TransactionController) Callback(c echo.Context) error {
+// HandleCallback applies an invoice callback stored by the webhook controller.
+func (tc TransactionController) HandleCallback(body []byte) error {
 
 	var callbackRequest common.TransactionCallbackRequest
-	c.Bind(&callbackRequest)
+	if err := json.Unmarshal(body, &callbackRequest); err != nil {
+		return err
+	}
+
+	if callbackRequest.ExternalID == "" {
+		return common.ErrInvalidCallback
+	}
 
 	var data models.Transaction
 	data.PaidAt, _ = time.Parse(time.RFC3339, callbackRequest.PaidAt)
@@ -126,17 +168,35 @@ func (tc TransactionController) Callback(c echo.Context) error {
 	data.PaymentChannel = callbackRequest.PaymentChannel
 	data.Status = callbackRequest.Status
 
-	var refund float64
+	// the gateway takes its fee out of what it settles, the paid amount is what the customer paid
+	data.PaidAmount = models.NewMoney(callbackRequest.PaidAmount)
+	if callbackRequest.AdjustedReceivedAmount > 0 {
+		data.GatewayFee = data.PaidAmount - models.NewMoney(callbackRequest.AdjustedReceivedAmount)
+	}
 
-	if callbackRequest.Status == "PAID" {
-		refund = 0
-	} else {
-		for _, item := range callbackRequest.Items {
-			refund += item.Price
-		}
+	data.SettlementStatus = callbackRequest.SettlementStatus
+	if data.Status == payment.INVOICE_PAID && data.SettlementStatus == "" {
+		data.SettlementStatus = constants.SETTLEMENT_PENDING
 	}
 
-	_, err := tc.Repo.Callback(callbackRequest.ExternalID, data, refund)
+	_, err := tc.Repo.Callback(callbackRequest.ExternalID, data)
+
+	return err
+}
+
+func (tc TransactionController) Accept(c echo.Context) error {
+
+	trxID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	user, _ := middlewares.ExtractTokenUser(c)
+
+	_, err = tc.Repo.Accept(trxID, user.PartnerID)
+	if errors.Is(err, helper.ErrInvalidTransition) {
+		return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
 	if err != nil {
 		return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
 	}
@@ -144,16 +204,22 @@ func (tc TransactionController) Callback(c echo.Context) error {
 	return c.JSON(http.StatusOK, common.NewSuccessOperationResponse())
 }
 
-func (tc TransactionController) Accept(c echo.Context) error {
+func (tc TransactionController) Reject(c echo.Context) error {
 
 	trxID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
 	}
 
+	var statusRequest TransactionStatusRequest
+	c.Bind(&statusRequest)
+
 	user, _ := middlewares.ExtractTokenUser(c)
 
-	_, err = tc.Repo.Accept(trxID, user.PartnerID)
+	_, err = tc.Repo.Reject(trxID, user.PartnerID, statusRequest.Reason)
+	if errors.Is(err, helper.ErrInvalidTransition) {
+		return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
 	if err != nil {
 		return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
 	}
@@ -161,7 +227,7 @@ func (tc TransactionController) Accept(c echo.Context) error {
 	return c.JSON(http.StatusOK, common.NewSuccessOperationResponse())
 }
 
-func (tc TransactionController) Reject(c echo.Context) error {
+func (tc TransactionController) Prepare(c echo.Context) error {
 
 	trxID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
@@ -170,7 +236,50 @@ func (tc TransactionController) Reject(c echo.Context) error {
 
 	user, _ := middlewares.ExtractTokenUser(c)
 
-	_, err = tc.Repo.Reject(trxID, user.PartnerID)
+	_, err = tc.Repo.Prepare(trxID, user.PartnerID)
+	if errors.Is(err, helper.ErrInvalidTransition) {
+		return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	if err != nil {
+		return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
+	}
+
+	return c.JSON(http.StatusOK, common.NewSuccessOperationResponse())
+}
+
+func (tc TransactionController) Ready(c echo.Context) error {
+
+	trxID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	user, _ := middlewares.ExtractTokenUser(c)
+
+	_, err = tc.Repo.Ready(trxID, user.PartnerID)
+	if errors.Is(err, helper.ErrInvalidTransition) {
+		return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	if err != nil {
+		return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
+	}
+
+	return c.JSON(http.StatusOK, common.NewSuccessOperationResponse())
+}
+
+func (tc TransactionController) Deliver(c echo.Context) error {
+
+	trxID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	user, _ := middlewares.ExtractTokenUser(c)
+
+	_, err = tc.Repo.Deliver(trxID, user.PartnerID)
+	if errors.Is(err, helper.ErrInvalidTransition) {
+		return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
 	if err != nil {
 		return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
 	}
@@ -188,6 +297,9 @@ func (tc TransactionController) Send(c echo.Context) error {
 	user, _ := middlewares.ExtractTokenUser(c)
 
 	_, err = tc.Repo.Send(trxID, user.PartnerID)
+	if errors.Is(err, helper.ErrInvalidTransition) {
+		return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
 	if err != nil {
 		return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
 	}
@@ -205,6 +317,9 @@ func (tc TransactionController) Confirm(c echo.Context) error {
 	user, _ := middlewares.ExtractTokenUser(c)
 
 	_, err = tc.Repo.Confirm(trxID, user.UserID)
+	if errors.Is(err, helper.ErrInvalidTransition) {
+		return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
 	if err != nil {
 		return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
 	}
@@ -212,6 +327,117 @@ func (tc TransactionController) Confirm(c echo.Context) error {
 	return c.JSON(http.StatusOK, common.NewSuccessOperationResponse())
 }
 
+func (tc TransactionController) Cancel(c echo.Context) error {
+
+	trxID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	var statusRequest TransactionStatusRequest
+	c.Bind(&statusRequest)
+
+	user, _ := middlewares.ExtractTokenUser(c)
+
+	_, err = tc.Repo.Cancel(trxID, user.UserID, statusRequest.Reason)
+	if errors.Is(err, helper.ErrInvalidTransition) || errors.Is(err, helper.ErrCancellationTooLate) || errors.Is(err, helper.ErrInvalidPayment) {
+		return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	if err != nil {
+		return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
+	}
+
+	return c.JSON(http.StatusOK, common.NewSuccessOperationResponse())
+}
+
+// RenewPayment gives the customer a new payment link when the last one expired before the order did.
+func (tc TransactionController) RenewPayment(c echo.Context) error {
+
+	trxID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	user, _ := middlewares.ExtractTokenUser(c)
+
+	trx, err := tc.Repo.RenewPayment(trxID, user.UserID)
+	if errors.Is(err, helper.ErrInvalidPayment) {
+		return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	if err != nil {
+		return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
+	}
+
+	invoices := invoiceResponses(trx.Invoices)
+	if len(invoices) == 0 {
+		return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
+	}
+
+	return c.JSON(http.StatusOK, common.SuccessResponse(invoices[len(invoices)-1]))
+}
+
+// Receipt streams the PDF receipt of a paid order to the customer who placed it.
+func (tc TransactionController) Receipt(c echo.Context) error {
+
+	trxID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	user, _ := middlewares.ExtractTokenUser(c)
+
+	trx, err := tc.Repo.GetOneForUser(trxID, user.UserID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
+	}
+
+	receipt, err := helper.RenderReceipt(trx)
+	if errors.Is(err, helper.ErrReceiptUnavailable) {
+		return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, common.ErrorResponse(http.StatusInternalServerError, "failed to render the receipt"))
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=\"receipt-%s.pdf\"", trx.InvoiceID))
+
+	return c.Blob(http.StatusOK, "application/pdf", receipt.Bytes())
+}
+
+func invoiceResponses(invoices []models.TransactionInvoice) []InvoiceResponse {
+	response := []InvoiceResponse{}
+
+	for _, invoice := range invoices {
+		response = append(response, InvoiceResponse{
+			InvoiceID:  invoice.ExternalID,
+			PaymentUrl: invoice.PaymentUrl,
+			Amount:     invoice.Amount,
+			Status:     invoice.Status,
+			ExpiresAt:  fmt.Sprint(invoice.ExpiresAt),
+			CreatedAt:  fmt.Sprint(invoice.CreatedAt),
+		})
+	}
+
+	return response
+}
+
+// TaxResponses lists the taxes of an order for a response.
+func TaxResponses(taxes []models.TransactionTax) []TaxResponse {
+	response := []TaxResponse{}
+
+	for _, tax := range taxes {
+		response = append(response, TaxResponse{
+			Type:      tax.Type,
+			Rate:      tax.Rate,
+			Inclusive: tax.Inclusive,
+			Base:      tax.Base,
+			Amount:    tax.Amount,
+		})
+	}
+
+	return response
+}
+
 func (tc TransactionController) GetAll(c echo.Context) error {
 	user, _ := middlewares.ExtractTokenUser(c)
 
@@ -226,18 +452,21 @@ func (tc TransactionController) GetAll(c echo.Context) error {
 
 	for _, trx := range data {
 
-		productItems := []product.ProductResponse{}
-		for _, item := range trx.Products {
+		productItems := []TransactionItemResponse{}
+		for _, item := range trx.DetailTransactions {
 			var productImage string
-			if item.Image != "" {
-				productImage = fmt.Sprintf(constants.LINK_TEMPLATE, constants.S3_BUCKET, constants.S3_REGION, item.Image)
+			if item.Product.Image != "" {
+				productImage = fmt.Sprintf(constants.LINK_TEMPLATE, constants.S3_BUCKET, constants.S3_REGION, item.Product.Image)
 			}
-			productItems = append(productItems, product.ProductResponse{
-				Title:       item.Title,
-				Image:       productImage,
-				Type:        item.Type,
-				Description: item.Description,
-				Price:       item.Price,
+			productItems = append(productItems, TransactionItemResponse{
+				ProductID: int(item.ProductID),
+				Title:     item.Title,
+				Image:     productImage,
+				Type:      item.Type,
+				Price:     item.Price,
+				Quantity:  item.Quantity,
+				Notes:     item.Notes,
+				Subtotal:  item.Price * models.Money(item.Quantity),
 			})
 		}
 
@@ -254,6 +483,10 @@ func (tc TransactionController) GetAll(c echo.Context) error {
 			DateTime:       fmt.Sprint(trx.DateTime),
 			Distance:       float32(trx.Distance),
 			TotalPrice:     trx.TotalPrice,
+			ShippingCost:   trx.ShippingCost,
+			VoucherCode:    trx.VoucherCode,
+			Discount:       trx.Discount,
+			Tax:            trx.Tax,
 			PaymentUrl:     trx.PaymentUrl,
 			PaymentMethod:  trx.PaymentMethod,
 			PaymentChannel: trx.PaymentChannel,
@@ -289,18 +522,21 @@ func (tc TransactionController) GetOne(c echo.Context) error {
 
 	response := []TransactionResponse{}
 
-	productItems := []product.ProductResponse{}
-	for _, item := range data.Products {
+	productItems := []TransactionItemResponse{}
+	for _, item := range data.DetailTransactions {
 		var productImage string
-			if item.Image != "" {
-				productImage = fmt.Sprintf(constants.LINK_TEMPLATE, constants.S3_BUCKET, constants.S3_REGION, item.Image)
-			}
-		productItems = append(productItems, product.ProductResponse{
-			Title:       item.Title,
-			Image:       productImage,
-			Type:        item.Type,
-			Description: item.Description,
-			Price:       item.Price,
+		if item.Product.Image != "" {
+			productImage = fmt.Sprintf(constants.LINK_TEMPLATE, constants.S3_BUCKET, constants.S3_REGION, item.Product.Image)
+		}
+		productItems = append(productItems, TransactionItemResponse{
+			ProductID: int(item.ProductID),
+			Title:     item.Title,
+			Image:     productImage,
+			Type:      item.Type,
+			Price:     item.Price,
+			Quantity:  item.Quantity,
+			Notes:     item.Notes,
+			Subtotal:  item.Price * models.Money(item.Quantity),
 		})
 	}
 
@@ -317,17 +553,60 @@ func (tc TransactionController) GetOne(c echo.Context) error {
 		DateTime:       fmt.Sprint(data.DateTime),
 		Distance:       float32(data.Distance),
 		TotalPrice:     data.TotalPrice,
+		ShippingCost:   data.ShippingCost,
+		VoucherCode:    data.VoucherCode,
+		Discount:       data.Discount,
+		Tax:            data.Tax,
 		PaymentUrl:     data.PaymentUrl,
 		PaymentMethod:  data.PaymentMethod,
 		PaymentChannel: data.PaymentChannel,
 		PaidAt:         fmt.Sprint(data.PaidAt),
 		Status:         data.Status,
 		Products:       productItems,
+		Taxes:          TaxResponses(data.Taxes),
+		Invoices:       invoiceResponses(data.Invoices),
 	})
 
 	return c.JSON(http.StatusOK, common.SuccessResponse(response))
 }
 
+func (tc TransactionController) Timeline(c echo.Context) error {
+
+	trxID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	user, _ := middlewares.ExtractTokenUser(c)
+
+	var histories []models.TransactionStatusHistory
+
+	if user.PartnerID != 0 {
+		histories, err = tc.Repo.GetTimelineForPartner(trxID, user.PartnerID)
+	} else {
+		histories, err = tc.Repo.GetTimelineForUser(trxID, user.UserID)
+	}
+
+	if err != nil {
+		return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
+	}
+
+	response := []TimelineResponse{}
+
+	for _, history := range histories {
+		response = append(response, TimelineResponse{
+			FromStatus: history.FromStatus,
+			Status:     history.ToStatus,
+			ActorID:    int(history.ActorID),
+			ActorRole:  history.ActorRole,
+			Reason:     history.Reason,
+			CreatedAt:  fmt.Sprint(history.CreatedAt),
+		})
+	}
+
+	return c.JSON(http.StatusOK, common.SuccessResponse(response))
+}
+
 func (tc TransactionController) Shipping(c echo.Context) error {
 	var shippingRequest ShippingCostRequest
 
@@ -337,16 +616,57 @@ func (tc TransactionController) Shipping(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, err.Error()))
 	}
 
+	partner, err := tc.Repo.GetPartner(shippingRequest.PartnerID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
+	}
+
 	distance, err := tc.Repo.GetDistance(shippingRequest.PartnerID, shippingRequest.Latitude, shippingRequest.Longtitude)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
 	}
 
-	cost := helper.CalculateShippingCost(distance)
+	if err := helper.ValidateDeliveryRadius(partner, distance); err != nil {
+		return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	// date, time and quantity are optional in a quote, the rules they touch are only checked when given
+	if shippingRequest.Date != "" && shippingRequest.Time != "" {
+		dateTime, err := helper.ParseEventTime(shippingRequest.Date, shippingRequest.Time)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+		}
+
+		if err := helper.ValidateLeadTime(partner, dateTime); err != nil {
+			return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, err.Error()))
+		}
+
+		if err := helper.ValidateOrderHours(partner, dateTime); err != nil {
+			return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, err.Error()))
+		}
+	}
+
+	if shippingRequest.Quantity != 0 {
+		if err := helper.ValidateOrderQuantity(partner, shippingRequest.Quantity); err != nil {
+			return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, err.Error()))
+		}
+	}
+
+	tariff, err := tc.Repo.GetShippingTariff(shippingRequest.PartnerID)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	shipping := helper.CalculateShipping(tariff, shippingRequest.Latitude, shippingRequest.Longtitude, distance, shippingRequest.Subtotal, shippingRequest.Quantity)
 
 	response := ShippingCostResponse{
-		Distance: distance,
-		Cost:     cost,
+		Distance:     distance,
+		Cost:         shipping.Total,
+		Zone:         shipping.Zone,
+		BaseCost:     shipping.BaseCost,
+		DistanceCost: shipping.DistanceCost,
+		Surcharge:    shipping.Surcharge,
+		Discount:     shipping.Discount,
 	}
 
 	return c.JSON(http.StatusOK, common.SuccessResponse(response))
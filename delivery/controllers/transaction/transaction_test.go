@@ -9,11 +9,13 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/furqonzt99/snackbox/constants"
 	"github.com/furqonzt99/snackbox/delivery/common"
 	"github.com/furqonzt99/snackbox/delivery/controllers/transaction"
 	"github.com/furqonzt99/snackbox/delivery/controllers/user"
+	"github.com/furqonzt99/snackbox/helper"
 	"github.com/furqonzt99/snackbox/models"
 	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
@@ -57,12 +59,11 @@ func TestTransaction(t *testing.T) {
 		e.Validator = &transaction.TransactionValidator{Validator: validator.New()}
 
 		bodyReq, _ := json.Marshal(transaction.TransactionRequest{
-			Quantity:   1,
 			Date:       "2022-02-22",
 			Time:       "09:00:00",
 			Latitude:   100,
 			Longtitude: 100,
-			Products:   []int{1},
+			Products:   []transaction.TransactionItemRequest{{ProductID: 1, Quantity: 1}},
 		})
 
 		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(bodyReq))
@@ -91,12 +92,11 @@ func TestTransaction(t *testing.T) {
 		e.Validator = &transaction.TransactionValidator{Validator: validator.New()}
 
 		bodyReq, _ := json.Marshal(transaction.TransactionRequest{
-			// Quantity:   1,
 			Date:       "2022-02-30",
 			Time:       "09:00:00",
 			Latitude:   100,
 			Longtitude: 100,
-			Products:   []int{1},
+			Products:   []transaction.TransactionItemRequest{{ProductID: 1}},
 		})
 
 		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(bodyReq))
@@ -125,12 +125,11 @@ func TestTransaction(t *testing.T) {
 		e.Validator = &transaction.TransactionValidator{Validator: validator.New()}
 
 		bodyReq, _ := json.Marshal(transaction.TransactionRequest{
-			Quantity:   1,
 			Date:       "2022-02-30",
 			Time:       "09:00:00",
 			Latitude:   100,
 			Longtitude: 100,
-			Products:   []int{1},
+			Products:   []transaction.TransactionItemRequest{{ProductID: 1, Quantity: 1}},
 		})
 
 		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(bodyReq))
@@ -153,18 +152,17 @@ func TestTransaction(t *testing.T) {
 		assert.Equal(t, "Not Found", responses.Message)
 	})
 
-	t.Run(`transaction bad request "reservate 3 days before the event time"`, func(t *testing.T) {
+	t.Run("transaction bad request lead time", func(t *testing.T) {
 
 		e := echo.New()
 		e.Validator = &transaction.TransactionValidator{Validator: validator.New()}
 
 		bodyReq, _ := json.Marshal(transaction.TransactionRequest{
-			Quantity:   1,
 			Date:       "2022-02-16",
 			Time:       "09:00:00",
 			Latitude:   100,
 			Longtitude: 100,
-			Products:   []int{1},
+			Products:   []transaction.TransactionItemRequest{{ProductID: 1, Quantity: 1}},
 		})
 
 		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(bodyReq))
@@ -184,7 +182,7 @@ func TestTransaction(t *testing.T) {
 		var responses common.ResponseSuccess
 
 		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
-		assert.Equal(t, "you must reservate 3 days before the event time!", responses.Message)
+		assert.Equal(t, "you must order at least 72 hours before the event time", responses.Message)
 	})
 
 	t.Run("transaction bad request order", func(t *testing.T) {
@@ -193,12 +191,11 @@ func TestTransaction(t *testing.T) {
 		e.Validator = &transaction.TransactionValidator{Validator: validator.New()}
 
 		bodyReq, _ := json.Marshal(transaction.TransactionRequest{
-			Quantity:   1,
 			Date:       "2022-02-27",
 			Time:       "09:00:00",
 			Latitude:   100,
 			Longtitude: 100,
-			Products:   []int{1},
+			Products:   []transaction.TransactionItemRequest{{ProductID: 1, Quantity: 1}},
 		})
 
 		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(bodyReq))
@@ -224,69 +221,61 @@ func TestTransaction(t *testing.T) {
 
 func TestTransactionCallback(t *testing.T) {
 	t.Run("callback success", func(t *testing.T) {
-		e := echo.New()
-
 		bodyReq, _ := json.Marshal(common.TransactionCallbackRequest{
 			ExternalID: "1",
 		})
 
-		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(bodyReq))
-		res := httptest.NewRecorder()
+		transactionController := transaction.NewTransactionController(mockTransaction{})
+		err := transactionController.HandleCallback(bodyReq)
 
-		context := e.NewContext(req, res)
-		context.SetPath("/transactions/callback")
+		assert.Nil(t, err)
+	})
 
-		transactionController := transaction.NewTransactionController(mockTransaction{})
-		transactionController.Callback(context)
+	t.Run("callback records the gateway fee", func(t *testing.T) {
+		bodyReq, _ := json.Marshal(common.TransactionCallbackRequest{
+			ExternalID:             "1",
+			Status:                 "PAID",
+			PaidAmount:             50000,
+			AdjustedReceivedAmount: 47780,
+		})
 
-		var responses common.ResponseSuccess
+		repo := &mockCallbackTransaction{}
+		transactionController := transaction.NewTransactionController(repo)
+		err := transactionController.HandleCallback(bodyReq)
 
-		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
-		assert.Equal(t, "Successful Operation", responses.Message)
+		assert.Nil(t, err)
+		assert.Equal(t, models.Money(50000), repo.received.PaidAmount)
+		assert.Equal(t, models.Money(2220), repo.received.GatewayFee)
+		assert.Equal(t, constants.SETTLEMENT_PENDING, repo.received.SettlementStatus)
 	})
 
 	t.Run("callback not found", func(t *testing.T) {
-		e := echo.New()
-
 		bodyReq, _ := json.Marshal(common.TransactionCallbackRequest{
 			ExternalID: "1",
 		})
 
-		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(bodyReq))
-		res := httptest.NewRecorder()
-
-		context := e.NewContext(req, res)
-		context.SetPath("/transactions/callback")
-
 		transactionController := transaction.NewTransactionController(mockFalseTransaction{})
-		transactionController.Callback(context)
+		err := transactionController.HandleCallback(bodyReq)
 
-		var responses common.ResponseSuccess
-
-		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
-		assert.Equal(t, "Not Found", responses.Message)
+		assert.NotNil(t, err)
 	})
 
-	t.Run("callback success", func(t *testing.T) { // not solved 1
-		e := echo.New()
-
+	t.Run("callback without external id", func(t *testing.T) {
 		bodyReq, _ := json.Marshal(common.TransactionCallbackRequest{
-			ExternalID: "1",
+			Status: "PAID",
 		})
 
-		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(bodyReq))
-		res := httptest.NewRecorder()
+		transactionController := transaction.NewTransactionController(mockTransaction{})
+		err := transactionController.HandleCallback(bodyReq)
 
-		context := e.NewContext(req, res)
-		context.SetPath("/transactions/callback")
+		assert.Equal(t, common.ErrInvalidCallback, err)
+	})
 
+	t.Run("callback invalid body", func(t *testing.T) {
 		transactionController := transaction.NewTransactionController(mockTransaction{})
-		transactionController.Callback(context)
+		err := transactionController.HandleCallback([]byte("not json"))
 
-		var responses common.ResponseSuccess
-
-		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
-		assert.Equal(t, "Successful Operation", responses.Message)
+		assert.NotNil(t, err)
 	})
 }
 
@@ -324,12 +313,11 @@ func TestAcceptTransaction(t *testing.T) {
 		e.Validator = &transaction.TransactionValidator{Validator: validator.New()}
 
 		bodyReq, _ := json.Marshal(transaction.TransactionRequest{
-			Quantity:   1,
 			Date:       "2022-02-22",
 			Time:       "09:00:00",
 			Latitude:   100,
 			Longtitude: 100,
-			Products:   []int{1},
+			Products:   []transaction.TransactionItemRequest{{ProductID: 1, Quantity: 1}},
 		})
 
 		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(bodyReq))
@@ -360,12 +348,11 @@ func TestAcceptTransaction(t *testing.T) {
 		e.Validator = &transaction.TransactionValidator{Validator: validator.New()}
 
 		bodyReq, _ := json.Marshal(transaction.TransactionRequest{
-			Quantity:   1,
 			Date:       "2022-02-22",
 			Time:       "09:00:00",
 			Latitude:   100,
 			Longtitude: 100,
-			Products:   []int{1},
+			Products:   []transaction.TransactionItemRequest{{ProductID: 1, Quantity: 1}},
 		})
 
 		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(bodyReq))
@@ -396,12 +383,11 @@ func TestAcceptTransaction(t *testing.T) {
 		e.Validator = &transaction.TransactionValidator{Validator: validator.New()}
 
 		bodyReq, _ := json.Marshal(transaction.TransactionRequest{
-			Quantity:   1,
 			Date:       "2022-02-22",
 			Time:       "09:00:00",
 			Latitude:   100,
 			Longtitude: 100,
-			Products:   []int{1},
+			Products:   []transaction.TransactionItemRequest{{ProductID: 1, Quantity: 1}},
 		})
 
 		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(bodyReq))
@@ -461,12 +447,11 @@ func TestRejectTransaction(t *testing.T) {
 		e.Validator = &transaction.TransactionValidator{Validator: validator.New()}
 
 		bodyReq, _ := json.Marshal(transaction.TransactionRequest{
-			Quantity:   1,
 			Date:       "2022-02-22",
 			Time:       "09:00:00",
 			Latitude:   100,
 			Longtitude: 100,
-			Products:   []int{1},
+			Products:   []transaction.TransactionItemRequest{{ProductID: 1, Quantity: 1}},
 		})
 
 		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(bodyReq))
@@ -497,12 +482,11 @@ func TestRejectTransaction(t *testing.T) {
 		e.Validator = &transaction.TransactionValidator{Validator: validator.New()}
 
 		bodyReq, _ := json.Marshal(transaction.TransactionRequest{
-			Quantity:   1,
 			Date:       "2022-02-22",
 			Time:       "09:00:00",
 			Latitude:   100,
 			Longtitude: 100,
-			Products:   []int{1},
+			Products:   []transaction.TransactionItemRequest{{ProductID: 1, Quantity: 1}},
 		})
 
 		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(bodyReq))
@@ -533,12 +517,11 @@ func TestRejectTransaction(t *testing.T) {
 		e.Validator = &transaction.TransactionValidator{Validator: validator.New()}
 
 		bodyReq, _ := json.Marshal(transaction.TransactionRequest{
-			Quantity:   1,
 			Date:       "2022-02-22",
 			Time:       "09:00:00",
 			Latitude:   100,
 			Longtitude: 100,
-			Products:   []int{1},
+			Products:   []transaction.TransactionItemRequest{{ProductID: 1, Quantity: 1}},
 		})
 
 		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(bodyReq))
@@ -779,7 +762,7 @@ func TestConfirmTransaction(t *testing.T) {
 	})
 }
 
-func TestGetAllTransaction(t *testing.T) {
+func TestCancelTransaction(t *testing.T) {
 	t.Run("Login", func(t *testing.T) {
 		e := echo.New()
 		e.Validator = &user.UserValidator{Validator: validator.New()}
@@ -807,7 +790,7 @@ func TestGetAllTransaction(t *testing.T) {
 		assert.NotNil(t, JwtToken)
 	})
 
-	t.Run("get all transaction success", func(t *testing.T) {
+	t.Run("cancel transaction success", func(t *testing.T) {
 
 		e := echo.New()
 
@@ -818,12 +801,12 @@ func TestGetAllTransaction(t *testing.T) {
 		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
 
 		context := e.NewContext(req, res)
-		context.SetPath("/transactions/:id/reject")
+		context.SetPath("/transactions/:id/cancel")
 		context.SetParamNames("id")
 		context.SetParamValues("1")
 
 		transactionController := transaction.NewTransactionController(mockTransaction{})
-		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(transactionController.GetAll)(context); err != nil {
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(transactionController.Cancel)(context); err != nil {
 			log.Fatal(err)
 			return
 		}
@@ -832,9 +815,87 @@ func TestGetAllTransaction(t *testing.T) {
 		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
 		assert.Equal(t, "Successful Operation", responses.Message)
 	})
+
+	t.Run("cancel transaction badrequest param", func(t *testing.T) {
+
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/transactions/:id/cancel")
+		context.SetParamNames("id")
+		context.SetParamValues("a")
+
+		transactionController := transaction.NewTransactionController(mockTransaction{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(transactionController.Cancel)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Bad Request", responses.Message)
+	})
+
+	t.Run("cancel transaction err Repo.Cancel", func(t *testing.T) {
+
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/transactions/:id/cancel")
+		context.SetParamNames("id")
+		context.SetParamValues("1")
+
+		transactionController := transaction.NewTransactionController(mockFalseTransaction{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(transactionController.Cancel)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Not Found", responses.Message)
+	})
+
+	t.Run("cancel transaction after the window", func(t *testing.T) {
+
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodPut, "/", nil)
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/transactions/:id/cancel")
+		context.SetParamNames("id")
+		context.SetParamValues("1")
+
+		transactionController := transaction.NewTransactionController(mockFalseTransaction2{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(transactionController.Cancel)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, helper.ErrCancellationTooLate.Error(), responses.Message)
+	})
 }
 
-func TestGetOneTransaction(t *testing.T) {
+func TestRenewPaymentTransaction(t *testing.T) {
 	t.Run("Login", func(t *testing.T) {
 		e := echo.New()
 		e.Validator = &user.UserValidator{Validator: validator.New()}
@@ -862,23 +923,23 @@ func TestGetOneTransaction(t *testing.T) {
 		assert.NotNil(t, JwtToken)
 	})
 
-	t.Run("getone transaction success", func(t *testing.T) {
+	t.Run("renew payment success", func(t *testing.T) {
 
 		e := echo.New()
 
-		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
 		res := httptest.NewRecorder()
 
 		req.Header.Set("Content-Type", "application/json")
 		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
 
 		context := e.NewContext(req, res)
-		context.SetPath("/transactions/:id")
+		context.SetPath("/transactions/:id/payment")
 		context.SetParamNames("id")
 		context.SetParamValues("1")
 
 		transactionController := transaction.NewTransactionController(mockTransaction{})
-		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(transactionController.GetOne)(context); err != nil {
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(transactionController.RenewPayment)(context); err != nil {
 			log.Fatal(err)
 			return
 		}
@@ -886,9 +947,13 @@ func TestGetOneTransaction(t *testing.T) {
 
 		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
 		assert.Equal(t, "Successful Operation", responses.Message)
+
+		data := responses.Data.(map[string]interface{})
+		assert.Equal(t, "SB-1-2", data["invoice_id"])
+		assert.Equal(t, "https://sandbox.invalid/invoices/2", data["payment_url"])
 	})
 
-	t.Run("confirm transaction badrequest param", func(t *testing.T) {
+	t.Run("renew payment badrequest param", func(t *testing.T) {
 
 		e := echo.New()
 
@@ -899,12 +964,12 @@ func TestGetOneTransaction(t *testing.T) {
 		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
 
 		context := e.NewContext(req, res)
-		context.SetPath("/transactions/:id")
+		context.SetPath("/transactions/:id/payment")
 		context.SetParamNames("id")
 		context.SetParamValues("a")
 
 		transactionController := transaction.NewTransactionController(mockTransaction{})
-		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(transactionController.GetOne)(context); err != nil {
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(transactionController.RenewPayment)(context); err != nil {
 			log.Fatal(err)
 			return
 		}
@@ -914,7 +979,7 @@ func TestGetOneTransaction(t *testing.T) {
 		assert.Equal(t, "Bad Request", responses.Message)
 	})
 
-	t.Run("confirm transaction err Repo.GetOneForUser", func(t *testing.T) {
+	t.Run("renew payment err Repo.RenewPayment", func(t *testing.T) {
 
 		e := echo.New()
 
@@ -925,12 +990,12 @@ func TestGetOneTransaction(t *testing.T) {
 		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
 
 		context := e.NewContext(req, res)
-		context.SetPath("/transactions/:id/reject")
+		context.SetPath("/transactions/:id/payment")
 		context.SetParamNames("id")
 		context.SetParamValues("1")
-		//yoga
+
 		transactionController := transaction.NewTransactionController(mockFalseTransaction{})
-		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(transactionController.GetOne)(context); err != nil {
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(transactionController.RenewPayment)(context); err != nil {
 			log.Fatal(err)
 			return
 		}
@@ -939,9 +1004,35 @@ func TestGetOneTransaction(t *testing.T) {
 		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
 		assert.Equal(t, "Not Found", responses.Message)
 	})
+
+	t.Run("renew payment while the link is still valid", func(t *testing.T) {
+
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/transactions/:id/payment")
+		context.SetParamNames("id")
+		context.SetParamValues("1")
+
+		transactionController := transaction.NewTransactionController(mockFalseTransaction2{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(transactionController.RenewPayment)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, helper.PaymentError("the payment link is still valid").Error(), responses.Message)
+	})
 }
 
-func TestShippingTransaction(t *testing.T) {
+func TestReceiptTransaction(t *testing.T) {
 	t.Run("Login", func(t *testing.T) {
 		e := echo.New()
 		e.Validator = &user.UserValidator{Validator: validator.New()}
@@ -969,111 +1060,722 @@ func TestShippingTransaction(t *testing.T) {
 		assert.NotNil(t, JwtToken)
 	})
 
-	t.Run("shipping success", func(t *testing.T) {
+	t.Run("receipt success", func(t *testing.T) {
 
 		e := echo.New()
 
-		e.Validator = &transaction.TransactionValidator{Validator: validator.New()}
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		res := httptest.NewRecorder()
 
-		requestBody, _ := json.Marshal(transaction.ShippingCostRequest{
-			PartnerID:  1,
-			Latitude:   100,
-			Longtitude: 100,
-		})
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
 
-		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(requestBody))
+		context := e.NewContext(req, res)
+		context.SetPath("/transactions/:id/receipt")
+		context.SetParamNames("id")
+		context.SetParamValues("1")
+
+		transactionController := transaction.NewTransactionController(mockTransaction{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(transactionController.Receipt)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.Equal(t, "application/pdf", res.Header().Get(echo.HeaderContentType))
+		assert.Equal(t, `attachment; filename="receipt-SB-1.pdf"`, res.Header().Get(echo.HeaderContentDisposition))
+		assert.True(t, bytes.HasPrefix(res.Body.Bytes(), []byte("%PDF")))
+	})
+
+	t.Run("receipt badrequest param", func(t *testing.T) {
+
+		e := echo.New()
 
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
 		res := httptest.NewRecorder()
 
-		req.Header.Set("Content-Type", "application/json")
 		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
 
 		context := e.NewContext(req, res)
-		context.SetPath("/transactions/:id")
+		context.SetPath("/transactions/:id/receipt")
 		context.SetParamNames("id")
-		context.SetParamValues("1")
+		context.SetParamValues("a")
 
 		transactionController := transaction.NewTransactionController(mockTransaction{})
-		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(transactionController.Shipping)(context); err != nil {
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(transactionController.Receipt)(context); err != nil {
 			log.Fatal(err)
 			return
 		}
 		var responses common.ResponseSuccess
 
 		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
-		assert.Equal(t, "Successful Operation", responses.Message)
+		assert.Equal(t, "Bad Request", responses.Message)
 	})
 
-	t.Run("shipping bad request", func(t *testing.T) {
+	t.Run("receipt err Repo.GetOneForUser", func(t *testing.T) {
 
 		e := echo.New()
 
-		e.Validator = &transaction.TransactionValidator{Validator: validator.New()}
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		res := httptest.NewRecorder()
 
-		requestBody, _ := json.Marshal(transaction.ShippingCostRequest{
-			// PartnerID:  1,
-			Latitude:   100,
-			Longtitude: 100,
-		})
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
 
-		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(requestBody))
+		context := e.NewContext(req, res)
+		context.SetPath("/transactions/:id/receipt")
+		context.SetParamNames("id")
+		context.SetParamValues("1")
+
+		transactionController := transaction.NewTransactionController(mockFalseTransaction{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(transactionController.Receipt)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Not Found", responses.Message)
+	})
+
+	t.Run("receipt of an unpaid transaction", func(t *testing.T) {
+
+		e := echo.New()
 
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
 		res := httptest.NewRecorder()
 
-		req.Header.Set("Content-Type", "application/json")
 		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
 
 		context := e.NewContext(req, res)
-		context.SetPath("/transactions/:id")
+		context.SetPath("/transactions/:id/receipt")
 		context.SetParamNames("id")
 		context.SetParamValues("1")
 
-		transactionController := transaction.NewTransactionController(mockTransaction{})
-		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(transactionController.Shipping)(context); err != nil {
+		transactionController := transaction.NewTransactionController(mockFalseTransaction2{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(transactionController.Receipt)(context); err != nil {
 			log.Fatal(err)
 			return
 		}
 		var responses common.ResponseSuccess
 
 		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
-		assert.Equal(t, 400, responses.Code)
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+		assert.Equal(t, helper.ErrReceiptUnavailable.Error(), responses.Message)
 	})
+}
 
-	t.Run("shipping err Repo.GetDistance", func(t *testing.T) {
-
+func TestGetAllTransaction(t *testing.T) {
+	t.Run("Login", func(t *testing.T) {
 		e := echo.New()
+		e.Validator = &user.UserValidator{Validator: validator.New()}
 
-		e.Validator = &transaction.TransactionValidator{Validator: validator.New()}
-
-		requestBody, _ := json.Marshal(transaction.ShippingCostRequest{
-			PartnerID:  1,
-			Latitude:   100,
-			Longtitude: 100,
+		requestBody, _ := json.Marshal(map[string]string{
+			"email":    "test@gmail.com",
+			"password": "test1234",
 		})
 
 		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(requestBody))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		context := e.NewContext(req, res)
+		context.SetPath("/login")
+
+		userController := user.NewUsersControllers(mockUserRepository{})
+		userController.LoginController()(context)
+
+		response := common.ResponseSuccess{}
+		json.Unmarshal([]byte(res.Body.Bytes()), &response)
+
+		JwtToken = response.Data.(string)
+		assert.Equal(t, "Successful Operation", response.Message)
+		assert.NotNil(t, JwtToken)
+	})
+
+	t.Run("get all transaction success", func(t *testing.T) {
 
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
 		res := httptest.NewRecorder()
 
 		req.Header.Set("Content-Type", "application/json")
 		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
 
 		context := e.NewContext(req, res)
-		context.SetPath("/transactions/:id")
+		context.SetPath("/transactions/:id/reject")
 		context.SetParamNames("id")
 		context.SetParamValues("1")
 
-		transactionController := transaction.NewTransactionController(mockFalseTransaction{})
-		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(transactionController.Shipping)(context); err != nil {
+		transactionController := transaction.NewTransactionController(mockTransaction{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(transactionController.GetAll)(context); err != nil {
 			log.Fatal(err)
 			return
 		}
 		var responses common.ResponseSuccess
 
 		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
-		assert.Equal(t, "Bad Request", responses.Message)
+		assert.Equal(t, "Successful Operation", responses.Message)
+	})
+}
+
+func TestGetOneTransaction(t *testing.T) {
+	t.Run("Login", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = &user.UserValidator{Validator: validator.New()}
+
+		requestBody, _ := json.Marshal(map[string]string{
+			"email":    "test@gmail.com",
+			"password": "test1234",
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(requestBody))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		context := e.NewContext(req, res)
+		context.SetPath("/login")
+
+		userController := user.NewUsersControllers(mockUserRepository{})
+		userController.LoginController()(context)
+
+		response := common.ResponseSuccess{}
+		json.Unmarshal([]byte(res.Body.Bytes()), &response)
+
+		JwtToken = response.Data.(string)
+		assert.Equal(t, "Successful Operation", response.Message)
+		assert.NotNil(t, JwtToken)
+	})
+
+	t.Run("getone transaction success", func(t *testing.T) {
+
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/transactions/:id")
+		context.SetParamNames("id")
+		context.SetParamValues("1")
+
+		transactionController := transaction.NewTransactionController(mockTransaction{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(transactionController.GetOne)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Successful Operation", responses.Message)
+	})
+
+	t.Run("confirm transaction badrequest param", func(t *testing.T) {
+
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/transactions/:id")
+		context.SetParamNames("id")
+		context.SetParamValues("a")
+
+		transactionController := transaction.NewTransactionController(mockTransaction{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(transactionController.GetOne)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Bad Request", responses.Message)
+	})
+
+	t.Run("confirm transaction err Repo.GetOneForUser", func(t *testing.T) {
+
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/transactions/:id/reject")
+		context.SetParamNames("id")
+		context.SetParamValues("1")
+		//yoga
+		transactionController := transaction.NewTransactionController(mockFalseTransaction{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(transactionController.GetOne)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Not Found", responses.Message)
+	})
+}
+
+func TestShippingTransaction(t *testing.T) {
+	t.Run("Login", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = &user.UserValidator{Validator: validator.New()}
+
+		requestBody, _ := json.Marshal(map[string]string{
+			"email":    "test@gmail.com",
+			"password": "test1234",
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(requestBody))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		context := e.NewContext(req, res)
+		context.SetPath("/login")
+
+		userController := user.NewUsersControllers(mockUserRepository{})
+		userController.LoginController()(context)
+
+		response := common.ResponseSuccess{}
+		json.Unmarshal([]byte(res.Body.Bytes()), &response)
+
+		JwtToken = response.Data.(string)
+		assert.Equal(t, "Successful Operation", response.Message)
+		assert.NotNil(t, JwtToken)
+	})
+
+	t.Run("shipping success", func(t *testing.T) {
+
+		e := echo.New()
+
+		e.Validator = &transaction.TransactionValidator{Validator: validator.New()}
+
+		requestBody, _ := json.Marshal(transaction.ShippingCostRequest{
+			PartnerID:  1,
+			Latitude:   100,
+			Longtitude: 100,
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(requestBody))
+
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/transactions/:id")
+		context.SetParamNames("id")
+		context.SetParamValues("1")
+
+		transactionController := transaction.NewTransactionController(mockTransaction{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(transactionController.Shipping)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Successful Operation", responses.Message)
+	})
+
+	t.Run("shipping bad request", func(t *testing.T) {
+
+		e := echo.New()
+
+		e.Validator = &transaction.TransactionValidator{Validator: validator.New()}
+
+		requestBody, _ := json.Marshal(transaction.ShippingCostRequest{
+			// PartnerID:  1,
+			Latitude:   100,
+			Longtitude: 100,
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(requestBody))
+
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/transactions/:id")
+		context.SetParamNames("id")
+		context.SetParamValues("1")
+
+		transactionController := transaction.NewTransactionController(mockTransaction{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(transactionController.Shipping)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, 400, responses.Code)
+	})
+
+	t.Run("shipping err Repo.GetDistance", func(t *testing.T) {
+
+		e := echo.New()
+
+		e.Validator = &transaction.TransactionValidator{Validator: validator.New()}
+
+		requestBody, _ := json.Marshal(transaction.ShippingCostRequest{
+			PartnerID:  1,
+			Latitude:   100,
+			Longtitude: 100,
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(requestBody))
+
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/transactions/:id")
+		context.SetParamNames("id")
+		context.SetParamValues("1")
+
+		transactionController := transaction.NewTransactionController(mockFalseTransaction{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(transactionController.Shipping)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Bad Request", responses.Message)
+	})
+
+
+	t.Run("shipping quantity above partner maximum", func(t *testing.T) {
+
+		e := echo.New()
+
+		e.Validator = &transaction.TransactionValidator{Validator: validator.New()}
+
+		requestBody, _ := json.Marshal(transaction.ShippingCostRequest{
+			PartnerID:  1,
+			Latitude:   100,
+			Longtitude: 100,
+			Quantity:   1000,
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(requestBody))
+
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/transactions/shipping")
+
+		transactionController := transaction.NewTransactionController(mockTransaction{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(transactionController.Shipping)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "the maximum order for this partner is 500 boxes", responses.Message)
+	})
+
+	t.Run("shipping partner not found", func(t *testing.T) {
+
+		e := echo.New()
+
+		e.Validator = &transaction.TransactionValidator{Validator: validator.New()}
+
+		requestBody, _ := json.Marshal(transaction.ShippingCostRequest{
+			PartnerID:  1,
+			Latitude:   100,
+			Longtitude: 100,
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(requestBody))
+
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/transactions/shipping")
+
+		transactionController := transaction.NewTransactionController(mockFalseTransaction2{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(transactionController.Shipping)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Not Found", responses.Message)
+	})
+
+	t.Run("shipping breakdown with surcharge", func(t *testing.T) {
+
+		e := echo.New()
+
+		e.Validator = &transaction.TransactionValidator{Validator: validator.New()}
+
+		requestBody, _ := json.Marshal(transaction.ShippingCostRequest{
+			PartnerID:  1,
+			Latitude:   100,
+			Longtitude: 100,
+			Quantity:   200,
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(requestBody))
+
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/transactions/shipping")
+
+		transactionController := transaction.NewTransactionController(mockTransaction{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(transactionController.Shipping)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		data := responses.Data.(map[string]interface{})
+		assert.Equal(t, float64(50000), data["base_cost"])
+		assert.Equal(t, float64(20000), data["surcharge"])
+		assert.Equal(t, float64(70000), data["cost"])
+	})
+}
+
+func TestPrepareTransaction(t *testing.T) {
+	t.Run("Login", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = &user.UserValidator{Validator: validator.New()}
+
+		requestBody, _ := json.Marshal(map[string]string{
+			"email":    "test@gmail.com",
+			"password": "test1234",
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(requestBody))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		context := e.NewContext(req, res)
+		context.SetPath("/login")
+
+		userController := user.NewUsersControllers(mockUserRepository{})
+		userController.LoginController()(context)
+
+		response := common.ResponseSuccess{}
+		json.Unmarshal([]byte(res.Body.Bytes()), &response)
+
+		JwtToken = response.Data.(string)
+		assert.Equal(t, "Successful Operation", response.Message)
+		assert.NotNil(t, JwtToken)
+	})
+
+	t.Run("prepare transaction success", func(t *testing.T) {
+
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodPut, "/", nil)
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/transactions/:id/prepare")
+		context.SetParamNames("id")
+		context.SetParamValues("1")
+
+		transactionController := transaction.NewTransactionController(mockTransaction{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(transactionController.Prepare)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Successful Operation", responses.Message)
+	})
+
+	t.Run("prepare transaction badrequest param", func(t *testing.T) {
+
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodPut, "/", nil)
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/transactions/:id/prepare")
+		context.SetParamNames("id")
+		context.SetParamValues("a")
+
+		transactionController := transaction.NewTransactionController(mockTransaction{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(transactionController.Prepare)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Bad Request", responses.Message)
+	})
+
+	t.Run("prepare transaction err Repo.Prepare", func(t *testing.T) {
+
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodPut, "/", nil)
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/transactions/:id/prepare")
+		context.SetParamNames("id")
+		context.SetParamValues("1")
+
+		transactionController := transaction.NewTransactionController(mockFalseTransaction{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(transactionController.Prepare)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Not Found", responses.Message)
+	})
+}
+
+func TestTimelineTransaction(t *testing.T) {
+	t.Run("Login", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = &user.UserValidator{Validator: validator.New()}
+
+		requestBody, _ := json.Marshal(map[string]string{
+			"email":    "test@gmail.com",
+			"password": "test1234",
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(requestBody))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		context := e.NewContext(req, res)
+		context.SetPath("/login")
+
+		userController := user.NewUsersControllers(mockUserRepository{})
+		userController.LoginController()(context)
+
+		response := common.ResponseSuccess{}
+		json.Unmarshal([]byte(res.Body.Bytes()), &response)
+
+		JwtToken = response.Data.(string)
+		assert.Equal(t, "Successful Operation", response.Message)
+		assert.NotNil(t, JwtToken)
+	})
+
+	t.Run("timeline transaction success", func(t *testing.T) {
+
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/transactions/:id/timeline")
+		context.SetParamNames("id")
+		context.SetParamValues("1")
+
+		transactionController := transaction.NewTransactionController(mockTransaction{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(transactionController.Timeline)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Successful Operation", responses.Message)
+	})
+
+	t.Run("timeline transaction badrequest param", func(t *testing.T) {
+
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/transactions/:id/timeline")
+		context.SetParamNames("id")
+		context.SetParamValues("a")
+
+		transactionController := transaction.NewTransactionController(mockTransaction{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(transactionController.Timeline)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Bad Request", responses.Message)
+	})
+
+	t.Run("timeline transaction not found", func(t *testing.T) {
+
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/transactions/:id/timeline")
+		context.SetParamNames("id")
+		context.SetParamValues("1")
+
+		transactionController := transaction.NewTransactionController(mockFalseTransaction{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(transactionController.Timeline)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Not Found", responses.Message)
 	})
-
 }
 
 //======================
@@ -1081,13 +1783,14 @@ func TestShippingTransaction(t *testing.T) {
 //======================
 type mockTransaction struct{}
 
-func (m mockTransaction) Order(transaction models.Transaction, email string, products []int) (models.Transaction, error) {
+func (m mockTransaction) Order(transaction models.Transaction, email string, items []models.DetailTransaction) (models.Transaction, error) {
 	return models.Transaction{
 		UserID:    1,
 		PartnerID: 2,
-		Products: []models.Product{
+		DetailTransactions: []models.DetailTransaction{
 			{
-				Title: "bakso",
+				Title:    "bakso",
+				Quantity: 1,
 			},
 		},
 	}, nil
@@ -1100,7 +1803,28 @@ func (m mockTransaction) Accept(trxID, partnerID int) (models.Transaction, error
 	}, nil
 }
 
-func (m mockTransaction) Reject(trxID, partnerID int) (models.Transaction, error) {
+func (m mockTransaction) Reject(trxID, partnerID int, reason string) (models.Transaction, error) {
+	return models.Transaction{
+		UserID:    1,
+		PartnerID: 2,
+	}, nil
+}
+
+func (m mockTransaction) Prepare(trxID, partnerID int) (models.Transaction, error) {
+	return models.Transaction{
+		UserID:    1,
+		PartnerID: 2,
+	}, nil
+}
+
+func (m mockTransaction) Ready(trxID, partnerID int) (models.Transaction, error) {
+	return models.Transaction{
+		UserID:    1,
+		PartnerID: 2,
+	}, nil
+}
+
+func (m mockTransaction) Deliver(trxID, partnerID int) (models.Transaction, error) {
 	return models.Transaction{
 		UserID:    1,
 		PartnerID: 2,
@@ -1121,6 +1845,26 @@ func (m mockTransaction) Confirm(trxID, userID int) (models.Transaction, error)
 	}, nil
 }
 
+func (m mockTransaction) Cancel(trxID, userID int, reason string) (models.Transaction, error) {
+	return models.Transaction{
+		UserID:    1,
+		PartnerID: 2,
+		Status:    constants.CANCEL_STATUS,
+	}, nil
+}
+
+func (m mockTransaction) RenewPayment(trxID, userID int) (models.Transaction, error) {
+	return models.Transaction{
+		UserID:    1,
+		PartnerID: 2,
+		Status:    constants.PENDING_STATUS,
+		Invoices: []models.TransactionInvoice{
+			{ExternalID: "SB-1", Status: "EXPIRED"},
+			{ExternalID: "SB-1-2", PaymentUrl: "https://sandbox.invalid/invoices/2", Status: "PENDING"},
+		},
+	}, nil
+}
+
 func (m mockTransaction) GetAllForPartner(partnerID int) ([]models.Transaction, error) {
 	return []models.Transaction{
 		{
@@ -1135,9 +1879,10 @@ func (m mockTransaction) GetAllForUser(userID int) ([]models.Transaction, error)
 		{
 			UserID:    1,
 			PartnerID: 2,
-			Products: []models.Product{
+			DetailTransactions: []models.DetailTransaction{
 				{
-					Title: "bakso",
+					Title:    "bakso",
+					Quantity: 1,
 				},
 			},
 		},
@@ -1148,9 +1893,23 @@ func (m mockTransaction) GetOneForUser(trxID, userID int) (models.Transaction, e
 	return models.Transaction{
 		UserID:    1,
 		PartnerID: 2,
-		Products: []models.Product{
+		InvoiceID: "SB-1",
+		Status:    "PAID",
+		PaidAt:    time.Now(),
+		User: models.User{
+			Name:  "test",
+			Email: "test@gmail.com",
+		},
+		Partner: models.Partner{
+			BussinessName: "test",
+			Address:       "jl. test",
+			City:          "malang",
+		},
+		DetailTransactions: []models.DetailTransaction{
 			{
-				Title: "bakso",
+				Title:    "bakso",
+				Price:    10000,
+				Quantity: 1,
 			},
 		},
 	}, nil
@@ -1160,27 +1919,85 @@ func (m mockTransaction) GetOneForPartner(trxID, partnerID int) (models.Transact
 	return models.Transaction{
 		UserID:    1,
 		PartnerID: 2,
-		Products: []models.Product{
+		DetailTransactions: []models.DetailTransaction{
 			{
-				Title: "bakso",
+				Title:    "bakso",
+				Quantity: 1,
 			},
 		},
 	}, nil
 }
 
+func (m mockTransaction) GetTimelineForUser(trxID, userID int) ([]models.TransactionStatusHistory, error) {
+	return []models.TransactionStatusHistory{
+		{
+			TransactionID: 1,
+			ToStatus:      "PENDING",
+			ActorID:       1,
+			ActorRole:     "user",
+		},
+	}, nil
+}
+
+func (m mockTransaction) GetTimelineForPartner(trxID, partnerID int) ([]models.TransactionStatusHistory, error) {
+	return []models.TransactionStatusHistory{
+		{
+			TransactionID: 1,
+			ToStatus:      "PENDING",
+			ActorID:       1,
+			ActorRole:     "user",
+		},
+	}, nil
+}
+
 func (m mockTransaction) GetPartnerFromProduct(productID int) (models.Partner, error) {
 	return models.Partner{
 		BussinessName: "test",
+		LeadTimeHours: 72,
+		MinQuantity:   1,
+		MaxDistance:   10,
+	}, nil
+}
+
+func (m mockTransaction) GetShippingTariff(partnerID int) (models.ShippingTariff, error) {
+	tariff := helper.DefaultShippingTariff()
+	tariff.Surcharges = []models.ShippingSurcharge{{MinQuantity: 100, Amount: 20000}}
+
+	return tariff, nil
+}
+
+func (m mockTransaction) GetPartner(partnerID int) (models.Partner, error) {
+	return models.Partner{
+		BussinessName: "test",
+		LeadTimeHours: 72,
+		MinQuantity:   1,
+		MaxQuantity:   500,
+		MaxDistance:   10,
 	}, nil
 }
 
-func (m mockTransaction) Callback(invId string, transaction models.Transaction, refund float64) (models.Transaction, error) {
+func (m mockTransaction) Callback(invId string, transaction models.Transaction) (models.Transaction, error) {
 	return models.Transaction{
 		UserID:    1,
 		PartnerID: 2,
 	}, nil
 }
 
+// mockCallbackTransaction keeps what the callback handler passed on to the repository.
+type mockCallbackTransaction struct {
+	mockTransaction
+	received models.Transaction
+}
+
+func (m *mockCallbackTransaction) Callback(invId string, transaction models.Transaction) (models.Transaction, error) {
+	m.received = transaction
+	return transaction, nil
+}
+
+func (m mockTransaction) Checkout(orders []models.Transaction, email, voucherCode string, items [][]models.DetailTransaction) ([]models.Transaction, error) {
+	return orders, nil
+}
+
 func (m mockTransaction) GetDistance(partnerID int, latitude, longtitude float64) (float64, error) {
 	return 1, nil
 }
@@ -1190,7 +2007,7 @@ func (m mockTransaction) GetDistance(partnerID int, latitude, longtitude float64
 //======================
 type mockFalseTransaction struct{}
 
-func (m mockFalseTransaction) Order(transaction models.Transaction, email string, products []int) (models.Transaction, error) {
+func (m mockFalseTransaction) Order(transaction models.Transaction, email string, items []models.DetailTransaction) (models.Transaction, error) {
 	return models.Transaction{
 		UserID:    1,
 		PartnerID: 2,
@@ -1204,7 +2021,28 @@ func (m mockFalseTransaction) Accept(trxID, partnerID int) (models.Transaction,
 	}, errors.New("FAILED")
 }
 
-func (m mockFalseTransaction) Reject(trxID, partnerID int) (models.Transaction, error) {
+func (m mockFalseTransaction) Reject(trxID, partnerID int, reason string) (models.Transaction, error) {
+	return models.Transaction{
+		UserID:    1,
+		PartnerID: 2,
+	}, errors.New("FAILED")
+}
+
+func (m mockFalseTransaction) Prepare(trxID, partnerID int) (models.Transaction, error) {
+	return models.Transaction{
+		UserID:    1,
+		PartnerID: 2,
+	}, errors.New("FAILED")
+}
+
+func (m mockFalseTransaction) Ready(trxID, partnerID int) (models.Transaction, error) {
+	return models.Transaction{
+		UserID:    1,
+		PartnerID: 2,
+	}, errors.New("FAILED")
+}
+
+func (m mockFalseTransaction) Deliver(trxID, partnerID int) (models.Transaction, error) {
 	return models.Transaction{
 		UserID:    1,
 		PartnerID: 2,
@@ -1222,6 +2060,14 @@ func (m mockFalseTransaction) Confirm(trxID, userID int) (models.Transaction, er
 	return models.Transaction{}, errors.New("FAILED")
 }
 
+func (m mockFalseTransaction) Cancel(trxID, userID int, reason string) (models.Transaction, error) {
+	return models.Transaction{}, errors.New("FAILED")
+}
+
+func (m mockFalseTransaction) RenewPayment(trxID, userID int) (models.Transaction, error) {
+	return models.Transaction{}, errors.New("FAILED")
+}
+
 func (m mockFalseTransaction) GetAllForPartner(partnerID int) ([]models.Transaction, error) {
 	return []models.Transaction{
 		{
@@ -1254,19 +2100,41 @@ func (m mockFalseTransaction) GetOneForPartner(trxID, partnerID int) (models.Tra
 	}, nil
 }
 
+func (m mockFalseTransaction) GetTimelineForUser(trxID, userID int) ([]models.TransactionStatusHistory, error) {
+	return nil, errors.New("FAILED")
+}
+
+func (m mockFalseTransaction) GetTimelineForPartner(trxID, partnerID int) ([]models.TransactionStatusHistory, error) {
+	return nil, errors.New("FAILED")
+}
+
 func (m mockFalseTransaction) GetPartnerFromProduct(productID int) (models.Partner, error) {
 	return models.Partner{
 		BussinessName: "test",
 	}, errors.New("FAILED")
 }
 
-func (m mockFalseTransaction) Callback(invId string, transaction models.Transaction, refund float64) (models.Transaction, error) {
+func (m mockFalseTransaction) GetShippingTariff(partnerID int) (models.ShippingTariff, error) {
+	return helper.DefaultShippingTariff(), nil
+}
+
+func (m mockFalseTransaction) GetPartner(partnerID int) (models.Partner, error) {
+	return models.Partner{
+		BussinessName: "test",
+	}, nil
+}
+
+func (m mockFalseTransaction) Callback(invId string, transaction models.Transaction) (models.Transaction, error) {
 	return models.Transaction{
 		UserID:    1,
 		PartnerID: 2,
 	}, errors.New("FAILED")
 
 }
+func (m mockFalseTransaction) Checkout(orders []models.Transaction, email, voucherCode string, items [][]models.DetailTransaction) ([]models.Transaction, error) {
+	return orders, nil
+}
+
 func (m mockFalseTransaction) GetDistance(partnerID int, latitude, longtitude float64) (float64, error) {
 	return 1, errors.New("FAILED")
 }
@@ -1276,7 +2144,7 @@ func (m mockFalseTransaction) GetDistance(partnerID int, latitude, longtitude fl
 //======================
 type mockFalseTransaction2 struct{}
 
-func (m mockFalseTransaction2) Order(transaction models.Transaction, email string, products []int) (models.Transaction, error) {
+func (m mockFalseTransaction2) Order(transaction models.Transaction, email string, items []models.DetailTransaction) (models.Transaction, error) {
 	return models.Transaction{
 		UserID:    1,
 		PartnerID: 2,
@@ -1290,7 +2158,28 @@ func (m mockFalseTransaction2) Accept(trxID, partnerID int) (models.Transaction,
 	}, nil
 }
 
-func (m mockFalseTransaction2) Reject(trxID, partnerID int) (models.Transaction, error) {
+func (m mockFalseTransaction2) Reject(trxID, partnerID int, reason string) (models.Transaction, error) {
+	return models.Transaction{
+		UserID:    1,
+		PartnerID: 2,
+	}, nil
+}
+
+func (m mockFalseTransaction2) Prepare(trxID, partnerID int) (models.Transaction, error) {
+	return models.Transaction{
+		UserID:    1,
+		PartnerID: 2,
+	}, nil
+}
+
+func (m mockFalseTransaction2) Ready(trxID, partnerID int) (models.Transaction, error) {
+	return models.Transaction{
+		UserID:    1,
+		PartnerID: 2,
+	}, nil
+}
+
+func (m mockFalseTransaction2) Deliver(trxID, partnerID int) (models.Transaction, error) {
 	return models.Transaction{
 		UserID:    1,
 		PartnerID: 2,
@@ -1311,6 +2200,14 @@ func (m mockFalseTransaction2) Confirm(trxID, userID int) (models.Transaction, e
 	}, nil
 }
 
+func (m mockFalseTransaction2) Cancel(trxID, userID int, reason string) (models.Transaction, error) {
+	return models.Transaction{}, helper.ErrCancellationTooLate
+}
+
+func (m mockFalseTransaction2) RenewPayment(trxID, userID int) (models.Transaction, error) {
+	return models.Transaction{}, helper.PaymentError("the payment link is still valid")
+}
+
 func (m mockFalseTransaction2) GetAllForPartner(partnerID int) ([]models.Transaction, error) {
 	return []models.Transaction{
 		{
@@ -1343,19 +2240,53 @@ func (m mockFalseTransaction2) GetOneForPartner(trxID, partnerID int) (models.Tr
 	}, nil
 }
 
+func (m mockFalseTransaction2) GetTimelineForUser(trxID, userID int) ([]models.TransactionStatusHistory, error) {
+	return []models.TransactionStatusHistory{
+		{
+			TransactionID: 1,
+			ToStatus:      "PENDING",
+			ActorID:       1,
+			ActorRole:     "user",
+		},
+	}, nil
+}
+
+func (m mockFalseTransaction2) GetTimelineForPartner(trxID, partnerID int) ([]models.TransactionStatusHistory, error) {
+	return []models.TransactionStatusHistory{
+		{
+			TransactionID: 1,
+			ToStatus:      "PENDING",
+			ActorID:       1,
+			ActorRole:     "user",
+		},
+	}, nil
+}
+
 func (m mockFalseTransaction2) GetPartnerFromProduct(productID int) (models.Partner, error) {
 	return models.Partner{
 		BussinessName: "test",
 	}, nil
 }
 
-func (m mockFalseTransaction2) Callback(invId string, transaction models.Transaction, refund float64) (models.Transaction, error) {
+func (m mockFalseTransaction2) GetShippingTariff(partnerID int) (models.ShippingTariff, error) {
+	return helper.DefaultShippingTariff(), nil
+}
+
+func (m mockFalseTransaction2) GetPartner(partnerID int) (models.Partner, error) {
+	return models.Partner{}, errors.New("FAILED")
+}
+
+func (m mockFalseTransaction2) Callback(invId string, transaction models.Transaction) (models.Transaction, error) {
 	return models.Transaction{
 		UserID:    1,
 		PartnerID: 2,
 	}, nil
 }
 
+func (m mockFalseTransaction2) Checkout(orders []models.Transaction, email, voucherCode string, items [][]models.DetailTransaction) ([]models.Transaction, error) {
+	return nil, errors.New("FAILED")
+}
+
 func (m mockFalseTransaction2) GetDistance(partnerID int, latitude, longtitude float64) (float64, error) {
 	return 1, errors.New("FAILED")
 }
@@ -30,27 +30,29 @@ type DeleteUserResponseFormat struct {
 }
 
 type UserResponse struct {
-	ID      uint    `json:"id"`
-	Email   string  `json:"email"`
-	Name    string  `json:"name"`
-	Address string  `json:"address"`
-	City    string  `json:"city"`
-	Balance float64 `json:"balance"`
-	Role    string  `json:"role"`
+	ID      uint         `json:"id"`
+	Email   string       `json:"email"`
+	Name    string       `json:"name"`
+	Address string       `json:"address"`
+	City    string       `json:"city"`
+	Balance models.Money `json:"balance"`
+	Role    string       `json:"role"`
 }
 
 type UserProfileResponse struct {
-	ID      uint    `json:"id"`
-	Email   string  `json:"email"`
-	Name    string  `json:"name"`
-	Photo   string  `json:"photo"`
-	Balance float64 `json:"balance"`
+	ID             uint         `json:"id"`
+	Email          string       `json:"email"`
+	Name           string       `json:"name"`
+	Photo          string       `json:"photo"`
+	Balance        models.Money `json:"balance"`
+	PendingBalance models.Money `json:"pending_balance"`
 }
 type UserProfileResponseWithPartner struct {
-	ID      uint                              `json:"id"`
-	Email   string                            `json:"email"`
-	Name    string                            `json:"name"`
-	Photo   string                            `json:"photo"`
-	Balance float64                           `json:"balance"`
-	Partner partner.GetPartnerProfileResponse `json:"partner"`
+	ID             uint                              `json:"id"`
+	Email          string                            `json:"email"`
+	Name           string                            `json:"name"`
+	Photo          string                            `json:"photo"`
+	Balance        models.Money                      `json:"balance"`
+	PendingBalance models.Money                      `json:"pending_balance"`
+	Partner        partner.GetPartnerProfileResponse `json:"partner"`
 }
@@ -110,21 +110,23 @@ func (uscon UserController) GetUserController() echo.HandlerFunc {
 
 		if user.Partner.ID == 0 {
 			data := UserProfileResponse{
-				ID:      user.ID,
-				Name:    user.Name,
-				Photo:   userProfile,
-				Email:   user.Email,
-				Balance: user.Balance,
+				ID:             user.ID,
+				Name:           user.Name,
+				Photo:          userProfile,
+				Email:          user.Email,
+				Balance:        user.Balance,
+				PendingBalance: user.PendingBalance,
 			}
 			return c.JSON(http.StatusOK, common.SuccessResponse(data))
 		}
 
 		data := UserProfileResponseWithPartner{
-			ID:      user.ID,
-			Name:    user.Name,
-			Photo:   userProfile,
-			Email:   user.Email,
-			Balance: user.Balance,
+			ID:             user.ID,
+			Name:           user.Name,
+			Photo:          userProfile,
+			Email:          user.Email,
+			Balance:        user.Balance,
+			PendingBalance: user.PendingBalance,
 			Partner: partner.GetPartnerProfileResponse{
 				ID:            int(user.Partner.ID),
 				BussinessName: user.Partner.BussinessName,
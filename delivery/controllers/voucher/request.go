@@ -0,0 +1,39 @@
+package voucher
+
+import (
+	"net/http"
+
+	"github.com/furqonzt99/snackbox/delivery/common"
+	"github.com/furqonzt99/snackbox/models"
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+// VoucherRequest describes a voucher. StartAt and EndAt are RFC 3339 timestamps,
+// e.g. 2026-01-01T00:00:00+07:00.
+type VoucherRequest struct {
+	Code         string       `json:"code" validate:"required,alphanum,max=32"`
+	Type         string       `json:"type" validate:"required,oneof=PERCENTAGE FLAT FREE_SHIPPING"`
+	Amount       models.Money `json:"amount" validate:"min=0"`
+	Percentage   float64      `json:"percentage" validate:"min=0"`
+	MaxDiscount  models.Money `json:"max_discount" validate:"min=0"`
+	MinSpend     models.Money `json:"min_spend" validate:"min=0"`
+	StartAt      string       `json:"start_at" validate:"required"`
+	EndAt        string       `json:"end_at" validate:"required"`
+	Quota        int          `json:"quota" validate:"min=0"`
+	PerUserLimit int          `json:"per_user_limit" validate:"min=0"`
+	PartnerIDs   []uint       `json:"partner_ids" validate:"dive,min=1"`
+	Categories   []string     `json:"categories" validate:"dive,required,max=32"`
+}
+
+type VoucherValidator struct {
+	Validator *validator.Validate
+}
+
+func (cv *VoucherValidator) Validate(i interface{}) error {
+	if err := cv.Validator.Struct(i); err != nil {
+		// Optionally, you could return the error to give each route more control over the status code
+		return echo.NewHTTPError(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	return nil
+}
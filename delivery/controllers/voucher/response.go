@@ -0,0 +1,20 @@
+package voucher
+
+import "github.com/furqonzt99/snackbox/models"
+
+type VoucherResponse struct {
+	ID           int          `json:"id"`
+	Code         string       `json:"code"`
+	Type         string       `json:"type"`
+	Amount       models.Money `json:"amount"`
+	Percentage   float64      `json:"percentage"`
+	MaxDiscount  models.Money `json:"max_discount"`
+	MinSpend     models.Money `json:"min_spend"`
+	StartAt      string       `json:"start_at"`
+	EndAt        string       `json:"end_at"`
+	Quota        int          `json:"quota"`
+	Used         int          `json:"used"`
+	PerUserLimit int          `json:"per_user_limit"`
+	PartnerIDs   []uint       `json:"partner_ids"`
+	Categories   []string     `json:"categories"`
+}
@@ -0,0 +1,184 @@
+package voucher
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/delivery/common"
+	"github.com/furqonzt99/snackbox/models"
+	"github.com/furqonzt99/snackbox/repositories/voucher"
+	"github.com/labstack/echo/v4"
+)
+
+type VoucherController struct {
+	Repo voucher.VoucherInterface
+}
+
+func NewVoucherController(voucher voucher.VoucherInterface) *VoucherController {
+	return &VoucherController{Repo: voucher}
+}
+
+func (vc VoucherController) Create(c echo.Context) error {
+	voucher, err := bindVoucher(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	voucher, err = vc.Repo.Create(voucher)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	return c.JSON(http.StatusOK, common.SuccessResponse(newVoucherResponse(voucher)))
+}
+
+func (vc VoucherController) GetAll(c echo.Context) error {
+	vouchers, err := vc.Repo.GetAll()
+	if err != nil {
+		return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
+	}
+
+	response := []VoucherResponse{}
+	for _, voucher := range vouchers {
+		response = append(response, newVoucherResponse(voucher))
+	}
+
+	return c.JSON(http.StatusOK, common.SuccessResponse(response))
+}
+
+func (vc VoucherController) Get(c echo.Context) error {
+	voucherID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	voucher, err := vc.Repo.Get(voucherID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
+	}
+
+	return c.JSON(http.StatusOK, common.SuccessResponse(newVoucherResponse(voucher)))
+}
+
+func (vc VoucherController) Update(c echo.Context) error {
+	voucherID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	voucher, err := bindVoucher(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	voucher, err = vc.Repo.Update(voucherID, voucher)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
+	}
+
+	return c.JSON(http.StatusOK, common.SuccessResponse(newVoucherResponse(voucher)))
+}
+
+func (vc VoucherController) Delete(c echo.Context) error {
+	voucherID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	if err := vc.Repo.Delete(voucherID); err != nil {
+		return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
+	}
+
+	return c.JSON(http.StatusOK, common.NewSuccessOperationResponse())
+}
+
+func bindVoucher(c echo.Context) (models.Voucher, error) {
+	var voucherRequest VoucherRequest
+
+	c.Bind(&voucherRequest)
+
+	if err := c.Validate(&voucherRequest); err != nil {
+		return models.Voucher{}, errors.New("invalid voucher request")
+	}
+
+	startAt, err := time.Parse(time.RFC3339, voucherRequest.StartAt)
+	if err != nil {
+		return models.Voucher{}, errors.New("start_at must be an RFC 3339 timestamp")
+	}
+
+	endAt, err := time.Parse(time.RFC3339, voucherRequest.EndAt)
+	if err != nil {
+		return models.Voucher{}, errors.New("end_at must be an RFC 3339 timestamp")
+	}
+
+	if !endAt.After(startAt) {
+		return models.Voucher{}, errors.New("end_at must be after start_at")
+	}
+
+	switch voucherRequest.Type {
+	case constants.PERCENTAGE_VOUCHER:
+		if voucherRequest.Percentage <= 0 || voucherRequest.Percentage > 100 {
+			return models.Voucher{}, errors.New("a percentage voucher needs a percentage between 0 and 100")
+		}
+	case constants.FLAT_VOUCHER:
+		if voucherRequest.Amount <= 0 {
+			return models.Voucher{}, errors.New("a flat voucher needs an amount above 0")
+		}
+	}
+
+	voucher := models.Voucher{
+		Code:         strings.ToUpper(voucherRequest.Code),
+		Type:         voucherRequest.Type,
+		Amount:       voucherRequest.Amount,
+		Percentage:   voucherRequest.Percentage,
+		MaxDiscount:  voucherRequest.MaxDiscount,
+		MinSpend:     voucherRequest.MinSpend,
+		StartAt:      startAt,
+		EndAt:        endAt,
+		Quota:        voucherRequest.Quota,
+		PerUserLimit: voucherRequest.PerUserLimit,
+	}
+
+	for _, partnerID := range voucherRequest.PartnerIDs {
+		voucher.Partners = append(voucher.Partners, models.VoucherPartner{PartnerID: partnerID})
+	}
+
+	for _, category := range voucherRequest.Categories {
+		voucher.Categories = append(voucher.Categories, models.VoucherCategory{Category: category})
+	}
+
+	return voucher, nil
+}
+
+func newVoucherResponse(voucher models.Voucher) VoucherResponse {
+	response := VoucherResponse{
+		ID:           int(voucher.ID),
+		Code:         voucher.Code,
+		Type:         voucher.Type,
+		Amount:       voucher.Amount,
+		Percentage:   voucher.Percentage,
+		MaxDiscount:  voucher.MaxDiscount,
+		MinSpend:     voucher.MinSpend,
+		StartAt:      voucher.StartAt.Format(time.RFC3339),
+		EndAt:        voucher.EndAt.Format(time.RFC3339),
+		Quota:        voucher.Quota,
+		Used:         voucher.Used,
+		PerUserLimit: voucher.PerUserLimit,
+		PartnerIDs:   []uint{},
+		Categories:   []string{},
+	}
+
+	for _, partner := range voucher.Partners {
+		response.PartnerIDs = append(response.PartnerIDs, partner.PartnerID)
+	}
+
+	for _, category := range voucher.Categories {
+		response.Categories = append(response.Categories, category.Category)
+	}
+
+	return response
+}
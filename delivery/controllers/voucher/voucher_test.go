@@ -0,0 +1,278 @@
+package voucher_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/furqonzt99/snackbox/delivery/common"
+	"github.com/furqonzt99/snackbox/delivery/controllers/voucher"
+	"github.com/furqonzt99/snackbox/models"
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVoucher(t *testing.T) {
+	t.Run("create voucher success", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = &voucher.VoucherValidator{Validator: validator.New()}
+
+		requestBody, _ := json.Marshal(voucher.VoucherRequest{
+			Code:         "hemat10",
+			Type:         "PERCENTAGE",
+			Percentage:   10,
+			MaxDiscount:  50000,
+			MinSpend:     100000,
+			StartAt:      "2026-01-01T00:00:00+07:00",
+			EndAt:        "2026-12-31T23:59:59+07:00",
+			Quota:        100,
+			PerUserLimit: 1,
+			PartnerIDs:   []uint{1},
+			Categories:   []string{"snackbox"},
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(requestBody))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+
+		context := e.NewContext(req, res)
+		context.SetPath("/vouchers")
+
+		voucherController := voucher.NewVoucherController(mockVoucher{})
+		voucherController.Create(context)
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Successful Operation", responses.Message)
+		assert.Equal(t, "HEMAT10", responses.Data.(map[string]interface{})["code"])
+	})
+
+	t.Run("create voucher percentage above 100", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = &voucher.VoucherValidator{Validator: validator.New()}
+
+		requestBody, _ := json.Marshal(voucher.VoucherRequest{
+			Code:       "GRATIS",
+			Type:       "PERCENTAGE",
+			Percentage: 150,
+			StartAt:    "2026-01-01T00:00:00+07:00",
+			EndAt:      "2026-12-31T23:59:59+07:00",
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(requestBody))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+
+		context := e.NewContext(req, res)
+		context.SetPath("/vouchers")
+
+		voucherController := voucher.NewVoucherController(mockVoucher{})
+		voucherController.Create(context)
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "a percentage voucher needs a percentage between 0 and 100", responses.Message)
+	})
+
+	t.Run("create voucher window ends before it starts", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = &voucher.VoucherValidator{Validator: validator.New()}
+
+		requestBody, _ := json.Marshal(voucher.VoucherRequest{
+			Code:    "ONGKIR",
+			Type:    "FREE_SHIPPING",
+			StartAt: "2026-12-31T00:00:00+07:00",
+			EndAt:   "2026-01-01T00:00:00+07:00",
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(requestBody))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+
+		context := e.NewContext(req, res)
+		context.SetPath("/vouchers")
+
+		voucherController := voucher.NewVoucherController(mockVoucher{})
+		voucherController.Create(context)
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "end_at must be after start_at", responses.Message)
+	})
+
+	t.Run("create voucher duplicate code", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = &voucher.VoucherValidator{Validator: validator.New()}
+
+		requestBody, _ := json.Marshal(voucher.VoucherRequest{
+			Code:    "HEMAT10",
+			Type:    "FLAT",
+			Amount:  10000,
+			StartAt: "2026-01-01T00:00:00+07:00",
+			EndAt:   "2026-12-31T23:59:59+07:00",
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(requestBody))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+
+		context := e.NewContext(req, res)
+		context.SetPath("/vouchers")
+
+		voucherController := voucher.NewVoucherController(mockFalseVoucher{})
+		voucherController.Create(context)
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Bad Request", responses.Message)
+	})
+
+	t.Run("get all vouchers", func(t *testing.T) {
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		res := httptest.NewRecorder()
+
+		context := e.NewContext(req, res)
+		context.SetPath("/vouchers")
+
+		voucherController := voucher.NewVoucherController(mockVoucher{})
+		voucherController.GetAll(context)
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Successful Operation", responses.Message)
+		assert.Equal(t, 1, len(responses.Data.([]interface{})))
+	})
+
+	t.Run("get voucher not found", func(t *testing.T) {
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		res := httptest.NewRecorder()
+
+		context := e.NewContext(req, res)
+		context.SetPath("/vouchers/:id")
+		context.SetParamNames("id")
+		context.SetParamValues("1")
+
+		voucherController := voucher.NewVoucherController(mockFalseVoucher{})
+		voucherController.Get(context)
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Not Found", responses.Message)
+	})
+
+	t.Run("update voucher bad request param", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = &voucher.VoucherValidator{Validator: validator.New()}
+
+		req := httptest.NewRequest(http.MethodPut, "/", nil)
+		res := httptest.NewRecorder()
+
+		context := e.NewContext(req, res)
+		context.SetPath("/vouchers/:id")
+		context.SetParamNames("id")
+		context.SetParamValues("abc")
+
+		voucherController := voucher.NewVoucherController(mockVoucher{})
+		voucherController.Update(context)
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Bad Request", responses.Message)
+	})
+
+	t.Run("delete voucher", func(t *testing.T) {
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodDelete, "/", nil)
+		res := httptest.NewRecorder()
+
+		context := e.NewContext(req, res)
+		context.SetPath("/vouchers/:id")
+		context.SetParamNames("id")
+		context.SetParamValues("1")
+
+		voucherController := voucher.NewVoucherController(mockVoucher{})
+		voucherController.Delete(context)
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Successful Operation", responses.Message)
+	})
+}
+
+// ======================
+// MOCK VOUCHER REPOSITORY
+// ======================
+type mockVoucher struct{}
+
+func (m mockVoucher) Create(voucher models.Voucher) (models.Voucher, error) {
+	voucher.ID = 1
+	return voucher, nil
+}
+
+func (m mockVoucher) GetAll() ([]models.Voucher, error) {
+	return []models.Voucher{
+		{
+			Code:       "HEMAT10",
+			Type:       "PERCENTAGE",
+			Percentage: 10,
+			StartAt:    time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+			EndAt:      time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}, nil
+}
+
+func (m mockVoucher) Get(voucherID int) (models.Voucher, error) {
+	return models.Voucher{Code: "HEMAT10", Type: "PERCENTAGE", Percentage: 10}, nil
+}
+
+func (m mockVoucher) Update(voucherID int, voucher models.Voucher) (models.Voucher, error) {
+	return voucher, nil
+}
+
+func (m mockVoucher) Delete(voucherID int) error {
+	return nil
+}
+
+type mockFalseVoucher struct{}
+
+func (m mockFalseVoucher) Create(voucher models.Voucher) (models.Voucher, error) {
+	return voucher, errors.New("FAILED")
+}
+
+func (m mockFalseVoucher) GetAll() ([]models.Voucher, error) {
+	return nil, errors.New("FAILED")
+}
+
+func (m mockFalseVoucher) Get(voucherID int) (models.Voucher, error) {
+	return models.Voucher{}, errors.New("FAILED")
+}
+
+func (m mockFalseVoucher) Update(voucherID int, voucher models.Voucher) (models.Voucher, error) {
+	return voucher, errors.New("FAILED")
+}
+
+func (m mockFalseVoucher) Delete(voucherID int) error {
+	return errors.New("FAILED")
+}
@@ -0,0 +1,33 @@
+package wallet
+
+import (
+	"net/http"
+
+	"github.com/furqonzt99/snackbox/delivery/common"
+	"github.com/furqonzt99/snackbox/models"
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+// AdjustmentRequest corrects a user's balance, a negative amount takes money off it.
+type AdjustmentRequest struct {
+	UserID uint         `json:"user_id" validate:"required"`
+	Amount models.Money `json:"amount" validate:"required"`
+	Reason string       `json:"reason" validate:"required,max=255"`
+}
+
+type TopupRequest struct {
+	Amount models.Money `json:"amount" validate:"required"`
+}
+
+type WalletValidator struct {
+	Validator *validator.Validate
+}
+
+func (wv *WalletValidator) Validate(i interface{}) error {
+	if err := wv.Validator.Struct(i); err != nil {
+		// Optionally, you could return the error to give each route more control over the status code
+		return echo.NewHTTPError(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package wallet
+
+import (
+	"time"
+
+	"github.com/furqonzt99/snackbox/models"
+)
+
+type LedgerEntryResponse struct {
+	ID          uint         `json:"id"`
+	Type        string       `json:"type"`
+	SourceType  string       `json:"source_type"`
+	SourceID    uint         `json:"source_id"`
+	Amount      models.Money `json:"amount"`
+	Balance     models.Money `json:"balance"`
+	Description string       `json:"description"`
+	CreatedAt   time.Time    `json:"created_at"`
+}
+
+type AdjustmentResponse struct {
+	ID      uint         `json:"id"`
+	UserID  uint         `json:"user_id"`
+	AdminID uint         `json:"admin_id"`
+	Amount  models.Money `json:"amount"`
+	Reason  string       `json:"reason"`
+}
+
+type TopupResponse struct {
+	ID             uint         `json:"id"`
+	ExternalID     string       `json:"external_id"`
+	Amount         models.Money `json:"amount"`
+	Status         string       `json:"status"`
+	PaymentUrl     string       `json:"payment_url"`
+	PaymentMethod  string       `json:"payment_method"`
+	PaymentChannel string       `json:"payment_channel"`
+	PaidAt         time.Time    `json:"paid_at"`
+	CreatedAt      time.Time    `json:"created_at"`
+}
+
+type MismatchResponse struct {
+	UserID        uint         `json:"user_id"`
+	Balance       models.Money `json:"balance"`
+	LedgerBalance models.Money `json:"ledger_balance"`
+}
+
+func newLedgerEntryResponse(entry models.LedgerEntry) LedgerEntryResponse {
+	return LedgerEntryResponse{
+		ID:          entry.ID,
+		Type:        entry.Type,
+		SourceType:  entry.SourceType,
+		SourceID:    entry.SourceID,
+		Amount:      entry.Amount,
+		Balance:     entry.Balance,
+		Description: entry.Description,
+		CreatedAt:   entry.CreatedAt,
+	}
+}
+
+func newTopupResponse(topup models.WalletTopup) TopupResponse {
+	return TopupResponse{
+		ID:             topup.ID,
+		ExternalID:     topup.ExternalID,
+		Amount:         topup.Amount,
+		Status:         topup.Status,
+		PaymentUrl:     topup.PaymentUrl,
+		PaymentMethod:  topup.PaymentMethod,
+		PaymentChannel: topup.PaymentChannel,
+		PaidAt:         topup.PaidAt,
+		CreatedAt:      topup.CreatedAt,
+	}
+}
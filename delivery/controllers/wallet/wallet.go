@@ -0,0 +1,174 @@
+package wallet
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/furqonzt99/snackbox/delivery/common"
+	"github.com/furqonzt99/snackbox/delivery/middlewares"
+	"github.com/furqonzt99/snackbox/helper"
+	"github.com/furqonzt99/snackbox/models"
+	"github.com/furqonzt99/snackbox/repositories/wallet"
+	"github.com/labstack/echo/v4"
+)
+
+type WalletController struct {
+	Repo wallet.WalletInterface
+}
+
+func NewWalletController(wallet wallet.WalletInterface) *WalletController {
+	return &WalletController{Repo: wallet}
+}
+
+// Ledger pages through the wallet entries of the signed in user, newest first,
+// each with the balance right after it.
+func (wc WalletController) Ledger(c echo.Context) error {
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	perpage, _ := strconv.Atoi(c.QueryParam("perpage"))
+
+	if page == 0 {
+		page = 1
+	}
+
+	if perpage == 0 {
+		perpage = 10
+	}
+
+	user, _ := middlewares.ExtractTokenUser(c)
+
+	entries, err := wc.Repo.GetLedger(user.UserID, (page-1)*perpage, perpage)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
+	}
+
+	response := []LedgerEntryResponse{}
+	for _, entry := range entries {
+		response = append(response, newLedgerEntryResponse(entry))
+	}
+
+	return c.JSON(http.StatusOK, common.PaginationResponse(page, perpage, response))
+}
+
+func (wc WalletController) Adjust(c echo.Context) error {
+	var adjustmentRequest AdjustmentRequest
+
+	if err := c.Bind(&adjustmentRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	if err := c.Validate(&adjustmentRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	admin, _ := middlewares.ExtractTokenUser(c)
+
+	adjustment, err := wc.Repo.Adjust(models.WalletAdjustment{
+		UserID:  adjustmentRequest.UserID,
+		AdminID: uint(admin.UserID),
+		Amount:  adjustmentRequest.Amount,
+		Reason:  adjustmentRequest.Reason,
+	})
+	if errors.Is(err, helper.ErrInsufficientBalance) {
+		return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	if err != nil {
+		return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
+	}
+
+	return c.JSON(http.StatusOK, common.SuccessResponse(AdjustmentResponse{
+		ID:      adjustment.ID,
+		UserID:  adjustment.UserID,
+		AdminID: adjustment.AdminID,
+		Amount:  adjustment.Amount,
+		Reason:  adjustment.Reason,
+	}))
+}
+
+// Reconcile lists the users whose balance does not match their wallet ledger.
+func (wc WalletController) Reconcile(c echo.Context) error {
+	mismatches, err := wc.Repo.Reconcile()
+	if err != nil {
+		return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
+	}
+
+	response := []MismatchResponse{}
+	for _, mismatch := range mismatches {
+		response = append(response, MismatchResponse{
+			UserID:        mismatch.UserID,
+			Balance:       mismatch.Balance,
+			LedgerBalance: mismatch.LedgerBalance,
+		})
+	}
+
+	return c.JSON(http.StatusOK, common.SuccessResponse(response))
+}
+
+func (wc WalletController) Topup(c echo.Context) error {
+	var topupRequest TopupRequest
+
+	if err := c.Bind(&topupRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	if err := c.Validate(&topupRequest); err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	if err := helper.ValidateTopupAmount(topupRequest.Amount); err != nil {
+		return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+
+	user, _ := middlewares.ExtractTokenUser(c)
+
+	topup, err := wc.Repo.Topup(models.WalletTopup{
+		UserID: uint(user.UserID),
+		Amount: topupRequest.Amount,
+	}, user.Email)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	return c.JSON(http.StatusOK, common.SuccessResponse(newTopupResponse(topup)))
+}
+
+func (wc WalletController) GetTopups(c echo.Context) error {
+	user, _ := middlewares.ExtractTokenUser(c)
+
+	topups, err := wc.Repo.GetTopups(user.UserID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
+	}
+
+	response := []TopupResponse{}
+	for _, topup := range topups {
+		response = append(response, newTopupResponse(topup))
+	}
+
+	return c.JSON(http.StatusOK, common.SuccessResponse(response))
+}
+
+// HandleTopupCallback applies an invoice callback for a top-up stored by the webhook controller.
+func (wc WalletController) HandleTopupCallback(body []byte) error {
+	var callbackRequest common.TransactionCallbackRequest
+	if err := json.Unmarshal(body, &callbackRequest); err != nil {
+		return err
+	}
+
+	if callbackRequest.ExternalID == "" {
+		return common.ErrInvalidCallback
+	}
+
+	topup := models.WalletTopup{
+		Status:         callbackRequest.Status,
+		PaymentMethod:  callbackRequest.PaymentMethod,
+		PaymentChannel: callbackRequest.PaymentChannel,
+	}
+	topup.PaidAt, _ = time.Parse(time.RFC3339, callbackRequest.PaidAt)
+
+	_, err := wc.Repo.TopupCallback(callbackRequest.ExternalID, topup)
+
+	return err
+}
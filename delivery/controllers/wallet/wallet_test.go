@@ -0,0 +1,435 @@
+package wallet_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/delivery/common"
+	"github.com/furqonzt99/snackbox/delivery/controllers/user"
+	"github.com/furqonzt99/snackbox/delivery/controllers/wallet"
+	"github.com/furqonzt99/snackbox/helper"
+	"github.com/furqonzt99/snackbox/models"
+	walletRepo "github.com/furqonzt99/snackbox/repositories/wallet"
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var JwtToken string
+
+func TestWallet(t *testing.T) {
+	t.Run("Test Login", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = &user.UserValidator{Validator: validator.New()}
+
+		requestBody, _ := json.Marshal(map[string]string{
+			"email":    "test@gmail.com",
+			"password": "test1234",
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(requestBody))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		context := e.NewContext(req, res)
+		context.SetPath("/login")
+
+		userController := user.NewUsersControllers(mockUserRepository{})
+		userController.LoginController()(context)
+
+		response := common.ResponseSuccess{}
+		json.Unmarshal([]byte(res.Body.Bytes()), &response)
+
+		JwtToken = response.Data.(string)
+		assert.Equal(t, "Successful Operation", response.Message)
+		assert.NotNil(t, JwtToken)
+	})
+
+	t.Run("get ledger", func(t *testing.T) {
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodGet, "/?page=2&perpage=5", nil)
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/wallet/ledger")
+
+		walletController := wallet.NewWalletController(mockWallet{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(walletController.Ledger)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		var responses common.ResponsePagination
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Successful Operation", responses.Message)
+		assert.Equal(t, 2, responses.Page)
+		assert.Equal(t, 5, responses.PerPage)
+		assert.Equal(t, float64(150000), responses.Data.([]interface{})[0].(map[string]interface{})["balance"])
+	})
+
+	t.Run("get ledger failed", func(t *testing.T) {
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/wallet/ledger")
+
+		walletController := wallet.NewWalletController(mockFalseWallet{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(walletController.Ledger)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Not Found", responses.Message)
+	})
+
+	t.Run("adjust balance", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = &wallet.WalletValidator{Validator: validator.New()}
+
+		requestBody, _ := json.Marshal(wallet.AdjustmentRequest{
+			UserID: 2,
+			Amount: -5000,
+			Reason: "double refund",
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(requestBody))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/wallet/adjustments")
+
+		walletController := wallet.NewWalletController(mockWallet{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(walletController.Adjust)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Successful Operation", responses.Message)
+		assert.Equal(t, float64(-5000), responses.Data.(map[string]interface{})["amount"])
+	})
+
+	t.Run("adjust balance without reason", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = &wallet.WalletValidator{Validator: validator.New()}
+
+		requestBody, _ := json.Marshal(wallet.AdjustmentRequest{
+			UserID: 2,
+			Amount: 5000,
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(requestBody))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/wallet/adjustments")
+
+		walletController := wallet.NewWalletController(mockWallet{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(walletController.Adjust)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Bad Request", responses.Message)
+	})
+
+	t.Run("adjust balance below zero", func(t *testing.T) {
+		e := echo.New()
+		e.Validator = &wallet.WalletValidator{Validator: validator.New()}
+
+		requestBody, _ := json.Marshal(wallet.AdjustmentRequest{
+			UserID: 2,
+			Amount: -500000,
+			Reason: "double refund",
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(requestBody))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/wallet/adjustments")
+
+		walletController := wallet.NewWalletController(mockFalseWallet{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(walletController.Adjust)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, helper.ErrInsufficientBalance.Error(), responses.Message)
+	})
+
+	t.Run("reconcile", func(t *testing.T) {
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		res := httptest.NewRecorder()
+
+		context := e.NewContext(req, res)
+		context.SetPath("/wallet/reconcile")
+
+		walletController := wallet.NewWalletController(mockWallet{})
+		walletController.Reconcile(context)
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Successful Operation", responses.Message)
+		assert.Equal(t, float64(3), responses.Data.([]interface{})[0].(map[string]interface{})["user_id"])
+	})
+
+	t.Run("topup", func(t *testing.T) {
+		constants.TOPUP_MIN_AMOUNT = 10000
+		constants.TOPUP_MAX_AMOUNT = 10000000
+
+		e := echo.New()
+		e.Validator = &wallet.WalletValidator{Validator: validator.New()}
+
+		requestBody, _ := json.Marshal(wallet.TopupRequest{
+			Amount: 50000,
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(requestBody))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/wallet/topups")
+
+		walletController := wallet.NewWalletController(mockWallet{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(walletController.Topup)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Successful Operation", responses.Message)
+		assert.Equal(t, "https://sandbox.invalid/invoices/1", responses.Data.(map[string]interface{})["payment_url"])
+	})
+
+	t.Run("topup below minimum", func(t *testing.T) {
+		constants.TOPUP_MIN_AMOUNT = 10000
+		constants.TOPUP_MAX_AMOUNT = 10000000
+
+		e := echo.New()
+		e.Validator = &wallet.WalletValidator{Validator: validator.New()}
+
+		requestBody, _ := json.Marshal(wallet.TopupRequest{
+			Amount: 500,
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(requestBody))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/wallet/topups")
+
+		walletController := wallet.NewWalletController(mockWallet{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(walletController.Topup)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+		assert.Equal(t, helper.ValidateTopupAmount(500).Error(), responses.Message)
+	})
+
+	t.Run("get topups", func(t *testing.T) {
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", JwtToken))
+
+		context := e.NewContext(req, res)
+		context.SetPath("/wallet/topups")
+
+		walletController := wallet.NewWalletController(mockWallet{})
+		if err := middleware.JWT([]byte(constants.JWT_SECRET_KEY))(walletController.GetTopups)(context); err != nil {
+			log.Fatal(err)
+			return
+		}
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Successful Operation", responses.Message)
+		assert.Equal(t, 1, len(responses.Data.([]interface{})))
+	})
+
+	t.Run("topup callback", func(t *testing.T) {
+		body, _ := json.Marshal(common.TransactionCallbackRequest{
+			ExternalID: "TOPUP-1",
+			Status:     constants.PAID_STATUS,
+			PaidAt:     "2022-01-10T08:00:00.000Z",
+		})
+
+		walletController := wallet.NewWalletController(mockWallet{})
+		assert.Nil(t, walletController.HandleTopupCallback(body))
+	})
+
+	t.Run("topup callback without external id", func(t *testing.T) {
+		body, _ := json.Marshal(common.TransactionCallbackRequest{
+			Status: constants.PAID_STATUS,
+		})
+
+		walletController := wallet.NewWalletController(mockWallet{})
+		assert.Equal(t, common.ErrInvalidCallback, walletController.HandleTopupCallback(body))
+	})
+
+	t.Run("topup callback failed", func(t *testing.T) {
+		body, _ := json.Marshal(common.TransactionCallbackRequest{
+			ExternalID: "TOPUP-1",
+			Status:     constants.PAID_STATUS,
+		})
+
+		walletController := wallet.NewWalletController(mockFalseWallet{})
+		assert.NotNil(t, walletController.HandleTopupCallback(body))
+	})
+}
+
+// ======================
+// MOCK WALLET REPOSITORY
+// ======================
+type mockWallet struct{}
+
+func (m mockWallet) GetLedger(userID, offset, limit int) ([]models.LedgerEntry, error) {
+	return []models.LedgerEntry{
+		{ID: 2, UserID: uint(userID), Type: constants.REFUND_ENTRY, Amount: 50000, Balance: 150000},
+		{ID: 1, UserID: uint(userID), Type: constants.PARTNER_EARNING_ENTRY, Amount: 100000, Balance: 100000},
+	}, nil
+}
+
+func (m mockWallet) Adjust(adjustment models.WalletAdjustment) (models.WalletAdjustment, error) {
+	adjustment.ID = 1
+	return adjustment, nil
+}
+
+func (m mockWallet) Reconcile() ([]walletRepo.WalletMismatch, error) {
+	return []walletRepo.WalletMismatch{{UserID: 3, Balance: 1000, LedgerBalance: 0}}, nil
+}
+
+func (m mockWallet) Topup(topup models.WalletTopup, email string) (models.WalletTopup, error) {
+	topup.ID = 1
+	topup.ExternalID = "TOPUP-1"
+	topup.Status = constants.PENDING_STATUS
+	topup.PaymentUrl = "https://sandbox.invalid/invoices/1"
+	return topup, nil
+}
+
+func (m mockWallet) GetTopups(userID int) ([]models.WalletTopup, error) {
+	return []models.WalletTopup{
+		{UserID: uint(userID), ExternalID: "TOPUP-1", Amount: 50000, Status: constants.PENDING_STATUS},
+	}, nil
+}
+
+func (m mockWallet) TopupCallback(externalID string, topup models.WalletTopup) (models.WalletTopup, error) {
+	topup.ExternalID = externalID
+	return topup, nil
+}
+
+type mockFalseWallet struct{}
+
+func (m mockFalseWallet) GetLedger(userID, offset, limit int) ([]models.LedgerEntry, error) {
+	return nil, errors.New("FAILED")
+}
+
+func (m mockFalseWallet) Adjust(adjustment models.WalletAdjustment) (models.WalletAdjustment, error) {
+	return adjustment, helper.ErrInsufficientBalance
+}
+
+func (m mockFalseWallet) Reconcile() ([]walletRepo.WalletMismatch, error) {
+	return nil, errors.New("FAILED")
+}
+
+func (m mockFalseWallet) Topup(topup models.WalletTopup, email string) (models.WalletTopup, error) {
+	return topup, errors.New("FAILED")
+}
+
+func (m mockFalseWallet) GetTopups(userID int) ([]models.WalletTopup, error) {
+	return nil, errors.New("FAILED")
+}
+
+func (m mockFalseWallet) TopupCallback(externalID string, topup models.WalletTopup) (models.WalletTopup, error) {
+	return topup, errors.New("FAILED")
+}
+
+// ======================
+// MOCK USER REPOSITORY
+// ======================
+type mockUserRepository struct{}
+
+func (m mockUserRepository) Register(newUser models.User) (models.User, error) {
+	return newUser, nil
+}
+
+func (m mockUserRepository) Login(email string) (models.User, error) {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("test1234"), 14)
+	return models.User{
+		Email:    "test@gmail.com",
+		Password: string(hash),
+	}, nil
+}
+
+func (m mockUserRepository) Get(userid int) (models.User, error) {
+	return models.User{
+		Email: "test@gmail.com",
+		Name:  "tester",
+	}, nil
+}
+
+func (m mockUserRepository) Update(newUser models.User, userId int) (models.User, error) {
+	return newUser, nil
+}
+
+func (m mockUserRepository) Delete(userId int) (models.User, error) {
+	return models.User{}, nil
+}
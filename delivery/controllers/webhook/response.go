@@ -0,0 +1,41 @@
+package webhook
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/furqonzt99/snackbox/models"
+)
+
+type WebhookEventResponse struct {
+	ID            uint              `json:"id"`
+	Source        string            `json:"source"`
+	EventID       string            `json:"event_id"`
+	Status        string            `json:"status"`
+	Attempts      int               `json:"attempts"`
+	Error         string            `json:"error"`
+	Headers       map[string]string `json:"headers"`
+	Body          string            `json:"body"`
+	NextAttemptAt time.Time         `json:"next_attempt_at"`
+	ProcessedAt   time.Time         `json:"processed_at"`
+	CreatedAt     time.Time         `json:"created_at"`
+}
+
+func newWebhookEventResponse(event models.WebhookEvent) WebhookEventResponse {
+	headers := map[string]string{}
+	json.Unmarshal([]byte(event.Headers), &headers)
+
+	return WebhookEventResponse{
+		ID:            event.ID,
+		Source:        event.Source,
+		EventID:       event.EventID,
+		Status:        event.Status,
+		Attempts:      event.Attempts,
+		Error:         event.Error,
+		Headers:       headers,
+		Body:          event.Body,
+		NextAttemptAt: event.NextAttemptAt,
+		ProcessedAt:   event.ProcessedAt,
+		CreatedAt:     event.CreatedAt,
+	}
+}
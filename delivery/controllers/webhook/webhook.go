@@ -0,0 +1,175 @@
+package webhook
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/delivery/common"
+	"github.com/furqonzt99/snackbox/helper"
+	"github.com/furqonzt99/snackbox/models"
+	"github.com/furqonzt99/snackbox/repositories/webhook"
+	"github.com/labstack/echo/v4"
+)
+
+// Queue hands stored events over to the background processor.
+type Queue interface {
+	Enqueue(id uint)
+}
+
+type WebhookController struct {
+	Repo  webhook.WebhookInterface
+	Queue Queue
+}
+
+func NewWebhookController(repo webhook.WebhookInterface, queue Queue) *WebhookController {
+	return &WebhookController{Repo: repo, Queue: queue}
+}
+
+// Receive stores a callback from the payment provider and queues it. The provider gets its
+// answer once the event is stored; a redelivered event is acknowledged without processing it again.
+func (wc WebhookController) Receive(source string) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		body, err := io.ReadAll(c.Request().Body)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+		}
+
+		event := models.WebhookEvent{
+			Source:  eventSource(source, body),
+			EventID: eventID(c.Request().Header, body),
+			Headers: encodeHeaders(c.Request().Header),
+			Body:    string(body),
+		}
+
+		valid := json.Valid(body)
+		if !valid {
+			event.Status = constants.WEBHOOK_FAILED
+			event.Error = "the body is not valid JSON"
+		}
+
+		event, created, err := wc.Repo.Receive(event)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, common.ErrorResponse(http.StatusInternalServerError, "the webhook could not be stored"))
+		}
+
+		if !valid {
+			return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+		}
+
+		if created {
+			wc.Queue.Enqueue(event.ID)
+		}
+
+		return c.JSON(http.StatusOK, common.NewSuccessOperationResponse())
+	}
+}
+
+func (wc WebhookController) GetAll(c echo.Context) error {
+	events, err := wc.Repo.GetAll(c.QueryParam("source"), c.QueryParam("status"))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
+	}
+
+	response := []WebhookEventResponse{}
+	for _, event := range events {
+		response = append(response, newWebhookEventResponse(event))
+	}
+
+	return c.JSON(http.StatusOK, common.SuccessResponse(response))
+}
+
+func (wc WebhookController) Get(c echo.Context) error {
+	eventID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	event, err := wc.Repo.Get(eventID)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
+	}
+
+	return c.JSON(http.StatusOK, common.SuccessResponse(newWebhookEventResponse(event)))
+}
+
+func (wc WebhookController) Replay(c echo.Context) error {
+	eventID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, common.NewBadRequestResponse())
+	}
+
+	event, err := wc.Repo.Replay(eventID)
+	if errors.Is(err, webhook.ErrNotReplayable) {
+		return c.JSON(http.StatusBadRequest, common.ErrorResponse(http.StatusBadRequest, err.Error()))
+	}
+	if err != nil {
+		return c.JSON(http.StatusNotFound, common.NewNotFoundResponse())
+	}
+
+	wc.Queue.Enqueue(event.ID)
+
+	return c.JSON(http.StatusOK, common.SuccessResponse(newWebhookEventResponse(event)))
+}
+
+// eventSource routes invoice callbacks for wallet top-ups away from the transaction handler,
+// both arrive on the same invoice callback URL.
+func eventSource(source string, body []byte) string {
+	if source != constants.TRANSACTION_WEBHOOK {
+		return source
+	}
+
+	var payload struct {
+		ExternalID string `json:"external_id"`
+	}
+	json.Unmarshal(body, &payload)
+
+	if helper.IsTopupID(payload.ExternalID) {
+		return constants.TOPUP_WEBHOOK
+	}
+
+	return source
+}
+
+// eventID identifies the event the way the provider does: by its webhook-id header when it
+// sends one, else by the id and status in the body, which is what a retry repeats.
+// Bodies without an id fall back to a hash of the body.
+func eventID(header http.Header, body []byte) string {
+	if id := header.Get("Webhook-Id"); id != "" {
+		return id
+	}
+
+	var payload struct {
+		ID     string `json:"id"`
+		Status string `json:"status"`
+	}
+	json.Unmarshal(body, &payload)
+
+	if payload.ID != "" {
+		return payload.ID + ":" + payload.Status
+	}
+
+	sum := sha256.Sum256(body)
+
+	return hex.EncodeToString(sum[:])
+}
+
+// encodeHeaders keeps the request headers for debugging, without the callback token.
+func encodeHeaders(header http.Header) string {
+	headers := map[string]string{}
+	for key := range header {
+		if key == "X-Callback-Token" {
+			continue
+		}
+		headers[key] = header.Get(key)
+	}
+
+	encoded, _ := json.Marshal(headers)
+
+	return string(encoded)
+}
@@ -0,0 +1,285 @@
+package webhook_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/delivery/common"
+	"github.com/furqonzt99/snackbox/delivery/controllers/webhook"
+	"github.com/furqonzt99/snackbox/models"
+	webhookRepo "github.com/furqonzt99/snackbox/repositories/webhook"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestWebhook(t *testing.T) {
+	t.Run("receive stores and queues the event", func(t *testing.T) {
+		e := echo.New()
+
+		bodyReq, _ := json.Marshal(map[string]interface{}{
+			"id":          "inv-1",
+			"external_id": "INV1",
+			"status":      "PAID",
+		})
+
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(bodyReq))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("X-Callback-Token", "secret")
+
+		context := e.NewContext(req, res)
+		context.SetPath("/transactions/callback")
+
+		repo := &mockWebhook{}
+		queue := &mockQueue{}
+		webhookController := webhook.NewWebhookController(repo, queue)
+		webhookController.Receive(constants.TRANSACTION_WEBHOOK)(context)
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Successful Operation", responses.Message)
+		assert.Equal(t, "inv-1:PAID", repo.received.EventID)
+		assert.Equal(t, constants.TRANSACTION_WEBHOOK, repo.received.Source)
+		assert.NotContains(t, repo.received.Headers, "secret")
+		assert.Equal(t, []uint{1}, queue.ids)
+	})
+
+	t.Run("receive routes top-up invoices to the top-up handler", func(t *testing.T) {
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(`{"id":"inv-2","external_id":"TOPUP-1","status":"PAID"}`))
+		res := httptest.NewRecorder()
+
+		context := e.NewContext(req, res)
+		context.SetPath("/transactions/callback")
+
+		repo := &mockWebhook{}
+		queue := &mockQueue{}
+		webhookController := webhook.NewWebhookController(repo, queue)
+		webhookController.Receive(constants.TRANSACTION_WEBHOOK)(context)
+
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.Equal(t, constants.TOPUP_WEBHOOK, repo.received.Source)
+	})
+
+	t.Run("receive acknowledges a redelivery without queueing it", func(t *testing.T) {
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(`{"external_id":"INV1","status":"FAILED"}`))
+		res := httptest.NewRecorder()
+
+		req.Header.Set("Webhook-Id", "evt-1")
+
+		context := e.NewContext(req, res)
+		context.SetPath("/cashouts/callback")
+
+		repo := &mockWebhook{duplicate: true}
+		queue := &mockQueue{}
+		webhookController := webhook.NewWebhookController(repo, queue)
+		webhookController.Receive(constants.CASHOUT_WEBHOOK)(context)
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Successful Operation", responses.Message)
+		assert.Equal(t, "evt-1", repo.received.EventID)
+		assert.Empty(t, queue.ids)
+	})
+
+	t.Run("receive invalid body", func(t *testing.T) {
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString("external_id=INV1"))
+		res := httptest.NewRecorder()
+
+		context := e.NewContext(req, res)
+		context.SetPath("/transactions/callback")
+
+		repo := &mockWebhook{}
+		queue := &mockQueue{}
+		webhookController := webhook.NewWebhookController(repo, queue)
+		webhookController.Receive(constants.TRANSACTION_WEBHOOK)(context)
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Bad Request", responses.Message)
+		assert.Equal(t, constants.WEBHOOK_FAILED, repo.received.Status)
+		assert.Empty(t, queue.ids)
+	})
+
+	t.Run("get all webhook events", func(t *testing.T) {
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodGet, "/?status=FAILED", nil)
+		res := httptest.NewRecorder()
+
+		context := e.NewContext(req, res)
+		context.SetPath("/webhooks")
+
+		webhookController := webhook.NewWebhookController(&mockWebhook{}, &mockQueue{})
+		webhookController.GetAll(context)
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Successful Operation", responses.Message)
+		assert.Equal(t, "FAILED", responses.Data.([]interface{})[0].(map[string]interface{})["status"])
+	})
+
+	t.Run("get webhook event not found", func(t *testing.T) {
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		res := httptest.NewRecorder()
+
+		context := e.NewContext(req, res)
+		context.SetPath("/webhooks/:id")
+		context.SetParamNames("id")
+		context.SetParamValues("99")
+
+		webhookController := webhook.NewWebhookController(&mockFalseWebhook{}, &mockQueue{})
+		webhookController.Get(context)
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Not Found", responses.Message)
+	})
+
+	t.Run("replay webhook event", func(t *testing.T) {
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		res := httptest.NewRecorder()
+
+		context := e.NewContext(req, res)
+		context.SetPath("/webhooks/:id/replay")
+		context.SetParamNames("id")
+		context.SetParamValues("1")
+
+		queue := &mockQueue{}
+		webhookController := webhook.NewWebhookController(&mockWebhook{}, queue)
+		webhookController.Replay(context)
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, "Successful Operation", responses.Message)
+		assert.Equal(t, []uint{1}, queue.ids)
+	})
+
+	t.Run("replay webhook event that did not fail", func(t *testing.T) {
+		e := echo.New()
+
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		res := httptest.NewRecorder()
+
+		context := e.NewContext(req, res)
+		context.SetPath("/webhooks/:id/replay")
+		context.SetParamNames("id")
+		context.SetParamValues("1")
+
+		queue := &mockQueue{}
+		webhookController := webhook.NewWebhookController(&mockFalseWebhook{}, queue)
+		webhookController.Replay(context)
+
+		var responses common.ResponseSuccess
+
+		json.Unmarshal([]byte(res.Body.Bytes()), &responses)
+		assert.Equal(t, webhookRepo.ErrNotReplayable.Error(), responses.Message)
+		assert.Empty(t, queue.ids)
+	})
+}
+
+// ======================
+// MOCK WEBHOOK REPOSITORY
+// ======================
+type mockQueue struct {
+	ids []uint
+}
+
+func (m *mockQueue) Enqueue(id uint) {
+	m.ids = append(m.ids, id)
+}
+
+type mockWebhook struct {
+	duplicate bool
+	received  models.WebhookEvent
+}
+
+func (m *mockWebhook) Receive(event models.WebhookEvent) (models.WebhookEvent, bool, error) {
+	m.received = event
+	event.ID = 1
+	return event, !m.duplicate, nil
+}
+
+func (m *mockWebhook) GetAll(source, status string) ([]models.WebhookEvent, error) {
+	return []models.WebhookEvent{{Model: gorm.Model{ID: 1}, Source: constants.CASHOUT_WEBHOOK, Status: status, Headers: "{}"}}, nil
+}
+
+func (m *mockWebhook) Get(id int) (models.WebhookEvent, error) {
+	return models.WebhookEvent{Model: gorm.Model{ID: uint(id)}}, nil
+}
+
+func (m *mockWebhook) GetDue(now time.Time, limit int) ([]models.WebhookEvent, error) {
+	return nil, nil
+}
+
+func (m *mockWebhook) Claim(id int, now time.Time, lease time.Duration) (models.WebhookEvent, bool, error) {
+	return models.WebhookEvent{}, false, nil
+}
+
+func (m *mockWebhook) Processed(id int, now time.Time) error {
+	return nil
+}
+
+func (m *mockWebhook) Failed(id int, message string, retryAt time.Time) error {
+	return nil
+}
+
+func (m *mockWebhook) Replay(id int) (models.WebhookEvent, error) {
+	return models.WebhookEvent{Model: gorm.Model{ID: uint(id)}, Status: constants.WEBHOOK_PENDING}, nil
+}
+
+type mockFalseWebhook struct{}
+
+func (m *mockFalseWebhook) Receive(event models.WebhookEvent) (models.WebhookEvent, bool, error) {
+	return event, false, errors.New("failed")
+}
+
+func (m *mockFalseWebhook) GetAll(source, status string) ([]models.WebhookEvent, error) {
+	return nil, errors.New("failed")
+}
+
+func (m *mockFalseWebhook) Get(id int) (models.WebhookEvent, error) {
+	return models.WebhookEvent{}, errors.New("failed")
+}
+
+func (m *mockFalseWebhook) GetDue(now time.Time, limit int) ([]models.WebhookEvent, error) {
+	return nil, errors.New("failed")
+}
+
+func (m *mockFalseWebhook) Claim(id int, now time.Time, lease time.Duration) (models.WebhookEvent, bool, error) {
+	return models.WebhookEvent{}, false, errors.New("failed")
+}
+
+func (m *mockFalseWebhook) Processed(id int, now time.Time) error {
+	return errors.New("failed")
+}
+
+func (m *mockFalseWebhook) Failed(id int, message string, retryAt time.Time) error {
+	return errors.New("failed")
+}
+
+func (m *mockFalseWebhook) Replay(id int) (models.WebhookEvent, error) {
+	return models.WebhookEvent{}, webhookRepo.ErrNotReplayable
+}
@@ -0,0 +1,279 @@
+package reconciler
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/delivery/common"
+	"github.com/furqonzt99/snackbox/delivery/webhook"
+	"github.com/furqonzt99/snackbox/models"
+	"github.com/furqonzt99/snackbox/payment"
+	"github.com/furqonzt99/snackbox/repositories/reconciliation"
+	"github.com/labstack/gommon/log"
+)
+
+const reconciliationLock = "reconciliation"
+
+// Locker is the part of the scheduler repository that hands out job leases.
+type Locker interface {
+	AcquireLock(name, owner string, ttl time.Duration) (bool, error)
+}
+
+// Reconciler looks up the invoices and disbursements the provider has not called back about
+// and applies what it finds through the same handlers the webhook processor uses. It also
+// polls the settlement of paid invoices, which the provider never calls back about.
+type Reconciler struct {
+	Repo     reconciliation.ReconciliationInterface
+	Locks    Locker
+	Provider payment.PaymentProvider
+	Handlers map[string]webhook.Handler
+	Owner    string
+}
+
+func NewReconciler(repo reconciliation.ReconciliationInterface, locks Locker, provider payment.PaymentProvider, handlers map[string]webhook.Handler) *Reconciler {
+	hostname, _ := os.Hostname()
+
+	return &Reconciler{Repo: repo, Locks: locks, Provider: provider, Handlers: handlers, Owner: fmt.Sprintf("%s-%d", hostname, os.Getpid())}
+}
+
+func (r Reconciler) interval() time.Duration {
+	return time.Duration(constants.RECONCILIATION_INTERVAL_MINUTES) * time.Minute
+}
+
+// Start reconciles on every interval in the background.
+func (r Reconciler) Start() {
+	go func() {
+		ticker := time.NewTicker(r.interval())
+		defer ticker.Stop()
+
+		for range ticker.C {
+			r.Run(time.Now())
+		}
+	}()
+}
+
+// Run reconciles as of now. Replicas that do not hold the lock skip the round.
+func (r Reconciler) Run(now time.Time) {
+	locked, err := r.Locks.AcquireLock(reconciliationLock, r.Owner, 2*r.interval())
+	if err != nil {
+		log.Warnf("reconciler: acquire lock: %v", err)
+		return
+	}
+	if !locked {
+		return
+	}
+
+	run, err := r.Reconcile(now)
+	if err != nil {
+		log.Warnf("reconciler: %v", err)
+		return
+	}
+
+	if len(run.Discrepancies) > 0 {
+		log.Infof("reconciler: run %d checked %d, %d missed callbacks applied, %d failed", run.ID, run.Checked, run.Applied, run.Failed)
+	}
+}
+
+// Reconcile checks every order, top-up and cashout that has been waiting for a callback for
+// longer than RECONCILIATION_GRACE_MINUTES and stores the run with what it found.
+func (r Reconciler) Reconcile(now time.Time) (models.ReconciliationRun, error) {
+	before := now.Add(-time.Duration(constants.RECONCILIATION_GRACE_MINUTES) * time.Minute)
+
+	run := models.ReconciliationRun{}
+
+	invoices, err := r.Repo.GetOpenInvoices(before)
+	if err != nil {
+		return run, err
+	}
+
+	for _, invoice := range invoices {
+		r.reconcileInvoice(&run, constants.TRANSACTION_WEBHOOK, models.ReconciliationDiscrepancy{
+			SourceType:  constants.TRANSACTION_SOURCE,
+			SourceID:    invoice.TransactionID,
+			Reference:   invoice.ExternalID,
+			LocalStatus: invoice.Status,
+			LocalAmount: invoice.Amount,
+		}, invoice.PaymentInvoiceID)
+	}
+
+	topups, err := r.Repo.GetOpenTopups(before)
+	if err != nil {
+		return run, err
+	}
+
+	for _, topup := range topups {
+		r.reconcileInvoice(&run, constants.TOPUP_WEBHOOK, models.ReconciliationDiscrepancy{
+			SourceType:  constants.TOPUP_SOURCE,
+			SourceID:    topup.ID,
+			Reference:   topup.ExternalID,
+			LocalStatus: topup.Status,
+			LocalAmount: topup.Amount,
+		}, topup.PaymentInvoiceID)
+	}
+
+	cashouts, err := r.Repo.GetOpenCashouts(before)
+	if err != nil {
+		return run, err
+	}
+
+	for _, cashout := range cashouts {
+		r.reconcileDisbursement(&run, models.ReconciliationDiscrepancy{
+			SourceType:  constants.CASHOUT_SOURCE,
+			SourceID:    cashout.ID,
+			Reference:   cashout.ExternalID,
+			LocalStatus: cashout.Status,
+			LocalAmount: cashout.Amount,
+		})
+	}
+
+	unsettled, err := r.Repo.GetUnsettledInvoices(before)
+	if err != nil {
+		return run, err
+	}
+
+	for _, invoice := range unsettled {
+		r.reconcileSettlement(&run, models.ReconciliationDiscrepancy{
+			SourceType:  constants.TRANSACTION_SOURCE,
+			SourceID:    invoice.TransactionID,
+			Reference:   invoice.ExternalID,
+			LocalStatus: constants.SETTLEMENT_PENDING,
+			LocalAmount: invoice.Amount,
+		})
+	}
+
+	return r.Repo.SaveRun(run)
+}
+
+// reconcileInvoice looks the invoice up and, once it is paid or expired, applies it as the
+// invoice callback would.
+func (r Reconciler) reconcileInvoice(run *models.ReconciliationRun, source string, record models.ReconciliationDiscrepancy, invoiceID string) {
+	run.Checked++
+
+	invoice, err := r.Provider.GetInvoice(invoiceID)
+	if err != nil {
+		lookupFailed(run, record, err)
+		return
+	}
+
+	record.ProviderStatus = invoice.Status
+	record.ProviderAmount = invoice.Amount
+
+	if invoice.Status == payment.INVOICE_PENDING {
+		return
+	}
+
+	if invoice.Status == payment.INVOICE_PAID && invoice.PaidAmount != record.LocalAmount {
+		mismatch := record
+		mismatch.Kind = constants.DISCREPANCY_AMOUNT_MISMATCH
+		mismatch.ProviderAmount = invoice.PaidAmount
+		mismatch.Note = fmt.Sprintf("Rp%d was paid for Rp%d", invoice.PaidAmount, record.LocalAmount)
+		run.Discrepancies = append(run.Discrepancies, mismatch)
+	}
+
+	callback := common.TransactionCallbackRequest{
+		ExternalID:             record.Reference,
+		PaymentMethod:          invoice.PaymentMethod,
+		PaymentChannel:         invoice.PaymentChannel,
+		PaidAmount:             invoice.PaidAmount.Float64(),
+		AdjustedReceivedAmount: invoice.ReceivedAmount.Float64(),
+		Status:                 invoice.Status,
+	}
+	if !invoice.PaidAt.IsZero() {
+		callback.PaidAt = invoice.PaidAt.Format(time.RFC3339)
+	}
+
+	r.apply(run, source, record, callback)
+}
+
+// reconcileDisbursement looks the disbursement up and, once it completed or failed, applies it
+// as the disbursement callback would.
+func (r Reconciler) reconcileDisbursement(run *models.ReconciliationRun, record models.ReconciliationDiscrepancy) {
+	run.Checked++
+
+	disbursement, err := r.Provider.GetDisbursement(record.Reference)
+	if err != nil {
+		lookupFailed(run, record, err)
+		return
+	}
+
+	record.ProviderStatus = disbursement.Status
+	record.ProviderAmount = disbursement.Amount
+
+	if disbursement.Status == payment.DISBURSEMENT_PENDING {
+		return
+	}
+
+	if disbursement.Amount != record.LocalAmount {
+		mismatch := record
+		mismatch.Kind = constants.DISCREPANCY_AMOUNT_MISMATCH
+		mismatch.Note = fmt.Sprintf("Rp%d was disbursed for Rp%d", disbursement.Amount, record.LocalAmount)
+		run.Discrepancies = append(run.Discrepancies, mismatch)
+	}
+
+	r.apply(run, constants.CASHOUT_WEBHOOK, record, common.CashoutCallbackRequest{
+		ExternalID: record.Reference,
+		Amount:     disbursement.Amount.Float64(),
+		Fee:        disbursement.Fee.Float64(),
+		Status:     disbursement.Status,
+	})
+}
+
+// reconcileSettlement asks the provider whether the money of a paid invoice reached the
+// platform's account and records it on the order once it did. No callback reports it, so
+// this is the only way an order leaves PENDING settlement.
+func (r Reconciler) reconcileSettlement(run *models.ReconciliationRun, record models.ReconciliationDiscrepancy) {
+	run.Checked++
+
+	settlement, err := r.Provider.GetSettlement(record.Reference)
+	if err != nil {
+		lookupFailed(run, record, err)
+		return
+	}
+
+	if settlement.Status != payment.SETTLEMENT_SETTLED && settlement.Status != payment.SETTLEMENT_EARLY_SETTLED {
+		return
+	}
+
+	if err := r.Repo.SettleTransaction(record.SourceID, settlement.Status); err != nil {
+		record.Kind = constants.DISCREPANCY_APPLY_FAILED
+		record.ProviderStatus = settlement.Status
+		record.Note = err.Error()
+		run.Failed++
+		run.Discrepancies = append(run.Discrepancies, record)
+	}
+}
+
+// apply hands the callback the provider never delivered to the webhook handler of the source.
+func (r Reconciler) apply(run *models.ReconciliationRun, source string, record models.ReconciliationDiscrepancy, callback interface{}) {
+	body, err := json.Marshal(callback)
+	if err == nil {
+		handler, ok := r.Handlers[source]
+		if !ok {
+			err = fmt.Errorf("no handler for %s webhooks", source)
+		} else {
+			err = handler(body)
+		}
+	}
+
+	if err != nil {
+		record.Kind = constants.DISCREPANCY_APPLY_FAILED
+		record.Note = err.Error()
+		run.Failed++
+	} else {
+		record.Kind = constants.DISCREPANCY_MISSED_CALLBACK
+		record.Note = fmt.Sprintf("%s at the provider, applied", record.ProviderStatus)
+		run.Applied++
+	}
+
+	run.Discrepancies = append(run.Discrepancies, record)
+}
+
+func lookupFailed(run *models.ReconciliationRun, record models.ReconciliationDiscrepancy, err error) {
+	record.Kind = constants.DISCREPANCY_LOOKUP_FAILED
+	record.Note = err.Error()
+	run.Failed++
+	run.Discrepancies = append(run.Discrepancies, record)
+}
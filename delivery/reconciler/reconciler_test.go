@@ -0,0 +1,289 @@
+package reconciler_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/delivery/reconciler"
+	"github.com/furqonzt99/snackbox/delivery/webhook"
+	"github.com/furqonzt99/snackbox/models"
+	"github.com/furqonzt99/snackbox/payment"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestReconcile(t *testing.T) {
+	constants.RECONCILIATION_INTERVAL_MINUTES = 30
+	constants.RECONCILIATION_GRACE_MINUTES = 15
+
+	now := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+	paidAt := now.Add(-time.Hour)
+
+	newRepo := func() *mockReconciliation {
+		return &mockReconciliation{
+			invoices: []models.TransactionInvoice{
+				{Model: gorm.Model{ID: 1}, TransactionID: 1, ExternalID: "INV-1", PaymentInvoiceID: "inv-1", Amount: 50000, Status: payment.INVOICE_PENDING},
+				{Model: gorm.Model{ID: 2}, TransactionID: 2, ExternalID: "INV-2-2", PaymentInvoiceID: "inv-2", Amount: 50000, Status: payment.INVOICE_PENDING},
+			},
+			topups: []models.WalletTopup{
+				{Model: gorm.Model{ID: 1}, ExternalID: "TOPUP-1", PaymentInvoiceID: "inv-3", Amount: 100000, Status: constants.PENDING_STATUS},
+			},
+			cashouts: []models.Cashout{
+				{Model: gorm.Model{ID: 1}, ExternalID: "CASHOUT-1", Amount: 200000, Status: constants.CASHOUT_PENDING},
+			},
+		}
+	}
+
+	newProvider := func() *mockProvider {
+		return &mockProvider{
+			invoices: map[string]payment.Invoice{
+				"inv-1": {Status: payment.INVOICE_PAID, Amount: 50000, PaidAmount: 50000, ReceivedAmount: 47780, PaymentMethod: "BANK_TRANSFER", PaidAt: paidAt},
+				"inv-2": {Status: payment.INVOICE_PENDING, Amount: 50000},
+				"inv-3": {Status: payment.INVOICE_EXPIRED, Amount: 100000},
+			},
+			disbursements: map[string]payment.Disbursement{
+				"CASHOUT-1": {Status: payment.DISBURSEMENT_FAILED, Amount: 200000},
+			},
+		}
+	}
+
+	t.Run("apply missed callbacks", func(t *testing.T) {
+		repo := newRepo()
+		handlers := newHandlers()
+
+		run, err := reconciler.NewReconciler(repo, &mockLocker{}, newProvider(), handlers.handlers).Reconcile(now)
+		assert.Nil(t, err)
+		assert.Equal(t, now.Add(-15*time.Minute), repo.before)
+		assert.Equal(t, 4, run.Checked)
+		assert.Equal(t, 3, run.Applied)
+		assert.Equal(t, 0, run.Failed)
+		assert.Len(t, run.Discrepancies, 3)
+		assert.Equal(t, constants.DISCREPANCY_MISSED_CALLBACK, run.Discrepancies[0].Kind)
+		assert.Equal(t, "INV-1", run.Discrepancies[0].Reference)
+
+		assert.Equal(t, "INV-1", handlers.bodies[constants.TRANSACTION_WEBHOOK][0]["external_id"])
+		assert.Equal(t, payment.INVOICE_PAID, handlers.bodies[constants.TRANSACTION_WEBHOOK][0]["status"])
+		assert.Equal(t, paidAt.Format(time.RFC3339), handlers.bodies[constants.TRANSACTION_WEBHOOK][0]["paid_at"])
+		assert.Equal(t, float64(50000), handlers.bodies[constants.TRANSACTION_WEBHOOK][0]["paid_amount"])
+		assert.Equal(t, float64(47780), handlers.bodies[constants.TRANSACTION_WEBHOOK][0]["adjusted_received_amount"])
+		assert.Equal(t, payment.INVOICE_EXPIRED, handlers.bodies[constants.TOPUP_WEBHOOK][0]["status"])
+		assert.Equal(t, payment.DISBURSEMENT_FAILED, handlers.bodies[constants.CASHOUT_WEBHOOK][0]["status"])
+		assert.Equal(t, float64(200000), handlers.bodies[constants.CASHOUT_WEBHOOK][0]["amount"])
+		assert.Equal(t, 1, repo.saved)
+	})
+
+	t.Run("amount mismatch", func(t *testing.T) {
+		provider := newProvider()
+		provider.invoices["inv-1"] = payment.Invoice{Status: payment.INVOICE_PAID, Amount: 50000, PaidAmount: 45000}
+
+		run, err := reconciler.NewReconciler(newRepo(), &mockLocker{}, provider, newHandlers().handlers).Reconcile(now)
+		assert.Nil(t, err)
+		assert.Equal(t, constants.DISCREPANCY_AMOUNT_MISMATCH, run.Discrepancies[0].Kind)
+		assert.Equal(t, models.Money(45000), run.Discrepancies[0].ProviderAmount)
+		assert.Equal(t, constants.DISCREPANCY_MISSED_CALLBACK, run.Discrepancies[1].Kind)
+	})
+
+	t.Run("lookup and apply failures", func(t *testing.T) {
+		provider := newProvider()
+		delete(provider.invoices, "inv-1")
+
+		handlers := newHandlers()
+		handlers.handlers[constants.CASHOUT_WEBHOOK] = func(body []byte) error {
+			return errors.New("FAILED")
+		}
+
+		run, err := reconciler.NewReconciler(newRepo(), &mockLocker{}, provider, handlers.handlers).Reconcile(now)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, run.Applied)
+		assert.Equal(t, 2, run.Failed)
+		assert.Equal(t, constants.DISCREPANCY_LOOKUP_FAILED, run.Discrepancies[0].Kind)
+		assert.Equal(t, constants.DISCREPANCY_APPLY_FAILED, run.Discrepancies[2].Kind)
+		assert.Equal(t, "FAILED", run.Discrepancies[2].Note)
+	})
+
+	t.Run("poll settlements", func(t *testing.T) {
+		repo := newRepo()
+		repo.unsettled = []models.TransactionInvoice{
+			{TransactionID: 3, ExternalID: "INV-3", Amount: 30000, Status: payment.INVOICE_PAID},
+			{TransactionID: 4, ExternalID: "INV-4", Amount: 40000, Status: payment.INVOICE_PAID},
+			{TransactionID: 5, ExternalID: "INV-5", Amount: 50000, Status: payment.INVOICE_PAID},
+		}
+
+		provider := newProvider()
+		provider.settlements = map[string]payment.Settlement{
+			"INV-3": {Status: payment.SETTLEMENT_SETTLED},
+			"INV-4": {Status: payment.SETTLEMENT_PENDING},
+		}
+
+		run, err := reconciler.NewReconciler(repo, &mockLocker{}, provider, newHandlers().handlers).Reconcile(now)
+		assert.Nil(t, err)
+		assert.Equal(t, 7, run.Checked)
+		assert.Equal(t, 1, run.Failed)
+		assert.Equal(t, map[uint]string{3: constants.SETTLEMENT_SETTLED}, repo.settled)
+
+		failed := run.Discrepancies[len(run.Discrepancies)-1]
+		assert.Equal(t, constants.DISCREPANCY_LOOKUP_FAILED, failed.Kind)
+		assert.Equal(t, "INV-5", failed.Reference)
+	})
+
+	t.Run("reconcile error", func(t *testing.T) {
+		repo := newRepo()
+		repo.err = errors.New("FAILED")
+
+		_, err := reconciler.NewReconciler(repo, &mockLocker{}, newProvider(), newHandlers().handlers).Reconcile(now)
+		assert.NotNil(t, err)
+		assert.Equal(t, 0, repo.saved)
+	})
+
+	t.Run("run with lock", func(t *testing.T) {
+		repo := newRepo()
+		locker := &mockLocker{locked: true}
+
+		reconciler.NewReconciler(repo, locker, newProvider(), newHandlers().handlers).Run(now)
+
+		assert.Equal(t, time.Hour, locker.ttl)
+		assert.Equal(t, 1, repo.saved)
+	})
+
+	t.Run("run without lock", func(t *testing.T) {
+		repo := newRepo()
+
+		reconciler.NewReconciler(repo, &mockLocker{locked: false}, newProvider(), newHandlers().handlers).Run(now)
+
+		assert.Equal(t, 0, repo.saved)
+	})
+}
+
+type recordedHandlers struct {
+	handlers map[string]webhook.Handler
+	bodies   map[string][]map[string]interface{}
+}
+
+func newHandlers() *recordedHandlers {
+	rh := &recordedHandlers{handlers: map[string]webhook.Handler{}, bodies: map[string][]map[string]interface{}{}}
+
+	for _, source := range []string{constants.TRANSACTION_WEBHOOK, constants.TOPUP_WEBHOOK, constants.CASHOUT_WEBHOOK} {
+		source := source
+		rh.handlers[source] = func(body []byte) error {
+			decoded := map[string]interface{}{}
+			json.Unmarshal(body, &decoded)
+			rh.bodies[source] = append(rh.bodies[source], decoded)
+			return nil
+		}
+	}
+
+	return rh
+}
+
+type mockLocker struct {
+	locked bool
+	ttl    time.Duration
+}
+
+func (m *mockLocker) AcquireLock(name, owner string, ttl time.Duration) (bool, error) {
+	m.ttl = ttl
+	return m.locked, nil
+}
+
+type mockReconciliation struct {
+	invoices  []models.TransactionInvoice
+	topups    []models.WalletTopup
+	cashouts  []models.Cashout
+	unsettled []models.TransactionInvoice
+	settled   map[uint]string
+	err       error
+	before    time.Time
+	saved     int
+}
+
+func (m *mockReconciliation) GetOpenInvoices(before time.Time) ([]models.TransactionInvoice, error) {
+	m.before = before
+	return m.invoices, m.err
+}
+
+func (m *mockReconciliation) GetOpenTopups(before time.Time) ([]models.WalletTopup, error) {
+	return m.topups, m.err
+}
+
+func (m *mockReconciliation) GetOpenCashouts(before time.Time) ([]models.Cashout, error) {
+	return m.cashouts, m.err
+}
+
+func (m *mockReconciliation) GetUnsettledInvoices(before time.Time) ([]models.TransactionInvoice, error) {
+	return m.unsettled, m.err
+}
+
+func (m *mockReconciliation) SettleTransaction(trxID uint, status string) error {
+	if m.settled == nil {
+		m.settled = map[uint]string{}
+	}
+	m.settled[trxID] = status
+	return nil
+}
+
+func (m *mockReconciliation) SaveRun(run models.ReconciliationRun) (models.ReconciliationRun, error) {
+	m.saved++
+	run.ID = uint(m.saved)
+	return run, nil
+}
+
+func (m *mockReconciliation) GetRuns() ([]models.ReconciliationRun, error) {
+	return nil, nil
+}
+
+func (m *mockReconciliation) GetRun(runID int) (models.ReconciliationRun, error) {
+	return models.ReconciliationRun{}, nil
+}
+
+type mockProvider struct {
+	invoices      map[string]payment.Invoice
+	disbursements map[string]payment.Disbursement
+	settlements   map[string]payment.Settlement
+}
+
+func (m *mockProvider) CreateInvoice(params payment.CreateInvoiceParams) (payment.Invoice, error) {
+	return payment.Invoice{}, nil
+}
+
+func (m *mockProvider) ExpireInvoice(invoiceID string) error {
+	return nil
+}
+
+func (m *mockProvider) GetInvoice(invoiceID string) (payment.Invoice, error) {
+	invoice, ok := m.invoices[invoiceID]
+	if !ok {
+		return invoice, payment.ErrSandboxNotFound
+	}
+	return invoice, nil
+}
+
+func (m *mockProvider) CreateDisbursement(params payment.CreateDisbursementParams) (payment.Disbursement, error) {
+	return payment.Disbursement{}, nil
+}
+
+func (m *mockProvider) GetDisbursement(externalID string) (payment.Disbursement, error) {
+	disbursement, ok := m.disbursements[externalID]
+	if !ok {
+		return disbursement, payment.ErrSandboxNotFound
+	}
+	return disbursement, nil
+}
+
+func (m *mockProvider) GetSettlement(externalID string) (payment.Settlement, error) {
+	settlement, ok := m.settlements[externalID]
+	if !ok {
+		return settlement, payment.ErrSandboxNotFound
+	}
+	return settlement, nil
+}
+
+func (m *mockProvider) GetAvailableBanks() ([]payment.Bank, error) {
+	return nil, nil
+}
+
+func (m *mockProvider) InquireBankAccount(bankCode, accountNumber string) (payment.BankAccount, error) {
+	return payment.BankAccount{}, nil
+}
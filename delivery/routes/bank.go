@@ -9,4 +9,10 @@ import (
 
 func RegisterBankPath(e *echo.Echo, BankController *bank.BankController)  {
 	e.GET("/banks",BankController.AvailableBanks, middleware.JWT([]byte(constants.JWT_SECRET_KEY)))
+	e.POST("/bank-accounts", BankController.CreateAccount, middleware.JWT([]byte(constants.JWT_SECRET_KEY)))
+	e.GET("/bank-accounts", BankController.GetAccounts, middleware.JWT([]byte(constants.JWT_SECRET_KEY)))
+	e.GET("/bank-accounts/:id", BankController.GetAccount, middleware.JWT([]byte(constants.JWT_SECRET_KEY)))
+	e.PUT("/bank-accounts/:id", BankController.UpdateAccount, middleware.JWT([]byte(constants.JWT_SECRET_KEY)))
+	e.DELETE("/bank-accounts/:id", BankController.DeleteAccount, middleware.JWT([]byte(constants.JWT_SECRET_KEY)))
+	e.PUT("/bank-accounts/:id/default", BankController.SetDefault, middleware.JWT([]byte(constants.JWT_SECRET_KEY)))
 }
\ No newline at end of file
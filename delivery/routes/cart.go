@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/delivery/controllers/cart"
+	"github.com/furqonzt99/snackbox/delivery/middlewares"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+func RegisterCartPath(e *echo.Echo, CartController *cart.CartController) {
+
+	e.GET("/carts", CartController.GetCart, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckUserRole)
+	e.POST("/carts", CartController.AddItem, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckUserRole)
+	e.PUT("/carts/:id", CartController.UpdateItem, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckUserRole)
+	e.DELETE("/carts/:id", CartController.RemoveItem, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckUserRole)
+	e.POST("/carts/checkout", CartController.Checkout, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckUserRole)
+}
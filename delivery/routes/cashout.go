@@ -12,5 +12,7 @@ func RegisterCashoutPath(e *echo.Echo, CashoutController *cashout.CashoutControl
 
 	e.POST("/cashouts", CashoutController.Cashout, middleware.JWT([]byte(constants.JWT_SECRET_KEY)))
 	e.GET("/cashouts", CashoutController.History, middleware.JWT([]byte(constants.JWT_SECRET_KEY)))
-	e.POST("/cashouts/callback", CashoutController.Callback, middlewares.CheckXHeaderToken)
+	e.GET("/cashouts/requests", CashoutController.Requests, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckAdminRole)
+	e.PUT("/cashouts/:id/approve", CashoutController.Approve, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckAdminRole)
+	e.PUT("/cashouts/:id/reject", CashoutController.Reject, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckAdminRole)
 }
\ No newline at end of file
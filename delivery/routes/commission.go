@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/delivery/controllers/commission"
+	"github.com/furqonzt99/snackbox/delivery/middlewares"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+func RegisterCommissionPath(e *echo.Echo, CommissionController *commission.CommissionController) {
+
+	e.POST("/commissions", CommissionController.Create, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckAdminRole)
+	e.GET("/commissions", CommissionController.GetAll, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckAdminRole)
+	e.PUT("/commissions/:id", CommissionController.Update, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckAdminRole)
+	e.DELETE("/commissions/:id", CommissionController.Delete, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckAdminRole)
+	e.GET("/commissions/fees", CommissionController.GetFees, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckAdminRole)
+	e.GET("/commissions/report", CommissionController.Report, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckAdminRole)
+	e.GET("/partners/fees", CommissionController.GetPartnerFees, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckPartnerRole)
+}
@@ -18,4 +18,9 @@ func RegisterPartnerPath(e *echo.Echo, partnerCtrl *partner.PartnerController) {
 	e.GET("/partners/:id/ratings", partnerCtrl.GetPartnerRating(), middleware.JWT([]byte(constants.JWT_SECRET_KEY)))
 	e.POST("/partners/submission/upload", partnerCtrl.Upload, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckUserRole)
 	e.GET("/partners/reports", partnerCtrl.Report(), middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckPartnerRole)
+	e.PUT("/partners/capacity", partnerCtrl.UpdateCapacity(), middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckPartnerRole)
+	e.PUT("/partners/rules", partnerCtrl.UpdateOrderRules(), middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckPartnerRole)
+	e.PUT("/partners/calendar", partnerCtrl.SetCalendar(), middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckPartnerRole)
+	e.DELETE("/partners/calendar/:date", partnerCtrl.DeleteCalendar(), middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckPartnerRole)
+	e.GET("/partners/:id/availability", partnerCtrl.GetAvailability(), middleware.JWT([]byte(constants.JWT_SECRET_KEY)))
 }
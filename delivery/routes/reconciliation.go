@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/delivery/controllers/reconciliation"
+	"github.com/furqonzt99/snackbox/delivery/middlewares"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+func RegisterReconciliationPath(e *echo.Echo, ReconciliationController *reconciliation.ReconciliationController) {
+
+	e.GET("/reconciliations", ReconciliationController.GetAll, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckAdminRole)
+	e.GET("/reconciliations/:id", ReconciliationController.Get, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckAdminRole)
+	e.POST("/reconciliations", ReconciliationController.Run, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckAdminRole)
+}
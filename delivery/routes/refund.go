@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/delivery/controllers/refund"
+	"github.com/furqonzt99/snackbox/delivery/middlewares"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+func RegisterRefundPath(e *echo.Echo, RefundController *refund.RefundController) {
+
+	e.POST("/transactions/:id/refunds", RefundController.Create, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckUserRole)
+	e.GET("/refunds", RefundController.GetAll, middleware.JWT([]byte(constants.JWT_SECRET_KEY)))
+	e.GET("/refunds/:id", RefundController.Get, middleware.JWT([]byte(constants.JWT_SECRET_KEY)))
+	e.POST("/refunds/:id/photo", RefundController.UploadPhoto, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckUserRole)
+	e.PUT("/refunds/:id/accept", RefundController.Accept, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckPartnerRole)
+	e.PUT("/refunds/:id/counter", RefundController.Counter, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckPartnerRole)
+	e.PUT("/refunds/:id/accept-counter", RefundController.AcceptCounter, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckUserRole)
+	e.PUT("/refunds/:id/escalate", RefundController.Escalate, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckUserRole)
+	e.PUT("/refunds/:id/resolve", RefundController.Resolve, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckAdminRole)
+}
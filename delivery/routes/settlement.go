@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/delivery/controllers/settlement"
+	"github.com/furqonzt99/snackbox/delivery/middlewares"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+func RegisterSettlementPath(e *echo.Echo, SettlementController *settlement.SettlementController) {
+
+	e.GET("/settlements/report", SettlementController.Report, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckAdminRole)
+}
@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/delivery/controllers/shipping"
+	"github.com/furqonzt99/snackbox/delivery/middlewares"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+func RegisterShippingPath(e *echo.Echo, ShippingController *shipping.ShippingController) {
+
+	e.GET("/shipping/tariffs", ShippingController.GetTariff, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckPartnerRole)
+	e.PUT("/shipping/tariffs", ShippingController.SaveTariff, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckPartnerRole)
+	e.DELETE("/shipping/tariffs", ShippingController.DeleteTariff, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckPartnerRole)
+	e.GET("/shipping/tariffs/default", ShippingController.GetDefaultTariff, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckAdminRole)
+	e.PUT("/shipping/tariffs/default", ShippingController.SaveDefaultTariff, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckAdminRole)
+}
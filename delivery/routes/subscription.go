@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/delivery/controllers/subscription"
+	"github.com/furqonzt99/snackbox/delivery/middlewares"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+func RegisterSubscriptionPath(e *echo.Echo, SubscriptionController *subscription.SubscriptionController) {
+
+	e.POST("/subscriptions", SubscriptionController.Create, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckUserRole)
+	e.GET("/subscriptions", SubscriptionController.GetAll, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckUserRole)
+	e.GET("/subscriptions/:id", SubscriptionController.Get, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckUserRole)
+	e.PUT("/subscriptions/:id/pause", SubscriptionController.Pause, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckUserRole)
+	e.PUT("/subscriptions/:id/resume", SubscriptionController.Resume, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckUserRole)
+	e.PUT("/subscriptions/:id/skip", SubscriptionController.SkipNext, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckUserRole)
+	e.PUT("/subscriptions/:id/cancel", SubscriptionController.Cancel, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckUserRole)
+}
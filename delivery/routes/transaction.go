@@ -11,12 +11,18 @@ import (
 func RegisterTransactionPath(e *echo.Echo, TransactionController *transaction.TransactionController) {
 
 	e.POST("/transactions/order", TransactionController.Order, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckUserRole)
-	e.POST("/transactions/callback", TransactionController.Callback, middlewares.CheckXHeaderToken)
 	e.PUT("/transactions/:id/accept", TransactionController.Accept, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckPartnerRole)
 	e.PUT("/transactions/:id/reject", TransactionController.Reject, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckPartnerRole)
+	e.PUT("/transactions/:id/prepare", TransactionController.Prepare, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckPartnerRole)
+	e.PUT("/transactions/:id/ready", TransactionController.Ready, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckPartnerRole)
+	e.PUT("/transactions/:id/deliver", TransactionController.Deliver, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckPartnerRole)
 	e.PUT("/transactions/:id/send", TransactionController.Send, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckPartnerRole)
 	e.PUT("/transactions/:id/confirm", TransactionController.Confirm, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckUserRole)
+	e.PUT("/transactions/:id/cancel", TransactionController.Cancel, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckUserRole)
+	e.POST("/transactions/:id/payment", TransactionController.RenewPayment, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckUserRole)
 	e.GET("/transactions", TransactionController.GetAll, middleware.JWT([]byte(constants.JWT_SECRET_KEY)))
 	e.GET("/transactions/:id", TransactionController.GetOne, middleware.JWT([]byte(constants.JWT_SECRET_KEY)))
+	e.GET("/transactions/:id/receipt", TransactionController.Receipt, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckUserRole)
+	e.GET("/transactions/:id/timeline", TransactionController.Timeline, middleware.JWT([]byte(constants.JWT_SECRET_KEY)))
 	e.POST("/transactions/shipping", TransactionController.Shipping, middleware.JWT([]byte(constants.JWT_SECRET_KEY)))
 }
\ No newline at end of file
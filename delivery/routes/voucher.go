@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/delivery/controllers/voucher"
+	"github.com/furqonzt99/snackbox/delivery/middlewares"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+func RegisterVoucherPath(e *echo.Echo, VoucherController *voucher.VoucherController) {
+
+	e.POST("/vouchers", VoucherController.Create, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckAdminRole)
+	e.GET("/vouchers", VoucherController.GetAll, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckAdminRole)
+	e.GET("/vouchers/:id", VoucherController.Get, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckAdminRole)
+	e.PUT("/vouchers/:id", VoucherController.Update, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckAdminRole)
+	e.DELETE("/vouchers/:id", VoucherController.Delete, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckAdminRole)
+}
@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/delivery/controllers/wallet"
+	"github.com/furqonzt99/snackbox/delivery/middlewares"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+func RegisterWalletPath(e *echo.Echo, WalletController *wallet.WalletController) {
+
+	e.GET("/wallet/ledger", WalletController.Ledger, middleware.JWT([]byte(constants.JWT_SECRET_KEY)))
+	e.POST("/wallet/topups", WalletController.Topup, middleware.JWT([]byte(constants.JWT_SECRET_KEY)))
+	e.GET("/wallet/topups", WalletController.GetTopups, middleware.JWT([]byte(constants.JWT_SECRET_KEY)))
+	e.POST("/wallet/adjustments", WalletController.Adjust, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckAdminRole)
+	e.GET("/wallet/reconcile", WalletController.Reconcile, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckAdminRole)
+}
@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/delivery/controllers/webhook"
+	"github.com/furqonzt99/snackbox/delivery/middlewares"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+func RegisterWebhookPath(e *echo.Echo, WebhookController *webhook.WebhookController) {
+
+	e.POST("/transactions/callback", WebhookController.Receive(constants.TRANSACTION_WEBHOOK), middlewares.CheckXHeaderToken)
+	e.POST("/cashouts/callback", WebhookController.Receive(constants.CASHOUT_WEBHOOK), middlewares.CheckXHeaderToken)
+	e.GET("/webhooks", WebhookController.GetAll, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckAdminRole)
+	e.GET("/webhooks/:id", WebhookController.Get, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckAdminRole)
+	e.POST("/webhooks/:id/replay", WebhookController.Replay, middleware.JWT([]byte(constants.JWT_SECRET_KEY)), middlewares.CheckAdminRole)
+}
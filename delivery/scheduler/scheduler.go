@@ -0,0 +1,184 @@
+package scheduler
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/helper"
+	"github.com/furqonzt99/snackbox/models"
+	"github.com/furqonzt99/snackbox/repositories/scheduler"
+	"github.com/furqonzt99/snackbox/repositories/subscription"
+	"github.com/labstack/gommon/log"
+)
+
+const orderTimeoutLock = "order_timeouts"
+
+// OrderPlacer is the part of the transaction repository that places subscription orders.
+type OrderPlacer interface {
+	Order(transaction models.Transaction, email string, items []models.DetailTransaction) (models.Transaction, error)
+	GetDistance(partnerID int, latitude, longtitude float64) (float64, error)
+}
+
+type Scheduler struct {
+	Repo          scheduler.SchedulerInterface
+	Subscriptions subscription.SubscriptionInterface
+	Orders        OrderPlacer
+	Owner         string
+}
+
+func NewScheduler(repo scheduler.SchedulerInterface, subscriptions subscription.SubscriptionInterface, orders OrderPlacer) *Scheduler {
+	hostname, _ := os.Hostname()
+
+	return &Scheduler{Repo: repo, Subscriptions: subscriptions, Orders: orders, Owner: fmt.Sprintf("%s-%d", hostname, os.Getpid())}
+}
+
+func (s Scheduler) interval() time.Duration {
+	return time.Duration(constants.SCHEDULER_INTERVAL_MINUTES) * time.Minute
+}
+
+// Start runs the order timeouts once right away and then on every interval, in the background.
+func (s Scheduler) Start() {
+	go func() {
+		ticker := time.NewTicker(s.interval())
+		defer ticker.Stop()
+
+		for {
+			s.Run(time.Now())
+			<-ticker.C
+		}
+	}()
+}
+
+// Run applies every order timeout as of now. Replicas that do not hold the lock skip the round.
+func (s Scheduler) Run(now time.Time) {
+	// the lease outlives one interval so the owner keeps it as long as it is alive
+	locked, err := s.Repo.AcquireLock(orderTimeoutLock, s.Owner, 2*s.interval())
+	if err != nil {
+		log.Warnf("scheduler: acquire lock: %v", err)
+		return
+	}
+	if !locked {
+		return
+	}
+
+	expired, err := s.Repo.ExpirePending(now.Add(-time.Duration(constants.PAYMENT_TIMEOUT_HOURS) * time.Hour))
+	if err != nil {
+		log.Warnf("scheduler: expire pending orders: %v", err)
+	}
+
+	rejected, err := s.Repo.RejectUnaccepted(now.Add(-time.Duration(constants.ACCEPT_TIMEOUT_HOURS) * time.Hour))
+	if err != nil {
+		log.Warnf("scheduler: reject unaccepted orders: %v", err)
+	}
+
+	confirmed, err := s.Repo.ConfirmDelivered(now.AddDate(0, 0, -constants.AUTO_CONFIRM_DAYS))
+	if err != nil {
+		log.Warnf("scheduler: confirm delivered orders: %v", err)
+	}
+
+	released, err := s.Repo.ReleaseEarnings(now)
+	if err != nil {
+		log.Warnf("scheduler: release partner earnings: %v", err)
+	}
+
+	placed, err := s.PlaceSubscriptionOrders(now)
+	if err != nil {
+		log.Warnf("scheduler: place subscription orders: %v", err)
+	}
+
+	if expired+rejected+confirmed+released+placed > 0 {
+		log.Infof("scheduler: %d expired, %d rejected, %d confirmed, %d earnings released, %d subscription orders placed", expired, rejected, confirmed, released, placed)
+	}
+}
+
+// PlaceSubscriptionOrders places the next order of every active subscription once its event time
+// is within the partner's lead time plus SUBSCRIPTION_ADVANCE_HOURS. A delivery that cannot be
+// ordered, because the partner is full on that date for example, is skipped with a note.
+func (s Scheduler) PlaceSubscriptionOrders(now time.Time) (int, error) {
+	subscriptions, err := s.Subscriptions.GetActive()
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+
+	for _, subscription := range subscriptions {
+		dateTime, err := helper.ParseEventTime(subscription.NextDate, subscription.Time)
+		if err != nil {
+			log.Warnf("scheduler: subscription %d: %v", subscription.ID, err)
+			continue
+		}
+
+		ahead := time.Duration(subscription.Partner.LeadTimeHours+constants.SUBSCRIPTION_ADVANCE_HOURS) * time.Hour
+		if dateTime.After(now.Add(ahead)) {
+			continue
+		}
+
+		// the order may have been placed by a round that failed to advance the subscription
+		ordered, err := s.Subscriptions.Ordered(int(subscription.ID), dateTime)
+		if err != nil {
+			log.Warnf("scheduler: subscription %d: %v", subscription.ID, err)
+			continue
+		}
+
+		var note string
+
+		if !ordered {
+			if err := s.placeSubscriptionOrder(subscription, dateTime); err != nil {
+				log.Warnf("scheduler: subscription %d on %s: %v", subscription.ID, subscription.NextDate, err)
+				note = fmt.Sprintf("the delivery on %s was skipped: %v", subscription.NextDate, err)
+			} else {
+				count++
+			}
+		}
+
+		if err := s.Subscriptions.Advance(int(subscription.ID), note); err != nil {
+			log.Warnf("scheduler: advance subscription %d: %v", subscription.ID, err)
+		}
+	}
+
+	return count, nil
+}
+
+// placeSubscriptionOrder orders like the customer would, paying with the SboxPay balance
+// first and issuing an invoice for whatever it does not cover.
+func (s Scheduler) placeSubscriptionOrder(subscription models.Subscription, dateTime time.Time) error {
+	distance, err := s.Orders.GetDistance(int(subscription.PartnerID), subscription.Latitude, subscription.Longtitude)
+	if err != nil {
+		return err
+	}
+
+	items := []models.DetailTransaction{}
+	var quantity int
+	for _, item := range subscription.Items {
+		items = append(items, models.DetailTransaction{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+			Notes:     item.Notes,
+		})
+		quantity += item.Quantity
+	}
+
+	if err := helper.ValidateOrderRules(subscription.Partner, dateTime, quantity, distance); err != nil {
+		return err
+	}
+
+	transaction := models.Transaction{
+		UserID:         subscription.UserID,
+		PartnerID:      subscription.PartnerID,
+		Buffet:         subscription.Buffet,
+		Quantity:       quantity,
+		DateTime:       dateTime,
+		Latitude:       subscription.Latitude,
+		Longtitude:     subscription.Longtitude,
+		Distance:       distance,
+		InvoiceID:      helper.NewInvoiceID(),
+		SubscriptionID: subscription.ID,
+	}
+
+	_, err = s.Orders.Order(transaction, subscription.User.Email, items)
+
+	return err
+}
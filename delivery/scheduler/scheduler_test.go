@@ -0,0 +1,224 @@
+package scheduler_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/delivery/scheduler"
+	"github.com/furqonzt99/snackbox/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestRun(t *testing.T) {
+	constants.PAYMENT_TIMEOUT_HOURS = 24
+	constants.ACCEPT_TIMEOUT_HOURS = 12
+	constants.AUTO_CONFIRM_DAYS = 3
+	constants.SCHEDULER_INTERVAL_MINUTES = 5
+
+	now := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	t.Run("run with lock", func(t *testing.T) {
+		repo := &mockScheduler{locked: true}
+
+		scheduler.NewScheduler(repo, &mockSubscription{}, &mockOrders{}).Run(now)
+
+		assert.Equal(t, 10*time.Minute, repo.ttl)
+		assert.Equal(t, now.Add(-24*time.Hour), repo.pendingBefore)
+		assert.Equal(t, now.Add(-12*time.Hour), repo.paidBefore)
+		assert.Equal(t, now.AddDate(0, 0, -3), repo.sendBefore)
+		assert.Equal(t, now, repo.releasedAt)
+	})
+
+	t.Run("run without lock", func(t *testing.T) {
+		repo := &mockScheduler{locked: false}
+
+		scheduler.NewScheduler(repo, &mockSubscription{}, &mockOrders{}).Run(now)
+
+		assert.True(t, repo.pendingBefore.IsZero())
+		assert.True(t, repo.paidBefore.IsZero())
+		assert.True(t, repo.sendBefore.IsZero())
+		assert.True(t, repo.releasedAt.IsZero())
+	})
+
+	t.Run("run with lock error", func(t *testing.T) {
+		repo := &mockScheduler{locked: true, lockErr: errors.New("FAILED")}
+
+		scheduler.NewScheduler(repo, &mockSubscription{}, &mockOrders{}).Run(now)
+
+		assert.True(t, repo.pendingBefore.IsZero())
+	})
+}
+
+func TestPlaceSubscriptionOrders(t *testing.T) {
+	constants.SUBSCRIPTION_ADVANCE_HOURS = 24
+
+	now := time.Now()
+	partner := models.Partner{LeadTimeHours: 24, MinQuantity: 10}
+
+	subscriptionAt := func(id uint, dateTime time.Time, quantity int) models.Subscription {
+		return models.Subscription{
+			Model:     gorm.Model{ID: id},
+			PartnerID: 1,
+			Partner:   partner,
+			User:      models.User{Email: "test@gmail.com"},
+			NextDate:  dateTime.UTC().Format("2006-01-02"),
+			Time:      dateTime.UTC().Format("15:04:05"),
+			Items:     []models.SubscriptionItem{{ProductID: 1, Quantity: quantity}},
+		}
+	}
+
+	t.Run("place due subscription order", func(t *testing.T) {
+		subscriptions := &mockSubscription{subscriptions: []models.Subscription{subscriptionAt(1, now.Add(30*time.Hour), 10)}}
+		orders := &mockOrders{}
+
+		placed, err := scheduler.NewScheduler(&mockScheduler{}, subscriptions, orders).PlaceSubscriptionOrders(now)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, placed)
+		assert.Equal(t, uint(1), orders.placed[0].SubscriptionID)
+		assert.Equal(t, 10, orders.placed[0].Quantity)
+		assert.Equal(t, map[int]string{1: ""}, subscriptions.advanced)
+	})
+
+	t.Run("wait for subscription too far ahead", func(t *testing.T) {
+		subscriptions := &mockSubscription{subscriptions: []models.Subscription{subscriptionAt(1, now.Add(72*time.Hour), 10)}}
+		orders := &mockOrders{}
+
+		placed, _ := scheduler.NewScheduler(&mockScheduler{}, subscriptions, orders).PlaceSubscriptionOrders(now)
+		assert.Equal(t, 0, placed)
+		assert.Equal(t, 0, len(orders.placed))
+		assert.Equal(t, 0, len(subscriptions.advanced))
+	})
+
+	t.Run("advance subscription already ordered", func(t *testing.T) {
+		subscriptions := &mockSubscription{subscriptions: []models.Subscription{subscriptionAt(1, now.Add(30*time.Hour), 10)}, ordered: true}
+		orders := &mockOrders{}
+
+		placed, _ := scheduler.NewScheduler(&mockScheduler{}, subscriptions, orders).PlaceSubscriptionOrders(now)
+		assert.Equal(t, 0, placed)
+		assert.Equal(t, 0, len(orders.placed))
+		assert.Equal(t, map[int]string{1: ""}, subscriptions.advanced)
+	})
+
+	t.Run("skip subscription delivery breaking the order rules", func(t *testing.T) {
+		subscription := subscriptionAt(1, now.Add(30*time.Hour), 5)
+		subscriptions := &mockSubscription{subscriptions: []models.Subscription{subscription}}
+		orders := &mockOrders{}
+
+		placed, _ := scheduler.NewScheduler(&mockScheduler{}, subscriptions, orders).PlaceSubscriptionOrders(now)
+		assert.Equal(t, 0, placed)
+		assert.Equal(t, 0, len(orders.placed))
+		assert.Equal(t, "the delivery on "+subscription.NextDate+" was skipped: the minimum order for this partner is 10 boxes", subscriptions.advanced[1])
+	})
+}
+
+// ======================
+// MOCK SCHEDULER REPOSITORY
+// ======================
+type mockScheduler struct {
+	locked  bool
+	lockErr error
+
+	ttl           time.Duration
+	pendingBefore time.Time
+	paidBefore    time.Time
+	sendBefore    time.Time
+	releasedAt    time.Time
+}
+
+func (m *mockScheduler) AcquireLock(name, owner string, ttl time.Duration) (bool, error) {
+	m.ttl = ttl
+	return m.locked, m.lockErr
+}
+
+func (m *mockScheduler) ExpirePending(before time.Time) (int, error) {
+	m.pendingBefore = before
+	return 1, nil
+}
+
+func (m *mockScheduler) RejectUnaccepted(before time.Time) (int, error) {
+	m.paidBefore = before
+	return 0, nil
+}
+
+func (m *mockScheduler) ConfirmDelivered(before time.Time) (int, error) {
+	m.sendBefore = before
+	return 0, errors.New("FAILED")
+}
+
+func (m *mockScheduler) ReleaseEarnings(now time.Time) (int, error) {
+	m.releasedAt = now
+	return 2, nil
+}
+
+// ======================
+// MOCK SUBSCRIPTION REPOSITORY
+// ======================
+type mockSubscription struct {
+	subscriptions []models.Subscription
+	ordered       bool
+
+	advanced map[int]string
+}
+
+func (m *mockSubscription) Create(subscription models.Subscription) (models.Subscription, error) {
+	return subscription, nil
+}
+
+func (m *mockSubscription) GetAll(userID int) ([]models.Subscription, error) {
+	return m.subscriptions, nil
+}
+
+func (m *mockSubscription) Get(subscriptionID, userID int) (models.Subscription, error) {
+	return models.Subscription{}, nil
+}
+
+func (m *mockSubscription) Pause(subscriptionID, userID int) (models.Subscription, error) {
+	return models.Subscription{}, nil
+}
+
+func (m *mockSubscription) Resume(subscriptionID, userID int) (models.Subscription, error) {
+	return models.Subscription{}, nil
+}
+
+func (m *mockSubscription) SkipNext(subscriptionID, userID int) (models.Subscription, error) {
+	return models.Subscription{}, nil
+}
+
+func (m *mockSubscription) Cancel(subscriptionID, userID int) (models.Subscription, error) {
+	return models.Subscription{}, nil
+}
+
+func (m *mockSubscription) GetActive() ([]models.Subscription, error) {
+	return m.subscriptions, nil
+}
+
+func (m *mockSubscription) Ordered(subscriptionID int, dateTime time.Time) (bool, error) {
+	return m.ordered, nil
+}
+
+func (m *mockSubscription) Advance(subscriptionID int, note string) error {
+	if m.advanced == nil {
+		m.advanced = map[int]string{}
+	}
+	m.advanced[subscriptionID] = note
+	return nil
+}
+
+// ======================
+// MOCK ORDER PLACER
+// ======================
+type mockOrders struct {
+	placed []models.Transaction
+}
+
+func (m *mockOrders) Order(transaction models.Transaction, email string, items []models.DetailTransaction) (models.Transaction, error) {
+	m.placed = append(m.placed, transaction)
+	return transaction, nil
+}
+
+func (m *mockOrders) GetDistance(partnerID int, latitude, longtitude float64) (float64, error) {
+	return 1, nil
+}
@@ -0,0 +1,119 @@
+package webhook
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/repositories/webhook"
+	"github.com/labstack/gommon/log"
+)
+
+const (
+	pollInterval = time.Minute
+	claimLease   = 5 * time.Minute
+	batchSize    = 50
+)
+
+// Handler applies the body of one webhook event. Returning an error schedules a retry.
+type Handler func(body []byte) error
+
+// Processor applies stored webhook events in the background. Events are picked up right
+// away when queued, and the due ones are polled for so retries and events left behind
+// by a restart are processed too.
+type Processor struct {
+	Repo     webhook.WebhookInterface
+	Handlers map[string]Handler
+	queue    chan uint
+}
+
+func NewProcessor(repo webhook.WebhookInterface) *Processor {
+	return &Processor{Repo: repo, Handlers: map[string]Handler{}, queue: make(chan uint, 100)}
+}
+
+func (p *Processor) Handle(source string, handler Handler) {
+	p.Handlers[source] = handler
+}
+
+// Enqueue asks for the event to be processed soon. When the queue is full the next poll takes it.
+func (p *Processor) Enqueue(id uint) {
+	select {
+	case p.queue <- id:
+	default:
+	}
+}
+
+func (p *Processor) Start() {
+	go func() {
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+
+		p.RunDue(time.Now())
+
+		for {
+			select {
+			case id := <-p.queue:
+				if err := p.Process(int(id), time.Now()); err != nil {
+					log.Warnf("webhook: event %d: %v", id, err)
+				}
+			case <-ticker.C:
+				p.RunDue(time.Now())
+			}
+		}
+	}()
+}
+
+// RunDue processes every event that is due as of now and returns how many succeeded.
+func (p *Processor) RunDue(now time.Time) int {
+	events, err := p.Repo.GetDue(now, batchSize)
+	if err != nil {
+		log.Warnf("webhook: get due events: %v", err)
+		return 0
+	}
+
+	count := 0
+
+	for _, event := range events {
+		if err := p.Process(int(event.ID), now); err != nil {
+			log.Warnf("webhook: event %d: %v", event.ID, err)
+			continue
+		}
+		count++
+	}
+
+	return count
+}
+
+// Process claims the event and runs its handler. A failed attempt is retried after
+// attempts² minutes until WEBHOOK_MAX_ATTEMPTS is reached, then the event is left FAILED.
+func (p *Processor) Process(id int, now time.Time) error {
+	event, claimed, err := p.Repo.Claim(id, now, claimLease)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return nil
+	}
+
+	handler, ok := p.Handlers[event.Source]
+	if !ok {
+		err = fmt.Errorf("no handler for %s webhooks", event.Source)
+	} else {
+		err = handler([]byte(event.Body))
+	}
+
+	if err == nil {
+		return p.Repo.Processed(id, now)
+	}
+
+	var retryAt time.Time
+	if ok && event.Attempts < constants.WEBHOOK_MAX_ATTEMPTS {
+		retryAt = now.Add(time.Duration(event.Attempts*event.Attempts) * time.Minute)
+	}
+
+	if failErr := p.Repo.Failed(id, err.Error(), retryAt); failErr != nil {
+		return failErr
+	}
+
+	return err
+}
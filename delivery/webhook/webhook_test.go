@@ -0,0 +1,155 @@
+package webhook_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/delivery/webhook"
+	"github.com/furqonzt99/snackbox/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestProcess(t *testing.T) {
+	constants.WEBHOOK_MAX_ATTEMPTS = 3
+
+	now := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	t.Run("process success", func(t *testing.T) {
+		repo := &mockWebhook{event: models.WebhookEvent{Model: gorm.Model{ID: 1}, Source: constants.TRANSACTION_WEBHOOK, Body: `{"external_id":"INV1"}`, Attempts: 1}}
+
+		var body string
+		processor := webhook.NewProcessor(repo)
+		processor.Handle(constants.TRANSACTION_WEBHOOK, func(b []byte) error {
+			body = string(b)
+			return nil
+		})
+
+		err := processor.Process(1, now)
+
+		assert.Nil(t, err)
+		assert.Equal(t, `{"external_id":"INV1"}`, body)
+		assert.True(t, repo.processed)
+	})
+
+	t.Run("process failure is retried later", func(t *testing.T) {
+		repo := &mockWebhook{event: models.WebhookEvent{Model: gorm.Model{ID: 1}, Source: constants.CASHOUT_WEBHOOK, Attempts: 2}}
+
+		processor := webhook.NewProcessor(repo)
+		processor.Handle(constants.CASHOUT_WEBHOOK, func(b []byte) error {
+			return errors.New("record not found")
+		})
+
+		err := processor.Process(1, now)
+
+		assert.NotNil(t, err)
+		assert.False(t, repo.processed)
+		assert.Equal(t, "record not found", repo.message)
+		assert.Equal(t, now.Add(4*time.Minute), repo.retryAt)
+	})
+
+	t.Run("process gives up after the last attempt", func(t *testing.T) {
+		repo := &mockWebhook{event: models.WebhookEvent{Model: gorm.Model{ID: 1}, Source: constants.CASHOUT_WEBHOOK, Attempts: 3}}
+
+		processor := webhook.NewProcessor(repo)
+		processor.Handle(constants.CASHOUT_WEBHOOK, func(b []byte) error {
+			return errors.New("record not found")
+		})
+
+		processor.Process(1, now)
+
+		assert.True(t, repo.failed)
+		assert.True(t, repo.retryAt.IsZero())
+	})
+
+	t.Run("process without handler", func(t *testing.T) {
+		repo := &mockWebhook{event: models.WebhookEvent{Model: gorm.Model{ID: 1}, Source: "unknown", Attempts: 1}}
+
+		processor := webhook.NewProcessor(repo)
+		processor.Process(1, now)
+
+		assert.True(t, repo.failed)
+		assert.True(t, repo.retryAt.IsZero())
+	})
+
+	t.Run("process event claimed elsewhere", func(t *testing.T) {
+		repo := &mockWebhook{event: models.WebhookEvent{Model: gorm.Model{ID: 1}, Source: constants.CASHOUT_WEBHOOK}, taken: true}
+
+		called := false
+		processor := webhook.NewProcessor(repo)
+		processor.Handle(constants.CASHOUT_WEBHOOK, func(b []byte) error {
+			called = true
+			return nil
+		})
+
+		err := processor.Process(1, now)
+
+		assert.Nil(t, err)
+		assert.False(t, called)
+		assert.False(t, repo.processed)
+	})
+}
+
+func TestRunDue(t *testing.T) {
+	now := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	repo := &mockWebhook{event: models.WebhookEvent{Model: gorm.Model{ID: 1}, Source: constants.TRANSACTION_WEBHOOK, Attempts: 1}}
+
+	processor := webhook.NewProcessor(repo)
+	processor.Handle(constants.TRANSACTION_WEBHOOK, func(b []byte) error { return nil })
+
+	assert.Equal(t, 1, processor.RunDue(now))
+	assert.Equal(t, now, repo.dueAt)
+}
+
+// ======================
+// MOCK WEBHOOK REPOSITORY
+// ======================
+type mockWebhook struct {
+	event     models.WebhookEvent
+	taken     bool
+	dueAt     time.Time
+	processed bool
+	failed    bool
+	message   string
+	retryAt   time.Time
+}
+
+func (m *mockWebhook) Receive(event models.WebhookEvent) (models.WebhookEvent, bool, error) {
+	return event, true, nil
+}
+
+func (m *mockWebhook) GetAll(source, status string) ([]models.WebhookEvent, error) {
+	return []models.WebhookEvent{m.event}, nil
+}
+
+func (m *mockWebhook) Get(id int) (models.WebhookEvent, error) {
+	return m.event, nil
+}
+
+func (m *mockWebhook) GetDue(now time.Time, limit int) ([]models.WebhookEvent, error) {
+	m.dueAt = now
+	return []models.WebhookEvent{m.event}, nil
+}
+
+func (m *mockWebhook) Claim(id int, now time.Time, lease time.Duration) (models.WebhookEvent, bool, error) {
+	return m.event, !m.taken, nil
+}
+
+func (m *mockWebhook) Processed(id int, now time.Time) error {
+	m.processed = true
+	return nil
+}
+
+func (m *mockWebhook) Failed(id int, message string, retryAt time.Time) error {
+	m.failed = retryAt.IsZero()
+	m.message = message
+	m.retryAt = retryAt
+	return nil
+}
+
+func (m *mockWebhook) Replay(id int) (models.WebhookEvent, error) {
+	return m.event, nil
+}
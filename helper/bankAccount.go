@@ -0,0 +1,19 @@
+package helper
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrInvalidBankAccount = errors.New("invalid bank account")
+
+func BankAccountError(reason string) error {
+	return fmt.Errorf("%w: %s", ErrInvalidBankAccount, reason)
+}
+
+// SameHolderName compares two account holder names the way banks print them, ignoring case
+// and extra spaces.
+func SameHolderName(a, b string) bool {
+	return strings.EqualFold(strings.Join(strings.Fields(a), " "), strings.Join(strings.Fields(b), " "))
+}
@@ -0,0 +1,67 @@
+package helper
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/models"
+	"gorm.io/gorm"
+)
+
+var ErrInvalidCashout = errors.New("invalid cashout")
+
+func CashoutError(reason string) error {
+	return fmt.Errorf("%w: %s", ErrInvalidCashout, reason)
+}
+
+// ValidateCashoutAmount checks the amount against CASHOUT_MIN_AMOUNT and CASHOUT_MAX_AMOUNT.
+func ValidateCashoutAmount(amount models.Money) error {
+	if amount < models.Money(constants.CASHOUT_MIN_AMOUNT) || amount > models.Money(constants.CASHOUT_MAX_AMOUNT) {
+		return CashoutError(fmt.Sprintf("the amount must be between Rp%d and Rp%d", constants.CASHOUT_MIN_AMOUNT, constants.CASHOUT_MAX_AMOUNT))
+	}
+
+	return nil
+}
+
+// CheckCashoutLimits checks that amount fits in what is left of the user's CASHOUT_DAILY_LIMIT
+// and CASHOUT_MONTHLY_LIMIT as of now. Cashouts that failed or were rejected do not count.
+func CheckCashoutLimits(tx *gorm.DB, userID uint, amount models.Money, now time.Time) error {
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+
+	limits := []struct {
+		since time.Time
+		limit int
+		name  string
+	}{
+		{day, constants.CASHOUT_DAILY_LIMIT, "daily"},
+		{month, constants.CASHOUT_MONTHLY_LIMIT, "monthly"},
+	}
+
+	for _, limit := range limits {
+		var total models.Money
+
+		err := tx.Model(&models.Cashout{}).Select("COALESCE(SUM(amount), 0)").
+			Where("user_id = ? AND status NOT IN ? AND created_at >= ?", userID, []string{constants.CASHOUT_FAILED, constants.CASHOUT_REJECTED}, limit.since).
+			Scan(&total).Error
+		if err != nil {
+			return err
+		}
+
+		if left := models.Money(limit.limit) - total; amount > left {
+			if left < 0 {
+				left = 0
+			}
+			return CashoutError(fmt.Sprintf("only Rp%d is left of the %s cashout limit of Rp%d", left, limit.name, limit.limit))
+		}
+	}
+
+	return nil
+}
+
+// NeedsCashoutApproval reports whether a cashout of amount waits for an admin.
+func NeedsCashoutApproval(amount models.Money) bool {
+	return amount > models.Money(constants.CASHOUT_APPROVAL_THRESHOLD)
+}
@@ -0,0 +1,108 @@
+package helper
+
+import (
+	"errors"
+	"time"
+
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/models"
+	"gorm.io/gorm"
+)
+
+// CommissionRate picks the most specific rule for a product category of the partner: the
+// partner's rule for the category, then the partner's own default, the global rule for the
+// category and the global default. Shipping only follows SHIPPING rules, so it is not charged
+// unless a rule says so. Without a matching rule there is no commission.
+func CommissionRate(rules []models.CommissionRule, partnerID uint, category string) float64 {
+	scopes := []models.CommissionRule{
+		{PartnerID: partnerID, Category: category},
+		{PartnerID: partnerID},
+		{Category: category},
+		{},
+	}
+	if category == constants.SHIPPING_COMMISSION {
+		scopes = []models.CommissionRule{{PartnerID: partnerID, Category: category}, {Category: category}}
+	}
+
+	for _, scope := range scopes {
+		for _, rule := range rules {
+			if rule.PartnerID == scope.PartnerID && rule.Category == scope.Category {
+				return rule.Rate
+			}
+		}
+	}
+
+	return 0
+}
+
+// CalculateFee splits the price of the order. Commission is taken from the products and the
+// shipping before any voucher discount, which the partner bears as before, and never from the
+// exclusive tax. When part of the order was refunded already, the commission shrinks in
+// proportion.
+func CalculateFee(rules []models.CommissionRule, trx models.Transaction, items []models.DetailTransaction, refunded models.Money) models.TransactionFee {
+	fee := models.TransactionFee{
+		TransactionID:  trx.ID,
+		PartnerID:      trx.PartnerID,
+		ShippingAmount: trx.ShippingCost,
+		Discount:       trx.Discount,
+		Tax:            trx.Tax,
+		Gross:          trx.TotalPrice,
+		Refunded:       refunded,
+	}
+
+	for _, item := range items {
+		amount := item.Price * models.Money(item.Quantity)
+		fee.ProductAmount += amount
+		fee.ProductCommission += amount.Mul(CommissionRate(rules, trx.PartnerID, item.Type) / 100)
+	}
+
+	fee.ShippingCommission = fee.ShippingAmount.Mul(CommissionRate(rules, trx.PartnerID, constants.SHIPPING_COMMISSION) / 100)
+
+	fee.Commission = fee.ProductCommission + fee.ShippingCommission
+	if fee.Refunded > 0 && fee.Gross > 0 {
+		fee.Commission = fee.Commission.Mul((fee.Gross - fee.Refunded).Float64() / fee.Gross.Float64())
+	}
+
+	fee.Commission = models.MinMoney(fee.Commission, fee.Gross-fee.Tax-fee.Refunded)
+	fee.Net = fee.Gross - fee.Refunded - fee.Commission
+
+	return fee
+}
+
+// ChargeCommission records the fee of the order once, with the rules in force when the
+// partner gets paid.
+func ChargeCommission(tx *gorm.DB, trx models.Transaction) (models.TransactionFee, error) {
+	fee := models.TransactionFee{}
+
+	err := tx.Where("transaction_id = ?", trx.ID).First(&fee).Error
+	if err == nil {
+		return fee, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fee, err
+	}
+
+	rules := []models.CommissionRule{}
+	if err := tx.Where("partner_id IN ?", []uint{0, trx.PartnerID}).Find(&rules).Error; err != nil {
+		return fee, err
+	}
+
+	items := []models.DetailTransaction{}
+	if err := tx.Where("transaction_id = ?", trx.ID).Find(&items).Error; err != nil {
+		return fee, err
+	}
+
+	refunded, err := RefundedAmount(tx, trx.ID)
+	if err != nil {
+		return fee, err
+	}
+
+	fee = CalculateFee(rules, trx, items, refunded)
+	fee.AvailableAt = time.Now().AddDate(0, 0, constants.EARNING_HOLD_DAYS)
+
+	if err := tx.Create(&fee).Error; err != nil {
+		return fee, err
+	}
+
+	return fee, nil
+}
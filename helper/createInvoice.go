@@ -1,34 +1,88 @@
 package helper
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/furqonzt99/snackbox/constants"
 	"github.com/furqonzt99/snackbox/models"
-	"github.com/xendit/xendit-go"
-	"github.com/xendit/xendit-go/invoice"
+	"github.com/furqonzt99/snackbox/payment"
 )
 
-func CreateInvoice(transaction models.Transaction, email string, balance float64) (models.Transaction, error) {
+var ErrInvalidPayment = errors.New("payment is not possible")
 
-	items := []xendit.InvoiceItem{}
+func PaymentError(reason string) error {
+	return fmt.Errorf("%w: %s", ErrInvalidPayment, reason)
+}
+
+// PaymentDeadline is when the scheduler expires an order that is still unpaid.
+func PaymentDeadline(transaction models.Transaction) time.Time {
+	return transaction.CreatedAt.Add(time.Duration(constants.PAYMENT_TIMEOUT_HOURS) * time.Hour)
+}
+
+// InvoiceDuration is how long, in seconds, an invoice issued now can be paid. It is capped at the
+// order's payment deadline so no invoice outlives the order it pays for.
+func InvoiceDuration(transaction models.Transaction, now time.Time) int {
+	duration := time.Duration(constants.INVOICE_DURATION_HOURS) * time.Hour
+
+	if left := PaymentDeadline(transaction).Sub(now); left < duration {
+		duration = left
+	}
+
+	return int(duration.Seconds())
+}
 
-	for _, product := range transaction.Products {
-		items = append(items, xendit.InvoiceItem{
-			Name:     product.Title,
-			Price:    product.Price,
-			Quantity: transaction.Quantity,
-			Category: product.Type,
+// invoiceItems lists the line items of the order's invoice: the products, the shipping with
+// its large order surcharge on a line of its own, the voucher discount and the exclusive taxes.
+// The second value names the inclusive taxes for the description.
+func invoiceItems(transaction models.Transaction) ([]payment.InvoiceItem, string) {
+	items := []payment.InvoiceItem{}
+
+	for _, item := range transaction.DetailTransactions {
+		items = append(items, payment.InvoiceItem{
+			Name:     item.Title,
+			Price:    item.Price,
+			Quantity: item.Quantity,
+			Category: item.Type,
 		})
 	}
 
-	shippingCost := CalculateShippingCost(transaction.Distance)
+	if delivery := transaction.ShippingCost - transaction.ShippingSurcharge; delivery > 0 {
+		items = append(items, payment.InvoiceItem{
+			Name:     "Shipping Cost",
+			Price:    delivery,
+			Quantity: 1,
+		})
+	}
 
-	items = append(items, xendit.InvoiceItem{
-		Name:     "Shipping Cost",
-		Price:    shippingCost,
-		Quantity: 1,
-	})
+	if transaction.ShippingSurcharge > 0 {
+		items = append(items, payment.InvoiceItem{
+			Name:     "Large Order Surcharge",
+			Price:    transaction.ShippingSurcharge,
+			Quantity: 1,
+		})
+	}
+
+	if transaction.Discount > 0 {
+		items = append(items, payment.InvoiceItem{
+			Name:     "Voucher " + transaction.VoucherCode,
+			Price:    -transaction.Discount,
+			Quantity: 1,
+		})
+	}
+
+	taxItems, includedTaxes := taxInvoiceItems(transaction.Taxes)
+
+	return append(items, taxItems...), includedTaxes
+}
+
+func CreateInvoice(provider payment.PaymentProvider, transaction models.Transaction, email string, balance models.Money, shipping ShippingBreakdown) (models.Transaction, error) {
+
+	transaction.ShippingCost = shipping.Total
+	transaction.ShippingSurcharge = shipping.Surcharge
+
+	items, includedTaxes := invoiceItems(transaction)
 	transaction.TotalPrice = SumTotalPrice(items)
 
 	totalPay := transaction.TotalPrice - balance
@@ -37,31 +91,81 @@ func CreateInvoice(transaction models.Transaction, email string, balance float64
 
 	if totalPay <= 0 {
 		transactionSuccess = models.Transaction{
-			TotalPrice:     transaction.TotalPrice,
-			PaymentChannel: "SboxPay",
-			PaymentMethod:  "Sboxpay",
-			PaidAt:         time.Now(),
-			Status:         "PAID",
+			TotalPrice:        transaction.TotalPrice,
+			ShippingCost:      shipping.Total,
+			ShippingSurcharge: shipping.Surcharge,
+			BalanceUsed:       transaction.TotalPrice,
+			PaymentChannel:    "SboxPay",
+			PaymentMethod:     "Sboxpay",
+			PaidAt:            time.Now(),
+			Status:            constants.PAID_STATUS,
 		}
 	} else {
-		data := invoice.CreateParams{
+		data := payment.CreateInvoiceParams{
 			ExternalID:  transaction.InvoiceID,
 			Amount:      totalPay,
-			Description: "SnackBox Invoice " + transaction.InvoiceID + " for " + email + " split with SboxPay Rp" + fmt.Sprint(balance),
+			Description: "SnackBox Invoice " + transaction.InvoiceID + " for " + email + " split with SboxPay Rp" + fmt.Sprint(balance) + includedTaxes,
 			PayerEmail:  email,
 			Items:       items,
+			Duration:    InvoiceDuration(transaction, time.Now()),
 		}
 
-		resp, err := invoice.Create(&data)
+		resp, err := provider.CreateInvoice(data)
 		if err != nil {
 			return transaction, err
 		}
 
 		transactionSuccess = models.Transaction{
-			PaymentUrl: resp.InvoiceURL,
-			TotalPrice: transaction.TotalPrice,
+			PaymentUrl:        resp.URL,
+			PaymentInvoiceID:  resp.ID,
+			TotalPrice:        transaction.TotalPrice,
+			ShippingCost:      shipping.Total,
+			ShippingSurcharge: shipping.Surcharge,
+			BalanceUsed:       balance,
 		}
 	}
 
 	return transactionSuccess, nil
 }
+
+// ReissueInvoice issues a new invoice under externalID for what is still left to pay on the order,
+// with the same line items as the first one. The order's Taxes must be loaded.
+func ReissueInvoice(provider payment.PaymentProvider, transaction models.Transaction, email, externalID string) (payment.Invoice, error) {
+	duration := InvoiceDuration(transaction, time.Now())
+	if duration <= 0 {
+		return payment.Invoice{}, PaymentError("the payment time of the order is over")
+	}
+
+	items, includedTaxes := invoiceItems(transaction)
+
+	data := payment.CreateInvoiceParams{
+		ExternalID:  externalID,
+		Amount:      transaction.TotalPrice - transaction.BalanceUsed,
+		Description: "SnackBox Invoice " + externalID + " for " + email + " split with SboxPay Rp" + fmt.Sprint(transaction.BalanceUsed) + includedTaxes,
+		PayerEmail:  email,
+		Items:       items,
+		Duration:    duration,
+	}
+
+	return provider.CreateInvoice(data)
+}
+
+// ExpireInvoice expires an invoice at the provider. An invoice the provider already expired
+// on its own counts as expired, a paid one is an error.
+func ExpireInvoice(provider payment.PaymentProvider, invoiceID string) error {
+	err := provider.ExpireInvoice(invoiceID)
+	if err == nil {
+		return nil
+	}
+
+	invoice, getErr := provider.GetInvoice(invoiceID)
+	if getErr == nil && invoice.Status == payment.INVOICE_EXPIRED {
+		return nil
+	}
+
+	if getErr == nil && invoice.Status == payment.INVOICE_PAID {
+		return PaymentError("the invoice is already paid, the payment is being confirmed")
+	}
+
+	return err
+}
@@ -0,0 +1,232 @@
+package helper
+
+import (
+	"errors"
+	"time"
+
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrInsufficientBalance = errors.New("the SboxPay balance is not enough")
+
+// LedgerAccount is a user's wallet or, with UserID 0, a system account.
+type LedgerAccount struct {
+	Name   string
+	UserID uint
+}
+
+func WalletAccount(userID uint) LedgerAccount {
+	return LedgerAccount{Name: constants.WALLET_ACCOUNT, UserID: userID}
+}
+
+// PendingAccount holds a partner's earnings until the hold period is over.
+func PendingAccount(userID uint) LedgerAccount {
+	return LedgerAccount{Name: constants.PENDING_ACCOUNT, UserID: userID}
+}
+
+func SystemAccount(name string) LedgerAccount {
+	return LedgerAccount{Name: name}
+}
+
+// LedgerTransfer moves Amount from one account to the other because of the source record.
+type LedgerTransfer struct {
+	From        LedgerAccount
+	To          LedgerAccount
+	Amount      models.Money
+	Type        string
+	SourceType  string
+	SourceID    uint
+	Description string
+}
+
+// PostLedger writes both entries of the transfer and keeps users.balance and
+// users.pending_balance in step with the wallet and pending entries. A transfer of the same
+// type for the same source is only posted once, so a retried callback or job does not move
+// the money again. Wallets and pending balances cannot go below zero.
+func PostLedger(tx *gorm.DB, transfer LedgerTransfer) error {
+	if transfer.Amount < 0 {
+		transfer.From, transfer.To = transfer.To, transfer.From
+		transfer.Amount = -transfer.Amount
+	}
+
+	if transfer.Amount == 0 {
+		return nil
+	}
+
+	var posted int64
+	if err := tx.Model(&models.LedgerEntry{}).
+		Where("type = ? AND source_type = ? AND source_id = ?", transfer.Type, transfer.SourceType, transfer.SourceID).
+		Where("account = ? AND user_id = ?", transfer.To.Name, transfer.To.UserID).
+		Count(&posted).Error; err != nil {
+		return err
+	}
+	if posted > 0 {
+		return nil
+	}
+
+	journalID := uuid.New().String()
+
+	if err := postLedgerEntry(tx, journalID, transfer.From, -transfer.Amount, transfer); err != nil {
+		return err
+	}
+
+	return postLedgerEntry(tx, journalID, transfer.To, transfer.Amount, transfer)
+}
+
+func postLedgerEntry(tx *gorm.DB, journalID string, account LedgerAccount, amount models.Money, transfer LedgerTransfer) error {
+	entry := models.LedgerEntry{
+		JournalID:   journalID,
+		Account:     account.Name,
+		UserID:      account.UserID,
+		Type:        transfer.Type,
+		SourceType:  transfer.SourceType,
+		SourceID:    transfer.SourceID,
+		Amount:      amount,
+		Description: transfer.Description,
+	}
+
+	if account.Name == constants.WALLET_ACCOUNT || account.Name == constants.PENDING_ACCOUNT {
+		user := models.User{}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, account.UserID).Error; err != nil {
+			return err
+		}
+
+		column, balance := "balance", user.Balance
+		if account.Name == constants.PENDING_ACCOUNT {
+			column, balance = "pending_balance", user.PendingBalance
+		}
+
+		entry.Balance = balance + amount
+		if entry.Balance < 0 {
+			return ErrInsufficientBalance
+		}
+
+		if err := tx.Model(&user).Update(column, entry.Balance).Error; err != nil {
+			return err
+		}
+	}
+
+	return tx.Create(&entry).Error
+}
+
+// OpenWalletBalances books the balance users had before the ledger existed as an
+// opening entry, so the ledger of every wallet adds up to users.balance.
+func OpenWalletBalances(db *gorm.DB) error {
+	users := []models.User{}
+
+	opened := db.Model(&models.LedgerEntry{}).Select("user_id").Where("account = ?", constants.WALLET_ACCOUNT)
+	if err := db.Where("balance <> 0 AND id NOT IN (?)", opened).Find(&users).Error; err != nil {
+		return err
+	}
+
+	for _, user := range users {
+		journalID := uuid.New().String()
+
+		entries := []models.LedgerEntry{
+			{JournalID: journalID, Account: constants.ADJUSTMENT_ACCOUNT, Type: constants.OPENING_BALANCE_ENTRY, SourceType: constants.USER_SOURCE, SourceID: user.ID, Amount: -user.Balance, Description: "opening balance"},
+			{JournalID: journalID, Account: constants.WALLET_ACCOUNT, UserID: user.ID, Type: constants.OPENING_BALANCE_ENTRY, SourceType: constants.USER_SOURCE, SourceID: user.ID, Amount: user.Balance, Balance: user.Balance, Description: "opening balance"},
+		}
+
+		if err := db.Create(&entries).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RefundOrder gives amount of what was paid for the order back to the customer's wallet.
+func RefundOrder(tx *gorm.DB, trx models.Transaction, amount models.Money) error {
+	return PostLedger(tx, LedgerTransfer{
+		From:        SystemAccount(constants.ESCROW_ACCOUNT),
+		To:          WalletAccount(trx.UserID),
+		Amount:      amount,
+		Type:        constants.REFUND_ENTRY,
+		SourceType:  constants.TRANSACTION_SOURCE,
+		SourceID:    trx.ID,
+		Description: "refund for order " + trx.InvoiceID,
+	})
+}
+
+// PayPartner releases the price of a confirmed order: the net amount to the partner's pending
+// balance, where it waits out the hold period, and the commission to the platform's revenue.
+func PayPartner(tx *gorm.DB, trx models.Transaction) error {
+	partner := models.Partner{}
+	if err := tx.First(&partner, trx.PartnerID).Error; err != nil {
+		return err
+	}
+
+	fee, err := ChargeCommission(tx, trx)
+	if err != nil {
+		return err
+	}
+
+	err = PostLedger(tx, LedgerTransfer{
+		From:        SystemAccount(constants.ESCROW_ACCOUNT),
+		To:          PendingAccount(partner.UserID),
+		Amount:      fee.Net,
+		Type:        constants.PARTNER_EARNING_ENTRY,
+		SourceType:  constants.TRANSACTION_SOURCE,
+		SourceID:    trx.ID,
+		Description: "earning from order " + trx.InvoiceID,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = PostLedger(tx, LedgerTransfer{
+		From:        SystemAccount(constants.ESCROW_ACCOUNT),
+		To:          SystemAccount(constants.REVENUE_ACCOUNT),
+		Amount:      fee.Commission,
+		Type:        constants.COMMISSION_ENTRY,
+		SourceType:  constants.TRANSACTION_SOURCE,
+		SourceID:    trx.ID,
+		Description: "commission on order " + trx.InvoiceID,
+	})
+	if err != nil {
+		return err
+	}
+
+	if !fee.AvailableAt.After(time.Now()) {
+		return ReleaseEarning(tx, fee.ID)
+	}
+
+	return nil
+}
+
+// ReleaseEarning moves what is left of the partner's earning from an order from the pending
+// balance to the wallet, where it can be cashed out. A released fee is left alone.
+func ReleaseEarning(tx *gorm.DB, feeID uint) error {
+	fee := models.TransactionFee{}
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Transaction").First(&fee, feeID).Error; err != nil {
+		return err
+	}
+
+	if !fee.ReleasedAt.IsZero() {
+		return nil
+	}
+
+	partner := models.Partner{}
+	if err := tx.First(&partner, fee.PartnerID).Error; err != nil {
+		return err
+	}
+
+	err := PostLedger(tx, LedgerTransfer{
+		From:        PendingAccount(partner.UserID),
+		To:          WalletAccount(partner.UserID),
+		Amount:      fee.Net,
+		Type:        constants.EARNING_RELEASE_ENTRY,
+		SourceType:  constants.TRANSACTION_SOURCE,
+		SourceID:    fee.TransactionID,
+		Description: "earning from order " + fee.Transaction.InvoiceID + " is available",
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Model(&fee).Update("released_at", time.Now()).Error
+}
@@ -0,0 +1,81 @@
+package helper
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/furqonzt99/snackbox/models"
+)
+
+const OrderHourFormat = "15:04"
+
+// ValidateOrderRules checks an order against every ordering rule of the partner.
+// A zero lead time, maximum quantity or delivery radius means the partner has no such limit.
+func ValidateOrderRules(partner models.Partner, dateTime time.Time, quantity int, distance float64) error {
+	if err := ValidateLeadTime(partner, dateTime); err != nil {
+		return err
+	}
+
+	if err := ValidateOrderHours(partner, dateTime); err != nil {
+		return err
+	}
+
+	if err := ValidateOrderQuantity(partner, quantity); err != nil {
+		return err
+	}
+
+	return ValidateDeliveryRadius(partner, distance)
+}
+
+func ValidateLeadTime(partner models.Partner, dateTime time.Time) error {
+	earliest := time.Now().Add(time.Duration(partner.LeadTimeHours) * time.Hour)
+
+	if earliest.After(dateTime) {
+		return fmt.Errorf("you must order at least %d hours before the event time", partner.LeadTimeHours)
+	}
+
+	return nil
+}
+
+// ValidateOrderHours checks the event clock time against the partner's order hours.
+// The hours may wrap around midnight, e.g. 18:00 to 02:00.
+func ValidateOrderHours(partner models.Partner, dateTime time.Time) error {
+	if partner.OrderOpenTime == "" || partner.OrderCloseTime == "" {
+		return nil
+	}
+
+	clock := dateTime.UTC().Format(OrderHourFormat)
+
+	var accepted bool
+	if partner.OrderOpenTime <= partner.OrderCloseTime {
+		accepted = clock >= partner.OrderOpenTime && clock <= partner.OrderCloseTime
+	} else {
+		accepted = clock >= partner.OrderOpenTime || clock <= partner.OrderCloseTime
+	}
+
+	if !accepted {
+		return fmt.Errorf("this partner only accepts event times between %v and %v", partner.OrderOpenTime, partner.OrderCloseTime)
+	}
+
+	return nil
+}
+
+func ValidateOrderQuantity(partner models.Partner, quantity int) error {
+	if quantity < partner.MinQuantity {
+		return fmt.Errorf("the minimum order for this partner is %d boxes", partner.MinQuantity)
+	}
+
+	if partner.MaxQuantity > 0 && quantity > partner.MaxQuantity {
+		return fmt.Errorf("the maximum order for this partner is %d boxes", partner.MaxQuantity)
+	}
+
+	return nil
+}
+
+func ValidateDeliveryRadius(partner models.Partner, distance float64) error {
+	if partner.MaxDistance > 0 && distance > partner.MaxDistance {
+		return fmt.Errorf("this partner only delivers within %v km, the address is %v km away", partner.MaxDistance, distance)
+	}
+
+	return nil
+}
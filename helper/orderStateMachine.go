@@ -0,0 +1,88 @@
+package helper
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/models"
+	"gorm.io/gorm"
+)
+
+var ErrInvalidTransition = errors.New("invalid transaction status transition")
+
+// orderTransitions lists, for every status, the statuses a transaction may move to next.
+// SEND is still reachable straight from ACCEPT so partners can skip the finer stages.
+var orderTransitions = map[string][]string{
+	constants.PENDING_STATUS:     {constants.PAID_STATUS, constants.EXPIRED_STATUS, constants.CANCEL_STATUS},
+	constants.PAID_STATUS:        {constants.ACCEPT_STATUS, constants.REJECT_STATUS, constants.CANCEL_STATUS},
+	constants.ACCEPT_STATUS:      {constants.PREPARING_STATUS, constants.SEND_STATUS},
+	constants.PREPARING_STATUS:   {constants.READY_STATUS, constants.SEND_STATUS},
+	constants.READY_STATUS:       {constants.ON_DELIVERY_STATUS, constants.SEND_STATUS},
+	constants.ON_DELIVERY_STATUS: {constants.SEND_STATUS},
+	constants.SEND_STATUS:        {constants.CONFIRM_STATUS},
+}
+
+type OrderActor struct {
+	ID   int
+	Role string
+}
+
+func SystemActor() OrderActor {
+	return OrderActor{Role: constants.SYSTEM_ROLE}
+}
+
+func CanTransitionOrder(from, to string) bool {
+	for _, status := range orderTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+// TransitionOrder moves the transaction to the given status and writes its status history row.
+// It must be called inside the database transaction that performs the rest of the change.
+// The status is only written while the row still has the status trx was read with, an order
+// someone else moved on in the meantime fails with ErrInvalidTransition and is left as it is.
+func TransitionOrder(tx *gorm.DB, trx *models.Transaction, status string, actor OrderActor, reason string) error {
+	from := trx.Status
+
+	if !CanTransitionOrder(from, status) {
+		return ErrInvalidTransition
+	}
+
+	result := tx.Model(trx).Where("status = ?", from).Update("status", status)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		trx.Status = from
+		return fmt.Errorf("%w: the order is no longer %s", ErrInvalidTransition, from)
+	}
+
+	// orders that will never be fulfilled give their voucher back
+	switch status {
+	case constants.EXPIRED_STATUS, constants.CANCEL_STATUS, constants.REJECT_STATUS:
+		if err := ReleaseVoucher(tx, trx.ID); err != nil {
+			return err
+		}
+	}
+
+	return RecordOrderStatus(tx, trx.ID, from, status, actor, reason)
+}
+
+func RecordOrderStatus(tx *gorm.DB, trxID uint, from, to string, actor OrderActor, reason string) error {
+	history := models.TransactionStatusHistory{
+		TransactionID: trxID,
+		FromStatus:    from,
+		ToStatus:      to,
+		ActorID:       uint(actor.ID),
+		ActorRole:     actor.Role,
+		Reason:        reason,
+	}
+
+	return tx.Create(&history).Error
+}
@@ -0,0 +1,120 @@
+package helper
+
+import (
+	"errors"
+	"time"
+
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/models"
+	"gorm.io/gorm"
+)
+
+const CalendarDateFormat = "2006-01-02"
+
+var ErrPartnerUnavailable = errors.New("the partner does not take orders on this date")
+var ErrCapacityExceeded = errors.New("the partner does not have enough capacity left on this date")
+
+// DayAvailability is the booking state of one partner on one event date.
+// A daily capacity of 0 means the partner takes any number of boxes that day,
+// which is reported as Unlimited. A calendar override of 0 closes the day
+// to new boxes instead.
+type DayAvailability struct {
+	Date      string
+	Capacity  int
+	Booked    int
+	Remaining int
+	Unlimited bool
+	Blackout  bool
+	Note      string
+}
+
+// EventDate is the calendar day an order is booked on. Event times are
+// entered as wall clock time and kept in UTC, so the day is taken in UTC too.
+func EventDate(dateTime time.Time) string {
+	return dateTime.UTC().Format(CalendarDateFormat)
+}
+
+// PartnerAvailability reports capacity, booked boxes and blackouts for every day from from to to inclusive.
+func PartnerAvailability(tx *gorm.DB, partner models.Partner, from, to time.Time) ([]DayAvailability, error) {
+	from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	to = time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+
+	calendars := []models.PartnerCalendar{}
+	if err := tx.Where("partner_id = ? AND date BETWEEN ? AND ?", partner.ID, from.Format(CalendarDateFormat), to.Format(CalendarDateFormat)).Find(&calendars).Error; err != nil {
+		return nil, err
+	}
+
+	overrides := map[string]models.PartnerCalendar{}
+	for _, calendar := range calendars {
+		overrides[calendar.Date] = calendar
+	}
+
+	trxs := []models.Transaction{}
+	if err := tx.Select("date_time", "quantity").
+		Where("partner_id = ? AND date_time >= ? AND date_time < ?", partner.ID, from, to.AddDate(0, 0, 1)).
+		Where("status NOT IN ?", []string{constants.EXPIRED_STATUS, constants.REJECT_STATUS, constants.CANCEL_STATUS}).
+		Find(&trxs).Error; err != nil {
+		return nil, err
+	}
+
+	booked := map[string]int{}
+	for _, trx := range trxs {
+		booked[EventDate(trx.DateTime)] += trx.Quantity
+	}
+
+	days := []DayAvailability{}
+
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		date := day.Format(CalendarDateFormat)
+
+		availability := DayAvailability{
+			Date:     date,
+			Capacity: partner.DailyCapacity,
+			Booked:   booked[date],
+		}
+		unlimited := partner.DailyCapacity == 0
+
+		if override, ok := overrides[date]; ok {
+			availability.Blackout = override.Blackout
+			availability.Note = override.Note
+			if override.Capacity != nil {
+				availability.Capacity = *override.Capacity
+				unlimited = false
+			}
+		}
+
+		switch {
+		case availability.Blackout:
+			availability.Capacity = 0
+		case unlimited:
+			availability.Unlimited = true
+		case availability.Capacity > availability.Booked:
+			availability.Remaining = availability.Capacity - availability.Booked
+		}
+
+		days = append(days, availability)
+	}
+
+	return days, nil
+}
+
+// ValidatePartnerCapacity checks that the partner can still take quantity boxes on the event date.
+// Lock the partner row first when calling it while placing an order, so concurrent orders queue up.
+func ValidatePartnerCapacity(tx *gorm.DB, partner models.Partner, dateTime time.Time, quantity int) error {
+	days, err := PartnerAvailability(tx, partner, dateTime.UTC(), dateTime.UTC())
+	if err != nil {
+		return err
+	}
+
+	day := days[0]
+
+	if day.Blackout {
+		return ErrPartnerUnavailable
+	}
+
+	if !day.Unlimited && quantity > day.Remaining {
+		return ErrCapacityExceeded
+	}
+
+	return nil
+}
@@ -6,42 +6,32 @@ import (
 	"time"
 
 	"github.com/furqonzt99/snackbox/models"
+	"github.com/furqonzt99/snackbox/payment"
 	"github.com/google/uuid"
-	"github.com/xendit/xendit-go/disbursement"
 )
 
-func PaymentCashout(data models.Cashout) (models.Cashout, error) {
+// PrepareCashout gives the cashout the external id and idempotency key of its disbursement, so
+// it can be stored before the provider is called.
+func PrepareCashout(data models.Cashout) models.Cashout {
+	data.ExternalID = strings.ToUpper(strings.ReplaceAll(uuid.New().String(), "-", ""))
+	data.IdempotenceKey = strings.ToUpper(strings.ReplaceAll(uuid.New().String(), "-", ""))
+	data.Description = fmt.Sprint("Cashout to ", data.AccountHolderName, " at ", time.Now())
 
-	externalID := strings.ToUpper(strings.ReplaceAll(uuid.New().String(), "-", ""))
-	IdempotenceKey := strings.ToUpper(strings.ReplaceAll(uuid.New().String(), "-", ""))
-	description := fmt.Sprint("Cashout to ", data.AccountHolderName, " at ", time.Now())
+	return data
+}
 
-	createData := disbursement.CreateParams{
-		IdempotencyKey:    IdempotenceKey,
-		ExternalID:        externalID,
+// PaymentCashout sends a prepared cashout to the provider as a disbursement.
+func PaymentCashout(provider payment.PaymentProvider, data models.Cashout) (payment.Disbursement, error) {
+
+	createData := payment.CreateDisbursementParams{
+		IdempotencyKey:    data.IdempotenceKey,
+		ExternalID:        data.ExternalID,
 		BankCode:          data.BankCode,
 		AccountHolderName: data.AccountHolderName,
 		AccountNumber:     data.AccountNumber,
-		Description:       description,
+		Description:       data.Description,
 		Amount:            data.Amount,
 	}
 
-	resp, err := disbursement.Create(&createData)
-	if err != nil {
-		return data, err
-	}
-
-	disbursementData := models.Cashout{
-		UserID:            data.UserID,
-		IdempotenceKey:    IdempotenceKey,
-		ExternalID:        externalID,
-		BankCode:          resp.BankCode,
-		AccountHolderName: resp.AccountHolderName,
-		AccountNumber:     createData.AccountNumber,
-		Amount:            resp.Amount,
-		Description:       description,
-		Status:            resp.Status,
-	}
-	
-	return disbursementData, nil
-}
\ No newline at end of file
+	return provider.CreateDisbursement(createData)
+}
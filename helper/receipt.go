@@ -0,0 +1,191 @@
+package helper
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/models"
+	"github.com/johnfercher/maroto/pkg/color"
+	"github.com/johnfercher/maroto/pkg/consts"
+	"github.com/johnfercher/maroto/pkg/pdf"
+	"github.com/johnfercher/maroto/pkg/props"
+	"github.com/leekchan/accounting"
+)
+
+var ErrReceiptUnavailable = errors.New("a receipt is only available for paid transactions")
+
+// receiptStatuses are the statuses of an order that was paid and not called off since.
+var receiptStatuses = map[string]bool{
+	constants.PAID_STATUS:        true,
+	constants.ACCEPT_STATUS:      true,
+	constants.PREPARING_STATUS:   true,
+	constants.READY_STATUS:       true,
+	constants.ON_DELIVERY_STATUS: true,
+	constants.SEND_STATUS:        true,
+	constants.CONFIRM_STATUS:     true,
+}
+
+var receiptBrandColor = color.Color{Red: 230, Green: 81, Blue: 0}
+
+// RenderReceipt renders the customer's receipt of a paid order as a PDF. The order needs its
+// User, Partner, DetailTransactions and Taxes loaded.
+func RenderReceipt(trx models.Transaction) (bytes.Buffer, error) {
+	if !receiptStatuses[trx.Status] || trx.PaidAt.IsZero() {
+		return bytes.Buffer{}, ErrReceiptUnavailable
+	}
+
+	ac := accounting.Accounting{Symbol: "Rp", Precision: 0}
+
+	m := pdf.NewMaroto(consts.Portrait, consts.A4)
+	m.SetPageMargins(15, 15, 15)
+
+	m.Row(14, func() {
+		m.Col(6, func() {
+			m.Text("SnackBox", props.Text{Size: 20, Style: consts.Bold, Family: consts.Arial, Color: receiptBrandColor})
+		})
+		m.Col(6, func() {
+			m.Text("RECEIPT", props.Text{Size: 16, Style: consts.Bold, Family: consts.Arial, Align: consts.Right})
+		})
+	})
+
+	m.Line(1)
+
+	receiptInfo := [][2]string{
+		{"Invoice", trx.InvoiceID},
+		{"Paid at", trx.PaidAt.Format("02 January 2006 15:04")},
+		{"Payment", strings.TrimSpace(trx.PaymentMethod + " " + trx.PaymentChannel)},
+		{"Event date", trx.DateTime.Format("02 January 2006 15:04")},
+	}
+
+	for _, info := range receiptInfo {
+		m.Row(6, func() {
+			m.Col(3, func() {
+				m.Text(info[0], props.Text{Top: 1, Size: 10, Style: consts.Bold})
+			})
+			m.Col(9, func() {
+				m.Text(info[1], props.Text{Top: 1, Size: 10})
+			})
+		})
+	}
+
+	m.Row(8, func() {})
+
+	m.Row(6, func() {
+		m.Col(6, func() {
+			m.Text("Billed to", props.Text{Size: 11, Style: consts.Bold})
+		})
+		m.Col(6, func() {
+			m.Text("Sold by", props.Text{Size: 11, Style: consts.Bold})
+		})
+	})
+
+	m.Row(18, func() {
+		m.Col(6, func() {
+			m.Text(trx.User.Name, props.Text{Top: 1, Size: 10})
+			m.Text(trx.User.Email, props.Text{Top: 6, Size: 10})
+			m.Text(joinAddress(trx.User.Address, trx.User.City), props.Text{Top: 11, Size: 10})
+		})
+		m.Col(6, func() {
+			m.Text(trx.Partner.BussinessName, props.Text{Top: 1, Size: 10})
+			m.Text(joinAddress(trx.Partner.Address, trx.Partner.City), props.Text{Top: 6, Size: 10})
+		})
+	})
+
+	contents := [][]string{}
+	var subtotal models.Money
+
+	for _, item := range trx.DetailTransactions {
+		amount := item.Price * models.Money(item.Quantity)
+		subtotal += amount
+
+		contents = append(contents, []string{item.Title, item.Type, ac.FormatMoney(item.Price), fmt.Sprint(item.Quantity), ac.FormatMoney(amount)})
+	}
+
+	m.TableList([]string{"Item", "Type", "Price", "Qty", "Amount"}, contents, props.TableList{
+		HeaderProp: props.TableListContent{
+			Size:      10,
+			Style:     consts.Bold,
+			GridSizes: []uint{4, 2, 2, 1, 3},
+		},
+		ContentProp: props.TableListContent{
+			Size:      10,
+			GridSizes: []uint{4, 2, 2, 1, 3},
+		},
+		Align:                consts.Left,
+		AlternatedBackground: &color.Color{Red: 240, Green: 240, Blue: 240},
+		HeaderContentSpace:   2,
+		Line:                 true,
+	})
+
+	totals := [][2]string{
+		{"Subtotal", ac.FormatMoney(subtotal)},
+		{"Shipping", ac.FormatMoney(trx.ShippingCost)},
+	}
+
+	if trx.Discount > 0 {
+		totals = append(totals, [2]string{"Voucher " + trx.VoucherCode, "-" + ac.FormatMoney(trx.Discount)})
+	}
+
+	for _, tax := range trx.Taxes {
+		if tax.Inclusive {
+			totals = append(totals, [2]string{taxName(tax) + " (included)", ac.FormatMoney(tax.Amount)})
+			continue
+		}
+
+		totals = append(totals, [2]string{taxName(tax), ac.FormatMoney(tax.Amount)})
+	}
+
+	totals = append(totals, [2]string{"Total", ac.FormatMoney(trx.TotalPrice)})
+
+	if trx.BalanceUsed > 0 {
+		totals = append(totals, [2]string{"Paid with SboxPay", ac.FormatMoney(trx.BalanceUsed)})
+	}
+
+	m.Row(4, func() {})
+
+	for i, total := range totals {
+		style := consts.Normal
+		if total[0] == "Total" {
+			style = consts.Bold
+		}
+
+		if i == len(totals)-1 || total[0] == "Total" {
+			m.Row(1, func() {})
+		}
+
+		m.Row(6, func() {
+			m.ColSpace(6)
+			m.Col(3, func() {
+				m.Text(total[0], props.Text{Top: 1, Size: 10, Style: style})
+			})
+			m.Col(3, func() {
+				m.Text(total[1], props.Text{Top: 1, Size: 10, Style: style, Align: consts.Right})
+			})
+		})
+	}
+
+	m.Row(15, func() {})
+
+	m.Row(6, func() {
+		m.Col(12, func() {
+			m.Text("Thank you for ordering with SnackBox. This receipt was issued electronically and is valid without a signature.", props.Text{Size: 8, Style: consts.Italic, Align: consts.Center})
+		})
+	})
+
+	return m.Output()
+}
+
+func joinAddress(address, city string) string {
+	parts := []string{}
+
+	for _, part := range []string{address, city} {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	return strings.Join(parts, ", ")
+}
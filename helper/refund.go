@@ -0,0 +1,117 @@
+package helper
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrInvalidRefund = errors.New("invalid refund")
+
+func RefundError(reason string) error {
+	return fmt.Errorf("%w: %s", ErrInvalidRefund, reason)
+}
+
+// RefundedAmount sums the refund requests approved on the transaction.
+func RefundedAmount(tx *gorm.DB, trxID uint) (models.Money, error) {
+	var refunded models.Money
+
+	err := tx.Model(&models.RefundRequest{}).Select("COALESCE(SUM(approved_amount), 0)").
+		Where("transaction_id = ? AND status = ?", trxID, constants.REFUND_APPROVED).
+		Scan(&refunded).Error
+
+	return refunded, err
+}
+
+// ValidateRefundAmount checks that amount is more than 0 and no more than what is left of the order price.
+func ValidateRefundAmount(tx *gorm.DB, trx models.Transaction, amount models.Money) error {
+	refunded, err := RefundedAmount(tx, trx.ID)
+	if err != nil {
+		return err
+	}
+
+	if amount <= 0 || amount > trx.TotalPrice-refunded {
+		return RefundError(fmt.Sprintf("the amount must be between Rp1 and Rp%d", trx.TotalPrice-refunded))
+	}
+
+	return nil
+}
+
+// SettleRefund credits an approved refund to the customer's wallet from escrow. While the
+// partner has not been paid the money is still there and the payout shrinks by it. Once paid,
+// it is taken back from the partner's pending balance, or the wallet when the earning was
+// already released, and the platform gives back its commission on it in proportion.
+func SettleRefund(tx *gorm.DB, trx models.Transaction, refund models.RefundRequest) error {
+	fee := models.TransactionFee{}
+
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("transaction_id = ?", trx.ID).First(&fee).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	if err == nil {
+		var platformShare models.Money
+		if remaining := fee.Gross - fee.Refunded; remaining > 0 {
+			platformShare = models.MinMoney(fee.Commission.Mul(refund.ApprovedAmount.Float64()/remaining.Float64()), fee.Commission)
+		}
+		partnerShare := refund.ApprovedAmount - platformShare
+
+		partner := models.Partner{}
+		if err := tx.First(&partner, trx.PartnerID).Error; err != nil {
+			return err
+		}
+
+		earning := PendingAccount(partner.UserID)
+		if !fee.ReleasedAt.IsZero() {
+			earning = WalletAccount(partner.UserID)
+		}
+
+		err = PostLedger(tx, LedgerTransfer{
+			From:        earning,
+			To:          SystemAccount(constants.ESCROW_ACCOUNT),
+			Amount:      partnerShare,
+			Type:        constants.REFUND_DEDUCTION_ENTRY,
+			SourceType:  constants.REFUND_SOURCE,
+			SourceID:    refund.ID,
+			Description: "refund claim on order " + trx.InvoiceID,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = PostLedger(tx, LedgerTransfer{
+			From:        SystemAccount(constants.REVENUE_ACCOUNT),
+			To:          SystemAccount(constants.ESCROW_ACCOUNT),
+			Amount:      platformShare,
+			Type:        constants.COMMISSION_REVERSAL_ENTRY,
+			SourceType:  constants.REFUND_SOURCE,
+			SourceID:    refund.ID,
+			Description: "commission on the refund claim on order " + trx.InvoiceID,
+		})
+		if err != nil {
+			return err
+		}
+
+		fee.Refunded += refund.ApprovedAmount
+		fee.Commission -= platformShare
+		fee.Net -= partnerShare
+
+		if err := tx.Model(&fee).Select("refunded", "commission", "net").Updates(&fee).Error; err != nil {
+			return err
+		}
+	}
+
+	return PostLedger(tx, LedgerTransfer{
+		From:        SystemAccount(constants.ESCROW_ACCOUNT),
+		To:          WalletAccount(trx.UserID),
+		Amount:      refund.ApprovedAmount,
+		Type:        constants.REFUND_ENTRY,
+		SourceType:  constants.REFUND_SOURCE,
+		SourceID:    refund.ID,
+		Description: "refund claim on order " + trx.InvoiceID,
+	})
+}
@@ -0,0 +1,31 @@
+package helper
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/google/uuid"
+)
+
+var ErrCancellationTooLate = errors.New("the cancellation window for this transaction has passed")
+
+func ParseEventTime(date, clock string) (time.Time, error) {
+	return time.Parse(time.RFC3339, fmt.Sprintf("%vT%vZ", date, clock))
+}
+
+func ValidateCancellationTime(dateTime time.Time) error {
+	deadline := dateTime.Add(-time.Duration(constants.CANCELLATION_WINDOW_HOURS) * time.Hour)
+
+	if time.Now().After(deadline) {
+		return ErrCancellationTooLate
+	}
+
+	return nil
+}
+
+func NewInvoiceID() string {
+	return strings.ToUpper(strings.ReplaceAll(uuid.New().String(), "-", ""))
+}
@@ -0,0 +1,160 @@
+package helper
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+
+	"github.com/furqonzt99/snackbox/models"
+	"gorm.io/gorm"
+)
+
+// ShippingBreakdown explains how the shipping cost of an order was put together.
+// Total is what the customer pays: BaseCost + DistanceCost + Surcharge - Discount.
+type ShippingBreakdown struct {
+	Distance     float64
+	Zone         string
+	BaseCost     models.Money
+	DistanceCost models.Money
+	Surcharge    models.Money
+	Discount     models.Money
+	Total        models.Money
+}
+
+// DefaultShippingTariff is used while neither the partner nor an admin has defined a tariff:
+// Rp50.000 for the first km and Rp15.000 for every km after it.
+func DefaultShippingTariff() models.ShippingTariff {
+	return models.ShippingTariff{
+		BaseCost:     50000,
+		BaseDistance: 1,
+		Tiers: []models.ShippingTariffTier{
+			{CostPerKm: 15000},
+		},
+	}
+}
+
+// FindShippingTariff loads the tariff of the partner, falling back to the admin default
+// and then to DefaultShippingTariff.
+func FindShippingTariff(tx *gorm.DB, partnerID uint) (models.ShippingTariff, error) {
+	for _, id := range []uint{partnerID, 0} {
+		tariff := models.ShippingTariff{}
+
+		err := tx.Preload("Tiers").Preload("Zones").Preload("Surcharges").Where("partner_id = ?", id).First(&tariff).Error
+		if err == nil {
+			return tariff, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return tariff, err
+		}
+	}
+
+	return DefaultShippingTariff(), nil
+}
+
+// CalculateShipping prices the delivery of an order of quantity boxes worth subtotal
+// to the given address, distance km away from the partner.
+func CalculateShipping(tariff models.ShippingTariff, latitude, longtitude, distance float64, subtotal models.Money, quantity int) ShippingBreakdown {
+	breakdown := ShippingBreakdown{Distance: distance}
+
+	zone, inZone := findShippingZone(tariff.Zones, latitude, longtitude)
+
+	if inZone {
+		breakdown.Zone = zone.Name
+		breakdown.BaseCost = zone.FlatCost
+	} else {
+		breakdown.BaseCost = tariff.BaseCost
+		breakdown.DistanceCost = distanceCost(tariff, distance)
+	}
+
+	reached := 0
+	for _, surcharge := range tariff.Surcharges {
+		if quantity >= surcharge.MinQuantity && surcharge.MinQuantity > reached {
+			reached = surcharge.MinQuantity
+			breakdown.Surcharge = surcharge.Amount
+		}
+	}
+
+	// free shipping waives the delivery itself, large order surcharges are still paid
+	if tariff.FreeShippingMinSubtotal > 0 && subtotal >= tariff.FreeShippingMinSubtotal {
+		breakdown.Discount = breakdown.BaseCost + breakdown.DistanceCost
+	}
+
+	breakdown.Total = breakdown.BaseCost + breakdown.DistanceCost + breakdown.Surcharge - breakdown.Discount
+
+	return breakdown
+}
+
+func distanceCost(tariff models.ShippingTariff, distance float64) models.Money {
+	tiers := append([]models.ShippingTariffTier{}, tariff.Tiers...)
+
+	// open ended tiers go last
+	sort.SliceStable(tiers, func(i, j int) bool {
+		if tiers[i].UpToKm == 0 || tiers[j].UpToKm == 0 {
+			return tiers[j].UpToKm == 0 && tiers[i].UpToKm != 0
+		}
+		return tiers[i].UpToKm < tiers[j].UpToKm
+	})
+
+	var cost models.Money
+	from := tariff.BaseDistance
+
+	for _, tier := range tiers {
+		if distance <= from {
+			break
+		}
+
+		upper := tier.UpToKm
+		if upper == 0 || upper > distance {
+			upper = distance
+		}
+
+		if upper > from {
+			cost += tier.CostPerKm.Mul(upper - from)
+			from = upper
+		}
+	}
+
+	return cost
+}
+
+func findShippingZone(zones []models.ShippingZone, latitude, longtitude float64) (models.ShippingZone, bool) {
+	for _, zone := range zones {
+		polygon, err := ParsePolygon(zone.Polygon)
+		if err != nil {
+			continue
+		}
+
+		if pointInPolygon(polygon, latitude, longtitude) {
+			return zone, true
+		}
+	}
+
+	return models.ShippingZone{}, false
+}
+
+func ParsePolygon(polygon string) ([][2]float64, error) {
+	points := [][2]float64{}
+
+	if err := json.Unmarshal([]byte(polygon), &points); err != nil {
+		return nil, err
+	}
+
+	return points, nil
+}
+
+// pointInPolygon uses ray casting, which is precise enough for city sized zones.
+func pointInPolygon(polygon [][2]float64, latitude, longtitude float64) bool {
+	inside := false
+
+	for i, j := 0, len(polygon)-1; i < len(polygon); j, i = i, i+1 {
+		latI, lngI := polygon[i][0], polygon[i][1]
+		latJ, lngJ := polygon[j][0], polygon[j][1]
+
+		if (lngI > longtitude) != (lngJ > longtitude) &&
+			latitude < (latJ-latI)*(longtitude-lngI)/(lngJ-lngI)+latI {
+			inside = !inside
+		}
+	}
+
+	return inside
+}
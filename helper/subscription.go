@@ -0,0 +1,175 @@
+package helper
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/furqonzt99/snackbox/models"
+)
+
+var ErrInvalidRecurrence = errors.New("the schedule must look like FREQ=WEEKLY;INTERVAL=1;BYDAY=MO,TH")
+var ErrSubscriptionStatus = errors.New("the subscription cannot be changed in its current status")
+var ErrSubscriptionPartners = errors.New("all products of a subscription must come from the same partner")
+
+const maxRecurrenceInterval = 52
+
+var recurrenceWeekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// Recurrence is the parsed form of a subscription schedule. It supports the
+// FREQ (DAILY, WEEKLY or MONTHLY), INTERVAL, BYDAY and BYMONTHDAY parts of an RRULE.
+// Without BYDAY or BYMONTHDAY the schedule repeats on the weekday or day of its start date.
+type Recurrence struct {
+	Frequency  string
+	Interval   int
+	ByDay      []time.Weekday
+	ByMonthDay int
+}
+
+func ParseRecurrence(rule string) (Recurrence, error) {
+	recurrence := Recurrence{Interval: 1}
+
+	for _, part := range strings.Split(strings.ToUpper(strings.TrimPrefix(rule, "RRULE:")), ";") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+
+		pair := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(pair) != 2 {
+			return recurrence, ErrInvalidRecurrence
+		}
+
+		switch pair[0] {
+		case "FREQ":
+			if pair[1] != "DAILY" && pair[1] != "WEEKLY" && pair[1] != "MONTHLY" {
+				return recurrence, ErrInvalidRecurrence
+			}
+			recurrence.Frequency = pair[1]
+		case "INTERVAL":
+			interval, err := strconv.Atoi(pair[1])
+			if err != nil || interval < 1 || interval > maxRecurrenceInterval {
+				return recurrence, ErrInvalidRecurrence
+			}
+			recurrence.Interval = interval
+		case "BYDAY":
+			for _, day := range strings.Split(pair[1], ",") {
+				weekday, ok := recurrenceWeekdays[day]
+				if !ok {
+					return recurrence, ErrInvalidRecurrence
+				}
+				recurrence.ByDay = append(recurrence.ByDay, weekday)
+			}
+		case "BYMONTHDAY":
+			day, err := strconv.Atoi(pair[1])
+			if err != nil || day < 1 || day > 31 {
+				return recurrence, ErrInvalidRecurrence
+			}
+			recurrence.ByMonthDay = day
+		default:
+			return recurrence, ErrInvalidRecurrence
+		}
+	}
+
+	if recurrence.Frequency == "" ||
+		(len(recurrence.ByDay) > 0 && recurrence.Frequency != "WEEKLY") ||
+		(recurrence.ByMonthDay > 0 && recurrence.Frequency != "MONTHLY") {
+		return recurrence, ErrInvalidRecurrence
+	}
+
+	return recurrence, nil
+}
+
+// Next returns the first date on or after from that the schedule starting at start occurs on.
+func (r Recurrence) Next(start, from time.Time) (time.Time, bool) {
+	if from.Before(start) {
+		from = start
+	}
+
+	limit := from.AddDate(r.Interval+1, 0, 0)
+
+	for day := from; day.Before(limit); day = day.AddDate(0, 0, 1) {
+		if r.occursOn(start, day) {
+			return day, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+func (r Recurrence) occursOn(start, day time.Time) bool {
+	switch r.Frequency {
+	case "DAILY":
+		return int(day.Sub(start).Hours()/24)%r.Interval == 0
+	case "WEEKLY":
+		weeks := int(startOfWeek(day).Sub(startOfWeek(start)).Hours()/24) / 7
+		if weeks%r.Interval != 0 {
+			return false
+		}
+
+		if len(r.ByDay) == 0 {
+			return day.Weekday() == start.Weekday()
+		}
+
+		for _, weekday := range r.ByDay {
+			if day.Weekday() == weekday {
+				return true
+			}
+		}
+
+		return false
+	case "MONTHLY":
+		months := (day.Year()-start.Year())*12 + int(day.Month()) - int(start.Month())
+		if months%r.Interval != 0 {
+			return false
+		}
+
+		if r.ByMonthDay == 0 {
+			return day.Day() == start.Day()
+		}
+
+		return day.Day() == r.ByMonthDay
+	}
+
+	return false
+}
+
+func startOfWeek(day time.Time) time.Time {
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
+
+// NextSubscriptionDate returns the first delivery date of the subscription on or after from,
+// or an empty string once the schedule has passed its end date.
+func NextSubscriptionDate(subscription models.Subscription, from time.Time) (string, error) {
+	recurrence, err := ParseRecurrence(subscription.Rule)
+	if err != nil {
+		return "", err
+	}
+
+	start, err := time.Parse(CalendarDateFormat, subscription.StartDate)
+	if err != nil {
+		return "", err
+	}
+
+	from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+
+	next, ok := recurrence.Next(start, from)
+	if !ok {
+		return "", nil
+	}
+
+	date := next.Format(CalendarDateFormat)
+	if subscription.EndDate != "" && date > subscription.EndDate {
+		return "", nil
+	}
+
+	return date, nil
+}
@@ -1,10 +1,13 @@
 package helper
 
-import "github.com/xendit/xendit-go"
+import (
+	"github.com/furqonzt99/snackbox/models"
+	"github.com/furqonzt99/snackbox/payment"
+)
 
-func SumTotalPrice(items []xendit.InvoiceItem) (totalPrice float64) {
+func SumTotalPrice(items []payment.InvoiceItem) (totalPrice models.Money) {
 	for _, item := range items {
-		totalPrice += (item.Price * float64(item.Quantity))
+		totalPrice += item.Price * models.Money(item.Quantity)
 	}
 
 	return totalPrice
@@ -0,0 +1,116 @@
+package helper
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/models"
+	"github.com/furqonzt99/snackbox/payment"
+	"gorm.io/gorm"
+)
+
+// CalculateTaxes computes a tax line for every rule of the partner. The voucher discount
+// lowers the base of what it was given on, the shipping cost for free shipping vouchers and
+// the products for the others.
+func CalculateTaxes(rules []models.TaxRule, subtotal, shipping, discount models.Money, voucherType string) []models.TransactionTax {
+	productBase, shippingBase := subtotal, shipping
+
+	if voucherType == constants.FREE_SHIPPING_VOUCHER {
+		shippingBase -= models.MinMoney(discount, shippingBase)
+	} else {
+		productBase -= models.MinMoney(discount, productBase)
+	}
+
+	taxes := []models.TransactionTax{}
+
+	for _, rule := range rules {
+		base := productBase
+		if rule.TaxShipping {
+			base += shippingBase
+		}
+
+		// an inclusive rate is part of the base already, base = net * (100 + rate) / 100
+		amount := base.Mul(rule.Rate / 100)
+		if rule.Inclusive {
+			amount = base.Mul(rule.Rate / (100 + rule.Rate))
+		}
+
+		taxes = append(taxes, models.TransactionTax{
+			Type:      rule.Type,
+			Rate:      rule.Rate,
+			Inclusive: rule.Inclusive,
+			Base:      base,
+			Amount:    amount,
+		})
+	}
+
+	return taxes
+}
+
+// ExclusiveTax is what the taxes add to the price of the order.
+func ExclusiveTax(taxes []models.TransactionTax) models.Money {
+	var total models.Money
+
+	for _, tax := range taxes {
+		if !tax.Inclusive {
+			total += tax.Amount
+		}
+	}
+
+	return total
+}
+
+// ApplyTaxes stores the tax lines of the order under the partner's current rules.
+func ApplyTaxes(tx *gorm.DB, trx models.Transaction, subtotal, shipping models.Money, voucherType string) ([]models.TransactionTax, error) {
+	rules := []models.TaxRule{}
+	if err := tx.Where("partner_id = ?", trx.PartnerID).Order("id").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+
+	taxes := CalculateTaxes(rules, subtotal, shipping, trx.Discount, voucherType)
+
+	for i := range taxes {
+		taxes[i].TransactionID = trx.ID
+
+		if err := tx.Create(&taxes[i]).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return taxes, nil
+}
+
+func taxName(tax models.TransactionTax) string {
+	return fmt.Sprintf("%s %s%%", tax.Type, strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.2f", tax.Rate), "0"), "."))
+}
+
+// taxInvoiceItems lists the exclusive taxes as invoice items. Inclusive taxes are part of the
+// item prices already, they are mentioned in the description returned next to the items.
+func taxInvoiceItems(taxes []models.TransactionTax) ([]payment.InvoiceItem, string) {
+	items := []payment.InvoiceItem{}
+	included := []string{}
+
+	for _, tax := range taxes {
+		if tax.Amount == 0 {
+			continue
+		}
+
+		if tax.Inclusive {
+			included = append(included, fmt.Sprintf("%s Rp%d", taxName(tax), tax.Amount))
+			continue
+		}
+
+		items = append(items, payment.InvoiceItem{
+			Name:     taxName(tax),
+			Price:    tax.Amount,
+			Quantity: 1,
+		})
+	}
+
+	if len(included) == 0 {
+		return items, ""
+	}
+
+	return items, ", includes " + strings.Join(included, ", ")
+}
@@ -0,0 +1,33 @@
+package helper
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/models"
+)
+
+// TopupPrefix starts the external id of every top-up invoice. Invoice callbacks
+// for top-ups and orders arrive on the same URL and are told apart by it.
+const TopupPrefix = "TOPUP-"
+
+var ErrInvalidTopup = errors.New("invalid top-up")
+
+func NewTopupID() string {
+	return TopupPrefix + NewInvoiceID()
+}
+
+func IsTopupID(externalID string) bool {
+	return strings.HasPrefix(externalID, TopupPrefix)
+}
+
+// ValidateTopupAmount checks the amount against TOPUP_MIN_AMOUNT and TOPUP_MAX_AMOUNT.
+func ValidateTopupAmount(amount models.Money) error {
+	if amount < models.Money(constants.TOPUP_MIN_AMOUNT) || amount > models.Money(constants.TOPUP_MAX_AMOUNT) {
+		return fmt.Errorf("%w: the amount must be between Rp%d and Rp%d", ErrInvalidTopup, constants.TOPUP_MIN_AMOUNT, constants.TOPUP_MAX_AMOUNT)
+	}
+
+	return nil
+}
@@ -0,0 +1,167 @@
+package helper
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrInvalidVoucher = errors.New("invalid voucher")
+
+func voucherError(reason string) error {
+	return fmt.Errorf("%w: %s", ErrInvalidVoucher, reason)
+}
+
+// ApplyVoucher redeems the voucher code on a freshly created order and returns the discount.
+// It must be called inside the database transaction that creates the order: the voucher row
+// stays locked until it commits, so concurrent orders cannot oversell the quota.
+func ApplyVoucher(tx *gorm.DB, code string, trx models.Transaction, items []models.DetailTransaction, shipping ShippingBreakdown) (models.Voucher, models.Money, error) {
+	voucher := models.Voucher{}
+
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("code = ?", code).First(&voucher).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return voucher, 0, voucherError("code does not exist")
+		}
+		return voucher, 0, err
+	}
+
+	if err := tx.Where("voucher_id = ?", voucher.ID).Find(&voucher.Partners).Error; err != nil {
+		return voucher, 0, err
+	}
+
+	if err := tx.Where("voucher_id = ?", voucher.ID).Find(&voucher.Categories).Error; err != nil {
+		return voucher, 0, err
+	}
+
+	var used int64
+	if voucher.PerUserLimit > 0 {
+		if err := tx.Model(&models.VoucherUsage{}).Where("voucher_id = ? AND user_id = ?", voucher.ID, trx.UserID).Count(&used).Error; err != nil {
+			return voucher, 0, err
+		}
+	}
+
+	discount, err := VoucherDiscount(voucher, trx.PartnerID, items, shipping, int(used), time.Now())
+	if err != nil {
+		return voucher, 0, err
+	}
+
+	if err := tx.Model(&voucher).UpdateColumn("used", gorm.Expr("used + 1")).Error; err != nil {
+		return voucher, 0, err
+	}
+
+	usage := models.VoucherUsage{
+		VoucherID:     voucher.ID,
+		UserID:        trx.UserID,
+		TransactionID: trx.ID,
+		Discount:      discount,
+	}
+
+	if err := tx.Create(&usage).Error; err != nil {
+		return voucher, 0, err
+	}
+
+	return voucher, discount, nil
+}
+
+// VoucherDiscount checks that the voucher can be used on the order and computes the discount.
+// userUsed is how many times the customer has redeemed the voucher before.
+func VoucherDiscount(voucher models.Voucher, partnerID uint, items []models.DetailTransaction, shipping ShippingBreakdown, userUsed int, now time.Time) (models.Money, error) {
+	if now.Before(voucher.StartAt) {
+		return 0, voucherError("not active yet")
+	}
+
+	if !voucher.EndAt.IsZero() && now.After(voucher.EndAt) {
+		return 0, voucherError("expired")
+	}
+
+	if voucher.Quota > 0 && voucher.Used >= voucher.Quota {
+		return 0, voucherError("quota has run out")
+	}
+
+	if voucher.PerUserLimit > 0 && userUsed >= voucher.PerUserLimit {
+		return 0, voucherError("you have reached the usage limit of this voucher")
+	}
+
+	if len(voucher.Partners) > 0 {
+		allowed := false
+		for _, partner := range voucher.Partners {
+			if partner.PartnerID == partnerID {
+				allowed = true
+			}
+		}
+
+		if !allowed {
+			return 0, voucherError("not valid for this partner")
+		}
+	}
+
+	categories := map[string]bool{}
+	for _, category := range voucher.Categories {
+		categories[category.Category] = true
+	}
+
+	// only the products the voucher is restricted to count towards the minimum spend and the discount
+	var eligible models.Money
+	for _, item := range items {
+		if len(categories) == 0 || categories[item.Type] {
+			eligible += item.Price * models.Money(item.Quantity)
+		}
+	}
+
+	if eligible == 0 {
+		return 0, voucherError("not valid for these products")
+	}
+
+	if eligible < voucher.MinSpend {
+		return 0, voucherError(fmt.Sprintf("the minimum spend is %d", voucher.MinSpend))
+	}
+
+	var discount models.Money
+
+	switch voucher.Type {
+	case constants.PERCENTAGE_VOUCHER:
+		discount = models.Money(math.Floor(eligible.Float64() * voucher.Percentage / 100))
+	case constants.FLAT_VOUCHER:
+		discount = models.MinMoney(voucher.Amount, eligible)
+	case constants.FREE_SHIPPING_VOUCHER:
+		// like the tariff's own free shipping, large order surcharges are still paid
+		discount = shipping.Total - shipping.Surcharge
+		if voucher.Amount > 0 {
+			discount = models.MinMoney(voucher.Amount, discount)
+		}
+	}
+
+	if voucher.MaxDiscount > 0 {
+		discount = models.MinMoney(voucher.MaxDiscount, discount)
+	}
+
+	if discount <= 0 {
+		return 0, voucherError("no discount applies to this order")
+	}
+
+	return discount, nil
+}
+
+// ReleaseVoucher gives the voucher redeemed by the transaction back to the quota.
+func ReleaseVoucher(tx *gorm.DB, trxID uint) error {
+	usage := models.VoucherUsage{}
+
+	if err := tx.Where("transaction_id = ?", trxID).First(&usage).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	if err := tx.Unscoped().Delete(&usage).Error; err != nil {
+		return err
+	}
+
+	return tx.Model(&models.Voucher{}).Where("id = ? AND used > 0", usage.VoucherID).UpdateColumn("used", gorm.Expr("used - 1")).Error
+}
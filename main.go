@@ -2,22 +2,49 @@ package main
 
 import (
 	config "github.com/furqonzt99/snackbox/configs"
+	"github.com/furqonzt99/snackbox/constants"
 	"github.com/furqonzt99/snackbox/delivery/controllers/bank"
+	"github.com/furqonzt99/snackbox/delivery/controllers/cart"
 	"github.com/furqonzt99/snackbox/delivery/controllers/cashout"
+	"github.com/furqonzt99/snackbox/delivery/controllers/commission"
 	"github.com/furqonzt99/snackbox/delivery/controllers/partner"
 	"github.com/furqonzt99/snackbox/delivery/controllers/product"
 	"github.com/furqonzt99/snackbox/delivery/controllers/rating"
+	"github.com/furqonzt99/snackbox/delivery/controllers/reconciliation"
+	"github.com/furqonzt99/snackbox/delivery/controllers/refund"
+	"github.com/furqonzt99/snackbox/delivery/controllers/settlement"
+	"github.com/furqonzt99/snackbox/delivery/controllers/shipping"
+	"github.com/furqonzt99/snackbox/delivery/controllers/subscription"
+	"github.com/furqonzt99/snackbox/delivery/controllers/tax"
 	"github.com/furqonzt99/snackbox/delivery/controllers/transaction"
 	"github.com/furqonzt99/snackbox/delivery/controllers/user"
+	"github.com/furqonzt99/snackbox/delivery/controllers/voucher"
+	"github.com/furqonzt99/snackbox/delivery/controllers/wallet"
+	"github.com/furqonzt99/snackbox/delivery/controllers/webhook"
 	"github.com/furqonzt99/snackbox/delivery/middlewares"
+	"github.com/furqonzt99/snackbox/delivery/reconciler"
 	"github.com/furqonzt99/snackbox/delivery/routes"
+	"github.com/furqonzt99/snackbox/delivery/scheduler"
+	wp "github.com/furqonzt99/snackbox/delivery/webhook"
 	br "github.com/furqonzt99/snackbox/repositories/bank"
+	ctr "github.com/furqonzt99/snackbox/repositories/cart"
 	cr "github.com/furqonzt99/snackbox/repositories/cashout"
+	cmr "github.com/furqonzt99/snackbox/repositories/commission"
 	pt "github.com/furqonzt99/snackbox/repositories/partner"
 	pd "github.com/furqonzt99/snackbox/repositories/product"
 	rr "github.com/furqonzt99/snackbox/repositories/rating"
+	rcr "github.com/furqonzt99/snackbox/repositories/reconciliation"
+	rfr "github.com/furqonzt99/snackbox/repositories/refund"
+	sr "github.com/furqonzt99/snackbox/repositories/scheduler"
+	str "github.com/furqonzt99/snackbox/repositories/settlement"
+	shr "github.com/furqonzt99/snackbox/repositories/shipping"
+	sur "github.com/furqonzt99/snackbox/repositories/subscription"
+	txr "github.com/furqonzt99/snackbox/repositories/tax"
 	tr "github.com/furqonzt99/snackbox/repositories/transaction"
 	ur "github.com/furqonzt99/snackbox/repositories/user"
+	vr "github.com/furqonzt99/snackbox/repositories/voucher"
+	wlr "github.com/furqonzt99/snackbox/repositories/wallet"
+	wr "github.com/furqonzt99/snackbox/repositories/webhook"
 	"github.com/furqonzt99/snackbox/utils"
 	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
@@ -31,14 +58,28 @@ func main() {
 
 	utils.InitialMigrate(db)
 
+	paymentProvider := utils.InitPaymentProvider(config)
+
 	//repo
 	userRepo := ur.NewUserRepo(db)
 	partnerRepo := pt.NewPartnerRepo(db)
 	productRepo := pd.NewProductRepo(db)
-	transactionRepo := tr.NewTransactionRepository(db)
+	transactionRepo := tr.NewTransactionRepository(db, paymentProvider)
 	ratingRepo := rr.NewRatingRepository(db)
-	cashoutRepo := cr.NewCashoutRepository(db)
-	bankRepo := br.NewBankRepository(db)
+	cashoutRepo := cr.NewCashoutRepository(db, paymentProvider)
+	bankRepo := br.NewBankRepository(db, paymentProvider)
+	cartRepo := ctr.NewCartRepository(db)
+	schedulerRepo := sr.NewSchedulerRepository(db, paymentProvider)
+	shippingRepo := shr.NewShippingRepository(db)
+	voucherRepo := vr.NewVoucherRepository(db)
+	subscriptionRepo := sur.NewSubscriptionRepository(db)
+	webhookRepo := wr.NewWebhookRepository(db)
+	walletRepo := wlr.NewWalletRepository(db, paymentProvider)
+	commissionRepo := cmr.NewCommissionRepository(db)
+	refundRepo := rfr.NewRefundRepository(db)
+	reconciliationRepo := rcr.NewReconciliationRepository(db)
+	taxRepo := txr.NewTaxRepository(db)
+	settlementRepo := str.NewSettlementRepository(db)
 
 	//controller
 	userCtrl := user.NewUsersControllers(userRepo)
@@ -48,6 +89,25 @@ func main() {
 	ratingController := rating.NewRatingController(ratingRepo)
 	cashoutController := cashout.NewCashoutController(cashoutRepo)
 	bankController := bank.NewBankController(bankRepo)
+	cartController := cart.NewCartController(cartRepo, transactionRepo)
+	shippingController := shipping.NewShippingController(shippingRepo)
+	voucherController := voucher.NewVoucherController(voucherRepo)
+	subscriptionController := subscription.NewSubscriptionController(subscriptionRepo)
+	walletController := wallet.NewWalletController(walletRepo)
+	commissionController := commission.NewCommissionController(commissionRepo)
+	refundController := refund.NewRefundController(refundRepo)
+	taxController := tax.NewTaxController(taxRepo)
+	settlementController := settlement.NewSettlementController(settlementRepo)
+
+	webhookProcessor := wp.NewProcessor(webhookRepo)
+	webhookProcessor.Handle(constants.TRANSACTION_WEBHOOK, transactionController.HandleCallback)
+	webhookProcessor.Handle(constants.CASHOUT_WEBHOOK, cashoutController.HandleCallback)
+	webhookProcessor.Handle(constants.TOPUP_WEBHOOK, walletController.HandleTopupCallback)
+	webhookController := webhook.NewWebhookController(webhookRepo, webhookProcessor)
+
+	// missed callbacks are applied by the same handlers as the webhooks
+	paymentReconciler := reconciler.NewReconciler(reconciliationRepo, schedulerRepo, paymentProvider, webhookProcessor.Handlers)
+	reconciliationController := reconciliation.NewReconciliationController(reconciliationRepo, paymentReconciler)
 
 	//echo package
 	e := echo.New()
@@ -61,6 +121,15 @@ func main() {
 	e.Validator = &transaction.TransactionValidator{Validator: validator.New()}
 	e.Validator = &rating.RatingValidator{Validator: validator.New()}
 	e.Validator = &cashout.CashoutValidator{Validator: validator.New()}
+	e.Validator = &cart.CartValidator{Validator: validator.New()}
+	e.Validator = &shipping.ShippingValidator{Validator: validator.New()}
+	e.Validator = &voucher.VoucherValidator{Validator: validator.New()}
+	e.Validator = &subscription.SubscriptionValidator{Validator: validator.New()}
+	e.Validator = &wallet.WalletValidator{Validator: validator.New()}
+	e.Validator = &commission.CommissionValidator{Validator: validator.New()}
+	e.Validator = &refund.RefundValidator{Validator: validator.New()}
+	e.Validator = &bank.BankValidator{Validator: validator.New()}
+	e.Validator = &tax.TaxValidator{Validator: validator.New()}
 
 	//routes
 	routes.RegisterUserPath(e, userCtrl)
@@ -70,6 +139,22 @@ func main() {
 	routes.RegisterRatingPath(e, ratingController)
 	routes.RegisterCashoutPath(e, cashoutController)
 	routes.RegisterBankPath(e, bankController)
+	routes.RegisterCartPath(e, cartController)
+	routes.RegisterShippingPath(e, shippingController)
+	routes.RegisterVoucherPath(e, voucherController)
+	routes.RegisterSubscriptionPath(e, subscriptionController)
+	routes.RegisterWebhookPath(e, webhookController)
+	routes.RegisterWalletPath(e, walletController)
+	routes.RegisterCommissionPath(e, commissionController)
+	routes.RegisterRefundPath(e, refundController)
+	routes.RegisterReconciliationPath(e, reconciliationController)
+	routes.RegisterTaxPath(e, taxController)
+	routes.RegisterSettlementPath(e, settlementController)
+
+	//background jobs
+	scheduler.NewScheduler(schedulerRepo, subscriptionRepo, transactionRepo).Start()
+	webhookProcessor.Start()
+	paymentReconciler.Start()
 
 	e.Logger.Fatal(e.Start(":" + config.Port))
 }
@@ -0,0 +1,18 @@
+package models
+
+import "gorm.io/gorm"
+
+// BankAccount is a payout account a user saved for cashouts. InquiredName is the holder the
+// bank reported for the account number, empty when the bank could not be asked, and
+// NameMatched whether it is the holder the user typed.
+type BankAccount struct {
+	gorm.Model
+	UserID            uint   `gorm:"index;uniqueIndex:idx_bank_account"`
+	BankCode          string `gorm:"size:32;uniqueIndex:idx_bank_account"`
+	BankName          string
+	AccountHolderName string
+	AccountNumber     string `gorm:"size:64;uniqueIndex:idx_bank_account"`
+	InquiredName      string
+	NameMatched       bool
+	IsDefault         bool
+}
@@ -0,0 +1,14 @@
+package models
+
+import "gorm.io/gorm"
+
+type CartItem struct {
+	gorm.Model
+	UserID    uint
+	PartnerID uint
+	ProductID uint
+	Quantity  int
+	Notes     string
+	Product   Product
+	Partner   Partner
+}
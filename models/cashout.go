@@ -1,17 +1,26 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type Cashout struct {
 	gorm.Model
 	UserID uint
+	BankAccountID uint
 	IdempotenceKey string
 	ExternalID string
 	BankCode string
 	AccountHolderName string
 	AccountNumber string
-	Amount float64
+	Amount Money
+	Fee Money
+	GatewayFee Money
 	Description string
 	Status string
+	Note string `gorm:"type:text"`
+	SettledAt time.Time `gorm:"default:null"`
 	User User
 }
\ No newline at end of file
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CommissionRule is the platform's cut, in percent. PartnerID 0 applies to every partner and
+// an empty Category to every product category; the SHIPPING category covers the shipping cost.
+type CommissionRule struct {
+	gorm.Model
+	PartnerID uint   `gorm:"uniqueIndex:idx_commission_rule"`
+	Category  string `gorm:"size:32;uniqueIndex:idx_commission_rule"`
+	Rate      float64
+}
+
+// TransactionFee is how the price of a confirmed order was split between the platform and the
+// partner. Gross, what the customer paid, is ProductAmount + ShippingAmount - Discount + Tax,
+// Tax being the exclusive tax charged on top of the prices. Commission is only taken from the
+// products and the shipping, the tax goes to the partner, who remits it, as part of Net, what
+// the partner earned, which is Gross - Refunded - Commission. Net stays in the partner's
+// pending balance until AvailableAt and is moved to the wallet at ReleasedAt.
+type TransactionFee struct {
+	gorm.Model
+	TransactionID      uint `gorm:"uniqueIndex"`
+	PartnerID          uint `gorm:"index"`
+	ProductAmount      Money
+	ShippingAmount     Money
+	Discount           Money
+	Tax                Money
+	Gross              Money
+	ProductCommission  Money
+	ShippingCommission Money
+	Refunded           Money
+	Commission         Money
+	Net                Money
+	AvailableAt        time.Time `gorm:"default:null;index"`
+	ReleasedAt         time.Time `gorm:"default:null"`
+	Transaction        Transaction
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// LedgerEntry is one leg of a balance movement. Every movement writes two entries with the
+// same JournalID whose amounts add up to zero; entries are never updated or deleted.
+// A positive Amount is money into Account. Balance is the wallet balance right after the
+// entry and is only kept for wallet accounts.
+type LedgerEntry struct {
+	ID          uint      `gorm:"primarykey"`
+	CreatedAt   time.Time `gorm:"index"`
+	JournalID   string    `gorm:"size:36;index"`
+	Account     string    `gorm:"size:32;uniqueIndex:idx_ledger_source"`
+	UserID      uint      `gorm:"index;uniqueIndex:idx_ledger_source"`
+	Type        string    `gorm:"size:32;uniqueIndex:idx_ledger_source"`
+	SourceType  string    `gorm:"size:32;uniqueIndex:idx_ledger_source"`
+	SourceID    uint      `gorm:"uniqueIndex:idx_ledger_source"`
+	Amount      Money
+	Balance     Money
+	Description string
+}
+
+// WalletAdjustment is a balance correction made by an admin, the source of an ADJUSTMENT entry.
+type WalletAdjustment struct {
+	gorm.Model
+	UserID  uint
+	AdminID uint
+	Amount  Money
+	Reason  string
+}
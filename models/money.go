@@ -0,0 +1,30 @@
+package models
+
+import "math"
+
+// Money is an amount in whole rupiah. Amounts are kept as integers so that sums, refunds and
+// balances add up exactly; a fraction only appears when a rate is applied, and Mul rounds it.
+type Money int64
+
+// NewMoney rounds an amount that arrives as a float, from the payment provider for example,
+// to the nearest rupiah.
+func NewMoney(amount float64) Money {
+	return Money(math.Round(amount))
+}
+
+// Mul multiplies the amount by factor, a distance or a percentage rate for example,
+// rounding the result to the nearest rupiah.
+func (m Money) Mul(factor float64) Money {
+	return NewMoney(float64(m) * factor)
+}
+
+func (m Money) Float64() float64 {
+	return float64(m)
+}
+
+func MinMoney(a, b Money) Money {
+	if a < b {
+		return a
+	}
+	return b
+}
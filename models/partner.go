@@ -13,6 +13,13 @@ type Partner struct {
 	City          string
 	LegalDocument string
 	Status        string `gorm:"default:DRAFT"`
+	DailyCapacity int
+	LeadTimeHours  int     `gorm:"default:72"`
+	MinQuantity    int     `gorm:"default:1"`
+	MaxQuantity    int
+	MaxDistance    float64 `gorm:"default:10"`
+	OrderOpenTime  string  `gorm:"size:5"`
+	OrderCloseTime string  `gorm:"size:5"`
 	Products      []Product
 	Ratings		  []Rating
 }
@@ -0,0 +1,15 @@
+package models
+
+import "gorm.io/gorm"
+
+// PartnerCalendar overrides a partner's daily capacity on a single date
+// (formatted as 2006-01-02). A nil Capacity keeps the daily capacity and
+// Blackout closes the date for new orders.
+type PartnerCalendar struct {
+	gorm.Model
+	PartnerID uint   `gorm:"uniqueIndex:idx_partner_date"`
+	Date      string `gorm:"size:10;uniqueIndex:idx_partner_date"`
+	Capacity  *int   `gorm:"default:null"`
+	Blackout  bool
+	Note      string
+}
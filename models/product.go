@@ -9,6 +9,6 @@ type Product struct {
 	Image		string
 	Type        string
 	Description string
-	Price       float64
+	Price       Money
 	Partner     Partner
 }
@@ -0,0 +1,31 @@
+package models
+
+import "gorm.io/gorm"
+
+// ReconciliationRun is one pass over the invoices and disbursements still waiting for a
+// callback. Checked is how many were looked up at the provider, Applied how many missed
+// callbacks were applied and Failed how many could not be looked up or applied.
+type ReconciliationRun struct {
+	gorm.Model
+	Checked       int
+	Applied       int
+	Failed        int
+	Discrepancies []ReconciliationDiscrepancy `gorm:"foreignKey:RunID"`
+}
+
+// ReconciliationDiscrepancy is a difference between a local record and what the provider
+// reports for it. SourceType and SourceID name the record, Reference is the external id the
+// provider knows it by.
+type ReconciliationDiscrepancy struct {
+	gorm.Model
+	RunID          uint   `gorm:"index"`
+	Kind           string `gorm:"size:16;index"`
+	SourceType     string `gorm:"size:16"`
+	SourceID       uint
+	Reference      string
+	LocalStatus    string
+	ProviderStatus string
+	LocalAmount    Money
+	ProviderAmount Money
+	Note           string `gorm:"type:text"`
+}
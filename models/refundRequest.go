@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RefundRequest is a customer's claim on a delivered order. Amount is what the customer asked
+// for, CounterAmount what the partner offered instead and ApprovedAmount what was refunded.
+type RefundRequest struct {
+	gorm.Model
+	TransactionID  uint   `gorm:"index"`
+	UserID         uint   `gorm:"index"`
+	PartnerID      uint   `gorm:"index"`
+	Reason         string `gorm:"type:text"`
+	Photo          string
+	Amount         Money
+	CounterAmount  Money
+	ApprovedAmount Money
+	Status         string    `gorm:"size:16;index"`
+	PartnerNote    string    `gorm:"type:text"`
+	AdminNote      string    `gorm:"type:text"`
+	ResolvedAt     time.Time `gorm:"default:null"`
+	Transaction    Transaction
+}
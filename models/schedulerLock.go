@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// SchedulerLock is a lease on a background job. Only the replica named in
+// Owner runs the job until LockedUntil passes without a renewal.
+type SchedulerLock struct {
+	Name        string `gorm:"primaryKey;size:64"`
+	Owner       string
+	LockedUntil time.Time
+}
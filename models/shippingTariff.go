@@ -0,0 +1,44 @@
+package models
+
+import "gorm.io/gorm"
+
+// ShippingTariff prices delivery for one partner. The tariff with PartnerID 0
+// is maintained by admins and applies to every partner without its own.
+type ShippingTariff struct {
+	gorm.Model
+	PartnerID               uint `gorm:"uniqueIndex"`
+	BaseCost                Money
+	BaseDistance            float64
+	FreeShippingMinSubtotal Money
+	Tiers                   []ShippingTariffTier
+	Zones                   []ShippingZone
+	Surcharges              []ShippingSurcharge
+}
+
+// ShippingTariffTier charges CostPerKm for every km from the end of the
+// previous tier (or the base distance) up to UpToKm. An UpToKm of 0 has no end.
+type ShippingTariffTier struct {
+	gorm.Model
+	ShippingTariffID uint
+	UpToKm           float64
+	CostPerKm        Money
+}
+
+// ShippingZone replaces the distance pricing with FlatCost for every delivery
+// address inside Polygon, a JSON array of [latitude, longtitude] points.
+type ShippingZone struct {
+	gorm.Model
+	ShippingTariffID uint
+	Name             string
+	Polygon          string `gorm:"type:text"`
+	FlatCost         Money
+}
+
+// ShippingSurcharge is added once an order reaches MinQuantity boxes.
+// Only the surcharge with the highest reached MinQuantity applies.
+type ShippingSurcharge struct {
+	gorm.Model
+	ShippingTariffID uint
+	MinQuantity      int
+	Amount           Money
+}
@@ -0,0 +1,34 @@
+package models
+
+import "gorm.io/gorm"
+
+// Subscription places the same order on every date of its schedule. Rule is an
+// RRULE-like schedule such as FREQ=WEEKLY;INTERVAL=1;BYDAY=MO,TH, dates are
+// YYYY-MM-DD and Time is the HH:MM:SS event time, as on orders. NextDate is the
+// first date whose order has not been placed yet, empty once the schedule has ended.
+type Subscription struct {
+	gorm.Model
+	UserID     uint
+	PartnerID  uint
+	Buffet     bool
+	Latitude   float64
+	Longtitude float64
+	Rule       string
+	Time       string `gorm:"size:8"`
+	StartDate  string `gorm:"size:10"`
+	EndDate    string `gorm:"size:10"`
+	NextDate   string `gorm:"size:10"`
+	Status     string `gorm:"default:ACTIVE"`
+	Note       string
+	User       User
+	Partner    Partner
+	Items      []SubscriptionItem
+}
+
+type SubscriptionItem struct {
+	SubscriptionID uint `gorm:"primaryKey"`
+	ProductID      uint `gorm:"primaryKey"`
+	Quantity       int
+	Notes          string
+	Product        Product
+}
@@ -0,0 +1,27 @@
+package models
+
+import "gorm.io/gorm"
+
+// TaxRule is a tax the partner charges on its orders, at Rate percent. Inclusive rules are
+// already part of the product prices, exclusive ones are added on top. The shipping cost is
+// only taxed when TaxShipping is set.
+type TaxRule struct {
+	gorm.Model
+	PartnerID   uint   `gorm:"uniqueIndex:idx_tax_rule"`
+	Type        string `gorm:"size:8;uniqueIndex:idx_tax_rule"`
+	Rate        float64
+	Inclusive   bool
+	TaxShipping bool
+}
+
+// TransactionTax is a tax line of an order, computed from the partner's rules when the order
+// is placed. Base is the taxed amount and Amount the tax in it or on top of it.
+type TransactionTax struct {
+	gorm.Model
+	TransactionID uint   `gorm:"index"`
+	Type          string `gorm:"size:8"`
+	Rate          float64
+	Inclusive     bool
+	Base          Money
+	Amount        Money
+}
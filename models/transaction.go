@@ -1,7 +1,6 @@
 package models
 
 import (
-	"errors"
 	"time"
 
 	"gorm.io/gorm"
@@ -17,29 +16,42 @@ type Transaction struct {
 	Latitude float64
 	Longtitude float64
 	Distance float64 `gorm:"default:null"`
-	TotalPrice float64
+	TotalPrice Money
+	ShippingCost Money
+	ShippingSurcharge Money
+	BalanceUsed Money
+	VoucherID uint `gorm:"default:null"`
+	VoucherCode string
+	Discount Money
+	Tax Money
+	SubscriptionID uint `gorm:"default:null"`
 	InvoiceID string
+	PaymentInvoiceID string
 	PaymentUrl string
 	PaymentChannel string
 	PaymentMethod string
 	PaidAt time.Time `gorm:"default:null"`
+	PaidAmount Money
+	GatewayFee Money
+	SettlementStatus string `gorm:"size:16"`
 	Status string `gorm:"default:PENDING"`
 	User User
 	Partner Partner
-	Products []Product `gorm:"many2many:detail_transactions;"`
+	DetailTransactions []DetailTransaction
+	Invoices []TransactionInvoice
+	Taxes []TransactionTax
 }
 
+// DetailTransaction is a single line item of a transaction. Title, Type and
+// Price are copied from the product when the order is placed, so the line
+// item keeps describing what was bought after the product is edited or deleted.
 type DetailTransaction struct {
-  TransactionID  uint `gorm:"primaryKey"`
-  ProductID uint `gorm:"primaryKey"`
-}
-
-func (DetailTransaction) BeforeCreate(db *gorm.DB) error {
-  err := db.SetupJoinTable(&Transaction{}, "Products", &DetailTransaction{})
-
-  if err != nil {
-	  return errors.New(err.Error())
-  }
-
-  return nil
+	TransactionID uint `gorm:"primaryKey"`
+	ProductID uint `gorm:"primaryKey"`
+	Title string
+	Type string
+	Price Money
+	Quantity int
+	Notes string
+	Product Product
 }
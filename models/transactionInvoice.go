@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TransactionInvoice is a payment invoice issued for an order. An order gets a new one, under a
+// new external id, every time the customer asks for a payment link after the last one expired.
+type TransactionInvoice struct {
+	gorm.Model
+	TransactionID    uint   `gorm:"index"`
+	ExternalID       string `gorm:"size:64;uniqueIndex"`
+	PaymentInvoiceID string
+	PaymentUrl       string
+	Amount           Money
+	Status           string    `gorm:"size:16;default:PENDING"`
+	ExpiresAt        time.Time `gorm:"default:null"`
+	Transaction      Transaction
+}
@@ -0,0 +1,13 @@
+package models
+
+import "gorm.io/gorm"
+
+type TransactionStatusHistory struct {
+	gorm.Model
+	TransactionID uint `gorm:"index"`
+	FromStatus    string
+	ToStatus      string
+	ActorID       uint
+	ActorRole     string
+	Reason        string
+}
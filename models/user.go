@@ -10,7 +10,8 @@ type User struct {
 	Password     string
 	Address      string
 	City         string
-	Balance      float64 `gorm:"default:0"`
+	Balance      Money `gorm:"default:0"`
+	PendingBalance Money `gorm:"default:0"`
 	Role         string  `gorm:"default:user"`
 	Partner      Partner
 	Transactions []Transaction
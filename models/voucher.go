@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Voucher is a platform promo code maintained by admins. PERCENTAGE vouchers take
+// Percentage off the order, FLAT vouchers take Amount off and FREE_SHIPPING vouchers
+// cover the shipping up to Amount. A Quota, MaxDiscount, PerUserLimit or free
+// shipping Amount of 0 means no limit. Without Partners or Categories the
+// voucher applies to every order.
+type Voucher struct {
+	gorm.Model
+	Code         string `gorm:"size:32;uniqueIndex"`
+	Type         string
+	Amount       Money
+	Percentage   float64
+	MaxDiscount  Money
+	MinSpend     Money
+	StartAt      time.Time
+	EndAt        time.Time
+	Quota        int
+	Used         int
+	PerUserLimit int
+	Partners     []VoucherPartner
+	Categories   []VoucherCategory
+}
+
+type VoucherPartner struct {
+	VoucherID uint `gorm:"primaryKey"`
+	PartnerID uint `gorm:"primaryKey"`
+}
+
+// VoucherCategory restricts a voucher to products of one type, e.g. snackbox.
+type VoucherCategory struct {
+	VoucherID uint   `gorm:"primaryKey"`
+	Category  string `gorm:"primaryKey;size:32"`
+}
+
+// VoucherUsage is one redemption of a voucher. It is removed again when the
+// order is expired, cancelled or rejected, giving the quota back.
+type VoucherUsage struct {
+	gorm.Model
+	VoucherID     uint
+	UserID        uint
+	TransactionID uint `gorm:"uniqueIndex"`
+	Discount      Money
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WalletTopup is SboxPay balance bought through a payment invoice. ExternalID is the
+// invoice's external id and starts with TOPUP- so callbacks can tell it from orders.
+type WalletTopup struct {
+	gorm.Model
+	UserID           uint
+	ExternalID       string `gorm:"size:64;uniqueIndex"`
+	Amount           Money
+	Status           string `gorm:"size:16;default:PENDING"`
+	PaymentInvoiceID string
+	PaymentUrl       string
+	PaymentMethod    string
+	PaymentChannel   string
+	PaidAt           time.Time `gorm:"default:null"`
+	User             User
+}
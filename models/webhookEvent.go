@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WebhookEvent is a callback as it was received from the payment provider.
+// Source and EventID together identify it, so a redelivery is stored only once.
+type WebhookEvent struct {
+	gorm.Model
+	Source        string    `gorm:"size:32;uniqueIndex:idx_webhook_event"`
+	EventID       string    `gorm:"size:191;uniqueIndex:idx_webhook_event"`
+	Headers       string    `gorm:"type:text"`
+	Body          string    `gorm:"type:text"`
+	Status        string    `gorm:"size:16;index"`
+	Attempts      int
+	Error         string    `gorm:"type:text"`
+	NextAttemptAt time.Time `gorm:"index"`
+	ProcessedAt   time.Time `gorm:"default:null"`
+}
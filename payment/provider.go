@@ -0,0 +1,120 @@
+// Package payment hides the payment gateway behind PaymentProvider so the rest of
+// the application never talks to Xendit directly.
+package payment
+
+import (
+	"time"
+
+	"github.com/furqonzt99/snackbox/models"
+)
+
+// invoice statuses, the same values Xendit sends in its callbacks
+const (
+	INVOICE_PENDING = "PENDING"
+	INVOICE_PAID    = "PAID"
+	INVOICE_EXPIRED = "EXPIRED"
+)
+
+// disbursement statuses
+const (
+	DISBURSEMENT_PENDING   = "PENDING"
+	DISBURSEMENT_COMPLETED = "COMPLETED"
+	DISBURSEMENT_FAILED    = "FAILED"
+)
+
+// settlement statuses of a paid invoice
+const (
+	SETTLEMENT_PENDING       = "PENDING"
+	SETTLEMENT_EARLY_SETTLED = "EARLY_SETTLED"
+	SETTLEMENT_SETTLED       = "SETTLED"
+)
+
+type PaymentProvider interface {
+	CreateInvoice(params CreateInvoiceParams) (Invoice, error)
+	ExpireInvoice(invoiceID string) error
+	GetInvoice(invoiceID string) (Invoice, error)
+	CreateDisbursement(params CreateDisbursementParams) (Disbursement, error)
+	GetDisbursement(externalID string) (Disbursement, error)
+	GetSettlement(externalID string) (Settlement, error)
+	GetAvailableBanks() ([]Bank, error)
+	InquireBankAccount(bankCode, accountNumber string) (BankAccount, error)
+}
+
+type InvoiceItem struct {
+	Name     string       `json:"name"`
+	Price    models.Money `json:"price"`
+	Quantity int          `json:"quantity"`
+	Category string       `json:"category,omitempty"`
+}
+
+// CreateInvoiceParams describes an invoice. Duration is how long the invoice can be paid, in seconds.
+type CreateInvoiceParams struct {
+	ExternalID  string
+	Amount      models.Money
+	Description string
+	PayerEmail  string
+	Items       []InvoiceItem
+	Duration    int
+}
+
+// Invoice is an invoice as the provider reports it. ReceivedAmount is what the provider settles
+// to the platform, the PaidAmount less its fee.
+type Invoice struct {
+	ID             string
+	ExternalID     string
+	URL            string
+	Status         string
+	Amount         models.Money
+	PaidAmount     models.Money
+	ReceivedAmount models.Money
+	PaymentMethod  string
+	PaymentChannel string
+	PaidAt         time.Time
+	ExpiryDate     time.Time
+	Items          []InvoiceItem
+}
+
+type CreateDisbursementParams struct {
+	IdempotencyKey    string
+	ExternalID        string
+	BankCode          string
+	AccountHolderName string
+	AccountNumber     string
+	Description       string
+	Amount            models.Money
+}
+
+// Disbursement is a payout to a bank account. Fee is what the provider charges for it, known
+// once the disbursement completed.
+type Disbursement struct {
+	ID                string
+	ExternalID        string
+	BankCode          string
+	AccountHolderName string
+	AccountNumber     string
+	Amount            models.Money
+	Fee               models.Money
+	Status            string
+}
+
+// Settlement is whether the money of a paid invoice reached the platform's account at the
+// provider yet, no callback reports it.
+type Settlement struct {
+	ExternalID string
+	Status     string
+	Fee        models.Money
+}
+
+type Bank struct {
+	Name            string `json:"name"`
+	Code            string `json:"code"`
+	CanDisburse     bool   `json:"can_disburse"`
+	CanNameValidate bool   `json:"can_name_validate"`
+}
+
+// BankAccount is what the bank reports about an account number.
+type BankAccount struct {
+	BankCode          string
+	AccountNumber     string
+	AccountHolderName string
+}
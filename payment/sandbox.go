@@ -0,0 +1,267 @@
+package payment
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/gommon/log"
+)
+
+var ErrSandboxNotFound = errors.New("sandbox: not found")
+
+// sandboxDisbursementFee is what the sandbox charges for a completed disbursement.
+const sandboxDisbursementFee = 4500
+
+// sandboxBanks is what the sandbox offers as disbursement banks.
+var sandboxBanks = []Bank{
+	{Name: "Bank Central Asia (BCA)", Code: "BCA", CanDisburse: true, CanNameValidate: true},
+	{Name: "Bank Mandiri", Code: "MANDIRI", CanDisburse: true, CanNameValidate: true},
+	{Name: "Bank Negara Indonesia (BNI)", Code: "BNI", CanDisburse: true, CanNameValidate: true},
+	{Name: "Bank Rakyat Indonesia (BRI)", Code: "BRI", CanDisburse: true, CanNameValidate: true},
+}
+
+// SandboxProvider is an in-process PaymentProvider for local development. It pays every
+// invoice and completes every disbursement after delay, then posts the same callbacks Xendit
+// would to callbackURL. Nothing is posted while callbackURL is empty.
+type SandboxProvider struct {
+	callbackURL   string
+	callbackToken string
+	delay         time.Duration
+	client        *http.Client
+
+	mu            sync.Mutex
+	invoices      map[string]Invoice
+	disbursements map[string]Disbursement
+}
+
+func NewSandboxProvider(callbackURL, callbackToken string, delay time.Duration) *SandboxProvider {
+	return &SandboxProvider{
+		callbackURL:   strings.TrimRight(callbackURL, "/"),
+		callbackToken: callbackToken,
+		delay:         delay,
+		client:        &http.Client{Timeout: 10 * time.Second},
+		invoices:      map[string]Invoice{},
+		disbursements: map[string]Disbursement{},
+	}
+}
+
+func (sp *SandboxProvider) CreateInvoice(params CreateInvoiceParams) (Invoice, error) {
+	id := newSandboxID()
+
+	inv := Invoice{
+		ID:         id,
+		ExternalID: params.ExternalID,
+		URL:        "https://sandbox.invalid/invoices/" + id,
+		Status:     INVOICE_PENDING,
+		Amount:     params.Amount,
+		ExpiryDate: time.Now().Add(time.Duration(params.Duration) * time.Second),
+		Items:      params.Items,
+	}
+
+	sp.mu.Lock()
+	sp.invoices[id] = inv
+	sp.mu.Unlock()
+
+	time.AfterFunc(sp.delay, func() { sp.payInvoice(id) })
+
+	return inv, nil
+}
+
+func (sp *SandboxProvider) ExpireInvoice(invoiceID string) error {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+
+	inv, ok := sp.invoices[invoiceID]
+	if !ok {
+		return ErrSandboxNotFound
+	}
+
+	if inv.Status == INVOICE_PAID {
+		return fmt.Errorf("sandbox: invoice %s is already paid", invoiceID)
+	}
+
+	inv.Status = INVOICE_EXPIRED
+	sp.invoices[invoiceID] = inv
+
+	return nil
+}
+
+func (sp *SandboxProvider) GetInvoice(invoiceID string) (Invoice, error) {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+
+	inv, ok := sp.invoices[invoiceID]
+	if !ok {
+		return inv, ErrSandboxNotFound
+	}
+
+	return inv, nil
+}
+
+func (sp *SandboxProvider) CreateDisbursement(params CreateDisbursementParams) (Disbursement, error) {
+	disbursement := Disbursement{
+		ID:                newSandboxID(),
+		ExternalID:        params.ExternalID,
+		BankCode:          params.BankCode,
+		AccountHolderName: params.AccountHolderName,
+		AccountNumber:     params.AccountNumber,
+		Amount:            params.Amount,
+		Status:            DISBURSEMENT_PENDING,
+	}
+
+	sp.mu.Lock()
+	sp.disbursements[disbursement.ID] = disbursement
+	sp.mu.Unlock()
+
+	time.AfterFunc(sp.delay, func() { sp.completeDisbursement(disbursement.ID) })
+
+	return disbursement, nil
+}
+
+func (sp *SandboxProvider) GetDisbursement(externalID string) (Disbursement, error) {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+
+	for _, disbursement := range sp.disbursements {
+		if disbursement.ExternalID == externalID {
+			return disbursement, nil
+		}
+	}
+
+	return Disbursement{}, ErrSandboxNotFound
+}
+
+// GetSettlement settles a paid invoice right away, the sandbox takes no fee on invoices.
+func (sp *SandboxProvider) GetSettlement(externalID string) (Settlement, error) {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+
+	for _, inv := range sp.invoices {
+		if inv.ExternalID != externalID || inv.Status != INVOICE_PAID {
+			continue
+		}
+
+		return Settlement{ExternalID: externalID, Status: SETTLEMENT_SETTLED, Fee: inv.PaidAmount - inv.ReceivedAmount}, nil
+	}
+
+	return Settlement{}, ErrSandboxNotFound
+}
+
+func (sp *SandboxProvider) GetAvailableBanks() ([]Bank, error) {
+	return append([]Bank{}, sandboxBanks...), nil
+}
+
+// InquireBankAccount knows every account number at the sandbox banks and names its holder
+// after it.
+func (sp *SandboxProvider) InquireBankAccount(bankCode, accountNumber string) (BankAccount, error) {
+	for _, bank := range sandboxBanks {
+		if bank.Code == bankCode {
+			return BankAccount{
+				BankCode:          bankCode,
+				AccountNumber:     accountNumber,
+				AccountHolderName: "SANDBOX ACCOUNT " + accountNumber,
+			}, nil
+		}
+	}
+
+	return BankAccount{}, fmt.Errorf("sandbox: unknown bank %s", bankCode)
+}
+
+// payInvoice pays the invoice unless it was expired in the meantime.
+func (sp *SandboxProvider) payInvoice(invoiceID string) {
+	sp.mu.Lock()
+	inv, ok := sp.invoices[invoiceID]
+	if !ok || inv.Status != INVOICE_PENDING {
+		sp.mu.Unlock()
+		return
+	}
+
+	inv.Status = INVOICE_PAID
+	inv.PaidAmount = inv.Amount
+	inv.ReceivedAmount = inv.Amount
+	inv.PaymentMethod = "BANK_TRANSFER"
+	inv.PaymentChannel = "SANDBOX"
+	inv.PaidAt = time.Now().UTC()
+	sp.invoices[invoiceID] = inv
+	sp.mu.Unlock()
+
+	sp.callback("/transactions/callback", map[string]interface{}{
+		"id":                       inv.ID,
+		"external_id":              inv.ExternalID,
+		"status":                   inv.Status,
+		"amount":                   inv.Amount,
+		"paid_amount":              inv.PaidAmount,
+		"adjusted_received_amount": inv.ReceivedAmount,
+		"payment_method":           inv.PaymentMethod,
+		"payment_channel":          inv.PaymentChannel,
+		"paid_at":                  inv.PaidAt.Format(time.RFC3339),
+		"items":                    inv.Items,
+	})
+}
+
+func (sp *SandboxProvider) completeDisbursement(disbursementID string) {
+	sp.mu.Lock()
+	disbursement, ok := sp.disbursements[disbursementID]
+	if !ok || disbursement.Status != DISBURSEMENT_PENDING {
+		sp.mu.Unlock()
+		return
+	}
+
+	disbursement.Status = DISBURSEMENT_COMPLETED
+	disbursement.Fee = sandboxDisbursementFee
+	sp.disbursements[disbursementID] = disbursement
+	sp.mu.Unlock()
+
+	sp.callback("/cashouts/callback", map[string]interface{}{
+		"id":                  disbursement.ID,
+		"external_id":         disbursement.ExternalID,
+		"bank_code":           disbursement.BankCode,
+		"account_holder_name": disbursement.AccountHolderName,
+		"amount":              disbursement.Amount,
+		"fee":                 disbursement.Fee,
+		"status":              disbursement.Status,
+	})
+}
+
+func (sp *SandboxProvider) callback(path string, body interface{}) {
+	if sp.callbackURL == "" {
+		return
+	}
+
+	payload, err := json.Marshal(body)
+	if err != nil {
+		log.Warnf("sandbox: callback %s: %v", path, err)
+		return
+	}
+
+	req, err := http.NewRequest(http.MethodPost, sp.callbackURL+path, bytes.NewReader(payload))
+	if err != nil {
+		log.Warnf("sandbox: callback %s: %v", path, err)
+		return
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Callback-Token", sp.callbackToken)
+
+	resp, err := sp.client.Do(req)
+	if err != nil {
+		log.Warnf("sandbox: callback %s: %v", path, err)
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		log.Warnf("sandbox: callback %s answered %s", path, resp.Status)
+	}
+}
+
+func newSandboxID() string {
+	return strings.ReplaceAll(uuid.New().String(), "-", "")
+}
@@ -0,0 +1,138 @@
+package payment_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/furqonzt99/snackbox/models"
+	"github.com/furqonzt99/snackbox/payment"
+	"github.com/stretchr/testify/assert"
+)
+
+type callback struct {
+	Path  string
+	Token string
+	Body  map[string]interface{}
+}
+
+func newCallbackServer(t *testing.T) (*httptest.Server, chan callback) {
+	callbacks := make(chan callback, 4)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := map[string]interface{}{}
+		json.NewDecoder(r.Body).Decode(&body)
+
+		callbacks <- callback{Path: r.URL.Path, Token: r.Header.Get("X-Callback-Token"), Body: body}
+	}))
+	t.Cleanup(server.Close)
+
+	return server, callbacks
+}
+
+func waitCallback(t *testing.T, callbacks chan callback) callback {
+	select {
+	case cb := <-callbacks:
+		return cb
+	case <-time.After(2 * time.Second):
+		t.Fatal("no callback received")
+		return callback{}
+	}
+}
+
+func TestSandboxInvoice(t *testing.T) {
+	server, callbacks := newCallbackServer(t)
+	provider := payment.NewSandboxProvider(server.URL, "secret", 10*time.Millisecond)
+
+	t.Run("pays the invoice and calls back", func(t *testing.T) {
+		inv, err := provider.CreateInvoice(payment.CreateInvoiceParams{
+			ExternalID: "INV-1",
+			Amount:     150000,
+			Items:      []payment.InvoiceItem{{Name: "Snack Box", Price: 150000, Quantity: 1}},
+			Duration:   3600,
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, payment.INVOICE_PENDING, inv.Status)
+		assert.NotEmpty(t, inv.URL)
+
+		cb := waitCallback(t, callbacks)
+		assert.Equal(t, "/transactions/callback", cb.Path)
+		assert.Equal(t, "secret", cb.Token)
+		assert.Equal(t, "INV-1", cb.Body["external_id"])
+		assert.Equal(t, payment.INVOICE_PAID, cb.Body["status"])
+
+		paid, err := provider.GetInvoice(inv.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, payment.INVOICE_PAID, paid.Status)
+		assert.Equal(t, models.Money(150000), paid.PaidAmount)
+
+		settlement, err := provider.GetSettlement("INV-1")
+		assert.Nil(t, err)
+		assert.Equal(t, payment.SETTLEMENT_SETTLED, settlement.Status)
+	})
+
+	t.Run("does not pay an expired invoice", func(t *testing.T) {
+		slow := payment.NewSandboxProvider(server.URL, "secret", 50*time.Millisecond)
+
+		inv, _ := slow.CreateInvoice(payment.CreateInvoiceParams{ExternalID: "INV-2", Amount: 1000})
+		assert.Nil(t, slow.ExpireInvoice(inv.ID))
+
+		time.Sleep(100 * time.Millisecond)
+
+		expired, _ := slow.GetInvoice(inv.ID)
+		assert.Equal(t, payment.INVOICE_EXPIRED, expired.Status)
+		assert.Equal(t, 0, len(callbacks))
+	})
+
+	t.Run("unknown invoice", func(t *testing.T) {
+		_, err := provider.GetInvoice("unknown")
+		assert.Equal(t, payment.ErrSandboxNotFound, err)
+		assert.Equal(t, payment.ErrSandboxNotFound, provider.ExpireInvoice("unknown"))
+
+		_, err = provider.GetSettlement("unknown")
+		assert.Equal(t, payment.ErrSandboxNotFound, err)
+	})
+}
+
+func TestSandboxDisbursement(t *testing.T) {
+	server, callbacks := newCallbackServer(t)
+	provider := payment.NewSandboxProvider(server.URL, "secret", 10*time.Millisecond)
+
+	disbursement, err := provider.CreateDisbursement(payment.CreateDisbursementParams{
+		ExternalID:        "CASHOUT-1",
+		BankCode:          "BCA",
+		AccountHolderName: "Budi",
+		AccountNumber:     "1234567890",
+		Amount:            50000,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, payment.DISBURSEMENT_PENDING, disbursement.Status)
+
+	cb := waitCallback(t, callbacks)
+	assert.Equal(t, "/cashouts/callback", cb.Path)
+	assert.Equal(t, "CASHOUT-1", cb.Body["external_id"])
+	assert.Equal(t, payment.DISBURSEMENT_COMPLETED, cb.Body["status"])
+	assert.Equal(t, float64(50000), cb.Body["amount"])
+	assert.Equal(t, float64(4500), cb.Body["fee"])
+
+	disbursement, err = provider.GetDisbursement("CASHOUT-1")
+	assert.Nil(t, err)
+	assert.Equal(t, payment.DISBURSEMENT_COMPLETED, disbursement.Status)
+	assert.Equal(t, models.Money(4500), disbursement.Fee)
+
+	_, err = provider.GetDisbursement("CASHOUT-2")
+	assert.Equal(t, payment.ErrSandboxNotFound, err)
+
+	banks, err := provider.GetAvailableBanks()
+	assert.Nil(t, err)
+	assert.NotEmpty(t, banks)
+
+	account, err := provider.InquireBankAccount("BCA", "1234567890")
+	assert.Nil(t, err)
+	assert.Equal(t, "SANDBOX ACCOUNT 1234567890", account.AccountHolderName)
+
+	_, err = provider.InquireBankAccount("UNKNOWN", "1234567890")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,262 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/furqonzt99/snackbox/models"
+	"github.com/xendit/xendit-go"
+	"github.com/xendit/xendit-go/client"
+	"github.com/xendit/xendit-go/disbursement"
+	"github.com/xendit/xendit-go/invoice"
+)
+
+type XenditProvider struct {
+	api *client.API
+}
+
+func NewXenditProvider(secretKey string) *XenditProvider {
+	return &XenditProvider{api: client.New(secretKey)}
+}
+
+func (xp *XenditProvider) CreateInvoice(params CreateInvoiceParams) (Invoice, error) {
+	items := []xendit.InvoiceItem{}
+	for _, item := range params.Items {
+		items = append(items, xendit.InvoiceItem{
+			Name:     item.Name,
+			Price:    item.Price.Float64(),
+			Quantity: item.Quantity,
+			Category: item.Category,
+		})
+	}
+
+	resp, err := xp.api.Invoice.Create(&invoice.CreateParams{
+		ExternalID:      params.ExternalID,
+		Amount:          params.Amount.Float64(),
+		Description:     params.Description,
+		PayerEmail:      params.PayerEmail,
+		Items:           items,
+		InvoiceDuration: params.Duration,
+	})
+	if err != nil {
+		return Invoice{}, err
+	}
+
+	return newXenditInvoice(resp), nil
+}
+
+func (xp *XenditProvider) ExpireInvoice(invoiceID string) error {
+	_, err := xp.api.Invoice.Expire(&invoice.ExpireParams{ID: invoiceID})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (xp *XenditProvider) GetInvoice(invoiceID string) (Invoice, error) {
+	resp, err := xp.api.Invoice.Get(&invoice.GetParams{ID: invoiceID})
+	if err != nil {
+		return Invoice{}, err
+	}
+
+	return newXenditInvoice(resp), nil
+}
+
+func (xp *XenditProvider) CreateDisbursement(params CreateDisbursementParams) (Disbursement, error) {
+	resp, err := xp.api.Disbursement.Create(&disbursement.CreateParams{
+		IdempotencyKey:    params.IdempotencyKey,
+		ExternalID:        params.ExternalID,
+		BankCode:          params.BankCode,
+		AccountHolderName: params.AccountHolderName,
+		AccountNumber:     params.AccountNumber,
+		Description:       params.Description,
+		Amount:            params.Amount.Float64(),
+	})
+	if err != nil {
+		return Disbursement{}, err
+	}
+
+	return Disbursement{
+		ID:                resp.ID,
+		ExternalID:        resp.ExternalID,
+		BankCode:          resp.BankCode,
+		AccountHolderName: resp.AccountHolderName,
+		AccountNumber:     params.AccountNumber,
+		Amount:            models.NewMoney(resp.Amount),
+		Status:            resp.Status,
+	}, nil
+}
+
+// GetDisbursement looks the disbursement up by the external id it was created with.
+func (xp *XenditProvider) GetDisbursement(externalID string) (Disbursement, error) {
+	resp, err := xp.api.Disbursement.GetByExternalID(&disbursement.GetByExternalIDParams{ExternalID: externalID})
+	if err != nil {
+		return Disbursement{}, err
+	}
+
+	if len(resp) == 0 {
+		return Disbursement{}, fmt.Errorf("xendit: no disbursement with external id %s", externalID)
+	}
+
+	// the external id is unique to each cashout, so there is one disbursement at most
+	found := Disbursement{
+		ID:                resp[0].ID,
+		ExternalID:        resp[0].ExternalID,
+		BankCode:          resp[0].BankCode,
+		AccountHolderName: resp[0].AccountHolderName,
+		Amount:            models.NewMoney(resp[0].Amount),
+		Status:            resp[0].Status,
+	}
+
+	// the disbursement API does not report the fee, the transaction of a completed one does
+	if found.Status == DISBURSEMENT_COMPLETED {
+		transaction, err := xp.findTransaction(externalID)
+		if err != nil {
+			return Disbursement{}, err
+		}
+
+		found.Fee = transaction.fee()
+	}
+
+	return found, nil
+}
+
+// GetSettlement looks the settlement of a paid invoice up in Xendit's transactions API.
+func (xp *XenditProvider) GetSettlement(externalID string) (Settlement, error) {
+	transaction, err := xp.findTransaction(externalID)
+	if err != nil {
+		return Settlement{}, err
+	}
+
+	return Settlement{
+		ExternalID: externalID,
+		Status:     transaction.SettlementStatus,
+		Fee:        transaction.fee(),
+	}, nil
+}
+
+func (xp *XenditProvider) GetAvailableBanks() ([]Bank, error) {
+	resp, err := xp.api.Disbursement.GetAvailableBanks()
+	if err != nil {
+		return nil, err
+	}
+
+	banks := []Bank{}
+	for _, bank := range resp {
+		banks = append(banks, Bank{
+			Name:            bank.Name,
+			Code:            bank.Code,
+			CanDisburse:     bank.CanDisburse,
+			CanNameValidate: bank.CanNameValidate,
+		})
+	}
+
+	return banks, nil
+}
+
+// InquireBankAccount asks the bank for the holder of the account through Xendit's name
+// validator, which xendit-go does not cover.
+func (xp *XenditProvider) InquireBankAccount(bankCode, accountNumber string) (BankAccount, error) {
+	resp := struct {
+		BankAccountHolderName string `json:"bank_account_holder_name"`
+	}{}
+
+	err := xp.api.Disbursement.APIRequester.Call(
+		context.Background(),
+		http.MethodPost,
+		xp.api.Disbursement.Opt.XenditURL+"/bank_account_data_requests",
+		xp.api.Disbursement.Opt.SecretKey,
+		&http.Header{},
+		map[string]string{"bank_code": bankCode, "bank_account_number": accountNumber},
+		&resp,
+	)
+	if err != nil {
+		return BankAccount{}, err
+	}
+
+	return BankAccount{
+		BankCode:          bankCode,
+		AccountNumber:     accountNumber,
+		AccountHolderName: resp.BankAccountHolderName,
+	}, nil
+}
+
+// xenditTransaction is a record of Xendit's transactions API, where every payment and
+// disbursement ends up with the fee Xendit took for it.
+type xenditTransaction struct {
+	ReferenceID      string `json:"reference_id"`
+	Status           string `json:"status"`
+	SettlementStatus string `json:"settlement_status"`
+	Fee              struct {
+		XenditFee     float64 `json:"xendit_fee"`
+		ValueAddedTax float64 `json:"value_added_tax"`
+	} `json:"fee"`
+}
+
+// fee is what Xendit charged, its value added tax included.
+func (t xenditTransaction) fee() models.Money {
+	return models.NewMoney(t.Fee.XenditFee + t.Fee.ValueAddedTax)
+}
+
+// findTransaction looks the transaction of a payment or a disbursement up by its external id.
+// xendit-go decodes these records without the settlement status, so the API is called directly.
+func (xp *XenditProvider) findTransaction(referenceID string) (xenditTransaction, error) {
+	resp := struct {
+		Data []xenditTransaction `json:"data"`
+	}{}
+
+	err := xp.api.Disbursement.APIRequester.Call(
+		context.Background(),
+		http.MethodGet,
+		xp.api.Disbursement.Opt.XenditURL+"/transactions?reference_id="+url.QueryEscape(referenceID),
+		xp.api.Disbursement.Opt.SecretKey,
+		&http.Header{},
+		nil,
+		&resp,
+	)
+	if err != nil {
+		return xenditTransaction{}, err
+	}
+
+	if len(resp.Data) == 0 {
+		return xenditTransaction{}, fmt.Errorf("xendit: no transaction with reference id %s", referenceID)
+	}
+
+	return resp.Data[0], nil
+}
+
+func newXenditInvoice(resp *xendit.Invoice) Invoice {
+	inv := Invoice{
+		ID:             resp.ID,
+		ExternalID:     resp.ExternalID,
+		URL:            resp.InvoiceURL,
+		Status:         resp.Status,
+		Amount:         models.NewMoney(resp.Amount),
+		PaidAmount:     models.NewMoney(resp.PaidAmount),
+		ReceivedAmount: models.NewMoney(resp.AdjustedReceivedAmount),
+		PaymentMethod:  resp.PaymentMethod,
+		PaymentChannel: resp.PaymentChannel,
+	}
+
+	if resp.PaidAt != nil {
+		inv.PaidAt = *resp.PaidAt
+	}
+
+	if resp.ExpiryDate != nil {
+		inv.ExpiryDate = *resp.ExpiryDate
+	}
+
+	for _, item := range resp.Items {
+		inv.Items = append(inv.Items, InvoiceItem{
+			Name:     item.Name,
+			Price:    models.NewMoney(item.Price),
+			Quantity: item.Quantity,
+			Category: item.Category,
+		})
+	}
+
+	return inv
+}
@@ -1,28 +1,248 @@
 package bank
 
 import (
-	"github.com/xendit/xendit-go"
-	"github.com/xendit/xendit-go/disbursement"
+	"github.com/furqonzt99/snackbox/helper"
+	"github.com/furqonzt99/snackbox/models"
+	"github.com/furqonzt99/snackbox/payment"
 	"gorm.io/gorm"
 )
 
 type BankInterface interface {
-	GetAvailableBanks() ([]xendit.DisbursementBank, error)
+	GetAvailableBanks() ([]payment.Bank, error)
+	CreateAccount(account models.BankAccount) (models.BankAccount, error)
+	GetAccounts(userID int) ([]models.BankAccount, error)
+	GetAccount(accountID, userID int) (models.BankAccount, error)
+	UpdateAccount(accountID, userID int, account models.BankAccount) (models.BankAccount, error)
+	DeleteAccount(accountID, userID int) error
+	SetDefault(accountID, userID int) (models.BankAccount, error)
 }
 
 type BankRepository struct {
-	db *gorm.DB
+	db       *gorm.DB
+	provider payment.PaymentProvider
 }
 
-func NewBankRepository(db *gorm.DB) *BankRepository {
-	return &BankRepository{db: db}
+func NewBankRepository(db *gorm.DB, provider payment.PaymentProvider) *BankRepository {
+	return &BankRepository{db: db, provider: provider}
 }
 
-func (br *BankRepository) GetAvailableBanks() ([]xendit.DisbursementBank, error) {
-	availableBanks, err := disbursement.GetAvailableBanks()
+func (br *BankRepository) GetAvailableBanks() ([]payment.Bank, error) {
+	availableBanks, err := br.provider.GetAvailableBanks()
 	if err != nil {
 		return availableBanks, err
 	}
 
 	return availableBanks, nil
 }
+
+// CreateAccount verifies and saves a payout account. The first account of a user becomes the
+// default one.
+func (br *BankRepository) CreateAccount(account models.BankAccount) (models.BankAccount, error) {
+	if err := br.verify(&account); err != nil {
+		return account, err
+	}
+
+	err := br.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkDuplicateAccount(tx, account); err != nil {
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&models.BankAccount{}).Where("user_id = ?", account.UserID).Count(&count).Error; err != nil {
+			return err
+		}
+
+		if count == 0 {
+			account.IsDefault = true
+		}
+
+		if account.IsDefault {
+			if err := clearDefault(tx, account.UserID); err != nil {
+				return err
+			}
+		}
+
+		return tx.Create(&account).Error
+	})
+
+	if err != nil {
+		return account, err
+	}
+
+	return account, nil
+}
+
+// GetAccounts lists the user's accounts, the default one first.
+func (br *BankRepository) GetAccounts(userID int) ([]models.BankAccount, error) {
+	accounts := []models.BankAccount{}
+
+	if err := br.db.Where("user_id = ?", userID).Order("is_default desc, id").Find(&accounts).Error; err != nil {
+		return nil, err
+	}
+
+	return accounts, nil
+}
+
+func (br *BankRepository) GetAccount(accountID, userID int) (models.BankAccount, error) {
+	account := models.BankAccount{}
+
+	if err := br.db.Where("id = ? AND user_id = ?", accountID, userID).First(&account).Error; err != nil {
+		return account, err
+	}
+
+	return account, nil
+}
+
+// UpdateAccount replaces the bank details of the account and verifies them again. The account
+// becomes the default one when asked to; a default account stays default until another one
+// takes its place.
+func (br *BankRepository) UpdateAccount(accountID, userID int, account models.BankAccount) (models.BankAccount, error) {
+	accountDB, err := br.GetAccount(accountID, userID)
+	if err != nil {
+		return accountDB, err
+	}
+
+	account.Model = accountDB.Model
+	account.UserID = accountDB.UserID
+	account.IsDefault = account.IsDefault || accountDB.IsDefault
+
+	if err := br.verify(&account); err != nil {
+		return accountDB, err
+	}
+
+	err = br.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkDuplicateAccount(tx, account); err != nil {
+			return err
+		}
+
+		if account.IsDefault && !accountDB.IsDefault {
+			if err := clearDefault(tx, account.UserID); err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&accountDB).
+			Select("bank_code", "bank_name", "account_holder_name", "account_number", "inquired_name", "name_matched", "is_default").
+			Updates(account).Error
+	})
+
+	if err != nil {
+		return accountDB, err
+	}
+
+	return account, nil
+}
+
+// DeleteAccount removes the account for good. When it was the default one, the oldest
+// remaining account takes over.
+func (br *BankRepository) DeleteAccount(accountID, userID int) error {
+	account, err := br.GetAccount(accountID, userID)
+	if err != nil {
+		return err
+	}
+
+	return br.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Delete(&account).Error; err != nil {
+			return err
+		}
+
+		if !account.IsDefault {
+			return nil
+		}
+
+		next := models.BankAccount{}
+		err := tx.Where("user_id = ?", account.UserID).Order("id").First(&next).Error
+		if err == gorm.ErrRecordNotFound {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&next).Update("is_default", true).Error
+	})
+}
+
+func (br *BankRepository) SetDefault(accountID, userID int) (models.BankAccount, error) {
+	account, err := br.GetAccount(accountID, userID)
+	if err != nil {
+		return account, err
+	}
+
+	err = br.db.Transaction(func(tx *gorm.DB) error {
+		if err := clearDefault(tx, account.UserID); err != nil {
+			return err
+		}
+
+		account.IsDefault = true
+
+		return tx.Model(&account).Update("is_default", true).Error
+	})
+
+	if err != nil {
+		return account, err
+	}
+
+	return account, nil
+}
+
+// verify checks the bank code against the banks the provider can disburse to and asks the bank
+// for the account holder where it tells. A failed inquiry does not stop the account from being
+// saved, it stays unverified.
+func (br *BankRepository) verify(account *models.BankAccount) error {
+	banks, err := br.provider.GetAvailableBanks()
+	if err != nil {
+		return err
+	}
+
+	var bank *payment.Bank
+	for i := range banks {
+		if banks[i].Code == account.BankCode && banks[i].CanDisburse {
+			bank = &banks[i]
+			break
+		}
+	}
+
+	if bank == nil {
+		return helper.BankAccountError("the bank " + account.BankCode + " is not available for cashouts")
+	}
+
+	account.BankName = bank.Name
+	account.InquiredName = ""
+	account.NameMatched = false
+
+	if !bank.CanNameValidate {
+		return nil
+	}
+
+	inquiry, err := br.provider.InquireBankAccount(account.BankCode, account.AccountNumber)
+	if err != nil {
+		return nil
+	}
+
+	account.InquiredName = inquiry.AccountHolderName
+	account.NameMatched = helper.SameHolderName(inquiry.AccountHolderName, account.AccountHolderName)
+
+	return nil
+}
+
+func checkDuplicateAccount(tx *gorm.DB, account models.BankAccount) error {
+	var count int64
+
+	err := tx.Model(&models.BankAccount{}).
+		Where("user_id = ? AND bank_code = ? AND account_number = ? AND id <> ?", account.UserID, account.BankCode, account.AccountNumber, account.ID).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return helper.BankAccountError("the bank account is already saved")
+	}
+
+	return nil
+}
+
+func clearDefault(tx *gorm.DB, userID uint) error {
+	return tx.Model(&models.BankAccount{}).Where("user_id = ? AND is_default = ?", userID, true).Update("is_default", false).Error
+}
@@ -1,27 +1,32 @@
 package bank_test
 
 import (
+	"errors"
 	"log"
 	"os"
 	"testing"
+	"time"
 
+	config "github.com/furqonzt99/snackbox/configs"
+	"github.com/furqonzt99/snackbox/helper"
+	"github.com/furqonzt99/snackbox/models"
+	"github.com/furqonzt99/snackbox/payment"
 	"github.com/furqonzt99/snackbox/repositories/bank"
+	"github.com/furqonzt99/snackbox/utils"
 	"github.com/joho/godotenv"
 	"github.com/stretchr/testify/assert"
-	"github.com/xendit/xendit-go"
 	"gorm.io/gorm"
 )
 
 func TestBank(t *testing.T) {
 	var db *gorm.DB
-	bankRepo := bank.NewBankRepository(db)
 
 	err := godotenv.Load()
 
 	if err != nil {
 		log.Fatal("Error loading .env file")
 	}
-	xendit.Opt.SecretKey = os.Getenv("XENDIT_SECRET_KEY")
+	bankRepo := bank.NewBankRepository(db, payment.NewXenditProvider(os.Getenv("XENDIT_SECRET_KEY")))
 
 	t.Run("GetAvailableBanks", func(t *testing.T) {
 
@@ -31,13 +36,13 @@ func TestBank(t *testing.T) {
 }
 
 func TestBankFailed(t *testing.T) {
-	var bankRepositoryFail *bank.BankRepository
+	var db *gorm.DB
 	err := godotenv.Load("./failedTest/.env")
 
 	if err != nil {
 		log.Fatal("Error loading .env file")
 	}
-	xendit.Opt.SecretKey = os.Getenv("XENDIT_SECRET_KEY_FAILED")
+	bankRepositoryFail := bank.NewBankRepository(db, payment.NewXenditProvider(os.Getenv("XENDIT_SECRET_KEY_FAILED")))
 
 	t.Run("GetAvailableBanksFialed", func(t *testing.T) {
 
@@ -45,3 +50,68 @@ func TestBankFailed(t *testing.T) {
 		assert.NotNil(t, err)
 	})
 }
+
+func TestBankAccount(t *testing.T) {
+	db := utils.InitDB(config.GetConfig())
+
+	db.Migrator().DropTable(&models.BankAccount{})
+	db.AutoMigrate(&models.BankAccount{})
+
+	bankRepo := bank.NewBankRepository(db, payment.NewSandboxProvider("", "", time.Hour))
+
+	t.Run("first account becomes the default", func(t *testing.T) {
+		res, err := bankRepo.CreateAccount(models.BankAccount{UserID: 1, BankCode: "BCA", AccountHolderName: "sandbox account 111", AccountNumber: "111"})
+		assert.Nil(t, err)
+		assert.True(t, res.IsDefault)
+		assert.True(t, res.NameMatched)
+		assert.Equal(t, "Bank Central Asia (BCA)", res.BankName)
+	})
+
+	t.Run("holder name mismatch", func(t *testing.T) {
+		res, err := bankRepo.CreateAccount(models.BankAccount{UserID: 1, BankCode: "BNI", AccountHolderName: "someone", AccountNumber: "222"})
+		assert.Nil(t, err)
+		assert.False(t, res.IsDefault)
+		assert.False(t, res.NameMatched)
+		assert.Equal(t, "SANDBOX ACCOUNT 222", res.InquiredName)
+	})
+
+	t.Run("unknown bank", func(t *testing.T) {
+		_, err := bankRepo.CreateAccount(models.BankAccount{UserID: 1, BankCode: "UNKNOWN", AccountHolderName: "someone", AccountNumber: "333"})
+		assert.True(t, errors.Is(err, helper.ErrInvalidBankAccount))
+	})
+
+	t.Run("duplicate account", func(t *testing.T) {
+		_, err := bankRepo.CreateAccount(models.BankAccount{UserID: 1, BankCode: "BCA", AccountHolderName: "someone", AccountNumber: "111"})
+		assert.True(t, errors.Is(err, helper.ErrInvalidBankAccount))
+	})
+
+	t.Run("set default", func(t *testing.T) {
+		res, err := bankRepo.SetDefault(2, 1)
+		assert.Nil(t, err)
+		assert.True(t, res.IsDefault)
+
+		accounts, _ := bankRepo.GetAccounts(1)
+		assert.Equal(t, uint(2), accounts[0].ID)
+		assert.False(t, accounts[1].IsDefault)
+	})
+
+	t.Run("update account", func(t *testing.T) {
+		res, err := bankRepo.UpdateAccount(2, 1, models.BankAccount{BankCode: "BNI", AccountHolderName: "Sandbox  Account 222", AccountNumber: "222"})
+		assert.Nil(t, err)
+		assert.True(t, res.IsDefault)
+		assert.True(t, res.NameMatched)
+	})
+
+	t.Run("other user's account", func(t *testing.T) {
+		_, err := bankRepo.GetAccount(1, 2)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("delete the default account", func(t *testing.T) {
+		assert.Nil(t, bankRepo.DeleteAccount(2, 1))
+
+		res, err := bankRepo.GetAccount(1, 1)
+		assert.Nil(t, err)
+		assert.True(t, res.IsDefault)
+	})
+}
@@ -0,0 +1,96 @@
+package cart
+
+import (
+	"github.com/furqonzt99/snackbox/models"
+	"gorm.io/gorm"
+)
+
+type CartInterface interface {
+	GetCart(userID int) ([]models.CartItem, error)
+	AddItem(item models.CartItem) (models.CartItem, error)
+	UpdateItem(itemID, userID int, item models.CartItem) (models.CartItem, error)
+	RemoveItem(itemID, userID int) error
+	GetProduct(productID int) (models.Product, error)
+}
+
+type CartRepository struct {
+	db *gorm.DB
+}
+
+func NewCartRepository(db *gorm.DB) *CartRepository {
+	return &CartRepository{db: db}
+}
+
+func (cr *CartRepository) GetCart(userID int) ([]models.CartItem, error) {
+	items := []models.CartItem{}
+
+	if err := cr.db.Preload("Product").Preload("Partner").Order("partner_id, id").Where("user_id = ?", userID).Find(&items).Error; err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// AddItem puts a product in the user's cart, adding up the quantity when the product is already there.
+func (cr *CartRepository) AddItem(item models.CartItem) (models.CartItem, error) {
+	var itemDB models.CartItem
+
+	err := cr.db.Where("user_id = ? AND product_id = ?", item.UserID, item.ProductID).First(&itemDB).Error
+
+	if err == gorm.ErrRecordNotFound {
+		if err := cr.db.Create(&item).Error; err != nil {
+			return item, err
+		}
+
+		return item, nil
+	}
+
+	if err != nil {
+		return item, err
+	}
+
+	itemDB.Quantity += item.Quantity
+	if item.Notes != "" {
+		itemDB.Notes = item.Notes
+	}
+
+	if err := cr.db.Save(&itemDB).Error; err != nil {
+		return itemDB, err
+	}
+
+	return itemDB, nil
+}
+
+func (cr *CartRepository) UpdateItem(itemID, userID int, item models.CartItem) (models.CartItem, error) {
+	var itemDB models.CartItem
+
+	if err := cr.db.Where("user_id = ?", userID).First(&itemDB, itemID).Error; err != nil {
+		return itemDB, err
+	}
+
+	if err := cr.db.Model(&itemDB).Updates(map[string]interface{}{"quantity": item.Quantity, "notes": item.Notes}).Error; err != nil {
+		return itemDB, err
+	}
+
+	return itemDB, nil
+}
+
+func (cr *CartRepository) RemoveItem(itemID, userID int) error {
+	var itemDB models.CartItem
+
+	if err := cr.db.Where("user_id = ?", userID).First(&itemDB, itemID).Error; err != nil {
+		return err
+	}
+
+	return cr.db.Unscoped().Delete(&itemDB).Error
+}
+
+func (cr *CartRepository) GetProduct(productID int) (models.Product, error) {
+	var product models.Product
+
+	if err := cr.db.First(&product, productID).Error; err != nil {
+		return product, err
+	}
+
+	return product, nil
+}
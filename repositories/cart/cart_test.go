@@ -0,0 +1,101 @@
+package cart_test
+
+import (
+	"testing"
+
+	config "github.com/furqonzt99/snackbox/configs"
+	"github.com/furqonzt99/snackbox/models"
+	"github.com/furqonzt99/snackbox/repositories/cart"
+	"github.com/furqonzt99/snackbox/utils"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+var configTest *config.AppConfig
+var db *gorm.DB
+var cartRepo *cart.CartRepository
+
+func TestCart(t *testing.T) {
+	configTest = config.GetConfig()
+	db = utils.InitDB(configTest)
+
+	db.Migrator().DropTable(&models.User{})
+	db.Migrator().DropTable(&models.Partner{})
+	db.Migrator().DropTable(&models.Product{})
+	db.Migrator().DropTable(&models.CartItem{})
+
+	cartRepo = cart.NewCartRepository(db)
+
+	db.AutoMigrate(&models.User{})
+	db.AutoMigrate(&models.Partner{})
+	db.AutoMigrate(&models.Product{})
+	db.AutoMigrate(&models.CartItem{})
+
+	//CREATE USER
+	dummyUser := models.User{
+		Email:    "test@gmail.com",
+		Password: "test1234",
+	}
+	db.Create(&dummyUser)
+
+	//CREATE PARTNER
+	dummyPartner := models.Partner{
+		UserID:        1,
+		BussinessName: "partner1",
+		Status:        "active",
+	}
+	db.Create(&dummyPartner)
+
+	//CREATE PRODUCT
+	dummyProduct := models.Product{
+		PartnerID: 1,
+		Title:     "rendang",
+		Type:      "ricebox",
+		Price:     1000,
+	}
+	db.Create(&dummyProduct)
+
+	t.Run("add item", func(t *testing.T) {
+		res, err := cartRepo.AddItem(models.CartItem{UserID: 1, PartnerID: 1, ProductID: 1, Quantity: 10})
+		assert.Nil(t, err)
+		assert.Equal(t, 10, res.Quantity)
+	})
+
+	t.Run("add same item sums quantity", func(t *testing.T) {
+		res, err := cartRepo.AddItem(models.CartItem{UserID: 1, PartnerID: 1, ProductID: 1, Quantity: 5, Notes: "no chili"})
+		assert.Nil(t, err)
+		assert.Equal(t, 15, res.Quantity)
+		assert.Equal(t, "no chili", res.Notes)
+	})
+
+	t.Run("get cart", func(t *testing.T) {
+		res, err := cartRepo.GetCart(1)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(res))
+		assert.Equal(t, "rendang", res[0].Product.Title)
+	})
+
+	t.Run("update item", func(t *testing.T) {
+		res, err := cartRepo.UpdateItem(1, 1, models.CartItem{Quantity: 20})
+		assert.Nil(t, err)
+		assert.Equal(t, 20, res.Quantity)
+	})
+
+	t.Run("update item of another user", func(t *testing.T) {
+		_, err := cartRepo.UpdateItem(1, 2, models.CartItem{Quantity: 20})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("remove item", func(t *testing.T) {
+		err := cartRepo.RemoveItem(1, 1)
+		assert.Nil(t, err)
+
+		res, _ := cartRepo.GetCart(1)
+		assert.Equal(t, 0, len(res))
+	})
+
+	t.Run("remove item not found", func(t *testing.T) {
+		err := cartRepo.RemoveItem(1, 1)
+		assert.NotNil(t, err)
+	})
+}
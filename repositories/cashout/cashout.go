@@ -1,8 +1,15 @@
 package cashout
 
 import (
+	"errors"
+	"time"
+
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/helper"
 	"github.com/furqonzt99/snackbox/models"
+	"github.com/furqonzt99/snackbox/payment"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type CashoutInterface interface {
@@ -11,37 +18,213 @@ type CashoutInterface interface {
 	CheckBalance(userID int) (models.User, error)
 	CallbackSuccess(extID string, cashout models.Cashout) (models.Cashout, error)
 	CallbackFailed(extID string, cashout models.Cashout) (models.Cashout, error)
+	GetAll(status string) ([]models.Cashout, error)
+	Approve(cashoutID int) (models.Cashout, error)
+	Reject(cashoutID int, note string) (models.Cashout, error)
 }
 
 type CashoutRepository struct {
-	db *gorm.DB
+	db       *gorm.DB
+	provider payment.PaymentProvider
 }
 
-func NewCashoutRepository(db *gorm.DB) *CashoutRepository {
-	return &CashoutRepository{db: db}
+func NewCashoutRepository(db *gorm.DB, provider payment.PaymentProvider) *CashoutRepository {
+	return &CashoutRepository{db: db, provider: provider}
 }
 
+// Cashout takes the amount and the cashout fee off the balance and pays it out to the saved bank
+// account, the user's default one when no BankAccountID is given. Up to CASHOUT_APPROVAL_THRESHOLD
+// the cashout is stored as PENDING and then sent to the bank account through the payment
+// provider, a larger one waits in REQUESTED status for an admin. When the provider refuses the
+// disbursement, the amount and the fee are given back and the cashout is FAILED. When the call
+// failed but the provider has the disbursement anyway, the cashout stays PENDING for its callback.
 func (cr *CashoutRepository) Cashout(cashout models.Cashout) (models.Cashout, error) {
-	var user models.User
+	err := cr.db.Transaction(func(tx *gorm.DB) error {
+		// one cashout of a user at a time, so two of them cannot both squeeze into the limits
+		user := models.User{}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, cashout.UserID).Error; err != nil {
+			return err
+		}
+
+		if err := helper.CheckCashoutLimits(tx, cashout.UserID, cashout.Amount, time.Now()); err != nil {
+			return err
+		}
+
+		account := models.BankAccount{}
+		query := tx.Where("user_id = ?", cashout.UserID)
+		if cashout.BankAccountID != 0 {
+			query = query.Where("id = ?", cashout.BankAccountID)
+		} else {
+			query = query.Where("is_default = ?", true)
+		}
+
+		err := query.First(&account).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return helper.CashoutError("the bank account was not found")
+		}
+		if err != nil {
+			return err
+		}
+
+		cashout.BankAccountID = account.ID
+		cashout.BankCode = account.BankCode
+		cashout.AccountHolderName = account.AccountHolderName
+		cashout.AccountNumber = account.AccountNumber
 
-	var err error
+		cashout.Fee = models.Money(constants.CASHOUT_FEE)
+		cashout.Status = constants.CASHOUT_REQUESTED
 
-	cr.db.Transaction(func(tx *gorm.DB) error {
-		if err = tx.Create(&cashout).Error; err != nil {
+		if !helper.NeedsCashoutApproval(cashout.Amount) {
+			cashout = helper.PrepareCashout(cashout)
+			cashout.Status = constants.CASHOUT_PENDING
+		}
+
+		if err := tx.Create(&cashout).Error; err != nil {
 			return err
 		}
 
-		if err = tx.First(&user, cashout.UserID).Error; err != nil {
+		err = helper.PostLedger(tx, helper.LedgerTransfer{
+			From:        helper.WalletAccount(cashout.UserID),
+			To:          helper.SystemAccount(constants.CASHOUT_ACCOUNT),
+			Amount:      cashout.Amount,
+			Type:        constants.CASHOUT_ENTRY,
+			SourceType:  constants.CASHOUT_SOURCE,
+			SourceID:    cashout.ID,
+			Description: "cashout to " + cashout.BankCode + " " + cashout.AccountNumber,
+		})
+		if err != nil {
 			return err
 		}
 
-		newBalance := user.Balance - cashout.Amount
+		err = helper.PostLedger(tx, helper.LedgerTransfer{
+			From:        helper.WalletAccount(cashout.UserID),
+			To:          helper.SystemAccount(constants.REVENUE_ACCOUNT),
+			Amount:      cashout.Fee,
+			Type:        constants.CASHOUT_FEE_ENTRY,
+			SourceType:  constants.CASHOUT_SOURCE,
+			SourceID:    cashout.ID,
+			Description: "fee for the cashout to " + cashout.BankCode + " " + cashout.AccountNumber,
+		})
+		return err
+	})
+
+	if err != nil {
+		return cashout, err
+	}
+
+	if cashout.Status != constants.CASHOUT_PENDING {
+		return cashout, nil
+	}
+
+	// the provider is called after the commit, so the user's row is not locked while it answers
+	if err := cr.disburse(&cashout); err != nil {
+		if cr.disbursed(cashout) {
+			return cashout, nil
+		}
+
+		failErr := cr.db.Transaction(func(tx *gorm.DB) error {
+			return failCashout(tx, &cashout, "refused")
+		})
+		if failErr != nil {
+			return cashout, failErr
+		}
+		return cashout, err
+	}
+
+	return cashout, nil
+}
+
+// GetAll lists the cashouts with the status, oldest first, for the admin's approval queue.
+func (cr *CashoutRepository) GetAll(status string) ([]models.Cashout, error) {
+	cashouts := []models.Cashout{}
+
+	if err := cr.db.Where("status = ?", status).Order("id").Find(&cashouts).Error; err != nil {
+		return nil, err
+	}
+
+	return cashouts, nil
+}
 
-		if err = tx.Model(&user).Update("balance", newBalance).Error; err != nil {
+// Approve sends a cashout waiting for an admin to the bank account. The cashout is moved to
+// PENDING first, when the provider refuses it, it goes back to REQUESTED. The external id and
+// idempotency key are kept, so approving it again cannot pay a disbursement out twice that the
+// provider took after all.
+func (cr *CashoutRepository) Approve(cashoutID int) (models.Cashout, error) {
+	cashout := models.Cashout{}
+
+	err := cr.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&cashout, cashoutID).Error; err != nil {
 			return err
 		}
 
-		return nil
+		if cashout.Status != constants.CASHOUT_REQUESTED {
+			return helper.CashoutError("only a requested cashout can be approved")
+		}
+
+		if cashout.IdempotenceKey == "" {
+			cashout = helper.PrepareCashout(cashout)
+		}
+		cashout.Status = constants.CASHOUT_PENDING
+
+		return tx.Model(&cashout).Updates(models.Cashout{
+			ExternalID:     cashout.ExternalID,
+			IdempotenceKey: cashout.IdempotenceKey,
+			Description:    cashout.Description,
+			Status:         cashout.Status,
+		}).Error
+	})
+
+	if err != nil {
+		return cashout, err
+	}
+
+	if err := cr.disburse(&cashout); err != nil {
+		if cr.disbursed(cashout) {
+			return cashout, nil
+		}
+
+		cashout.Status = constants.CASHOUT_REQUESTED
+
+		revertErr := cr.db.Model(&cashout).Where("status = ?", constants.CASHOUT_PENDING).Update("status", cashout.Status).Error
+		if revertErr != nil {
+			return cashout, revertErr
+		}
+		return cashout, err
+	}
+
+	return cashout, nil
+}
+
+// Reject turns down a cashout waiting for an admin and gives the amount and the fee back. A
+// cashout whose approval failed is only rejected when the provider does not have its disbursement.
+func (cr *CashoutRepository) Reject(cashoutID int, note string) (models.Cashout, error) {
+	cashout := models.Cashout{}
+
+	if err := cr.db.First(&cashout, cashoutID).Error; err != nil {
+		return cashout, err
+	}
+
+	if cashout.ExternalID != "" && cr.disbursed(cashout) {
+		return cashout, helper.CashoutError("the provider already has the disbursement of this cashout")
+	}
+
+	err := cr.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&cashout, cashoutID).Error; err != nil {
+			return err
+		}
+
+		if cashout.Status != constants.CASHOUT_REQUESTED {
+			return helper.CashoutError("only a requested cashout can be rejected")
+		}
+
+		if err := reverseCashout(tx, cashout, "rejected"); err != nil {
+			return err
+		}
+
+		cashout.Status = constants.CASHOUT_REJECTED
+		cashout.Note = note
+
+		return tx.Model(&cashout).Updates(models.Cashout{Status: cashout.Status, Note: cashout.Note}).Error
 	})
 
 	if err != nil {
@@ -51,6 +234,75 @@ func (cr *CashoutRepository) Cashout(cashout models.Cashout) (models.Cashout, er
 	return cashout, nil
 }
 
+// disburse hands a cashout stored as PENDING to the payment provider. It runs after the cashout
+// is committed, so no row stays locked while the provider answers. Only the bank details the
+// provider reports are copied, the status is left to the disbursement callback.
+func (cr *CashoutRepository) disburse(cashout *models.Cashout) error {
+	disbursement, err := helper.PaymentCashout(cr.provider, *cashout)
+	if err != nil {
+		return err
+	}
+
+	cashout.BankCode = disbursement.BankCode
+	cashout.AccountHolderName = disbursement.AccountHolderName
+
+	return cr.db.Model(cashout).Updates(models.Cashout{BankCode: cashout.BankCode, AccountHolderName: cashout.AccountHolderName}).Error
+}
+
+// disbursed tells whether the provider has the disbursement of the cashout, after a call to
+// create it failed. A lookup that fails too counts as not disbursed.
+func (cr *CashoutRepository) disbursed(cashout models.Cashout) bool {
+	_, err := cr.provider.GetDisbursement(cashout.ExternalID)
+	return err == nil
+}
+
+// failCashout gives the amount and the fee of a PENDING cashout back and marks it FAILED. A
+// cashout that is no longer PENDING is left alone, so it is only given back once.
+func failCashout(tx *gorm.DB, cashout *models.Cashout, reason string) error {
+	cashoutDB := models.Cashout{}
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&cashoutDB, cashout.ID).Error; err != nil {
+		return err
+	}
+
+	if cashoutDB.Status != constants.CASHOUT_PENDING {
+		return nil
+	}
+
+	if err := reverseCashout(tx, cashoutDB, reason); err != nil {
+		return err
+	}
+
+	cashout.Status = constants.CASHOUT_FAILED
+
+	return tx.Model(&cashoutDB).Update("status", cashout.Status).Error
+}
+
+// reverseCashout gives the amount and the fee of the cashout back to the user.
+func reverseCashout(tx *gorm.DB, cashout models.Cashout, reason string) error {
+	err := helper.PostLedger(tx, helper.LedgerTransfer{
+		From:        helper.SystemAccount(constants.CASHOUT_ACCOUNT),
+		To:          helper.WalletAccount(cashout.UserID),
+		Amount:      cashout.Amount,
+		Type:        constants.CASHOUT_REVERSAL_ENTRY,
+		SourceType:  constants.CASHOUT_SOURCE,
+		SourceID:    cashout.ID,
+		Description: "cashout to " + cashout.BankCode + " " + cashout.AccountNumber + " " + reason,
+	})
+	if err != nil {
+		return err
+	}
+
+	return helper.PostLedger(tx, helper.LedgerTransfer{
+		From:        helper.SystemAccount(constants.REVENUE_ACCOUNT),
+		To:          helper.WalletAccount(cashout.UserID),
+		Amount:      cashout.Fee,
+		Type:        constants.CASHOUT_FEE_REVERSAL_ENTRY,
+		SourceType:  constants.CASHOUT_SOURCE,
+		SourceID:    cashout.ID,
+		Description: "fee for the cashout to " + cashout.BankCode + " " + cashout.AccountNumber + " " + reason,
+	})
+}
+
 func (cr *CashoutRepository) History(userID int) ([]models.Cashout, error) {
 	var cashouts []models.Cashout
 
@@ -71,6 +323,9 @@ func (cr *CashoutRepository) CheckBalance(userID int) (models.User, error) {
 	return user, nil
 }
 
+// CallbackSuccess records the completed disbursement. SettledAt is when the first callback
+// arrived, a redelivered one keeps it. When the callback does not carry the provider's fee, the
+// disbursement is looked up for it.
 func (cr *CashoutRepository) CallbackSuccess(extID string, cashout models.Cashout) (models.Cashout, error) {
 
 	var cashoutDB models.Cashout
@@ -79,6 +334,18 @@ func (cr *CashoutRepository) CallbackSuccess(extID string, cashout models.Cashou
 		return cashout, err
 	}
 
+	if cashout.GatewayFee == 0 && cashoutDB.GatewayFee == 0 {
+		disbursement, err := cr.provider.GetDisbursement(extID)
+		if err != nil {
+			return cashout, err
+		}
+		cashout.GatewayFee = disbursement.Fee
+	}
+
+	if cashoutDB.SettledAt.IsZero() {
+		cashout.SettledAt = time.Now()
+	}
+
 	if err := cr.db.Model(&cashoutDB).Updates(cashout).Error; err != nil {
 		return cashout, err
 	}
@@ -86,33 +353,27 @@ func (cr *CashoutRepository) CallbackSuccess(extID string, cashout models.Cashou
 	return cashout, nil
 }
 
+// CallbackFailed gives the amount and the fee back to the user. A cashout that already failed is left
+// alone, so a redelivered callback does not credit the balance twice.
 func (cr *CashoutRepository) CallbackFailed(extID string, cashout models.Cashout) (models.Cashout, error) {
 
-	var user models.User
 	var cashoutDB models.Cashout
 
-	var err error
-	cr.db.Transaction(func(tx *gorm.DB) error {
+	err := cr.db.Transaction(func(tx *gorm.DB) error {
 
-		if err = tx.First(&cashoutDB, "external_id = ?", extID).Error; err != nil {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&cashoutDB, "external_id = ?", extID).Error; err != nil {
 			return err
 		}
 
-		if err = tx.First(&user, cashout.UserID).Error; err != nil {
-			return err
+		if cashoutDB.Status == cashout.Status {
+			return nil
 		}
 
-		newBalance := user.Balance + cashout.Amount
-
-		if err = tx.Model(&user).Update("balance", newBalance).Error; err != nil {
+		if err := reverseCashout(tx, cashoutDB, "failed"); err != nil {
 			return err
 		}
 
-		if err = tx.Model(&cashoutDB).Updates(cashout).Error; err != nil {
-			return err
-		}
-
-		return nil
+		return tx.Model(&cashoutDB).Updates(cashout).Error
 	})
 
 	if err != nil {
@@ -1,10 +1,15 @@
 package cashout_test
 
 import (
+	"errors"
 	"testing"
+	"time"
 
 	config "github.com/furqonzt99/snackbox/configs"
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/helper"
 	"github.com/furqonzt99/snackbox/models"
+	"github.com/furqonzt99/snackbox/payment"
 	"github.com/furqonzt99/snackbox/repositories/cashout"
 	"github.com/furqonzt99/snackbox/repositories/partner"
 	"github.com/furqonzt99/snackbox/repositories/user"
@@ -28,18 +33,20 @@ func TestCashout(t *testing.T) {
 	db.Migrator().DropTable(&models.Product{})
 	db.Migrator().DropTable(&models.Rating{})
 	db.Migrator().DropTable(&models.Transaction{})
+	db.Migrator().DropTable(&models.LedgerEntry{})
 	db.Migrator().DropTable(&models.DetailTransaction{})
 	db.Migrator().DropTable(&models.Cashout{})
 
 	userRepo = user.NewUserRepo(db)
 	partnerRepo = partner.NewPartnerRepo(db)
-	cashoutRepo = cashout.NewCashoutRepository(db)
+	cashoutRepo = cashout.NewCashoutRepository(db, utils.InitPaymentProvider(configTest))
 
 	db.AutoMigrate(&models.User{})
 	db.AutoMigrate(&models.Partner{})
 	db.AutoMigrate(&models.Product{})
 	db.AutoMigrate(&models.Rating{})
 	db.AutoMigrate(&models.Transaction{})
+	db.AutoMigrate(&models.LedgerEntry{})
 	db.AutoMigrate(&models.DetailTransaction{})
 	db.AutoMigrate(&models.Cashout{})
 
@@ -64,7 +71,7 @@ func TestCashout(t *testing.T) {
 		mockCashout.Amount = 300
 		res, _ := cashoutRepo.Cashout(mockCashout)
 		// assert.Nil(t, err)
-		assert.Equal(t, float64(300), res.Amount) //sudah
+		assert.Equal(t, models.Money(300), res.Amount) //sudah
 	})
 
 	t.Run("cashout failed", func(t *testing.T) {
@@ -74,7 +81,7 @@ func TestCashout(t *testing.T) {
 
 		_, err := cashoutRepo.Cashout(mockCashout2)
 		assert.NotNil(t, err)
-		// assert.Equal(t, float64(300), res.Amount) //sudah
+		// assert.Equal(t, models.Money(300), res.Amount) //sudah
 	})
 
 }
@@ -88,18 +95,20 @@ func TestHistory(t *testing.T) {
 	db.Migrator().DropTable(&models.Product{})
 	db.Migrator().DropTable(&models.Rating{})
 	db.Migrator().DropTable(&models.Transaction{})
+	db.Migrator().DropTable(&models.LedgerEntry{})
 	db.Migrator().DropTable(&models.DetailTransaction{})
 	db.Migrator().DropTable(&models.Cashout{})
 
 	userRepo = user.NewUserRepo(db)
 	partnerRepo = partner.NewPartnerRepo(db)
-	cashoutRepo = cashout.NewCashoutRepository(db)
+	cashoutRepo = cashout.NewCashoutRepository(db, utils.InitPaymentProvider(configTest))
 
 	db.AutoMigrate(&models.User{})
 	db.AutoMigrate(&models.Partner{})
 	db.AutoMigrate(&models.Product{})
 	db.AutoMigrate(&models.Rating{})
 	db.AutoMigrate(&models.Transaction{})
+	db.AutoMigrate(&models.LedgerEntry{})
 	db.AutoMigrate(&models.DetailTransaction{})
 	db.AutoMigrate(&models.Cashout{})
 
@@ -122,7 +131,7 @@ func TestHistory(t *testing.T) {
 
 		res, _ := cashoutRepo.History(1)
 
-		assert.Equal(t, float64(500), res[0].Amount)
+		assert.Equal(t, models.Money(500), res[0].Amount)
 	})
 
 	t.Run("cashout failed", func(t *testing.T) {
@@ -143,18 +152,20 @@ func TestCallbackSuccess(t *testing.T) {
 	db.Migrator().DropTable(&models.Product{})
 	db.Migrator().DropTable(&models.Rating{})
 	db.Migrator().DropTable(&models.Transaction{})
+	db.Migrator().DropTable(&models.LedgerEntry{})
 	db.Migrator().DropTable(&models.DetailTransaction{})
 	db.Migrator().DropTable(&models.Cashout{})
 
 	userRepo = user.NewUserRepo(db)
 	partnerRepo = partner.NewPartnerRepo(db)
-	cashoutRepo = cashout.NewCashoutRepository(db)
+	cashoutRepo = cashout.NewCashoutRepository(db, utils.InitPaymentProvider(configTest))
 
 	db.AutoMigrate(&models.User{})
 	db.AutoMigrate(&models.Partner{})
 	db.AutoMigrate(&models.Product{})
 	db.AutoMigrate(&models.Rating{})
 	db.AutoMigrate(&models.Transaction{})
+	db.AutoMigrate(&models.LedgerEntry{})
 	db.AutoMigrate(&models.DetailTransaction{})
 	db.AutoMigrate(&models.Cashout{})
 
@@ -178,9 +189,12 @@ func TestCallbackSuccess(t *testing.T) {
 		var mockCashout2 models.Cashout
 		mockCashout2.UserID = 1
 		mockCashout2.Amount = 300
+		mockCashout2.GatewayFee = 4500
 		res, _ := cashoutRepo.CallbackSuccess("22", mockCashout2)
 		// assert.Nil(t, err)
-		assert.Equal(t, float64(300), res.Amount)
+		assert.Equal(t, models.Money(300), res.Amount)
+		assert.Equal(t, models.Money(4500), res.GatewayFee)
+		assert.False(t, res.SettledAt.IsZero())
 	})
 
 	t.Run("CallbackSuccess failed 1", func(t *testing.T) {
@@ -189,7 +203,7 @@ func TestCallbackSuccess(t *testing.T) {
 		mockCashout2.Amount = 300
 		res, _ := cashoutRepo.CallbackSuccess("11", mockCashout2)
 		// assert.Nil(t, err)
-		assert.Equal(t, float64(300), res.Amount)
+		assert.Equal(t, models.Money(300), res.Amount)
 	})
 
 	t.Run("CallbackSuccess failed 2", func(t *testing.T) { //masih gagal
@@ -199,7 +213,7 @@ func TestCallbackSuccess(t *testing.T) {
 		mockCashout2.Amount = 300
 		res, _ := cashoutRepo.CallbackSuccess("22", mockCashout2)
 		// assert.Nil(t, err)
-		assert.Equal(t, float64(300), res.Amount)
+		assert.Equal(t, models.Money(300), res.Amount)
 	})
 }
 
@@ -212,18 +226,20 @@ func TestCheckBalance(t *testing.T) {
 	db.Migrator().DropTable(&models.Product{})
 	db.Migrator().DropTable(&models.Rating{})
 	db.Migrator().DropTable(&models.Transaction{})
+	db.Migrator().DropTable(&models.LedgerEntry{})
 	db.Migrator().DropTable(&models.DetailTransaction{})
 	db.Migrator().DropTable(&models.Cashout{})
 
 	userRepo = user.NewUserRepo(db)
 	partnerRepo = partner.NewPartnerRepo(db)
-	cashoutRepo = cashout.NewCashoutRepository(db)
+	cashoutRepo = cashout.NewCashoutRepository(db, utils.InitPaymentProvider(configTest))
 
 	db.AutoMigrate(&models.User{})
 	db.AutoMigrate(&models.Partner{})
 	db.AutoMigrate(&models.Product{})
 	db.AutoMigrate(&models.Rating{})
 	db.AutoMigrate(&models.Transaction{})
+	db.AutoMigrate(&models.LedgerEntry{})
 	db.AutoMigrate(&models.DetailTransaction{})
 	db.AutoMigrate(&models.Cashout{})
 
@@ -249,7 +265,7 @@ func TestCheckBalance(t *testing.T) {
 		mockCashout2.Amount = 300
 		res, _ := cashoutRepo.CheckBalance(1)
 		// assert.Nil(t, err)
-		assert.Equal(t, float64(1000), res.Balance)
+		assert.Equal(t, models.Money(1000), res.Balance)
 	})
 
 	t.Run("CheckBalance success", func(t *testing.T) {
@@ -258,7 +274,7 @@ func TestCheckBalance(t *testing.T) {
 		mockCashout2.Amount = 300
 		res, _ := cashoutRepo.CheckBalance(3)
 		// assert.Nil(t, err)
-		assert.Equal(t, float64(0), res.Balance)
+		assert.Equal(t, models.Money(0), res.Balance)
 	})
 
 }
@@ -272,18 +288,20 @@ func TestCallBackFailed(t *testing.T) {
 	db.Migrator().DropTable(&models.Product{})
 	db.Migrator().DropTable(&models.Rating{})
 	db.Migrator().DropTable(&models.Transaction{})
+	db.Migrator().DropTable(&models.LedgerEntry{})
 	db.Migrator().DropTable(&models.DetailTransaction{})
 	db.Migrator().DropTable(&models.Cashout{})
 
 	userRepo = user.NewUserRepo(db)
 	partnerRepo = partner.NewPartnerRepo(db)
-	cashoutRepo = cashout.NewCashoutRepository(db)
+	cashoutRepo = cashout.NewCashoutRepository(db, utils.InitPaymentProvider(configTest))
 
 	db.AutoMigrate(&models.User{})
 	db.AutoMigrate(&models.Partner{})
 	db.AutoMigrate(&models.Product{})
 	db.AutoMigrate(&models.Rating{})
 	db.AutoMigrate(&models.Transaction{})
+	db.AutoMigrate(&models.LedgerEntry{})
 	db.AutoMigrate(&models.DetailTransaction{})
 	db.AutoMigrate(&models.Cashout{})
 
@@ -309,7 +327,7 @@ func TestCallBackFailed(t *testing.T) {
 		mockCashout2.Amount = 300
 		res, _ := cashoutRepo.CallbackFailed("22", mockCashout2)
 		// assert.Nil(t, err)
-		assert.Equal(t, float64(300), res.Amount)
+		assert.Equal(t, models.Money(300), res.Amount)
 	})
 
 	t.Run("CheckBalance failed 1", func(t *testing.T) {
@@ -318,7 +336,7 @@ func TestCallBackFailed(t *testing.T) {
 		mockCashout2.Amount = 300
 		res, _ := cashoutRepo.CallbackFailed("99", mockCashout2)
 		// assert.Nil(t, err)
-		assert.Equal(t, float64(300), res.Amount)
+		assert.Equal(t, models.Money(300), res.Amount)
 	})
 
 	t.Run("CheckBalance failed 2", func(t *testing.T) {
@@ -327,7 +345,7 @@ func TestCallBackFailed(t *testing.T) {
 		mockCashout3.Amount = 300
 		res, _ := cashoutRepo.CallbackFailed("22", mockCashout3)
 		// assert.Nil(t, err)
-		assert.Equal(t, float64(300), res.Amount)
+		assert.Equal(t, models.Money(300), res.Amount)
 	})
 
 	t.Run("CheckBalance failed 2", func(t *testing.T) {
@@ -336,7 +354,7 @@ func TestCallBackFailed(t *testing.T) {
 		mockCashout3.Amount = 300
 		res, _ := cashoutRepo.CallbackFailed("22", mockCashout3)
 		// assert.Nil(t, err)
-		assert.Equal(t, float64(300), res.Amount)
+		assert.Equal(t, models.Money(300), res.Amount)
 	})
 
 	// t.Run("CheckBalance success", func(t *testing.T) {
@@ -345,7 +363,193 @@ func TestCallBackFailed(t *testing.T) {
 	// 	mockCashout2.Amount = 300
 	// 	res, _ := cashoutRepo.CheckBalance(3)
 	// 	// assert.Nil(t, err)
-	// 	assert.Equal(t, float64(0), res.Balance)
+	// 	assert.Equal(t, models.Money(0), res.Balance)
 	// })
 
 }
+
+func TestCashoutApproval(t *testing.T) {
+	configTest = config.GetConfig()
+	db = utils.InitDB(configTest)
+
+	constants.CASHOUT_MIN_AMOUNT = 10000
+	constants.CASHOUT_MAX_AMOUNT = 50000000
+	constants.CASHOUT_DAILY_LIMIT = 8000000
+	constants.CASHOUT_MONTHLY_LIMIT = 20000000
+	constants.CASHOUT_FEE = 2500
+	constants.CASHOUT_APPROVAL_THRESHOLD = 5000000
+
+	db.Migrator().DropTable(&models.User{})
+	db.Migrator().DropTable(&models.LedgerEntry{})
+	db.Migrator().DropTable(&models.Cashout{})
+	db.Migrator().DropTable(&models.BankAccount{})
+
+	userRepo = user.NewUserRepo(db)
+	cashoutRepo = cashout.NewCashoutRepository(db, payment.NewSandboxProvider("", "", time.Hour))
+
+	db.AutoMigrate(&models.User{})
+	db.AutoMigrate(&models.LedgerEntry{})
+	db.AutoMigrate(&models.Cashout{})
+	db.AutoMigrate(&models.BankAccount{})
+
+	//CREATE USER
+	dummyUser := models.User{
+		Email:    "test@gmail.com",
+		Password: "test1234",
+		Balance:  20000000,
+	}
+	userRepo.Register(dummyUser)
+
+	db.Create(&models.BankAccount{UserID: 1, BankCode: "BCA", AccountHolderName: "test", AccountNumber: "1", IsDefault: true})
+
+	balance := func() models.Money {
+		var user models.User
+		db.First(&user, 1)
+		return user.Balance
+	}
+
+	newCashout := func(amount models.Money) models.Cashout {
+		return models.Cashout{UserID: 1, Amount: amount}
+	}
+
+	t.Run("cashout to an unknown bank account", func(t *testing.T) {
+		unknown := newCashout(1000000)
+		unknown.BankAccountID = 10
+
+		_, err := cashoutRepo.Cashout(unknown)
+		assert.True(t, errors.Is(err, helper.ErrInvalidCashout))
+		assert.Equal(t, models.Money(20000000), balance())
+	})
+
+	t.Run("cashout below the threshold", func(t *testing.T) {
+		res, err := cashoutRepo.Cashout(newCashout(1000000))
+		assert.Nil(t, err)
+		assert.Equal(t, uint(1), res.BankAccountID)
+		assert.Equal(t, "BCA", res.BankCode)
+		assert.Equal(t, constants.CASHOUT_PENDING, res.Status)
+		assert.Equal(t, models.Money(2500), res.Fee)
+		assert.Equal(t, models.Money(18997500), balance())
+	})
+
+	t.Run("cashout above the threshold", func(t *testing.T) {
+		res, err := cashoutRepo.Cashout(newCashout(6000000))
+		assert.Nil(t, err)
+		assert.Equal(t, constants.CASHOUT_REQUESTED, res.Status)
+		assert.Equal(t, "", res.ExternalID)
+		assert.Equal(t, models.Money(12995000), balance())
+
+		requested, _ := cashoutRepo.GetAll(constants.CASHOUT_REQUESTED)
+		assert.Equal(t, 1, len(requested))
+	})
+
+	t.Run("cashout over the daily limit", func(t *testing.T) {
+		_, err := cashoutRepo.Cashout(newCashout(2000000))
+		assert.True(t, errors.Is(err, helper.ErrInvalidCashout))
+		assert.Equal(t, models.Money(12995000), balance())
+	})
+
+	t.Run("reject cashout", func(t *testing.T) {
+		res, err := cashoutRepo.Reject(2, "the account holder does not match")
+		assert.Nil(t, err)
+		assert.Equal(t, constants.CASHOUT_REJECTED, res.Status)
+		assert.Equal(t, models.Money(18997500), balance())
+
+		_, err = cashoutRepo.Reject(2, "again")
+		assert.True(t, errors.Is(err, helper.ErrInvalidCashout))
+		assert.Equal(t, models.Money(18997500), balance())
+	})
+
+	t.Run("approve cashout", func(t *testing.T) {
+		// the rejected cashout no longer counts towards the daily limit
+		_, err := cashoutRepo.Cashout(newCashout(6000000))
+		assert.Nil(t, err)
+
+		res, err := cashoutRepo.Approve(3)
+		assert.Nil(t, err)
+		assert.Equal(t, constants.CASHOUT_PENDING, res.Status)
+		assert.NotEqual(t, "", res.ExternalID)
+		assert.Equal(t, models.Money(2500), res.Fee)
+		assert.Equal(t, models.Money(12995000), balance())
+
+		_, err = cashoutRepo.Approve(3)
+		assert.True(t, errors.Is(err, helper.ErrInvalidCashout))
+	})
+
+	t.Run("failed disbursement gives the fee back", func(t *testing.T) {
+		var cashoutDB models.Cashout
+		db.First(&cashoutDB, 3)
+
+		_, err := cashoutRepo.CallbackFailed(cashoutDB.ExternalID, models.Cashout{Status: constants.CASHOUT_FAILED})
+		assert.Nil(t, err)
+		assert.Equal(t, models.Money(18997500), balance())
+	})
+
+	t.Run("refused disbursement gives the amount back", func(t *testing.T) {
+		refusingRepo := cashout.NewCashoutRepository(db, refusingProvider{})
+
+		res, err := refusingRepo.Cashout(newCashout(100000))
+		assert.NotNil(t, err)
+		assert.Equal(t, constants.CASHOUT_FAILED, res.Status)
+		assert.Equal(t, uint(1), res.BankAccountID)
+		assert.Equal(t, models.Money(18997500), balance())
+	})
+
+	t.Run("refused approval keeps the idempotency key", func(t *testing.T) {
+		refusingRepo := cashout.NewCashoutRepository(db, refusingProvider{})
+
+		_, err := cashoutRepo.Cashout(newCashout(6000000))
+		assert.Nil(t, err)
+
+		_, err = refusingRepo.Approve(5)
+		assert.NotNil(t, err)
+
+		var refused models.Cashout
+		db.First(&refused, 5)
+		assert.Equal(t, constants.CASHOUT_REQUESTED, refused.Status)
+		assert.NotEqual(t, "", refused.IdempotenceKey)
+
+		_, err = refusingRepo.Approve(5)
+		assert.NotNil(t, err)
+
+		var retried models.Cashout
+		db.First(&retried, 5)
+		assert.Equal(t, refused.IdempotenceKey, retried.IdempotenceKey)
+		assert.Equal(t, refused.ExternalID, retried.ExternalID)
+	})
+
+	t.Run("timed out approval stays pending", func(t *testing.T) {
+		timingOutRepo := cashout.NewCashoutRepository(db, timingOutProvider{})
+
+		res, err := timingOutRepo.Approve(5)
+		assert.Nil(t, err)
+		assert.Equal(t, constants.CASHOUT_PENDING, res.Status)
+
+		_, err = timingOutRepo.Reject(5, "too late")
+		assert.True(t, errors.Is(err, helper.ErrInvalidCashout))
+	})
+}
+
+type refusingProvider struct {
+	payment.PaymentProvider
+}
+
+func (p refusingProvider) CreateDisbursement(params payment.CreateDisbursementParams) (payment.Disbursement, error) {
+	return payment.Disbursement{}, errors.New("FAILED")
+}
+
+func (p refusingProvider) GetDisbursement(externalID string) (payment.Disbursement, error) {
+	return payment.Disbursement{}, errors.New("NOT FOUND")
+}
+
+// timingOutProvider takes the disbursement but the answer never arrives.
+type timingOutProvider struct {
+	payment.PaymentProvider
+}
+
+func (p timingOutProvider) CreateDisbursement(params payment.CreateDisbursementParams) (payment.Disbursement, error) {
+	return payment.Disbursement{}, errors.New("TIMEOUT")
+}
+
+func (p timingOutProvider) GetDisbursement(externalID string) (payment.Disbursement, error) {
+	return payment.Disbursement{ExternalID: externalID, Status: payment.DISBURSEMENT_PENDING}, nil
+}
@@ -0,0 +1,119 @@
+package commission
+
+import (
+	"time"
+
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/models"
+	"gorm.io/gorm"
+)
+
+// RevenueRow sums the fees of the orders confirmed in one period.
+type RevenueRow struct {
+	Period       string
+	Transactions int
+	Gross        models.Money
+	Refunded     models.Money
+	Commission   models.Money
+	Net          models.Money
+}
+
+type CommissionInterface interface {
+	Create(rule models.CommissionRule) (models.CommissionRule, error)
+	GetAll() ([]models.CommissionRule, error)
+	Update(ruleID int, rule models.CommissionRule) (models.CommissionRule, error)
+	Delete(ruleID int) error
+	GetFees(partnerID, offset, limit int) ([]models.TransactionFee, error)
+	Report(from, to time.Time, period string) ([]RevenueRow, error)
+}
+
+type CommissionRepository struct {
+	db *gorm.DB
+}
+
+func NewCommissionRepository(db *gorm.DB) *CommissionRepository {
+	return &CommissionRepository{db: db}
+}
+
+func (cr *CommissionRepository) Create(rule models.CommissionRule) (models.CommissionRule, error) {
+	if err := cr.db.Create(&rule).Error; err != nil {
+		return rule, err
+	}
+
+	return rule, nil
+}
+
+func (cr *CommissionRepository) GetAll() ([]models.CommissionRule, error) {
+	rules := []models.CommissionRule{}
+
+	if err := cr.db.Order("partner_id, category").Find(&rules).Error; err != nil {
+		return rules, err
+	}
+
+	return rules, nil
+}
+
+// Update changes the rate of the rule, its scope stays as it was created.
+func (cr *CommissionRepository) Update(ruleID int, rule models.CommissionRule) (models.CommissionRule, error) {
+	current := models.CommissionRule{}
+
+	if err := cr.db.First(&current, ruleID).Error; err != nil {
+		return current, err
+	}
+
+	if err := cr.db.Model(&current).Update("rate", rule.Rate).Error; err != nil {
+		return current, err
+	}
+
+	return current, nil
+}
+
+// Delete removes the rule for good, so the same scope can get a new rule later.
+// Fees already recorded keep the commission they were charged.
+func (cr *CommissionRepository) Delete(ruleID int) error {
+	rule := models.CommissionRule{}
+
+	if err := cr.db.First(&rule, ruleID).Error; err != nil {
+		return err
+	}
+
+	return cr.db.Unscoped().Delete(&rule).Error
+}
+
+// GetFees lists the fees of the partner's orders, newest first, or of every partner when partnerID is 0.
+func (cr *CommissionRepository) GetFees(partnerID, offset, limit int) ([]models.TransactionFee, error) {
+	fees := []models.TransactionFee{}
+
+	query := cr.db.Preload("Transaction")
+	if partnerID != 0 {
+		query = query.Where("partner_id = ?", partnerID)
+	}
+
+	if err := query.Order("id desc").Offset(offset).Limit(limit).Find(&fees).Error; err != nil {
+		return nil, err
+	}
+
+	return fees, nil
+}
+
+// Report sums the fees recorded from from up to, but not including, to by day or by month.
+func (cr *CommissionRepository) Report(from, to time.Time, period string) ([]RevenueRow, error) {
+	rows := []RevenueRow{}
+
+	format := "%Y-%m"
+	if period == constants.DAILY_PERIOD {
+		format = "%Y-%m-%d"
+	}
+
+	err := cr.db.Model(&models.TransactionFee{}).
+		Select("DATE_FORMAT(created_at, ?) AS period, COUNT(*) AS transactions, SUM(gross) AS gross, SUM(refunded) AS refunded, SUM(commission) AS commission, SUM(net) AS net", format).
+		Where("created_at >= ? AND created_at < ?", from, to).
+		Group("period").
+		Order("period").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,138 @@
+package commission_test
+
+import (
+	"testing"
+	"time"
+
+	config "github.com/furqonzt99/snackbox/configs"
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/helper"
+	"github.com/furqonzt99/snackbox/models"
+	"github.com/furqonzt99/snackbox/repositories/commission"
+	"github.com/furqonzt99/snackbox/utils"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+var configTest *config.AppConfig
+var db *gorm.DB
+var commissionRepo *commission.CommissionRepository
+
+func TestCommission(t *testing.T) {
+	configTest = config.GetConfig()
+	db = utils.InitDB(configTest)
+
+	constants.EARNING_HOLD_DAYS = 0
+
+	db.Migrator().DropTable(&models.RefundRequest{})
+	db.Migrator().DropTable(&models.TransactionFee{})
+	db.Migrator().DropTable(&models.CommissionRule{})
+	db.Migrator().DropTable(&models.LedgerEntry{})
+	db.Migrator().DropTable(&models.DetailTransaction{})
+	db.Migrator().DropTable(&models.Transaction{})
+	db.Migrator().DropTable(&models.Partner{})
+	db.Migrator().DropTable(&models.User{})
+
+	commissionRepo = commission.NewCommissionRepository(db)
+
+	db.AutoMigrate(&models.User{})
+	db.AutoMigrate(&models.Partner{})
+	db.AutoMigrate(&models.Transaction{})
+	db.AutoMigrate(&models.DetailTransaction{})
+	db.AutoMigrate(&models.LedgerEntry{})
+	db.AutoMigrate(&models.CommissionRule{})
+	db.AutoMigrate(&models.TransactionFee{})
+	db.AutoMigrate(&models.RefundRequest{})
+
+	db.Create(&models.User{Email: "partner@gmail.com", Password: "test1234", Role: "partner"})
+	db.Create(&models.User{Email: "user@gmail.com", Password: "test1234", Role: "user"})
+	db.Create(&models.Partner{UserID: 1, BussinessName: "partner1", Status: "active"})
+
+	trx := models.Transaction{PartnerID: 1, UserID: 2, InvoiceID: "INV1", TotalPrice: 40000, ShippingCost: 10000, Status: constants.SEND_STATUS}
+	db.Create(&trx)
+	db.Create(&models.DetailTransaction{TransactionID: trx.ID, Type: "snack", Price: 10000, Quantity: 2})
+	db.Create(&models.DetailTransaction{TransactionID: trx.ID, Type: "drink", Price: 5000, Quantity: 2})
+
+	t.Run("create rules", func(t *testing.T) {
+		for _, rule := range []models.CommissionRule{
+			{Rate: 10},
+			{Category: "snack", Rate: 20},
+			{PartnerID: 1, Category: "drink", Rate: 5},
+			{Category: constants.SHIPPING_COMMISSION, Rate: 50},
+		} {
+			_, err := commissionRepo.Create(rule)
+			assert.Nil(t, err)
+		}
+
+		rules, _ := commissionRepo.GetAll()
+		assert.Equal(t, 4, len(rules))
+	})
+
+	t.Run("create rule for an existing scope", func(t *testing.T) {
+		_, err := commissionRepo.Create(models.CommissionRule{Category: "snack", Rate: 15})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("update rule", func(t *testing.T) {
+		res, err := commissionRepo.Update(1, models.CommissionRule{Rate: 12})
+		assert.Nil(t, err)
+		assert.Equal(t, float64(12), res.Rate)
+	})
+
+	t.Run("pay partner net of commission", func(t *testing.T) {
+		assert.Nil(t, helper.PayPartner(db, trx))
+		assert.Nil(t, helper.PayPartner(db, trx))
+
+		// snack 20% of 20000, drink 5% of 10000 and shipping 50% of 10000
+		fees, err := commissionRepo.GetFees(1, 0, 10)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(fees))
+		assert.Equal(t, models.Money(9500), fees[0].Commission)
+		assert.Equal(t, models.Money(30500), fees[0].Net)
+		assert.Equal(t, "INV1", fees[0].Transaction.InvoiceID)
+
+		user := models.User{}
+		db.First(&user, 1)
+		assert.Equal(t, models.Money(30500), user.Balance)
+	})
+
+	t.Run("exclusive tax is not commissioned", func(t *testing.T) {
+		rules, _ := commissionRepo.GetAll()
+
+		taxed := trx
+		taxed.Tax = 4000
+		taxed.TotalPrice = 44000
+
+		items := []models.DetailTransaction{
+			{Type: "snack", Price: 10000, Quantity: 2},
+			{Type: "drink", Price: 5000, Quantity: 2},
+		}
+
+		fee := helper.CalculateFee(rules, taxed, items, 0)
+		assert.Equal(t, models.Money(44000), fee.Gross)
+		assert.Equal(t, models.Money(4000), fee.Tax)
+		assert.Equal(t, models.Money(9500), fee.Commission)
+		assert.Equal(t, models.Money(34500), fee.Net)
+	})
+
+	t.Run("revenue report", func(t *testing.T) {
+		now := time.Now()
+
+		rows, err := commissionRepo.Report(now.AddDate(0, 0, -1), now.AddDate(0, 0, 1), constants.DAILY_PERIOD)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(rows))
+		assert.Equal(t, 1, rows[0].Transactions)
+		assert.Equal(t, models.Money(9500), rows[0].Commission)
+
+		rows, _ = commissionRepo.Report(now.AddDate(0, 0, 1), now.AddDate(0, 0, 2), constants.DAILY_PERIOD)
+		assert.Equal(t, 0, len(rows))
+	})
+
+	t.Run("delete rule", func(t *testing.T) {
+		assert.Nil(t, commissionRepo.Delete(2))
+		assert.NotNil(t, commissionRepo.Delete(2))
+
+		_, err := commissionRepo.Create(models.CommissionRule{Category: "snack", Rate: 15})
+		assert.Nil(t, err)
+	})
+}
@@ -1,10 +1,26 @@
 package partner
 
 import (
+	"time"
+
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/helper"
 	"github.com/furqonzt99/snackbox/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// TaxRow sums one tax of the partner's orders placed in one period.
+type TaxRow struct {
+	Period    string
+	Type      string
+	Rate      float64
+	Inclusive bool
+	Orders    int
+	Base      models.Money
+	Amount    models.Money
+}
+
 type PartnerInterface interface {
 	ApplyPartner(partner models.Partner) (models.Partner, error)
 	GetAllPartner() ([]models.Partner, error)
@@ -15,6 +31,12 @@ type PartnerInterface interface {
 	RejectPartner(partner models.Partner) error
 	UploadDocument(partnerID int, partner models.Partner) (models.Partner, error)
 	Report(partnerId int) ([]models.Transaction, error)
+	TaxReport(partnerId int, period string) ([]TaxRow, error)
+	UpdateCapacity(partnerId int, capacity int) error
+	UpdateOrderRules(partnerId int, rules models.Partner) (models.Partner, error)
+	SetCalendar(calendar models.PartnerCalendar) (models.PartnerCalendar, error)
+	DeleteCalendar(partnerId int, date string) error
+	GetAvailability(partnerId int, from, to time.Time) ([]helper.DayAvailability, error)
 }
 
 type PartnerRepository struct {
@@ -124,7 +146,97 @@ func (p *PartnerRepository) GetPartner(partnerId int) (models.Partner, error) {
 func (p *PartnerRepository) Report(partnerId int) ([]models.Transaction, error) {
 
 	var transaction []models.Transaction
-	p.db.Order("created_at desc").Where("status <> ? AND status <> ?", "PENDING", "UNPAID").Preload("User").Preload("Partner").Preload("Products").Find(&transaction, "partner_id = ?", partnerId)
+	p.db.Order("created_at desc").Where("status <> ? AND status <> ?", constants.PENDING_STATUS, constants.EXPIRED_STATUS).Preload("User").Preload("Partner").Preload("DetailTransactions").Find(&transaction, "partner_id = ?", partnerId)
 
 	return transaction, nil
 }
+
+// TaxReport sums the taxes of the partner's paid orders by day or by month. Orders that were
+// cancelled, rejected or never paid owe no tax and are left out.
+func (p *PartnerRepository) TaxReport(partnerId int, period string) ([]TaxRow, error) {
+	rows := []TaxRow{}
+
+	format := "%Y-%m"
+	if period == constants.DAILY_PERIOD {
+		format = "%Y-%m-%d"
+	}
+
+	err := p.db.Model(&models.TransactionTax{}).
+		Select("DATE_FORMAT(transactions.created_at, ?) AS period, transaction_taxes.type, transaction_taxes.rate, transaction_taxes.inclusive, COUNT(*) AS orders, SUM(transaction_taxes.base) AS base, SUM(transaction_taxes.amount) AS amount", format).
+		Joins("JOIN transactions ON transactions.id = transaction_taxes.transaction_id AND transactions.deleted_at IS NULL").
+		Where("transactions.partner_id = ? AND transactions.status NOT IN ?", partnerId, []string{constants.PENDING_STATUS, constants.EXPIRED_STATUS, constants.CANCEL_STATUS, constants.REJECT_STATUS}).
+		Group("period, transaction_taxes.type, transaction_taxes.rate, transaction_taxes.inclusive").
+		Order("period, transaction_taxes.type").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+func (p *PartnerRepository) UpdateCapacity(partnerId int, capacity int) error {
+	var partner models.Partner
+
+	if err := p.db.First(&partner, partnerId).Error; err != nil {
+		return err
+	}
+
+	return p.db.Model(&partner).Update("daily_capacity", capacity).Error
+}
+
+// UpdateOrderRules saves every ordering rule field of rules, zero values included.
+func (p *PartnerRepository) UpdateOrderRules(partnerId int, rules models.Partner) (models.Partner, error) {
+	var partner models.Partner
+
+	if err := p.db.First(&partner, partnerId).Error; err != nil {
+		return partner, err
+	}
+
+	err := p.db.Model(&partner).Select("lead_time_hours", "min_quantity", "max_quantity", "max_distance", "order_open_time", "order_close_time").Updates(rules).Error
+	if err != nil {
+		return partner, err
+	}
+
+	if err := p.db.First(&partner, partnerId).Error; err != nil {
+		return partner, err
+	}
+
+	return partner, nil
+}
+
+// SetCalendar creates or replaces the override of a single date.
+func (p *PartnerRepository) SetCalendar(calendar models.PartnerCalendar) (models.PartnerCalendar, error) {
+	err := p.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "partner_id"}, {Name: "date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"updated_at", "capacity", "blackout", "note"}),
+	}).Create(&calendar).Error
+	if err != nil {
+		return calendar, err
+	}
+
+	return calendar, nil
+}
+
+func (p *PartnerRepository) DeleteCalendar(partnerId int, date string) error {
+	res := p.db.Unscoped().Where("partner_id = ? AND date = ?", partnerId, date).Delete(&models.PartnerCalendar{})
+	if res.Error != nil {
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+func (p *PartnerRepository) GetAvailability(partnerId int, from, to time.Time) ([]helper.DayAvailability, error) {
+	var partner models.Partner
+
+	if err := p.db.First(&partner, partnerId).Error; err != nil {
+		return nil, err
+	}
+
+	return helper.PartnerAvailability(p.db, partner, from, to)
+}
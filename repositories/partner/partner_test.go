@@ -2,8 +2,11 @@ package partner_test
 
 import (
 	"testing"
+	"time"
 
 	config "github.com/furqonzt99/snackbox/configs"
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/helper"
 
 	"github.com/furqonzt99/snackbox/models"
 	"github.com/furqonzt99/snackbox/repositories/partner"
@@ -31,6 +34,7 @@ func TestApplyPartner(t *testing.T) {
 	db.Migrator().DropTable(&models.Product{})
 	db.Migrator().DropTable(&models.Rating{})
 	db.Migrator().DropTable(&models.Transaction{})
+	db.Migrator().DropTable(&models.LedgerEntry{})
 	db.Migrator().DropTable(&models.DetailTransaction{})
 	db.Migrator().DropTable(&models.Cashout{})
 
@@ -42,6 +46,7 @@ func TestApplyPartner(t *testing.T) {
 	db.AutoMigrate(&models.Product{})
 	db.AutoMigrate(&models.Rating{})
 	db.AutoMigrate(&models.Transaction{})
+	db.AutoMigrate(&models.LedgerEntry{})
 	db.AutoMigrate(&models.DetailTransaction{})
 	db.AutoMigrate(&models.Cashout{})
 
@@ -82,6 +87,7 @@ func TestUpload(t *testing.T) {
 	db.Migrator().DropTable(&models.Product{})
 	db.Migrator().DropTable(&models.Rating{})
 	db.Migrator().DropTable(&models.Transaction{})
+	db.Migrator().DropTable(&models.LedgerEntry{})
 	db.Migrator().DropTable(&models.DetailTransaction{})
 	db.Migrator().DropTable(&models.Cashout{})
 
@@ -93,6 +99,7 @@ func TestUpload(t *testing.T) {
 	db.AutoMigrate(&models.Product{})
 	db.AutoMigrate(&models.Rating{})
 	db.AutoMigrate(&models.Transaction{})
+	db.AutoMigrate(&models.LedgerEntry{})
 	db.AutoMigrate(&models.DetailTransaction{})
 	db.AutoMigrate(&models.Cashout{})
 
@@ -141,6 +148,7 @@ func TestGetAllPartner(t *testing.T) {
 	db.Migrator().DropTable(&models.Product{})
 	db.Migrator().DropTable(&models.Rating{})
 	db.Migrator().DropTable(&models.Transaction{})
+	db.Migrator().DropTable(&models.LedgerEntry{})
 	db.Migrator().DropTable(&models.DetailTransaction{})
 	db.Migrator().DropTable(&models.Cashout{})
 
@@ -152,6 +160,7 @@ func TestGetAllPartner(t *testing.T) {
 	db.AutoMigrate(&models.Product{})
 	db.AutoMigrate(&models.Rating{})
 	db.AutoMigrate(&models.Transaction{})
+	db.AutoMigrate(&models.LedgerEntry{})
 	db.AutoMigrate(&models.DetailTransaction{})
 	db.AutoMigrate(&models.Cashout{})
 
@@ -193,6 +202,7 @@ func TestFindPartner(t *testing.T) {
 	db.Migrator().DropTable(&models.Product{})
 	db.Migrator().DropTable(&models.Rating{})
 	db.Migrator().DropTable(&models.Transaction{})
+	db.Migrator().DropTable(&models.LedgerEntry{})
 	db.Migrator().DropTable(&models.DetailTransaction{})
 	db.Migrator().DropTable(&models.Cashout{})
 
@@ -204,6 +214,7 @@ func TestFindPartner(t *testing.T) {
 	db.AutoMigrate(&models.Product{})
 	db.AutoMigrate(&models.Rating{})
 	db.AutoMigrate(&models.Transaction{})
+	db.AutoMigrate(&models.LedgerEntry{})
 	db.AutoMigrate(&models.DetailTransaction{})
 	db.AutoMigrate(&models.Cashout{})
 
@@ -244,6 +255,7 @@ func TestFindUserId(t *testing.T) {
 	db.Migrator().DropTable(&models.Product{})
 	db.Migrator().DropTable(&models.Rating{})
 	db.Migrator().DropTable(&models.Transaction{})
+	db.Migrator().DropTable(&models.LedgerEntry{})
 	db.Migrator().DropTable(&models.DetailTransaction{})
 	db.Migrator().DropTable(&models.Cashout{})
 
@@ -255,6 +267,7 @@ func TestFindUserId(t *testing.T) {
 	db.AutoMigrate(&models.Product{})
 	db.AutoMigrate(&models.Rating{})
 	db.AutoMigrate(&models.Transaction{})
+	db.AutoMigrate(&models.LedgerEntry{})
 	db.AutoMigrate(&models.DetailTransaction{})
 	db.AutoMigrate(&models.Cashout{})
 
@@ -294,6 +307,7 @@ func TestAcceptPartner(t *testing.T) {
 	db.Migrator().DropTable(&models.Product{})
 	db.Migrator().DropTable(&models.Rating{})
 	db.Migrator().DropTable(&models.Transaction{})
+	db.Migrator().DropTable(&models.LedgerEntry{})
 	db.Migrator().DropTable(&models.DetailTransaction{})
 	db.Migrator().DropTable(&models.Cashout{})
 
@@ -305,6 +319,7 @@ func TestAcceptPartner(t *testing.T) {
 	db.AutoMigrate(&models.Product{})
 	db.AutoMigrate(&models.Rating{})
 	db.AutoMigrate(&models.Transaction{})
+	db.AutoMigrate(&models.LedgerEntry{})
 	db.AutoMigrate(&models.DetailTransaction{})
 	db.AutoMigrate(&models.Cashout{})
 
@@ -355,6 +370,7 @@ func TestRejectPartner(t *testing.T) {
 	db.Migrator().DropTable(&models.Product{})
 	db.Migrator().DropTable(&models.Rating{})
 	db.Migrator().DropTable(&models.Transaction{})
+	db.Migrator().DropTable(&models.LedgerEntry{})
 	db.Migrator().DropTable(&models.DetailTransaction{})
 	db.Migrator().DropTable(&models.Cashout{})
 
@@ -366,6 +382,7 @@ func TestRejectPartner(t *testing.T) {
 	db.AutoMigrate(&models.Product{})
 	db.AutoMigrate(&models.Rating{})
 	db.AutoMigrate(&models.Transaction{})
+	db.AutoMigrate(&models.LedgerEntry{})
 	db.AutoMigrate(&models.DetailTransaction{})
 	db.AutoMigrate(&models.Cashout{})
 
@@ -469,6 +486,7 @@ func TestGetPartner(t *testing.T) {
 	db.Migrator().DropTable(&models.Product{})
 	db.Migrator().DropTable(&models.Rating{})
 	db.Migrator().DropTable(&models.Transaction{})
+	db.Migrator().DropTable(&models.LedgerEntry{})
 	db.Migrator().DropTable(&models.DetailTransaction{})
 	db.Migrator().DropTable(&models.Cashout{})
 
@@ -480,6 +498,7 @@ func TestGetPartner(t *testing.T) {
 	db.AutoMigrate(&models.Product{})
 	db.AutoMigrate(&models.Rating{})
 	db.AutoMigrate(&models.Transaction{})
+	db.AutoMigrate(&models.LedgerEntry{})
 	db.AutoMigrate(&models.DetailTransaction{})
 	db.AutoMigrate(&models.Cashout{})
 
@@ -522,21 +541,25 @@ func TestReport(t *testing.T) {
 	db.Migrator().DropTable(&models.Product{})
 	db.Migrator().DropTable(&models.Rating{})
 	db.Migrator().DropTable(&models.Transaction{})
+	db.Migrator().DropTable(&models.LedgerEntry{})
 	db.Migrator().DropTable(&models.DetailTransaction{})
 	db.Migrator().DropTable(&models.Cashout{})
+	db.Migrator().DropTable(&models.TransactionTax{})
 
 	userRepo = usr.NewUserRepo(db)
 	partnerRepo = partner.NewPartnerRepo(db)
 	productRepo = product.NewProductRepo(db)
-	transactionRepo = transaction.NewTransactionRepository(db)
+	transactionRepo = transaction.NewTransactionRepository(db, utils.InitPaymentProvider(configTest))
 
 	db.AutoMigrate(&models.User{})
 	db.AutoMigrate(&models.Partner{})
 	db.AutoMigrate(&models.Product{})
 	db.AutoMigrate(&models.Rating{})
 	db.AutoMigrate(&models.Transaction{})
+	db.AutoMigrate(&models.LedgerEntry{})
 	db.AutoMigrate(&models.DetailTransaction{})
 	db.AutoMigrate(&models.Cashout{})
+	db.AutoMigrate(&models.TransactionTax{})
 
 	//CREATE USER
 	dummyUser := models.User{
@@ -592,6 +615,18 @@ func TestReport(t *testing.T) {
 	}
 	db.Create(&dummyTransactionDetail)
 
+	dummyCancelled := models.Transaction{
+		Model:     gorm.Model{CreatedAt: time.Now().Add(-time.Hour)},
+		PartnerID: 1,
+		UserID:    2,
+		Quantity:  1,
+		Status:    "CANCEL",
+	}
+	db.Create(&dummyCancelled)
+
+	db.Create(&models.TransactionTax{TransactionID: 1, Type: "PB1", Rate: 10, Base: 1000, Amount: 100})
+	db.Create(&models.TransactionTax{TransactionID: 2, Type: "PB1", Rate: 10, Base: 5000, Amount: 500})
+
 	t.Run("get partner", func(t *testing.T) {
 
 		res, _ := partnerRepo.Report(1)
@@ -599,4 +634,131 @@ func TestReport(t *testing.T) {
 
 	})
 
+	t.Run("tax report", func(t *testing.T) {
+
+		res, err := partnerRepo.TaxReport(1, constants.MONTHLY_PERIOD)
+		assert.Nil(t, err)
+		assert.Len(t, res, 1)
+		assert.Equal(t, time.Now().Format("2006-01"), res[0].Period)
+		assert.Equal(t, 1, res[0].Orders)
+		assert.Equal(t, models.Money(100), res[0].Amount)
+
+	})
+
+}
+
+func TestAvailability(t *testing.T) {
+	configTest = config.GetConfig()
+	db = utils.InitDB(configTest)
+
+	db.Migrator().DropTable(&models.User{})
+	db.Migrator().DropTable(&models.Partner{})
+	db.Migrator().DropTable(&models.Transaction{})
+	db.Migrator().DropTable(&models.LedgerEntry{})
+	db.Migrator().DropTable(&models.PartnerCalendar{})
+
+	userRepo = usr.NewUserRepo(db)
+	partnerRepo = partner.NewPartnerRepo(db)
+
+	db.AutoMigrate(&models.User{})
+	db.AutoMigrate(&models.Partner{})
+	db.AutoMigrate(&models.Transaction{})
+	db.AutoMigrate(&models.LedgerEntry{})
+	db.AutoMigrate(&models.PartnerCalendar{})
+
+	//CREATE USER
+	dummyUser := models.User{
+		Email:    "test@gmail.com",
+		Password: "test1234",
+	}
+	userRepo.Register(dummyUser)
+
+	//CREATE PARTNER
+	dummyPartner := models.Partner{
+		UserID:        1,
+		BussinessName: "partner1",
+		Status:        "active",
+	}
+	partnerRepo.ApplyPartner(dummyPartner)
+
+	//CREATE TRANSACTIONS
+	db.Create(&models.Transaction{PartnerID: 1, UserID: 1, Quantity: 30, DateTime: time.Date(2026, 12, 1, 10, 0, 0, 0, time.UTC), Status: constants.PAID_STATUS})
+	db.Create(&models.Transaction{PartnerID: 1, UserID: 1, Quantity: 50, DateTime: time.Date(2026, 12, 1, 23, 0, 0, 0, time.UTC), Status: constants.CANCEL_STATUS})
+
+	from := time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 12, 3, 0, 0, 0, 0, time.UTC)
+
+	t.Run("unlimited without capacity", func(t *testing.T) {
+		res, err := partnerRepo.GetAvailability(1, from, to)
+		assert.Nil(t, err)
+		assert.Equal(t, 3, len(res))
+		assert.True(t, res[0].Unlimited)
+		assert.Equal(t, 30, res[0].Booked)
+	})
+
+	t.Run("update capacity", func(t *testing.T) {
+		err := partnerRepo.UpdateCapacity(1, 100)
+		assert.Nil(t, err)
+
+		res, _ := partnerRepo.GetAvailability(1, from, to)
+		assert.Equal(t, 70, res[0].Remaining)
+		assert.Equal(t, 100, res[1].Remaining)
+	})
+
+	t.Run("set calendar", func(t *testing.T) {
+		capacity := 40
+		_, err := partnerRepo.SetCalendar(models.PartnerCalendar{PartnerID: 1, Date: "2026-12-01", Capacity: &capacity})
+		assert.Nil(t, err)
+		_, err = partnerRepo.SetCalendar(models.PartnerCalendar{PartnerID: 1, Date: "2026-12-02", Blackout: true, Note: "closed"})
+		assert.Nil(t, err)
+
+		res, _ := partnerRepo.GetAvailability(1, from, to)
+		assert.Equal(t, 10, res[0].Remaining)
+		assert.True(t, res[1].Blackout)
+		assert.Equal(t, 0, res[1].Remaining)
+	})
+
+	t.Run("validate capacity", func(t *testing.T) {
+		var dummy models.Partner
+		db.First(&dummy, 1)
+
+		assert.Nil(t, helper.ValidatePartnerCapacity(db, dummy, time.Date(2026, 12, 1, 12, 0, 0, 0, time.UTC), 10))
+		assert.Equal(t, helper.ErrCapacityExceeded, helper.ValidatePartnerCapacity(db, dummy, time.Date(2026, 12, 1, 12, 0, 0, 0, time.UTC), 11))
+		assert.Equal(t, helper.ErrPartnerUnavailable, helper.ValidatePartnerCapacity(db, dummy, time.Date(2026, 12, 2, 12, 0, 0, 0, time.UTC), 1))
+	})
+
+	t.Run("zero capacity override", func(t *testing.T) {
+		zero := 0
+		_, err := partnerRepo.SetCalendar(models.PartnerCalendar{PartnerID: 1, Date: "2026-12-03", Capacity: &zero})
+		assert.Nil(t, err)
+
+		res, _ := partnerRepo.GetAvailability(1, from, to)
+		assert.False(t, res[2].Unlimited)
+		assert.Equal(t, 0, res[2].Remaining)
+
+		var dummy models.Partner
+		db.First(&dummy, 1)
+		assert.Equal(t, helper.ErrCapacityExceeded, helper.ValidatePartnerCapacity(db, dummy, time.Date(2026, 12, 3, 12, 0, 0, 0, time.UTC), 1))
+	})
+
+	t.Run("delete calendar", func(t *testing.T) {
+		err := partnerRepo.DeleteCalendar(1, "2026-12-02")
+		assert.Nil(t, err)
+
+		err = partnerRepo.DeleteCalendar(1, "2026-12-02")
+		assert.NotNil(t, err)
+	})
+
+	t.Run("update order rules", func(t *testing.T) {
+		res, err := partnerRepo.UpdateOrderRules(1, models.Partner{LeadTimeHours: 0, MinQuantity: 10, MaxDistance: 25})
+		assert.Nil(t, err)
+		assert.Equal(t, 0, res.LeadTimeHours)
+		assert.Equal(t, 10, res.MinQuantity)
+		assert.Equal(t, float64(25), res.MaxDistance)
+	})
+
+	t.Run("availability partner not found", func(t *testing.T) {
+		_, err := partnerRepo.GetAvailability(99, from, to)
+		assert.NotNil(t, err)
+	})
 }
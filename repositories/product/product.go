@@ -68,9 +68,9 @@ func (p *ProductRepository) GetAllProduct(offset, pageSize int, search, category
 
 	nearestPartner := []int{}
 	const EARTH_RADIUS_IN_KILOMETER = 6371 
-	const MAX_DISTANCE = 10 
 
-	p.db.Raw("SELECT id, (? * ACOS ( COS ( RADIANS ( ? ) ) * COS ( RADIANS (latitude) ) * COS ( RADIANS (longtitude) - RADIANS ( ? ) ) + SIN ( RADIANS ( ? ) ) * SIN ( RADIANS (latitude)))) AS distance FROM partners HAVING distance < ? ORDER BY distance", EARTH_RADIUS_IN_KILOMETER, latitude, longtitude, latitude, MAX_DISTANCE).Scan(&nearestPartner)
+	// every partner delivers within its own radius, a radius of 0 means no limit
+	p.db.Raw("SELECT id FROM (SELECT id, max_distance, (? * ACOS ( COS ( RADIANS ( ? ) ) * COS ( RADIANS (latitude) ) * COS ( RADIANS (longtitude) - RADIANS ( ? ) ) + SIN ( RADIANS ( ? ) ) * SIN ( RADIANS (latitude)))) AS distance FROM partners) AS nearest WHERE max_distance = 0 OR distance <= max_distance ORDER BY distance", EARTH_RADIUS_IN_KILOMETER, latitude, longtitude, latitude).Scan(&nearestPartner)
 
 	p.db.Offset(offset).Limit(pageSize).Where("partner_id IN ? AND title LIKE ? AND type LIKE ?", nearestPartner, "%"+search+"%", "%"+category+"%").Find(&products)
 	
@@ -1,6 +1,7 @@
 package rating
 
 import (
+	"github.com/furqonzt99/snackbox/constants"
 	"github.com/furqonzt99/snackbox/models"
 	"gorm.io/gorm"
 )
@@ -23,9 +24,7 @@ func NewRatingRepository(db *gorm.DB) *RatingRepository {
 func (rr RatingRepository) IsCanGiveRating(userId, transactionId int) (models.Transaction, error) {
 	var transaction models.Transaction
 
-	const CONFIRM_STATUS = "CONFIRM"
-
-	if err := rr.db.Where("user_id = ? AND id = ? AND status = ?", userId, transactionId, CONFIRM_STATUS).First(&transaction).Error; err != nil {
+	if err := rr.db.Where("user_id = ? AND id = ? AND status = ?", userId, transactionId, constants.CONFIRM_STATUS).First(&transaction).Error; err != nil {
 		return transaction, err
 	}
 
@@ -30,12 +30,13 @@ func TestCreate(t *testing.T) {
 	db.Migrator().DropTable(&models.Product{})
 	db.Migrator().DropTable(&models.Rating{})
 	db.Migrator().DropTable(&models.Transaction{})
+	db.Migrator().DropTable(&models.LedgerEntry{})
 	db.Migrator().DropTable(&models.DetailTransaction{})
 	db.Migrator().DropTable(&models.Cashout{})
 
 	userRepo = user.NewUserRepo(db)
 	partnerRepo = partner.NewPartnerRepo(db)
-	transactionRepo = transaction.NewTransactionRepository(db)
+	transactionRepo = transaction.NewTransactionRepository(db, utils.InitPaymentProvider(configTest))
 	ratingRepo = rating.NewRatingRepository(db)
 
 	db.AutoMigrate(&models.User{})
@@ -43,6 +44,7 @@ func TestCreate(t *testing.T) {
 	db.AutoMigrate(&models.Product{})
 	db.AutoMigrate(&models.Rating{})
 	db.AutoMigrate(&models.Transaction{})
+	db.AutoMigrate(&models.LedgerEntry{})
 	db.AutoMigrate(&models.DetailTransaction{})
 	db.AutoMigrate(&models.Cashout{})
 
@@ -109,12 +111,13 @@ func TestUpdate(t *testing.T) {
 	db.Migrator().DropTable(&models.Product{})
 	db.Migrator().DropTable(&models.Rating{})
 	db.Migrator().DropTable(&models.Transaction{})
+	db.Migrator().DropTable(&models.LedgerEntry{})
 	db.Migrator().DropTable(&models.DetailTransaction{})
 	db.Migrator().DropTable(&models.Cashout{})
 
 	userRepo = user.NewUserRepo(db)
 	partnerRepo = partner.NewPartnerRepo(db)
-	transactionRepo = transaction.NewTransactionRepository(db)
+	transactionRepo = transaction.NewTransactionRepository(db, utils.InitPaymentProvider(configTest))
 	ratingRepo = rating.NewRatingRepository(db)
 
 	db.AutoMigrate(&models.User{})
@@ -122,6 +125,7 @@ func TestUpdate(t *testing.T) {
 	db.AutoMigrate(&models.Product{})
 	db.AutoMigrate(&models.Rating{})
 	db.AutoMigrate(&models.Transaction{})
+	db.AutoMigrate(&models.LedgerEntry{})
 	db.AutoMigrate(&models.DetailTransaction{})
 	db.AutoMigrate(&models.Cashout{})
 
@@ -196,12 +200,13 @@ func TestGetByTrxID(t *testing.T) {
 	db.Migrator().DropTable(&models.Product{})
 	db.Migrator().DropTable(&models.Rating{})
 	db.Migrator().DropTable(&models.Transaction{})
+	db.Migrator().DropTable(&models.LedgerEntry{})
 	db.Migrator().DropTable(&models.DetailTransaction{})
 	db.Migrator().DropTable(&models.Cashout{})
 
 	userRepo = user.NewUserRepo(db)
 	partnerRepo = partner.NewPartnerRepo(db)
-	transactionRepo = transaction.NewTransactionRepository(db)
+	transactionRepo = transaction.NewTransactionRepository(db, utils.InitPaymentProvider(configTest))
 	ratingRepo = rating.NewRatingRepository(db)
 
 	db.AutoMigrate(&models.User{})
@@ -209,6 +214,7 @@ func TestGetByTrxID(t *testing.T) {
 	db.AutoMigrate(&models.Product{})
 	db.AutoMigrate(&models.Rating{})
 	db.AutoMigrate(&models.Transaction{})
+	db.AutoMigrate(&models.LedgerEntry{})
 	db.AutoMigrate(&models.DetailTransaction{})
 	db.AutoMigrate(&models.Cashout{})
 
@@ -283,12 +289,13 @@ func TestIsCanGiveRating(t *testing.T) {
 	db.Migrator().DropTable(&models.Product{})
 	db.Migrator().DropTable(&models.Rating{})
 	db.Migrator().DropTable(&models.Transaction{})
+	db.Migrator().DropTable(&models.LedgerEntry{})
 	db.Migrator().DropTable(&models.DetailTransaction{})
 	db.Migrator().DropTable(&models.Cashout{})
 
 	userRepo = user.NewUserRepo(db)
 	partnerRepo = partner.NewPartnerRepo(db)
-	transactionRepo = transaction.NewTransactionRepository(db)
+	transactionRepo = transaction.NewTransactionRepository(db, utils.InitPaymentProvider(configTest))
 	ratingRepo = rating.NewRatingRepository(db)
 
 	db.AutoMigrate(&models.User{})
@@ -296,6 +303,7 @@ func TestIsCanGiveRating(t *testing.T) {
 	db.AutoMigrate(&models.Product{})
 	db.AutoMigrate(&models.Rating{})
 	db.AutoMigrate(&models.Transaction{})
+	db.AutoMigrate(&models.LedgerEntry{})
 	db.AutoMigrate(&models.DetailTransaction{})
 	db.AutoMigrate(&models.Cashout{})
 
@@ -0,0 +1,125 @@
+package reconciliation
+
+import (
+	"time"
+
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/models"
+	"github.com/furqonzt99/snackbox/payment"
+	"gorm.io/gorm"
+)
+
+type ReconciliationInterface interface {
+	GetOpenInvoices(before time.Time) ([]models.TransactionInvoice, error)
+	GetOpenTopups(before time.Time) ([]models.WalletTopup, error)
+	GetOpenCashouts(before time.Time) ([]models.Cashout, error)
+	GetUnsettledInvoices(before time.Time) ([]models.TransactionInvoice, error)
+	SettleTransaction(trxID uint, status string) error
+	SaveRun(run models.ReconciliationRun) (models.ReconciliationRun, error)
+	GetRuns() ([]models.ReconciliationRun, error)
+	GetRun(runID int) (models.ReconciliationRun, error)
+}
+
+type ReconciliationRepository struct {
+	db *gorm.DB
+}
+
+func NewReconciliationRepository(db *gorm.DB) *ReconciliationRepository {
+	return &ReconciliationRepository{db: db}
+}
+
+// GetOpenInvoices lists the unpaid invoices of orders still waiting for payment that were last
+// touched before the time. Every invoice issued for an order is checked, not only the latest.
+func (rr *ReconciliationRepository) GetOpenInvoices(before time.Time) ([]models.TransactionInvoice, error) {
+	invoices := []models.TransactionInvoice{}
+
+	err := rr.db.Joins("JOIN transactions ON transactions.id = transaction_invoices.transaction_id AND transactions.deleted_at IS NULL").
+		Where("transaction_invoices.status = ? AND transactions.status = ? AND transaction_invoices.updated_at <= ?", payment.INVOICE_PENDING, constants.PENDING_STATUS, before).
+		Order("transaction_invoices.id").Find(&invoices).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return invoices, nil
+}
+
+// GetOpenTopups lists the top-ups with an invoice that is still unpaid and was last touched
+// before the time.
+func (rr *ReconciliationRepository) GetOpenTopups(before time.Time) ([]models.WalletTopup, error) {
+	topups := []models.WalletTopup{}
+
+	err := rr.db.Where("status = ? AND payment_invoice_id <> '' AND updated_at <= ?", constants.PENDING_STATUS, before).
+		Order("id").Find(&topups).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return topups, nil
+}
+
+// GetOpenCashouts lists the cashouts sent to the bank that were neither completed nor failed
+// before the time. Cashouts waiting for an admin were not sent yet and are left out.
+func (rr *ReconciliationRepository) GetOpenCashouts(before time.Time) ([]models.Cashout, error) {
+	cashouts := []models.Cashout{}
+
+	err := rr.db.Where("status = ? AND external_id <> '' AND updated_at <= ?", constants.CASHOUT_PENDING, before).
+		Order("id").Find(&cashouts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return cashouts, nil
+}
+
+// GetUnsettledInvoices lists the paid invoices of orders paid before the time whose money the
+// provider has not settled yet.
+func (rr *ReconciliationRepository) GetUnsettledInvoices(before time.Time) ([]models.TransactionInvoice, error) {
+	invoices := []models.TransactionInvoice{}
+
+	err := rr.db.Joins("JOIN transactions ON transactions.id = transaction_invoices.transaction_id AND transactions.deleted_at IS NULL").
+		Where("transaction_invoices.status = ? AND transactions.settlement_status = ? AND transactions.paid_at <= ?", payment.INVOICE_PAID, constants.SETTLEMENT_PENDING, before).
+		Order("transaction_invoices.id").Find(&invoices).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return invoices, nil
+}
+
+// SettleTransaction records the settlement status the provider reported on an order that was
+// still waiting for it.
+func (rr *ReconciliationRepository) SettleTransaction(trxID uint, status string) error {
+	return rr.db.Model(&models.Transaction{}).
+		Where("id = ? AND settlement_status = ?", trxID, constants.SETTLEMENT_PENDING).
+		Update("settlement_status", status).Error
+}
+
+// SaveRun stores the run together with its discrepancies.
+func (rr *ReconciliationRepository) SaveRun(run models.ReconciliationRun) (models.ReconciliationRun, error) {
+	if err := rr.db.Create(&run).Error; err != nil {
+		return run, err
+	}
+
+	return run, nil
+}
+
+// GetRuns lists the runs, latest first, without their discrepancies.
+func (rr *ReconciliationRepository) GetRuns() ([]models.ReconciliationRun, error) {
+	runs := []models.ReconciliationRun{}
+
+	if err := rr.db.Order("id desc").Find(&runs).Error; err != nil {
+		return nil, err
+	}
+
+	return runs, nil
+}
+
+func (rr *ReconciliationRepository) GetRun(runID int) (models.ReconciliationRun, error) {
+	run := models.ReconciliationRun{}
+
+	if err := rr.db.Preload("Discrepancies").First(&run, runID).Error; err != nil {
+		return run, err
+	}
+
+	return run, nil
+}
@@ -0,0 +1,116 @@
+package reconciliation_test
+
+import (
+	"testing"
+	"time"
+
+	config "github.com/furqonzt99/snackbox/configs"
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/models"
+	"github.com/furqonzt99/snackbox/payment"
+	"github.com/furqonzt99/snackbox/repositories/reconciliation"
+	"github.com/furqonzt99/snackbox/utils"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+var configTest *config.AppConfig
+var db *gorm.DB
+var reconciliationRepo *reconciliation.ReconciliationRepository
+
+func TestReconciliation(t *testing.T) {
+	configTest = config.GetConfig()
+	db = utils.InitDB(configTest)
+
+	db.Migrator().DropTable(&models.ReconciliationDiscrepancy{})
+	db.Migrator().DropTable(&models.ReconciliationRun{})
+	db.Migrator().DropTable(&models.WalletTopup{})
+	db.Migrator().DropTable(&models.Cashout{})
+	db.Migrator().DropTable(&models.TransactionInvoice{})
+	db.Migrator().DropTable(&models.Transaction{})
+
+	reconciliationRepo = reconciliation.NewReconciliationRepository(db)
+
+	db.AutoMigrate(&models.Transaction{})
+	db.AutoMigrate(&models.TransactionInvoice{})
+	db.AutoMigrate(&models.Cashout{})
+	db.AutoMigrate(&models.WalletTopup{})
+	db.AutoMigrate(&models.ReconciliationRun{})
+	db.AutoMigrate(&models.ReconciliationDiscrepancy{})
+
+	db.Create(&models.Transaction{InvoiceID: "INV1", PaymentInvoiceID: "inv1-2", Status: constants.PENDING_STATUS})
+	db.Create(&models.Transaction{InvoiceID: "INV2", Status: constants.PENDING_STATUS})
+	db.Create(&models.Transaction{InvoiceID: "INV3", PaymentInvoiceID: "inv3", Status: constants.PAID_STATUS})
+	db.Create(&models.TransactionInvoice{TransactionID: 1, ExternalID: "INV1", PaymentInvoiceID: "inv1", Status: payment.INVOICE_EXPIRED})
+	db.Create(&models.TransactionInvoice{TransactionID: 1, ExternalID: "INV1-2", PaymentInvoiceID: "inv1-2", Status: payment.INVOICE_PENDING})
+	db.Create(&models.TransactionInvoice{TransactionID: 3, ExternalID: "INV3", PaymentInvoiceID: "inv3", Status: payment.INVOICE_PENDING})
+	db.Create(&models.Transaction{InvoiceID: "INV4", PaymentInvoiceID: "inv4-2", PaidAt: time.Now(), SettlementStatus: constants.SETTLEMENT_PENDING, Status: constants.PAID_STATUS})
+	db.Create(&models.TransactionInvoice{TransactionID: 4, ExternalID: "INV4-2", PaymentInvoiceID: "inv4-2", Status: payment.INVOICE_PAID})
+	db.Create(&models.WalletTopup{ExternalID: "TOPUP-1", PaymentInvoiceID: "inv4", Status: constants.PENDING_STATUS})
+	db.Create(&models.Cashout{ExternalID: "CASHOUT-1", Status: constants.CASHOUT_PENDING})
+	db.Create(&models.Cashout{Status: constants.CASHOUT_REQUESTED})
+
+	later := time.Now().Add(time.Minute)
+
+	t.Run("open records", func(t *testing.T) {
+		invoices, err := reconciliationRepo.GetOpenInvoices(later)
+		assert.Nil(t, err)
+		assert.Len(t, invoices, 1)
+		assert.Equal(t, "INV1-2", invoices[0].ExternalID)
+
+		topups, err := reconciliationRepo.GetOpenTopups(later)
+		assert.Nil(t, err)
+		assert.Len(t, topups, 1)
+
+		cashouts, err := reconciliationRepo.GetOpenCashouts(later)
+		assert.Nil(t, err)
+		assert.Len(t, cashouts, 1)
+		assert.Equal(t, "CASHOUT-1", cashouts[0].ExternalID)
+	})
+
+	t.Run("records within the grace period", func(t *testing.T) {
+		invoices, err := reconciliationRepo.GetOpenInvoices(time.Now().Add(-time.Hour))
+		assert.Nil(t, err)
+		assert.Len(t, invoices, 0)
+	})
+
+	t.Run("unsettled invoices", func(t *testing.T) {
+		invoices, err := reconciliationRepo.GetUnsettledInvoices(later)
+		assert.Nil(t, err)
+		assert.Len(t, invoices, 1)
+		assert.Equal(t, "INV4-2", invoices[0].ExternalID)
+
+		assert.Nil(t, reconciliationRepo.SettleTransaction(4, constants.SETTLEMENT_SETTLED))
+
+		invoices, err = reconciliationRepo.GetUnsettledInvoices(later)
+		assert.Nil(t, err)
+		assert.Len(t, invoices, 0)
+	})
+
+	t.Run("save and get run", func(t *testing.T) {
+		run, err := reconciliationRepo.SaveRun(models.ReconciliationRun{
+			Checked: 1,
+			Applied: 1,
+			Discrepancies: []models.ReconciliationDiscrepancy{{
+				Kind:       constants.DISCREPANCY_MISSED_CALLBACK,
+				SourceType: constants.TRANSACTION_SOURCE,
+				SourceID:   1,
+				Reference:  "INV1",
+			}},
+		})
+		assert.Nil(t, err)
+
+		res, err := reconciliationRepo.GetRun(int(run.ID))
+		assert.Nil(t, err)
+		assert.Equal(t, "INV1", res.Discrepancies[0].Reference)
+
+		runs, err := reconciliationRepo.GetRuns()
+		assert.Nil(t, err)
+		assert.Len(t, runs, 1)
+	})
+
+	t.Run("get run not found", func(t *testing.T) {
+		_, err := reconciliationRepo.GetRun(99)
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,258 @@
+package refund
+
+import (
+	"errors"
+	"time"
+
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/helper"
+	"github.com/furqonzt99/snackbox/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RefundInterface interface {
+	Create(refund models.RefundRequest) (models.RefundRequest, error)
+	GetAll(userID, partnerID int, status string) ([]models.RefundRequest, error)
+	Get(refundID int) (models.RefundRequest, error)
+	UploadPhoto(refundID, userID int, photo string) (models.RefundRequest, error)
+	Accept(refundID, partnerID int) (models.RefundRequest, error)
+	Counter(refundID, partnerID int, amount models.Money, note string) (models.RefundRequest, error)
+	AcceptCounter(refundID, userID int) (models.RefundRequest, error)
+	Escalate(refundID, userID int) (models.RefundRequest, error)
+	Resolve(refundID int, amount models.Money, note string) (models.RefundRequest, error)
+}
+
+type RefundRepository struct {
+	db *gorm.DB
+}
+
+func NewRefundRepository(db *gorm.DB) *RefundRepository {
+	return &RefundRepository{db: db}
+}
+
+// Create files a claim on one of the customer's delivered orders. An order has one open claim at a time.
+func (rr *RefundRepository) Create(refund models.RefundRequest) (models.RefundRequest, error) {
+	err := rr.db.Transaction(func(tx *gorm.DB) error {
+		trx := models.Transaction{}
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ?", refund.UserID).First(&trx, refund.TransactionID).Error; err != nil {
+			return err
+		}
+
+		if trx.Status != constants.SEND_STATUS && trx.Status != constants.CONFIRM_STATUS {
+			return helper.RefundError("only delivered orders can be refunded")
+		}
+
+		var open int64
+		if err := tx.Model(&models.RefundRequest{}).
+			Where("transaction_id = ? AND status NOT IN ?", trx.ID, []string{constants.REFUND_APPROVED, constants.REFUND_REJECTED}).
+			Count(&open).Error; err != nil {
+			return err
+		}
+
+		if open > 0 {
+			return helper.RefundError("this order already has an open refund request")
+		}
+
+		if err := helper.ValidateRefundAmount(tx, trx, refund.Amount); err != nil {
+			return err
+		}
+
+		refund.PartnerID = trx.PartnerID
+		refund.Status = constants.REFUND_REQUESTED
+
+		return tx.Create(&refund).Error
+	})
+
+	if err != nil {
+		return refund, err
+	}
+
+	return rr.Get(int(refund.ID))
+}
+
+// GetAll lists refund requests, newest first, of the customer or the partner when their id is
+// not 0, optionally with the given status.
+func (rr *RefundRepository) GetAll(userID, partnerID int, status string) ([]models.RefundRequest, error) {
+	refunds := []models.RefundRequest{}
+
+	query := rr.db.Preload("Transaction")
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	if partnerID != 0 {
+		query = query.Where("partner_id = ?", partnerID)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Order("id desc").Find(&refunds).Error; err != nil {
+		return nil, err
+	}
+
+	return refunds, nil
+}
+
+func (rr *RefundRepository) Get(refundID int) (models.RefundRequest, error) {
+	refund := models.RefundRequest{}
+
+	if err := rr.db.Preload("Transaction").First(&refund, refundID).Error; err != nil {
+		return refund, err
+	}
+
+	return refund, nil
+}
+
+func (rr *RefundRepository) UploadPhoto(refundID, userID int, photo string) (models.RefundRequest, error) {
+	refund := models.RefundRequest{}
+
+	if err := rr.db.Where("user_id = ?", userID).First(&refund, refundID).Error; err != nil {
+		return refund, err
+	}
+
+	if err := rr.db.Model(&refund).Update("photo", photo).Error; err != nil {
+		return refund, err
+	}
+
+	return rr.Get(refundID)
+}
+
+// Accept approves the amount the customer asked for.
+func (rr *RefundRepository) Accept(refundID, partnerID int) (models.RefundRequest, error) {
+	return rr.update(refundID, func(tx *gorm.DB, refund *models.RefundRequest) error {
+		if refund.PartnerID != uint(partnerID) {
+			return gorm.ErrRecordNotFound
+		}
+
+		if refund.Status != constants.REFUND_REQUESTED {
+			return helper.RefundError("only a new request can be accepted")
+		}
+
+		return approve(tx, refund, refund.Amount)
+	})
+}
+
+// Counter offers the customer a lower amount instead.
+func (rr *RefundRepository) Counter(refundID, partnerID int, amount models.Money, note string) (models.RefundRequest, error) {
+	return rr.update(refundID, func(tx *gorm.DB, refund *models.RefundRequest) error {
+		if refund.PartnerID != uint(partnerID) {
+			return gorm.ErrRecordNotFound
+		}
+
+		if refund.Status != constants.REFUND_REQUESTED {
+			return helper.RefundError("only a new request can be countered")
+		}
+
+		if amount <= 0 || amount >= refund.Amount {
+			return helper.RefundError("the counter offer must be more than 0 and less than the requested amount")
+		}
+
+		refund.CounterAmount = amount
+		refund.PartnerNote = note
+		refund.Status = constants.REFUND_COUNTERED
+
+		return tx.Model(refund).Select("counter_amount", "partner_note", "status").Updates(refund).Error
+	})
+}
+
+// AcceptCounter approves the amount the partner offered.
+func (rr *RefundRepository) AcceptCounter(refundID, userID int) (models.RefundRequest, error) {
+	return rr.update(refundID, func(tx *gorm.DB, refund *models.RefundRequest) error {
+		if refund.UserID != uint(userID) {
+			return gorm.ErrRecordNotFound
+		}
+
+		if refund.Status != constants.REFUND_COUNTERED {
+			return helper.RefundError("there is no counter offer to accept")
+		}
+
+		return approve(tx, refund, refund.CounterAmount)
+	})
+}
+
+// Escalate hands the request over to an admin when the customer and the partner do not agree.
+func (rr *RefundRepository) Escalate(refundID, userID int) (models.RefundRequest, error) {
+	return rr.update(refundID, func(tx *gorm.DB, refund *models.RefundRequest) error {
+		if refund.UserID != uint(userID) {
+			return gorm.ErrRecordNotFound
+		}
+
+		if refund.Status != constants.REFUND_REQUESTED && refund.Status != constants.REFUND_COUNTERED {
+			return helper.RefundError("only a request waiting for an agreement can be escalated")
+		}
+
+		refund.Status = constants.REFUND_DISPUTED
+
+		return tx.Model(refund).Update("status", refund.Status).Error
+	})
+}
+
+// Resolve is the admin's ruling on an open request: amount is refunded, 0 rejects the request.
+func (rr *RefundRepository) Resolve(refundID int, amount models.Money, note string) (models.RefundRequest, error) {
+	return rr.update(refundID, func(tx *gorm.DB, refund *models.RefundRequest) error {
+		if refund.Status == constants.REFUND_APPROVED || refund.Status == constants.REFUND_REJECTED {
+			return helper.RefundError("the request is already closed")
+		}
+
+		refund.AdminNote = note
+
+		if amount == 0 {
+			refund.Status = constants.REFUND_REJECTED
+			refund.ResolvedAt = time.Now()
+
+			return tx.Model(refund).Select("admin_note", "status", "resolved_at").Updates(refund).Error
+		}
+
+		if err := tx.Model(refund).Update("admin_note", note).Error; err != nil {
+			return err
+		}
+
+		return approve(tx, refund, amount)
+	})
+}
+
+// update locks the request while apply moves it along.
+func (rr *RefundRepository) update(refundID int, apply func(tx *gorm.DB, refund *models.RefundRequest) error) (models.RefundRequest, error) {
+	err := rr.db.Transaction(func(tx *gorm.DB) error {
+		refund := models.RefundRequest{}
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&refund, refundID).Error; err != nil {
+			return err
+		}
+
+		return apply(tx, &refund)
+	})
+
+	if err != nil {
+		if errors.Is(err, helper.ErrInsufficientBalance) {
+			return models.RefundRequest{}, helper.RefundError("the partner's balance is not enough to cover the refund")
+		}
+		return models.RefundRequest{}, err
+	}
+
+	return rr.Get(refundID)
+}
+
+// approve refunds amount to the customer and closes the request.
+func approve(tx *gorm.DB, refund *models.RefundRequest, amount models.Money) error {
+	trx := models.Transaction{}
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&trx, refund.TransactionID).Error; err != nil {
+		return err
+	}
+
+	if err := helper.ValidateRefundAmount(tx, trx, amount); err != nil {
+		return err
+	}
+
+	refund.ApprovedAmount = amount
+	refund.Status = constants.REFUND_APPROVED
+	refund.ResolvedAt = time.Now()
+
+	if err := tx.Model(refund).Select("approved_amount", "status", "resolved_at").Updates(refund).Error; err != nil {
+		return err
+	}
+
+	return helper.SettleRefund(tx, trx, *refund)
+}
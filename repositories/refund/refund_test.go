@@ -0,0 +1,171 @@
+package refund_test
+
+import (
+	"errors"
+	"testing"
+
+	config "github.com/furqonzt99/snackbox/configs"
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/helper"
+	"github.com/furqonzt99/snackbox/models"
+	"github.com/furqonzt99/snackbox/repositories/refund"
+	"github.com/furqonzt99/snackbox/utils"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+var configTest *config.AppConfig
+var db *gorm.DB
+var refundRepo *refund.RefundRepository
+
+func TestRefund(t *testing.T) {
+	configTest = config.GetConfig()
+	db = utils.InitDB(configTest)
+
+	constants.EARNING_HOLD_DAYS = 7
+
+	db.Migrator().DropTable(&models.RefundRequest{})
+	db.Migrator().DropTable(&models.TransactionFee{})
+	db.Migrator().DropTable(&models.CommissionRule{})
+	db.Migrator().DropTable(&models.LedgerEntry{})
+	db.Migrator().DropTable(&models.DetailTransaction{})
+	db.Migrator().DropTable(&models.Transaction{})
+	db.Migrator().DropTable(&models.Partner{})
+	db.Migrator().DropTable(&models.User{})
+
+	refundRepo = refund.NewRefundRepository(db)
+
+	db.AutoMigrate(&models.User{})
+	db.AutoMigrate(&models.Partner{})
+	db.AutoMigrate(&models.Transaction{})
+	db.AutoMigrate(&models.DetailTransaction{})
+	db.AutoMigrate(&models.LedgerEntry{})
+	db.AutoMigrate(&models.CommissionRule{})
+	db.AutoMigrate(&models.TransactionFee{})
+	db.AutoMigrate(&models.RefundRequest{})
+
+	db.Create(&models.User{Email: "partner@gmail.com", Password: "test1234", Role: "partner"})
+	db.Create(&models.User{Email: "user@gmail.com", Password: "test1234", Role: "user"})
+	db.Create(&models.Partner{UserID: 1, BussinessName: "partner1", Status: "active"})
+	db.Create(&models.CommissionRule{Rate: 10})
+
+	trx := models.Transaction{PartnerID: 1, UserID: 2, InvoiceID: "INV1", TotalPrice: 50000, Status: constants.SEND_STATUS}
+	db.Create(&trx)
+	db.Create(&models.DetailTransaction{TransactionID: trx.ID, Type: "snack", Price: 10000, Quantity: 5})
+	db.Create(&models.Transaction{PartnerID: 1, UserID: 2, InvoiceID: "INV2", TotalPrice: 50000, Status: constants.PENDING_STATUS})
+
+	balance := func(userID int) models.Money {
+		user := models.User{}
+		db.First(&user, userID)
+		return user.Balance
+	}
+
+	pending := func(userID int) models.Money {
+		user := models.User{}
+		db.First(&user, userID)
+		return user.PendingBalance
+	}
+
+	t.Run("create on an undelivered order", func(t *testing.T) {
+		_, err := refundRepo.Create(models.RefundRequest{TransactionID: 2, UserID: 2, Reason: "late", Amount: 10000})
+		assert.True(t, errors.Is(err, helper.ErrInvalidRefund))
+	})
+
+	t.Run("create above the order price", func(t *testing.T) {
+		_, err := refundRepo.Create(models.RefundRequest{TransactionID: 1, UserID: 2, Reason: "crushed", Amount: 60000})
+		assert.True(t, errors.Is(err, helper.ErrInvalidRefund))
+	})
+
+	t.Run("create on someone else's order", func(t *testing.T) {
+		_, err := refundRepo.Create(models.RefundRequest{TransactionID: 1, UserID: 1, Reason: "crushed", Amount: 20000})
+		assert.True(t, errors.Is(err, gorm.ErrRecordNotFound))
+	})
+
+	t.Run("create", func(t *testing.T) {
+		res, err := refundRepo.Create(models.RefundRequest{TransactionID: 1, UserID: 2, Reason: "crushed", Amount: 20000})
+		assert.Nil(t, err)
+		assert.Equal(t, constants.REFUND_REQUESTED, res.Status)
+		assert.Equal(t, uint(1), res.PartnerID)
+
+		_, err = refundRepo.Create(models.RefundRequest{TransactionID: 1, UserID: 2, Reason: "crushed", Amount: 5000})
+		assert.True(t, errors.Is(err, helper.ErrInvalidRefund))
+	})
+
+	t.Run("counter", func(t *testing.T) {
+		_, err := refundRepo.Counter(1, 1, 25000, "too much")
+		assert.True(t, errors.Is(err, helper.ErrInvalidRefund))
+
+		_, err = refundRepo.Counter(1, 2, 10000, "not my order")
+		assert.NotNil(t, err)
+
+		res, err := refundRepo.Counter(1, 1, 10000, "only one box was damaged")
+		assert.Nil(t, err)
+		assert.Equal(t, constants.REFUND_COUNTERED, res.Status)
+	})
+
+	t.Run("accept counter before the payout", func(t *testing.T) {
+		res, err := refundRepo.AcceptCounter(1, 2)
+		assert.Nil(t, err)
+		assert.Equal(t, constants.REFUND_APPROVED, res.Status)
+		assert.Equal(t, models.Money(10000), res.ApprovedAmount)
+		assert.Equal(t, models.Money(10000), balance(2))
+
+		_, err = refundRepo.AcceptCounter(1, 2)
+		assert.True(t, errors.Is(err, helper.ErrInvalidRefund))
+	})
+
+	t.Run("payout net of the refund", func(t *testing.T) {
+		assert.Nil(t, helper.PayPartner(db, trx))
+
+		// 10% of 50000 scaled down to the 40000 left after the refund
+		fee := models.TransactionFee{}
+		db.First(&fee)
+		assert.Equal(t, models.Money(10000), fee.Refunded)
+		assert.Equal(t, models.Money(4000), fee.Commission)
+		assert.Equal(t, models.Money(36000), fee.Net)
+		assert.Equal(t, models.Money(36000), pending(1))
+	})
+
+	t.Run("escalate and resolve after the payout", func(t *testing.T) {
+		_, err := refundRepo.Create(models.RefundRequest{TransactionID: 1, UserID: 2, Reason: "more crushed boxes", Amount: 20000})
+		assert.Nil(t, err)
+
+		res, err := refundRepo.Escalate(2, 2)
+		assert.Nil(t, err)
+		assert.Equal(t, constants.REFUND_DISPUTED, res.Status)
+
+		res, err = refundRepo.Resolve(2, 20000, "photos show four damaged boxes")
+		assert.Nil(t, err)
+		assert.Equal(t, constants.REFUND_APPROVED, res.Status)
+
+		// half of what was left is refunded, the partner and the platform each give back half of their share
+		fee := models.TransactionFee{}
+		db.First(&fee)
+		assert.Equal(t, models.Money(30000), fee.Refunded)
+		assert.Equal(t, models.Money(2000), fee.Commission)
+		assert.Equal(t, models.Money(18000), fee.Net)
+		assert.Equal(t, models.Money(18000), pending(1))
+		assert.Equal(t, models.Money(30000), balance(2))
+
+		// the rest of the earning becomes available after the hold period
+		assert.Nil(t, helper.ReleaseEarning(db, fee.ID))
+		assert.Equal(t, models.Money(0), pending(1))
+		assert.Equal(t, models.Money(18000), balance(1))
+	})
+
+	t.Run("reject", func(t *testing.T) {
+		_, err := refundRepo.Create(models.RefundRequest{TransactionID: 1, UserID: 2, Reason: "still not happy", Amount: 30000})
+		assert.True(t, errors.Is(err, helper.ErrInvalidRefund))
+
+		_, err = refundRepo.Create(models.RefundRequest{TransactionID: 1, UserID: 2, Reason: "still not happy", Amount: 5000})
+		assert.Nil(t, err)
+
+		res, err := refundRepo.Resolve(3, 0, "the order was delivered as described")
+		assert.Nil(t, err)
+		assert.Equal(t, constants.REFUND_REJECTED, res.Status)
+		assert.Equal(t, models.Money(30000), balance(2))
+
+		refunds, _ := refundRepo.GetAll(2, 0, constants.REFUND_APPROVED)
+		assert.Equal(t, 2, len(refunds))
+	})
+}
@@ -0,0 +1,190 @@
+package scheduler
+
+import (
+	"time"
+
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/helper"
+	"github.com/furqonzt99/snackbox/models"
+	"github.com/furqonzt99/snackbox/payment"
+	"github.com/labstack/gommon/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SchedulerInterface interface {
+	AcquireLock(name, owner string, ttl time.Duration) (bool, error)
+	ExpirePending(before time.Time) (int, error)
+	RejectUnaccepted(before time.Time) (int, error)
+	ConfirmDelivered(before time.Time) (int, error)
+	ReleaseEarnings(now time.Time) (int, error)
+}
+
+type SchedulerRepository struct {
+	db       *gorm.DB
+	provider payment.PaymentProvider
+}
+
+func NewSchedulerRepository(db *gorm.DB, provider payment.PaymentProvider) *SchedulerRepository {
+	return &SchedulerRepository{db: db, provider: provider}
+}
+
+// AcquireLock takes or renews the lease on a job. It reports false while
+// another owner holds a lease that has not run out yet.
+func (sr *SchedulerRepository) AcquireLock(name, owner string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+
+	lock := models.SchedulerLock{Name: name, LockedUntil: now}
+	if err := sr.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&lock).Error; err != nil {
+		return false, err
+	}
+
+	res := sr.db.Model(&models.SchedulerLock{}).
+		Where("name = ? AND (owner = ? OR locked_until <= ?)", name, owner, now).
+		Updates(map[string]interface{}{"owner": owner, "locked_until": now.Add(ttl)})
+	if res.Error != nil {
+		return false, res.Error
+	}
+
+	return res.RowsAffected == 1, nil
+}
+
+// ExpirePending expires orders that are still unpaid after the payment timeout
+// and gives back the SboxPay balance that was put towards them.
+func (sr *SchedulerRepository) ExpirePending(before time.Time) (int, error) {
+	trxs := []models.Transaction{}
+
+	if err := sr.db.Where("status = ? AND created_at <= ?", constants.PENDING_STATUS, before).Find(&trxs).Error; err != nil {
+		return 0, err
+	}
+
+	count := 0
+
+	for _, trx := range trxs {
+		// a failed expiry may mean the invoice was paid meanwhile, so leave the order for the callback
+		if trx.PaymentInvoiceID != "" {
+			if err := helper.ExpireInvoice(sr.provider, trx.PaymentInvoiceID); err != nil {
+				log.Warnf("scheduler: expire invoice of transaction %d: %v", trx.ID, err)
+				continue
+			}
+		}
+
+		err := sr.transition(trx.ID, constants.PENDING_STATUS, constants.EXPIRED_STATUS, "payment timeout", func(tx *gorm.DB, trx models.Transaction) error {
+			return helper.RefundOrder(tx, trx, trx.BalanceUsed)
+		})
+		if err != nil {
+			log.Warnf("scheduler: expire transaction %d: %v", trx.ID, err)
+			continue
+		}
+
+		count++
+	}
+
+	return count, nil
+}
+
+// RejectUnaccepted rejects paid orders the partner did not accept in time
+// and refunds the customer to the SboxPay balance.
+func (sr *SchedulerRepository) RejectUnaccepted(before time.Time) (int, error) {
+	trxs := []models.Transaction{}
+
+	if err := sr.db.Where("status = ? AND COALESCE(paid_at, updated_at) <= ?", constants.PAID_STATUS, before).Find(&trxs).Error; err != nil {
+		return 0, err
+	}
+
+	count := 0
+
+	for _, trx := range trxs {
+		err := sr.transition(trx.ID, constants.PAID_STATUS, constants.REJECT_STATUS, "not accepted by partner in time", func(tx *gorm.DB, trx models.Transaction) error {
+			return helper.RefundOrder(tx, trx, trx.TotalPrice)
+		})
+		if err != nil {
+			log.Warnf("scheduler: reject transaction %d: %v", trx.ID, err)
+			continue
+		}
+
+		count++
+	}
+
+	return count, nil
+}
+
+// ConfirmDelivered confirms orders the customer did not confirm after delivery
+// and credits the partner the same way a manual confirmation does. The delivery
+// time is taken from the status history, later writes to the order keep updated_at moving.
+func (sr *SchedulerRepository) ConfirmDelivered(before time.Time) (int, error) {
+	trxs := []models.Transaction{}
+
+	err := sr.db.Joins("JOIN transaction_status_histories ON transaction_status_histories.transaction_id = transactions.id AND transaction_status_histories.to_status = ? AND transaction_status_histories.deleted_at IS NULL", constants.SEND_STATUS).
+		Where("transactions.status = ? AND transaction_status_histories.created_at <= ?", constants.SEND_STATUS, before).
+		Find(&trxs).Error
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+
+	for _, trx := range trxs {
+		err := sr.transition(trx.ID, constants.SEND_STATUS, constants.CONFIRM_STATUS, "confirmed automatically", func(tx *gorm.DB, trx models.Transaction) error {
+			return helper.PayPartner(tx, trx)
+		})
+		if err != nil {
+			log.Warnf("scheduler: confirm transaction %d: %v", trx.ID, err)
+			continue
+		}
+
+		count++
+	}
+
+	return count, nil
+}
+
+// ReleaseEarnings makes the partner earnings whose hold period is over as of now available
+// for cashout.
+func (sr *SchedulerRepository) ReleaseEarnings(now time.Time) (int, error) {
+	fees := []models.TransactionFee{}
+
+	if err := sr.db.Where("released_at IS NULL AND available_at <= ?", now).Find(&fees).Error; err != nil {
+		return 0, err
+	}
+
+	count := 0
+
+	for _, fee := range fees {
+		err := sr.db.Transaction(func(tx *gorm.DB) error {
+			return helper.ReleaseEarning(tx, fee.ID)
+		})
+		if err != nil {
+			log.Warnf("scheduler: release earning of transaction %d: %v", fee.TransactionID, err)
+			continue
+		}
+
+		count++
+	}
+
+	return count, nil
+}
+
+// transition locks the transaction row and re-checks its status before moving it. The
+// customer, partner and payment callback paths lock the same row and TransitionOrder only
+// writes over the status it read, so whichever side acts first wins and the other one
+// fails with ErrInvalidTransition instead of overriding it.
+func (sr *SchedulerRepository) transition(trxID uint, from, to, reason string, apply func(tx *gorm.DB, trx models.Transaction) error) error {
+	return sr.db.Transaction(func(tx *gorm.DB) error {
+		trx := models.Transaction{}
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&trx, trxID).Error; err != nil {
+			return err
+		}
+
+		if trx.Status != from {
+			return helper.ErrInvalidTransition
+		}
+
+		if err := helper.TransitionOrder(tx, &trx, to, helper.SystemActor(), reason); err != nil {
+			return err
+		}
+
+		return apply(tx, trx)
+	})
+}
@@ -0,0 +1,145 @@
+package scheduler_test
+
+import (
+	"testing"
+	"time"
+
+	config "github.com/furqonzt99/snackbox/configs"
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/models"
+	"github.com/furqonzt99/snackbox/repositories/scheduler"
+	"github.com/furqonzt99/snackbox/utils"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+var configTest *config.AppConfig
+var db *gorm.DB
+var schedulerRepo *scheduler.SchedulerRepository
+
+func TestScheduler(t *testing.T) {
+	configTest = config.GetConfig()
+	db = utils.InitDB(configTest)
+
+	constants.EARNING_HOLD_DAYS = 7
+
+	db.Migrator().DropTable(&models.User{})
+	db.Migrator().DropTable(&models.Partner{})
+	db.Migrator().DropTable(&models.Transaction{})
+	db.Migrator().DropTable(&models.DetailTransaction{})
+	db.Migrator().DropTable(&models.RefundRequest{})
+	db.Migrator().DropTable(&models.TransactionFee{})
+	db.Migrator().DropTable(&models.CommissionRule{})
+	db.Migrator().DropTable(&models.LedgerEntry{})
+	db.Migrator().DropTable(&models.TransactionStatusHistory{})
+	db.Migrator().DropTable(&models.VoucherUsage{})
+	db.Migrator().DropTable(&models.Voucher{})
+	db.Migrator().DropTable(&models.SchedulerLock{})
+
+	schedulerRepo = scheduler.NewSchedulerRepository(db, utils.InitPaymentProvider(configTest))
+
+	db.AutoMigrate(&models.User{})
+	db.AutoMigrate(&models.Partner{})
+	db.AutoMigrate(&models.Transaction{})
+	db.AutoMigrate(&models.DetailTransaction{})
+	db.AutoMigrate(&models.LedgerEntry{})
+	db.AutoMigrate(&models.CommissionRule{})
+	db.AutoMigrate(&models.TransactionFee{})
+	db.AutoMigrate(&models.RefundRequest{})
+	db.AutoMigrate(&models.TransactionStatusHistory{})
+	db.AutoMigrate(&models.Voucher{})
+	db.AutoMigrate(&models.VoucherUsage{})
+	db.AutoMigrate(&models.SchedulerLock{})
+
+	//CREATE USERS
+	db.Create(&models.User{Email: "partner@gmail.com", Password: "test1234", Role: "partner"})
+	db.Create(&models.User{Email: "user@gmail.com", Password: "test1234", Role: "user"})
+
+	//CREATE PARTNER
+	db.Create(&models.Partner{UserID: 1, BussinessName: "partner1", Status: "active"})
+
+	old := time.Now().AddDate(0, 0, -10)
+
+	//CREATE TRANSACTIONS
+	db.Create(&models.Transaction{Model: gorm.Model{CreatedAt: old}, PartnerID: 1, UserID: 2, TotalPrice: 5000, BalanceUsed: 1000, Status: constants.PENDING_STATUS})
+	db.Create(&models.Transaction{PartnerID: 1, UserID: 2, TotalPrice: 5000, PaidAt: old, Status: constants.PAID_STATUS})
+	db.Create(&models.Transaction{PartnerID: 1, UserID: 2, TotalPrice: 7000, Status: constants.SEND_STATUS})
+	db.Create(&models.Transaction{PartnerID: 1, UserID: 2, TotalPrice: 5000, Status: constants.PENDING_STATUS})
+	db.Create(&models.Transaction{Model: gorm.Model{UpdatedAt: old}, PartnerID: 1, UserID: 2, TotalPrice: 3000, Status: constants.SEND_STATUS})
+
+	//CREATE STATUS HISTORIES
+	db.Create(&models.TransactionStatusHistory{Model: gorm.Model{CreatedAt: old}, TransactionID: 3, FromStatus: constants.ON_DELIVERY_STATUS, ToStatus: constants.SEND_STATUS})
+	db.Create(&models.TransactionStatusHistory{TransactionID: 5, FromStatus: constants.ON_DELIVERY_STATUS, ToStatus: constants.SEND_STATUS})
+
+	t.Run("acquire lock", func(t *testing.T) {
+		locked, err := schedulerRepo.AcquireLock("job", "a", time.Minute)
+		assert.Nil(t, err)
+		assert.True(t, locked)
+	})
+
+	t.Run("acquire lock held by another owner", func(t *testing.T) {
+		locked, err := schedulerRepo.AcquireLock("job", "b", time.Minute)
+		assert.Nil(t, err)
+		assert.False(t, locked)
+	})
+
+	t.Run("renew lock", func(t *testing.T) {
+		locked, err := schedulerRepo.AcquireLock("job", "a", time.Minute)
+		assert.Nil(t, err)
+		assert.True(t, locked)
+	})
+
+	t.Run("expire pending", func(t *testing.T) {
+		count, err := schedulerRepo.ExpirePending(time.Now().AddDate(0, 0, -1))
+		assert.Nil(t, err)
+		assert.Equal(t, 1, count)
+
+		var trx models.Transaction
+		db.First(&trx, 1)
+		assert.Equal(t, constants.EXPIRED_STATUS, trx.Status)
+	})
+
+	t.Run("reject unaccepted", func(t *testing.T) {
+		count, err := schedulerRepo.RejectUnaccepted(time.Now().AddDate(0, 0, -1))
+		assert.Nil(t, err)
+		assert.Equal(t, 1, count)
+
+		var user models.User
+		db.First(&user, 2)
+		assert.Equal(t, models.Money(6000), user.Balance)
+	})
+
+	t.Run("confirm delivered", func(t *testing.T) {
+		count, err := schedulerRepo.ConfirmDelivered(time.Now().AddDate(0, 0, -3))
+		assert.Nil(t, err)
+		assert.Equal(t, 1, count)
+
+		var user models.User
+		db.First(&user, 1)
+		assert.Equal(t, models.Money(0), user.Balance)
+		assert.Equal(t, models.Money(7000), user.PendingBalance)
+	})
+
+	t.Run("release earnings", func(t *testing.T) {
+		count, err := schedulerRepo.ReleaseEarnings(time.Now())
+		assert.Nil(t, err)
+		assert.Equal(t, 0, count)
+
+		count, err = schedulerRepo.ReleaseEarnings(time.Now().AddDate(0, 0, 8))
+		assert.Nil(t, err)
+		assert.Equal(t, 1, count)
+
+		var user models.User
+		db.First(&user, 1)
+		assert.Equal(t, models.Money(7000), user.Balance)
+		assert.Equal(t, models.Money(0), user.PendingBalance)
+
+		count, _ = schedulerRepo.ReleaseEarnings(time.Now().AddDate(0, 0, 8))
+		assert.Equal(t, 0, count)
+	})
+
+	t.Run("nothing left to do", func(t *testing.T) {
+		count, _ := schedulerRepo.ExpirePending(time.Now().AddDate(0, 0, -1))
+		assert.Equal(t, 0, count)
+	})
+}
@@ -0,0 +1,115 @@
+package settlement
+
+import (
+	"sort"
+	"time"
+
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/models"
+	"gorm.io/gorm"
+)
+
+// SettlementRow sums the money that went through the payment gateway in one period.
+// GrossCollected is what customers paid on order invoices and Settled the part of it, less the
+// gateway's fee, the gateway already settled. GatewayFees are charged on invoices and on
+// disbursements alike. Cashouts are split between partners and customers cashing out their
+// balance. PlatformMargin is the commission and the cashout fees the platform kept, less the
+// gateway fees it paid.
+type SettlementRow struct {
+	Period           string
+	Transactions     int
+	GrossCollected   models.Money
+	GatewayFees      models.Money
+	Settled          models.Money
+	Refunds          models.Money
+	Cashouts         int
+	PartnerPayouts   models.Money
+	CustomerCashouts models.Money
+	CashoutFees      models.Money
+	Commission       models.Money
+	PlatformMargin   models.Money
+}
+
+type SettlementInterface interface {
+	Report(from, to time.Time, period string) ([]SettlementRow, error)
+}
+
+type SettlementRepository struct {
+	db *gorm.DB
+}
+
+func NewSettlementRepository(db *gorm.DB) *SettlementRepository {
+	return &SettlementRepository{db: db}
+}
+
+// Report sums the invoices paid, the refunds booked on the ledger, the cashouts completed and
+// the commission booked from from up to, but not including, to by day or by month.
+func (sr *SettlementRepository) Report(from, to time.Time, period string) ([]SettlementRow, error) {
+	format := "%Y-%m"
+	if period == constants.DAILY_PERIOD {
+		format = "%Y-%m-%d"
+	}
+
+	queries := []*gorm.DB{
+		sr.db.Model(&models.Transaction{}).
+			Select("DATE_FORMAT(paid_at, ?) AS period, COUNT(*) AS transactions, SUM(paid_amount) AS gross_collected, SUM(gateway_fee) AS gateway_fees, "+
+				"SUM(CASE WHEN settlement_status IN ? THEN paid_amount - gateway_fee ELSE 0 END) AS settled",
+				format, []string{constants.SETTLEMENT_SETTLED, constants.SETTLEMENT_EARLY_SETTLED}).
+			Where("paid_amount > 0 AND paid_at >= ? AND paid_at < ?", from, to),
+		// the wallet side of every refund, cancelled and rejected orders as well as approved claims
+		sr.db.Model(&models.LedgerEntry{}).
+			Select("DATE_FORMAT(created_at, ?) AS period, SUM(amount) AS refunds", format).
+			Where("type = ? AND account = ? AND created_at >= ? AND created_at < ?", constants.REFUND_ENTRY, constants.WALLET_ACCOUNT, from, to),
+		sr.db.Model(&models.Cashout{}).
+			Joins("LEFT JOIN partners ON partners.user_id = cashouts.user_id AND partners.deleted_at IS NULL").
+			Select("DATE_FORMAT(cashouts.settled_at, ?) AS period, COUNT(*) AS cashouts, "+
+				"SUM(CASE WHEN partners.id IS NOT NULL THEN cashouts.amount ELSE 0 END) AS partner_payouts, "+
+				"SUM(CASE WHEN partners.id IS NULL THEN cashouts.amount ELSE 0 END) AS customer_cashouts, "+
+				"SUM(cashouts.fee) AS cashout_fees, SUM(cashouts.gateway_fee) AS gateway_fees", format).
+			Where("cashouts.status = ? AND cashouts.settled_at >= ? AND cashouts.settled_at < ?", constants.CASHOUT_COMPLETED, from, to),
+		sr.db.Model(&models.TransactionFee{}).
+			Select("DATE_FORMAT(created_at, ?) AS period, SUM(commission) AS commission", format).
+			Where("created_at >= ? AND created_at < ?", from, to),
+	}
+
+	periods := map[string]*SettlementRow{}
+
+	for _, query := range queries {
+		rows := []SettlementRow{}
+
+		if err := query.Group("period").Scan(&rows).Error; err != nil {
+			return nil, err
+		}
+
+		for _, row := range rows {
+			total, ok := periods[row.Period]
+			if !ok {
+				total = &SettlementRow{Period: row.Period}
+				periods[row.Period] = total
+			}
+
+			total.Transactions += row.Transactions
+			total.GrossCollected += row.GrossCollected
+			total.GatewayFees += row.GatewayFees
+			total.Settled += row.Settled
+			total.Refunds += row.Refunds
+			total.Cashouts += row.Cashouts
+			total.PartnerPayouts += row.PartnerPayouts
+			total.CustomerCashouts += row.CustomerCashouts
+			total.CashoutFees += row.CashoutFees
+			total.Commission += row.Commission
+		}
+	}
+
+	report := []SettlementRow{}
+	for _, row := range periods {
+		row.PlatformMargin = row.Commission + row.CashoutFees - row.GatewayFees
+		report = append(report, *row)
+	}
+
+	sort.Slice(report, func(i, j int) bool {
+		return report[i].Period < report[j].Period
+	})
+
+	return report, nil
+}
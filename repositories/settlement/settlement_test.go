@@ -0,0 +1,90 @@
+package settlement_test
+
+import (
+	"testing"
+	"time"
+
+	config "github.com/furqonzt99/snackbox/configs"
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/models"
+	"github.com/furqonzt99/snackbox/repositories/settlement"
+	"github.com/furqonzt99/snackbox/utils"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+var configTest *config.AppConfig
+var db *gorm.DB
+var settlementRepo *settlement.SettlementRepository
+
+func TestSettlement(t *testing.T) {
+	configTest = config.GetConfig()
+	db = utils.InitDB(configTest)
+
+	db.Migrator().DropTable(&models.LedgerEntry{})
+	db.Migrator().DropTable(&models.TransactionFee{})
+	db.Migrator().DropTable(&models.Cashout{})
+	db.Migrator().DropTable(&models.Transaction{})
+	db.Migrator().DropTable(&models.Partner{})
+	db.Migrator().DropTable(&models.User{})
+
+	settlementRepo = settlement.NewSettlementRepository(db)
+
+	db.AutoMigrate(&models.User{})
+	db.AutoMigrate(&models.Partner{})
+	db.AutoMigrate(&models.Transaction{})
+	db.AutoMigrate(&models.Cashout{})
+	db.AutoMigrate(&models.TransactionFee{})
+	db.AutoMigrate(&models.LedgerEntry{})
+
+	now := time.Now()
+
+	db.Create(&models.User{Email: "partner@gmail.com", Password: "test1234", Role: "partner"})
+	db.Create(&models.User{Email: "user@gmail.com", Password: "test1234", Role: "user"})
+	db.Create(&models.Partner{UserID: 1, BussinessName: "partner1", Status: "active"})
+
+	settled := models.Transaction{PartnerID: 1, UserID: 2, InvoiceID: "INV1", TotalPrice: 100000, PaidAmount: 100000, GatewayFee: 4440, SettlementStatus: constants.SETTLEMENT_SETTLED, PaidAt: now, Status: constants.CONFIRM_STATUS}
+	pending := models.Transaction{PartnerID: 1, UserID: 2, InvoiceID: "INV2", TotalPrice: 50000, PaidAmount: 50000, GatewayFee: 2220, SettlementStatus: constants.SETTLEMENT_PENDING, PaidAt: now, Status: constants.PAID_STATUS}
+	balance := models.Transaction{PartnerID: 1, UserID: 2, InvoiceID: "INV3", TotalPrice: 20000, BalanceUsed: 20000, PaidAt: now, Status: constants.PAID_STATUS}
+	db.Create(&settled)
+	db.Create(&pending)
+	db.Create(&balance)
+
+	db.Create(&models.TransactionFee{TransactionID: settled.ID, PartnerID: 1, Gross: 100000, Refunded: 10000, Commission: 9000, Net: 81000})
+
+	// an approved claim on the settled order and a cancelled order, each with its escrow side
+	db.Create(&models.LedgerEntry{JournalID: "J1", Account: constants.ESCROW_ACCOUNT, Type: constants.REFUND_ENTRY, SourceType: constants.REFUND_SOURCE, SourceID: 1, Amount: -10000})
+	db.Create(&models.LedgerEntry{JournalID: "J1", Account: constants.WALLET_ACCOUNT, UserID: 2, Type: constants.REFUND_ENTRY, SourceType: constants.REFUND_SOURCE, SourceID: 1, Amount: 10000})
+	db.Create(&models.LedgerEntry{JournalID: "J2", Account: constants.ESCROW_ACCOUNT, Type: constants.REFUND_ENTRY, SourceType: constants.TRANSACTION_SOURCE, SourceID: 4, Amount: -5000})
+	db.Create(&models.LedgerEntry{JournalID: "J2", Account: constants.WALLET_ACCOUNT, UserID: 2, Type: constants.REFUND_ENTRY, SourceType: constants.TRANSACTION_SOURCE, SourceID: 4, Amount: 5000})
+
+	db.Create(&models.Cashout{UserID: 1, ExternalID: "CO1", Amount: 81000, Fee: 2500, GatewayFee: 4500, Status: constants.CASHOUT_COMPLETED, SettledAt: now})
+	db.Create(&models.Cashout{UserID: 2, ExternalID: "CO2", Amount: 10000, Fee: 2500, GatewayFee: 4500, Status: constants.CASHOUT_COMPLETED, SettledAt: now})
+	db.Create(&models.Cashout{UserID: 2, ExternalID: "CO3", Amount: 10000, Fee: 2500, Status: constants.CASHOUT_FAILED})
+
+	t.Run("settlement report", func(t *testing.T) {
+		rows, err := settlementRepo.Report(now.AddDate(0, 0, -1), now.AddDate(0, 0, 1), constants.DAILY_PERIOD)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(rows))
+
+		row := rows[0]
+		assert.Equal(t, now.Format("2006-01-02"), row.Period)
+		assert.Equal(t, 2, row.Transactions)
+		assert.Equal(t, models.Money(150000), row.GrossCollected)
+		assert.Equal(t, models.Money(95560), row.Settled)
+		assert.Equal(t, models.Money(15660), row.GatewayFees)
+		assert.Equal(t, models.Money(15000), row.Refunds)
+		assert.Equal(t, 2, row.Cashouts)
+		assert.Equal(t, models.Money(81000), row.PartnerPayouts)
+		assert.Equal(t, models.Money(10000), row.CustomerCashouts)
+		assert.Equal(t, models.Money(5000), row.CashoutFees)
+		assert.Equal(t, models.Money(9000), row.Commission)
+		assert.Equal(t, models.Money(-1660), row.PlatformMargin)
+	})
+
+	t.Run("settlement report of a range without activity", func(t *testing.T) {
+		rows, err := settlementRepo.Report(now.AddDate(0, 0, 1), now.AddDate(0, 0, 2), constants.DAILY_PERIOD)
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(rows))
+	})
+}
@@ -0,0 +1,72 @@
+package shipping
+
+import (
+	"github.com/furqonzt99/snackbox/models"
+	"gorm.io/gorm"
+)
+
+type ShippingInterface interface {
+	GetTariff(partnerID int) (models.ShippingTariff, error)
+	SaveTariff(tariff models.ShippingTariff) (models.ShippingTariff, error)
+	DeleteTariff(partnerID int) error
+}
+
+type ShippingRepository struct {
+	db *gorm.DB
+}
+
+func NewShippingRepository(db *gorm.DB) *ShippingRepository {
+	return &ShippingRepository{db: db}
+}
+
+// GetTariff returns the tariff stored for exactly this partner, 0 being the admin default.
+func (sr *ShippingRepository) GetTariff(partnerID int) (models.ShippingTariff, error) {
+	tariff := models.ShippingTariff{}
+
+	if err := sr.db.Preload("Tiers").Preload("Zones").Preload("Surcharges").Where("partner_id = ?", partnerID).First(&tariff).Error; err != nil {
+		return tariff, err
+	}
+
+	return tariff, nil
+}
+
+// SaveTariff replaces the partner's tariff, tiers, zones and surcharges included.
+func (sr *ShippingRepository) SaveTariff(tariff models.ShippingTariff) (models.ShippingTariff, error) {
+	err := sr.db.Transaction(func(tx *gorm.DB) error {
+		if err := deleteTariff(tx, tariff.PartnerID); err != nil {
+			return err
+		}
+
+		return tx.Create(&tariff).Error
+	})
+
+	if err != nil {
+		return tariff, err
+	}
+
+	return sr.GetTariff(int(tariff.PartnerID))
+}
+
+func (sr *ShippingRepository) DeleteTariff(partnerID int) error {
+	tariff := models.ShippingTariff{}
+
+	if err := sr.db.Where("partner_id = ?", partnerID).First(&tariff).Error; err != nil {
+		return err
+	}
+
+	return sr.db.Transaction(func(tx *gorm.DB) error {
+		return deleteTariff(tx, uint(partnerID))
+	})
+}
+
+func deleteTariff(tx *gorm.DB, partnerID uint) error {
+	tariffIDs := tx.Model(&models.ShippingTariff{}).Select("id").Where("partner_id = ?", partnerID)
+
+	for _, model := range []interface{}{&models.ShippingTariffTier{}, &models.ShippingZone{}, &models.ShippingSurcharge{}} {
+		if err := tx.Unscoped().Where("shipping_tariff_id IN (?)", tariffIDs).Delete(model).Error; err != nil {
+			return err
+		}
+	}
+
+	return tx.Unscoped().Where("partner_id = ?", partnerID).Delete(&models.ShippingTariff{}).Error
+}
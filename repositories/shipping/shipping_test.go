@@ -0,0 +1,79 @@
+package shipping_test
+
+import (
+	"testing"
+
+	config "github.com/furqonzt99/snackbox/configs"
+	"github.com/furqonzt99/snackbox/helper"
+	"github.com/furqonzt99/snackbox/models"
+	"github.com/furqonzt99/snackbox/repositories/shipping"
+	"github.com/furqonzt99/snackbox/utils"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+var configTest *config.AppConfig
+var db *gorm.DB
+var shippingRepo *shipping.ShippingRepository
+
+func TestShipping(t *testing.T) {
+	configTest = config.GetConfig()
+	db = utils.InitDB(configTest)
+
+	db.Migrator().DropTable(&models.ShippingSurcharge{})
+	db.Migrator().DropTable(&models.ShippingZone{})
+	db.Migrator().DropTable(&models.ShippingTariffTier{})
+	db.Migrator().DropTable(&models.ShippingTariff{})
+
+	shippingRepo = shipping.NewShippingRepository(db)
+
+	db.AutoMigrate(&models.ShippingTariff{})
+	db.AutoMigrate(&models.ShippingTariffTier{})
+	db.AutoMigrate(&models.ShippingZone{})
+	db.AutoMigrate(&models.ShippingSurcharge{})
+
+	t.Run("fall back to built in tariff", func(t *testing.T) {
+		res, err := helper.FindShippingTariff(db, 1)
+		assert.Nil(t, err)
+		assert.Equal(t, models.Money(50000), res.BaseCost)
+	})
+
+	t.Run("save default tariff", func(t *testing.T) {
+		res, err := shippingRepo.SaveTariff(models.ShippingTariff{BaseCost: 30000, Tiers: []models.ShippingTariffTier{{CostPerKm: 1000}}})
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(res.Tiers))
+
+		tariff, _ := helper.FindShippingTariff(db, 1)
+		assert.Equal(t, models.Money(30000), tariff.BaseCost)
+	})
+
+	t.Run("save partner tariff", func(t *testing.T) {
+		_, err := shippingRepo.SaveTariff(models.ShippingTariff{PartnerID: 1, BaseCost: 10000, Surcharges: []models.ShippingSurcharge{{MinQuantity: 50, Amount: 5000}}})
+		assert.Nil(t, err)
+
+		res, err := shippingRepo.SaveTariff(models.ShippingTariff{PartnerID: 1, BaseCost: 15000, Zones: []models.ShippingZone{{Name: "center", Polygon: "[[0,0],[0,1],[1,1]]"}}})
+		assert.Nil(t, err)
+		assert.Equal(t, models.Money(15000), res.BaseCost)
+		assert.Equal(t, 0, len(res.Surcharges))
+		assert.Equal(t, 1, len(res.Zones))
+
+		tariff, _ := helper.FindShippingTariff(db, 1)
+		assert.Equal(t, models.Money(15000), tariff.BaseCost)
+	})
+
+	t.Run("delete partner tariff", func(t *testing.T) {
+		err := shippingRepo.DeleteTariff(1)
+		assert.Nil(t, err)
+
+		_, err = shippingRepo.GetTariff(1)
+		assert.NotNil(t, err)
+
+		tariff, _ := helper.FindShippingTariff(db, 1)
+		assert.Equal(t, models.Money(30000), tariff.BaseCost)
+	})
+
+	t.Run("delete missing tariff", func(t *testing.T) {
+		err := shippingRepo.DeleteTariff(2)
+		assert.NotNil(t, err)
+	})
+}
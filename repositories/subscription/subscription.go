@@ -0,0 +1,224 @@
+package subscription
+
+import (
+	"time"
+
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/helper"
+	"github.com/furqonzt99/snackbox/models"
+	"gorm.io/gorm"
+)
+
+type SubscriptionInterface interface {
+	Create(subscription models.Subscription) (models.Subscription, error)
+	GetAll(userID int) ([]models.Subscription, error)
+	Get(subscriptionID, userID int) (models.Subscription, error)
+	Pause(subscriptionID, userID int) (models.Subscription, error)
+	Resume(subscriptionID, userID int) (models.Subscription, error)
+	SkipNext(subscriptionID, userID int) (models.Subscription, error)
+	Cancel(subscriptionID, userID int) (models.Subscription, error)
+	GetActive() ([]models.Subscription, error)
+	Ordered(subscriptionID int, dateTime time.Time) (bool, error)
+	Advance(subscriptionID int, note string) error
+}
+
+type SubscriptionRepository struct {
+	db *gorm.DB
+}
+
+func NewSubscriptionRepository(db *gorm.DB) *SubscriptionRepository {
+	return &SubscriptionRepository{db: db}
+}
+
+// Create takes the partner from the products and schedules the first delivery on or after the start date.
+func (sr *SubscriptionRepository) Create(subscription models.Subscription) (models.Subscription, error) {
+	for _, item := range subscription.Items {
+		product := models.Product{}
+		if err := sr.db.First(&product, item.ProductID).Error; err != nil {
+			return subscription, err
+		}
+
+		if subscription.PartnerID != 0 && subscription.PartnerID != product.PartnerID {
+			return subscription, helper.ErrSubscriptionPartners
+		}
+
+		subscription.PartnerID = product.PartnerID
+	}
+
+	start, err := time.Parse(helper.CalendarDateFormat, subscription.StartDate)
+	if err != nil {
+		return subscription, err
+	}
+
+	subscription.NextDate, err = helper.NextSubscriptionDate(subscription, start)
+	if err != nil {
+		return subscription, err
+	}
+
+	if subscription.NextDate == "" {
+		return subscription, helper.ErrInvalidRecurrence
+	}
+
+	subscription.Status = constants.SUBSCRIPTION_ACTIVE
+
+	if err := sr.db.Create(&subscription).Error; err != nil {
+		return subscription, err
+	}
+
+	return sr.Get(int(subscription.ID), int(subscription.UserID))
+}
+
+func (sr *SubscriptionRepository) GetAll(userID int) ([]models.Subscription, error) {
+	subscriptions := []models.Subscription{}
+
+	if err := sr.db.Preload("Items.Product").Where("user_id = ?", userID).Order("id desc").Find(&subscriptions).Error; err != nil {
+		return subscriptions, err
+	}
+
+	return subscriptions, nil
+}
+
+func (sr *SubscriptionRepository) Get(subscriptionID, userID int) (models.Subscription, error) {
+	subscription := models.Subscription{}
+
+	if err := sr.db.Preload("Items.Product").Where("user_id = ?", userID).First(&subscription, subscriptionID).Error; err != nil {
+		return subscription, err
+	}
+
+	return subscription, nil
+}
+
+func (sr *SubscriptionRepository) Pause(subscriptionID, userID int) (models.Subscription, error) {
+	return sr.change(subscriptionID, userID, []string{constants.SUBSCRIPTION_ACTIVE}, func(subscription *models.Subscription) error {
+		subscription.Status = constants.SUBSCRIPTION_PAUSED
+		return nil
+	})
+}
+
+// Resume picks the schedule up again from today, deliveries missed while paused are not placed.
+func (sr *SubscriptionRepository) Resume(subscriptionID, userID int) (models.Subscription, error) {
+	return sr.change(subscriptionID, userID, []string{constants.SUBSCRIPTION_PAUSED}, func(subscription *models.Subscription) error {
+		from := time.Now().UTC()
+		if next, err := time.Parse(helper.CalendarDateFormat, subscription.NextDate); err == nil && next.After(from) {
+			from = next
+		}
+
+		next, err := helper.NextSubscriptionDate(*subscription, from)
+		if err != nil {
+			return err
+		}
+
+		subscription.NextDate = next
+		subscription.Status = constants.SUBSCRIPTION_ACTIVE
+		if next == "" {
+			subscription.Status = constants.SUBSCRIPTION_ENDED
+		}
+
+		return nil
+	})
+}
+
+// SkipNext moves the schedule past its next delivery. Orders that are already placed
+// are not touched, those are cancelled like any other transaction.
+func (sr *SubscriptionRepository) SkipNext(subscriptionID, userID int) (models.Subscription, error) {
+	return sr.change(subscriptionID, userID, []string{constants.SUBSCRIPTION_ACTIVE, constants.SUBSCRIPTION_PAUSED}, func(subscription *models.Subscription) error {
+		return advance(subscription, "")
+	})
+}
+
+func (sr *SubscriptionRepository) Cancel(subscriptionID, userID int) (models.Subscription, error) {
+	return sr.change(subscriptionID, userID, []string{constants.SUBSCRIPTION_ACTIVE, constants.SUBSCRIPTION_PAUSED}, func(subscription *models.Subscription) error {
+		subscription.Status = constants.SUBSCRIPTION_CANCELLED
+		subscription.NextDate = ""
+		return nil
+	})
+}
+
+func (sr *SubscriptionRepository) change(subscriptionID, userID int, from []string, apply func(subscription *models.Subscription) error) (models.Subscription, error) {
+	subscription, err := sr.Get(subscriptionID, userID)
+	if err != nil {
+		return subscription, err
+	}
+
+	allowed := false
+	for _, status := range from {
+		if subscription.Status == status {
+			allowed = true
+		}
+	}
+
+	if !allowed {
+		return subscription, helper.ErrSubscriptionStatus
+	}
+
+	if err := apply(&subscription); err != nil {
+		return subscription, err
+	}
+
+	if err := sr.db.Model(&subscription).Select("status", "next_date", "note").Updates(&subscription).Error; err != nil {
+		return subscription, err
+	}
+
+	return subscription, nil
+}
+
+// GetActive returns every subscription that still has deliveries to place,
+// with what the scheduler needs to place them.
+func (sr *SubscriptionRepository) GetActive() ([]models.Subscription, error) {
+	subscriptions := []models.Subscription{}
+
+	if err := sr.db.Preload("User").Preload("Partner").Preload("Items").
+		Where("status = ? AND next_date <> ''", constants.SUBSCRIPTION_ACTIVE).
+		Find(&subscriptions).Error; err != nil {
+		return subscriptions, err
+	}
+
+	return subscriptions, nil
+}
+
+// Ordered reports whether the order of the delivery at dateTime has been placed already.
+func (sr *SubscriptionRepository) Ordered(subscriptionID int, dateTime time.Time) (bool, error) {
+	var count int64
+
+	if err := sr.db.Model(&models.Transaction{}).Where("subscription_id = ? AND date_time = ?", subscriptionID, dateTime).Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// Advance moves the subscription on to its following delivery date, the note tells
+// the customer why the delivery just passed was not placed.
+func (sr *SubscriptionRepository) Advance(subscriptionID int, note string) error {
+	subscription := models.Subscription{}
+
+	if err := sr.db.First(&subscription, subscriptionID).Error; err != nil {
+		return err
+	}
+
+	if err := advance(&subscription, note); err != nil {
+		return err
+	}
+
+	return sr.db.Model(&subscription).Select("status", "next_date", "note").Updates(&subscription).Error
+}
+
+func advance(subscription *models.Subscription, note string) error {
+	current, err := time.Parse(helper.CalendarDateFormat, subscription.NextDate)
+	if err != nil {
+		return err
+	}
+
+	next, err := helper.NextSubscriptionDate(*subscription, current.AddDate(0, 0, 1))
+	if err != nil {
+		return err
+	}
+
+	subscription.NextDate = next
+	subscription.Note = note
+	if next == "" {
+		subscription.Status = constants.SUBSCRIPTION_ENDED
+	}
+
+	return nil
+}
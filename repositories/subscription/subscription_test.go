@@ -0,0 +1,128 @@
+package subscription_test
+
+import (
+	"testing"
+	"time"
+
+	config "github.com/furqonzt99/snackbox/configs"
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/helper"
+	"github.com/furqonzt99/snackbox/models"
+	"github.com/furqonzt99/snackbox/repositories/subscription"
+	"github.com/furqonzt99/snackbox/utils"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+var configTest *config.AppConfig
+var db *gorm.DB
+var subscriptionRepo *subscription.SubscriptionRepository
+
+func TestSubscription(t *testing.T) {
+	configTest = config.GetConfig()
+	db = utils.InitDB(configTest)
+
+	db.Migrator().DropTable(&models.SubscriptionItem{})
+	db.Migrator().DropTable(&models.Subscription{})
+	db.Migrator().DropTable(&models.Transaction{})
+	db.Migrator().DropTable(&models.LedgerEntry{})
+	db.Migrator().DropTable(&models.Product{})
+
+	subscriptionRepo = subscription.NewSubscriptionRepository(db)
+
+	db.AutoMigrate(&models.Product{})
+	db.AutoMigrate(&models.Transaction{})
+	db.AutoMigrate(&models.LedgerEntry{})
+	db.AutoMigrate(&models.Subscription{})
+	db.AutoMigrate(&models.SubscriptionItem{})
+
+	db.Create(&models.Product{PartnerID: 1, Title: "risoles", Type: "snackbox", Price: 10000})
+	db.Create(&models.Product{PartnerID: 2, Title: "rendang", Type: "ricebox", Price: 25000})
+
+	// 2030-01-07 is a monday
+	weekly := models.Subscription{
+		UserID:    1,
+		Rule:      "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH",
+		Time:      "09:00:00",
+		StartDate: "2030-01-08",
+		EndDate:   "2030-01-31",
+		Items:     []models.SubscriptionItem{{ProductID: 1, Quantity: 20}},
+	}
+
+	t.Run("next dates of the schedules", func(t *testing.T) {
+		next, _ := helper.NextSubscriptionDate(weekly, time.Date(2030, 1, 11, 0, 0, 0, 0, time.UTC))
+		assert.Equal(t, "2030-01-21", next)
+
+		monthly := models.Subscription{Rule: "FREQ=MONTHLY;BYMONTHDAY=31", StartDate: "2030-01-01"}
+		next, _ = helper.NextSubscriptionDate(monthly, time.Date(2030, 2, 1, 0, 0, 0, 0, time.UTC))
+		assert.Equal(t, "2030-03-31", next)
+
+		daily := models.Subscription{Rule: "FREQ=DAILY;INTERVAL=3", StartDate: "2030-01-01"}
+		next, _ = helper.NextSubscriptionDate(daily, time.Date(2030, 1, 5, 0, 0, 0, 0, time.UTC))
+		assert.Equal(t, "2030-01-07", next)
+	})
+
+	t.Run("create subscription", func(t *testing.T) {
+		res, err := subscriptionRepo.Create(weekly)
+		assert.Nil(t, err)
+		assert.Equal(t, uint(1), res.PartnerID)
+		assert.Equal(t, "2030-01-10", res.NextDate)
+		assert.Equal(t, constants.SUBSCRIPTION_ACTIVE, res.Status)
+	})
+
+	t.Run("create subscription from several partners", func(t *testing.T) {
+		mixed := weekly
+		mixed.Items = []models.SubscriptionItem{{ProductID: 1, Quantity: 20}, {ProductID: 2, Quantity: 20}}
+
+		_, err := subscriptionRepo.Create(mixed)
+		assert.Equal(t, helper.ErrSubscriptionPartners, err)
+	})
+
+	t.Run("skip next delivery", func(t *testing.T) {
+		res, err := subscriptionRepo.SkipNext(1, 1)
+		assert.Nil(t, err)
+		assert.Equal(t, "2030-01-21", res.NextDate)
+	})
+
+	t.Run("pause and resume", func(t *testing.T) {
+		res, err := subscriptionRepo.Pause(1, 1)
+		assert.Nil(t, err)
+		assert.Equal(t, constants.SUBSCRIPTION_PAUSED, res.Status)
+
+		active, _ := subscriptionRepo.GetActive()
+		assert.Equal(t, 0, len(active))
+
+		_, err = subscriptionRepo.Pause(1, 1)
+		assert.Equal(t, helper.ErrSubscriptionStatus, err)
+
+		res, err = subscriptionRepo.Resume(1, 1)
+		assert.Nil(t, err)
+		assert.Equal(t, "2030-01-21", res.NextDate)
+	})
+
+	t.Run("ordered occurrence", func(t *testing.T) {
+		dateTime, _ := helper.ParseEventTime("2030-01-21", "09:00:00")
+		db.Create(&models.Transaction{UserID: 1, PartnerID: 1, DateTime: dateTime, SubscriptionID: 1})
+
+		ordered, err := subscriptionRepo.Ordered(1, dateTime)
+		assert.Nil(t, err)
+		assert.True(t, ordered)
+	})
+
+	t.Run("advance until the end date", func(t *testing.T) {
+		assert.Nil(t, subscriptionRepo.Advance(1, ""))
+		res, _ := subscriptionRepo.Get(1, 1)
+		assert.Equal(t, "2030-01-24", res.NextDate)
+
+		assert.Nil(t, subscriptionRepo.Advance(1, "the partner is fully booked"))
+		res, _ = subscriptionRepo.Get(1, 1)
+		assert.Equal(t, "", res.NextDate)
+		assert.Equal(t, constants.SUBSCRIPTION_ENDED, res.Status)
+		assert.Equal(t, "the partner is fully booked", res.Note)
+	})
+
+	t.Run("cancel ended subscription", func(t *testing.T) {
+		_, err := subscriptionRepo.Cancel(1, 1)
+		assert.Equal(t, helper.ErrSubscriptionStatus, err)
+	})
+}
@@ -0,0 +1,71 @@
+package tax
+
+import (
+	"github.com/furqonzt99/snackbox/models"
+	"gorm.io/gorm"
+)
+
+type TaxInterface interface {
+	GetRules(partnerID int) ([]models.TaxRule, error)
+	Create(rule models.TaxRule) (models.TaxRule, error)
+	Update(ruleID, partnerID int, rule models.TaxRule) (models.TaxRule, error)
+	Delete(ruleID, partnerID int) error
+}
+
+type TaxRepository struct {
+	db *gorm.DB
+}
+
+func NewTaxRepository(db *gorm.DB) *TaxRepository {
+	return &TaxRepository{db: db}
+}
+
+func (tr *TaxRepository) GetRules(partnerID int) ([]models.TaxRule, error) {
+	rules := []models.TaxRule{}
+
+	if err := tr.db.Where("partner_id = ?", partnerID).Order("id").Find(&rules).Error; err != nil {
+		return rules, err
+	}
+
+	return rules, nil
+}
+
+func (tr *TaxRepository) Create(rule models.TaxRule) (models.TaxRule, error) {
+	if err := tr.db.Create(&rule).Error; err != nil {
+		return rule, err
+	}
+
+	return rule, nil
+}
+
+// Update changes the rate and how the tax is applied, the type stays as it was created.
+// Orders placed before keep the tax lines they were placed with.
+func (tr *TaxRepository) Update(ruleID, partnerID int, rule models.TaxRule) (models.TaxRule, error) {
+	current := models.TaxRule{}
+
+	if err := tr.db.Where("partner_id = ?", partnerID).First(&current, ruleID).Error; err != nil {
+		return current, err
+	}
+
+	err := tr.db.Model(&current).Updates(map[string]interface{}{
+		"rate":         rule.Rate,
+		"inclusive":    rule.Inclusive,
+		"tax_shipping": rule.TaxShipping,
+	}).Error
+	if err != nil {
+		return current, err
+	}
+
+	return current, nil
+}
+
+func (tr *TaxRepository) Delete(ruleID, partnerID int) error {
+	rule := models.TaxRule{}
+
+	if err := tr.db.Where("partner_id = ?", partnerID).First(&rule, ruleID).Error; err != nil {
+		return err
+	}
+
+	// hard delete so the partner can create a rule of the same type again
+	return tr.db.Unscoped().Delete(&rule).Error
+}
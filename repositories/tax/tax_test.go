@@ -0,0 +1,92 @@
+package tax_test
+
+import (
+	"testing"
+
+	config "github.com/furqonzt99/snackbox/configs"
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/helper"
+	"github.com/furqonzt99/snackbox/models"
+	"github.com/furqonzt99/snackbox/repositories/tax"
+	"github.com/furqonzt99/snackbox/utils"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+var configTest *config.AppConfig
+var db *gorm.DB
+var taxRepo *tax.TaxRepository
+
+func TestTax(t *testing.T) {
+	configTest = config.GetConfig()
+	db = utils.InitDB(configTest)
+
+	db.Migrator().DropTable(&models.TransactionTax{})
+	db.Migrator().DropTable(&models.TaxRule{})
+
+	taxRepo = tax.NewTaxRepository(db)
+
+	db.AutoMigrate(&models.TaxRule{})
+	db.AutoMigrate(&models.TransactionTax{})
+
+	t.Run("create rule", func(t *testing.T) {
+		rule, err := taxRepo.Create(models.TaxRule{PartnerID: 1, Type: constants.PB1_TAX, Rate: 10})
+		assert.Nil(t, err)
+		assert.Equal(t, uint(1), rule.ID)
+	})
+
+	t.Run("create rule for an existing type", func(t *testing.T) {
+		_, err := taxRepo.Create(models.TaxRule{PartnerID: 1, Type: constants.PB1_TAX, Rate: 5})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("update rule", func(t *testing.T) {
+		rule, err := taxRepo.Update(1, 1, models.TaxRule{Rate: 10, Inclusive: true, TaxShipping: true})
+		assert.Nil(t, err)
+		assert.True(t, rule.Inclusive)
+		assert.True(t, rule.TaxShipping)
+		assert.Equal(t, constants.PB1_TAX, rule.Type)
+	})
+
+	t.Run("update rule of another partner", func(t *testing.T) {
+		_, err := taxRepo.Update(1, 2, models.TaxRule{Rate: 11})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("apply taxes", func(t *testing.T) {
+		taxRepo.Create(models.TaxRule{PartnerID: 1, Type: constants.VAT_TAX, Rate: 11})
+
+		taxes, err := helper.ApplyTaxes(db, models.Transaction{Model: gorm.Model{ID: 7}, PartnerID: 1, Discount: 1000}, 11000, 5000, constants.FLAT_VOUCHER)
+		assert.Nil(t, err)
+		assert.Len(t, taxes, 2)
+
+		// PB1 is inclusive and taxes the shipping, (10000 + 5000) * 10 / 110
+		assert.Equal(t, models.Money(15000), taxes[0].Base)
+		assert.Equal(t, models.Money(1364), taxes[0].Amount)
+
+		// VAT is exclusive on the products only, 10000 * 11 / 100
+		assert.Equal(t, models.Money(10000), taxes[1].Base)
+		assert.Equal(t, models.Money(1100), taxes[1].Amount)
+		assert.Equal(t, models.Money(1100), helper.ExclusiveTax(taxes))
+
+		stored := []models.TransactionTax{}
+		db.Where("transaction_id = ?", 7).Find(&stored)
+		assert.Len(t, stored, 2)
+	})
+
+	t.Run("free shipping voucher lowers the shipping base", func(t *testing.T) {
+		rules := []models.TaxRule{{Type: constants.PB1_TAX, Rate: 10, TaxShipping: true}}
+
+		taxes := helper.CalculateTaxes(rules, 10000, 5000, 5000, constants.FREE_SHIPPING_VOUCHER)
+		assert.Equal(t, models.Money(10000), taxes[0].Base)
+		assert.Equal(t, models.Money(1000), taxes[0].Amount)
+	})
+
+	t.Run("delete rule", func(t *testing.T) {
+		assert.Nil(t, taxRepo.Delete(1, 1))
+		assert.NotNil(t, taxRepo.Delete(1, 1))
+
+		rules, _ := taxRepo.GetRules(1)
+		assert.Len(t, rules, 1)
+	})
+}
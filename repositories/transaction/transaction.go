@@ -1,49 +1,112 @@
 package transaction
 
 import (
+	"errors"
 	"fmt"
+	"sort"
 	"strconv"
+	"time"
 
+	"github.com/furqonzt99/snackbox/constants"
 	"github.com/furqonzt99/snackbox/helper"
 	"github.com/furqonzt99/snackbox/models"
+	"github.com/furqonzt99/snackbox/payment"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type TransactionInterface interface {
-	Order(transaction models.Transaction, email string, products []int) (models.Transaction, error)
+	Order(transaction models.Transaction, email string, items []models.DetailTransaction) (models.Transaction, error)
+	Checkout(orders []models.Transaction, email, voucherCode string, items [][]models.DetailTransaction) ([]models.Transaction, error)
 	Accept(trxID, partnerID int) (models.Transaction, error)
-	Reject(trxID, partnerID int) (models.Transaction, error)
+	Reject(trxID, partnerID int, reason string) (models.Transaction, error)
+	Prepare(trxID, partnerID int) (models.Transaction, error)
+	Ready(trxID, partnerID int) (models.Transaction, error)
+	Deliver(trxID, partnerID int) (models.Transaction, error)
 	Send(trxID, partnerID int) (models.Transaction, error)
 	Confirm(trxID, userID int) (models.Transaction, error)
+	Cancel(trxID, userID int, reason string) (models.Transaction, error)
+	RenewPayment(trxID, userID int) (models.Transaction, error)
 	GetAllForPartner(partnerID int) ([]models.Transaction, error)
 	GetAllForUser(userID int) ([]models.Transaction, error)
 	GetOneForUser(trxID, userID int) (models.Transaction, error)
 	GetOneForPartner(trxID, partnerID int) (models.Transaction, error)
+	GetTimelineForUser(trxID, userID int) ([]models.TransactionStatusHistory, error)
+	GetTimelineForPartner(trxID, partnerID int) ([]models.TransactionStatusHistory, error)
 	GetDistance(partnerID int, latitude, longtitude float64) (float64, error)
 
 	GetPartnerFromProduct(productID int) (models.Partner, error)
-	Callback(invId string, transaction models.Transaction, refund float64) (models.Transaction, error)
+	GetPartner(partnerID int) (models.Partner, error)
+	GetShippingTariff(partnerID int) (models.ShippingTariff, error)
+	Callback(invId string, transaction models.Transaction) (models.Transaction, error)
 }
 
 type TransactionRepository struct {
-	db *gorm.DB
+	db       *gorm.DB
+	provider payment.PaymentProvider
 }
 
-func NewTransactionRepository(db *gorm.DB) *TransactionRepository {
-	return &TransactionRepository{db: db}
+func NewTransactionRepository(db *gorm.DB, provider payment.PaymentProvider) *TransactionRepository {
+	return &TransactionRepository{db: db, provider: provider}
 }
 
-func (tr *TransactionRepository) Order(transaction models.Transaction, email string, products []int) (models.Transaction, error) {
+// Order places a single order in one database transaction. The invoice is expired again when
+// a step after issuing it fails.
+func (tr *TransactionRepository) Order(transaction models.Transaction, email string, items []models.DetailTransaction) (models.Transaction, error) {
+	var invoiceID string
+
 	err := tr.db.Transaction(func(tx *gorm.DB) error {
-		if err := tx.Create(&transaction).Error; err != nil {
+		var err error
+		invoiceID, err = tr.placeOrder(tx, &transaction, items, transaction.VoucherCode, email)
+		return err
+	})
+
+	if err != nil {
+		if invoiceID != "" {
+			helper.ExpireInvoice(tr.provider, invoiceID)
+		}
+		return transaction, err
+	}
+
+	if err := tr.db.Preload("User").Preload("DetailTransactions.Product").Preload("Taxes").First(&transaction, transaction.ID).Error; err != nil {
+		return transaction, err
+	}
+
+	return transaction, nil
+}
+
+// Checkout places the orders of a cart, one per partner, in a single database transaction. The
+// partners are locked before the first order is created, and the cart items of those partners
+// are removed with it, so either every order is placed or none is. The voucher goes to the
+// first order it is valid for. Invoices already issued when a later order fails are expired again.
+func (tr *TransactionRepository) Checkout(orders []models.Transaction, email, voucherCode string, items [][]models.DetailTransaction) ([]models.Transaction, error) {
+	invoiceIDs := []string{}
+
+	err := tr.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockPartners(tx, orders); err != nil {
 			return err
 		}
 
-		for _, product := range products {
-			if err := tx.Create(&models.DetailTransaction{
-				TransactionID: transaction.ID,
-				ProductID:     uint(product),
-			}).Error; err != nil {
+		for i := range orders {
+			order := orders[i]
+
+			// an order the voucher is not valid for is placed again without it,
+			// and the voucher is tried on the next one
+			invoiceID, err := tr.placeOrderSavepoint(tx, &orders[i], items[i], voucherCode, email)
+			if errors.Is(err, helper.ErrInvalidVoucher) && i < len(orders)-1 {
+				orders[i] = order
+				invoiceID, err = tr.placeOrderSavepoint(tx, &orders[i], items[i], "", email)
+			} else if voucherCode != "" && err == nil {
+				voucherCode = ""
+			}
+			if invoiceID != "" {
+				invoiceIDs = append(invoiceIDs, invoiceID)
+			}
+			if err != nil {
+				return err
+			}
+
+			if err := tx.Unscoped().Where("user_id = ? AND partner_id = ?", orders[i].UserID, orders[i].PartnerID).Delete(&models.CartItem{}).Error; err != nil {
 				return err
 			}
 		}
@@ -52,150 +115,458 @@ func (tr *TransactionRepository) Order(transaction models.Transaction, email str
 	})
 
 	if err != nil {
-		return transaction, err
+		for _, invoiceID := range invoiceIDs {
+			helper.ExpireInvoice(tr.provider, invoiceID)
+		}
+		return orders, err
 	}
 
-	var user models.User
-	if err := tr.db.First(&user, "email = ?", email).Error; err != nil {
-		return transaction, err
+	for i := range orders {
+		if err := tr.db.Preload("User").Preload("DetailTransactions.Product").Preload("Taxes").First(&orders[i], orders[i].ID).Error; err != nil {
+			return orders, err
+		}
 	}
 
-	err = tr.db.Transaction(func(tx *gorm.DB) error {
+	return orders, nil
+}
+
+// lockPartners locks the partners of the orders in id order, so two checkouts cannot deadlock.
+func lockPartners(tx *gorm.DB, orders []models.Transaction) error {
+	partnerIDs := []int{}
+	for _, order := range orders {
+		partnerIDs = append(partnerIDs, int(order.PartnerID))
+	}
+	sort.Ints(partnerIDs)
 
-		if err := tr.db.Preload("Products").First(&transaction, transaction.ID).Error; err != nil {
+	for _, partnerID := range partnerIDs {
+		partner := models.Partner{}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&partner, partnerID).Error; err != nil {
 			return err
 		}
+	}
 
-		var transactionPayment models.Transaction
+	return nil
+}
 
-		transactionPayment, err = helper.CreateInvoice(transaction, email, user.Balance)
-		if err != nil {
-			return err
+// placeOrderSavepoint places the order inside a savepoint, which is rolled back when it fails.
+func (tr *TransactionRepository) placeOrderSavepoint(tx *gorm.DB, transaction *models.Transaction, items []models.DetailTransaction, voucherCode, email string) (string, error) {
+	var invoiceID string
+
+	err := tx.Transaction(func(tx *gorm.DB) error {
+		var err error
+		invoiceID, err = tr.placeOrder(tx, transaction, items, voucherCode, email)
+		return err
+	})
+
+	return invoiceID, err
+}
+
+// placeOrder reserves the partner's capacity, stores the order with its voucher and taxes and
+// pays it. It returns the id of the invoice it created at the provider, also when a later step fails.
+func (tr *TransactionRepository) placeOrder(tx *gorm.DB, transaction *models.Transaction, items []models.DetailTransaction, voucherCode, email string) (string, error) {
+	// lock the partner so concurrent orders for the same date cannot both take the last boxes
+	partner := models.Partner{}
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&partner, transaction.PartnerID).Error; err != nil {
+		return "", err
+	}
+
+	if err := helper.ValidatePartnerCapacity(tx, partner, transaction.DateTime, transaction.Quantity); err != nil {
+		return "", err
+	}
+
+	transaction.VoucherCode = ""
+
+	details, subtotal, err := createOrder(tx, transaction, items)
+	if err != nil {
+		return "", err
+	}
+
+	tariff, err := helper.FindShippingTariff(tx, transaction.PartnerID)
+	if err != nil {
+		return "", err
+	}
+
+	shipping := helper.CalculateShipping(tariff, transaction.Latitude, transaction.Longtitude, transaction.Distance, subtotal, transaction.Quantity)
+
+	voucher := models.Voucher{}
+
+	if voucherCode != "" {
+		if voucher, err = applyOrderVoucher(tx, transaction, voucherCode, details, shipping); err != nil {
+			return "", err
 		}
+	}
+
+	if err := taxOrder(tx, transaction, subtotal, shipping, voucher.Type); err != nil {
+		return "", err
+	}
+
+	// lock the user so a concurrent order cannot spend the same balance, and read it again
+	// for every order of a checkout, the one before may have used up the balance
+	var user models.User
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, "email = ?", email).Error; err != nil {
+		return "", err
+	}
+
+	return tr.payOrder(tx, transaction, user, email, shipping)
+}
+
+// createOrder stores the order as PENDING with its details priced from the partner's products.
+func createOrder(tx *gorm.DB, transaction *models.Transaction, items []models.DetailTransaction) ([]models.DetailTransaction, models.Money, error) {
+	details := []models.DetailTransaction{}
+	var subtotal models.Money
+
+	if err := tx.Create(transaction).Error; err != nil {
+		return details, subtotal, err
+	}
 
-		balanceRemain := user.Balance - transactionPayment.TotalPrice
-		if balanceRemain <= 0 {
-			balanceRemain = 0
+	if err := helper.RecordOrderStatus(tx, transaction.ID, "", constants.PENDING_STATUS, helper.OrderActor{ID: int(transaction.UserID), Role: constants.USER_ROLE}, ""); err != nil {
+		return details, subtotal, err
+	}
+
+	for _, item := range items {
+		product := models.Product{}
+		if err := tx.Where("partner_id = ?", transaction.PartnerID).First(&product, item.ProductID).Error; err != nil {
+			return details, subtotal, err
 		}
 
-		// update user balance
-		if err := tx.Model(&user).Update("balance", balanceRemain).Error; err != nil {
-			return err
+		detail := models.DetailTransaction{
+			TransactionID: transaction.ID,
+			ProductID:     product.ID,
+			Title:         product.Title,
+			Type:          product.Type,
+			Price:         product.Price,
+			Quantity:      item.Quantity,
+			Notes:         item.Notes,
 		}
 
-		if err := tx.Model(&transaction).Updates(transactionPayment).Error; err != nil {
-			return err
+		if err := tx.Create(&detail).Error; err != nil {
+			return details, subtotal, err
 		}
 
-		return nil
-	})
+		details = append(details, detail)
+		subtotal += detail.Price * models.Money(detail.Quantity)
+	}
+
+	return details, subtotal, nil
+}
 
+// applyOrderVoucher redeems the voucher on the order.
+func applyOrderVoucher(tx *gorm.DB, transaction *models.Transaction, code string, details []models.DetailTransaction, shipping helper.ShippingBreakdown) (models.Voucher, error) {
+	voucher, discount, err := helper.ApplyVoucher(tx, code, *transaction, details, shipping)
 	if err != nil {
-		return transaction, err
+		return models.Voucher{}, err
 	}
 
-	if err := tr.db.Preload("User").Preload("Products").First(&transaction, transaction.ID).Error; err != nil {
-		return transaction, err
+	if err := tx.Model(transaction).Updates(models.Transaction{VoucherID: voucher.ID, VoucherCode: voucher.Code, Discount: discount}).Error; err != nil {
+		return models.Voucher{}, err
 	}
 
-	return transaction, nil
+	return voucher, nil
+}
+
+func taxOrder(tx *gorm.DB, transaction *models.Transaction, subtotal models.Money, shipping helper.ShippingBreakdown, voucherType string) error {
+	taxes, err := helper.ApplyTaxes(tx, *transaction, subtotal, shipping.Total, voucherType)
+	if err != nil {
+		return err
+	}
+
+	if tax := helper.ExclusiveTax(taxes); tax > 0 {
+		transaction.Tax = tax
+		return tx.Model(transaction).Update("tax", tax).Error
+	}
+
+	return nil
+}
+
+// payOrder issues the invoice for the order, takes what the user's balance covers into escrow
+// and marks the order PAID when the balance covers all of it. It returns the id of the invoice
+// it created at the provider, also when a later step fails.
+func (tr *TransactionRepository) payOrder(tx *gorm.DB, transaction *models.Transaction, user models.User, email string, shipping helper.ShippingBreakdown) (string, error) {
+	if err := tx.Preload("DetailTransactions").Preload("Taxes").First(transaction, transaction.ID).Error; err != nil {
+		return "", err
+	}
+
+	transactionPayment, err := helper.CreateInvoice(tr.provider, *transaction, email, user.Balance, shipping)
+	if err != nil {
+		return "", err
+	}
+
+	err = helper.PostLedger(tx, helper.LedgerTransfer{
+		From:        helper.WalletAccount(user.ID),
+		To:          helper.SystemAccount(constants.ESCROW_ACCOUNT),
+		Amount:      transactionPayment.BalanceUsed,
+		Type:        constants.ORDER_PAYMENT_ENTRY,
+		SourceType:  constants.TRANSACTION_SOURCE,
+		SourceID:    transaction.ID,
+		Description: "payment for order " + transaction.InvoiceID,
+	})
+	if err != nil {
+		return transactionPayment.PaymentInvoiceID, err
+	}
+
+	// paid in full with SboxPay, the status goes through the state machine
+	paymentStatus := transactionPayment.Status
+	transactionPayment.Status = ""
+
+	if err := tx.Model(transaction).Updates(transactionPayment).Error; err != nil {
+		return transactionPayment.PaymentInvoiceID, err
+	}
+
+	if transactionPayment.PaymentInvoiceID != "" {
+		invoice := models.TransactionInvoice{
+			TransactionID:    transaction.ID,
+			ExternalID:       transaction.InvoiceID,
+			PaymentInvoiceID: transactionPayment.PaymentInvoiceID,
+			PaymentUrl:       transactionPayment.PaymentUrl,
+			Amount:           transactionPayment.TotalPrice - transactionPayment.BalanceUsed,
+			Status:           payment.INVOICE_PENDING,
+			ExpiresAt:        time.Now().Add(time.Duration(helper.InvoiceDuration(*transaction, time.Now())) * time.Second),
+		}
+
+		if err := tx.Create(&invoice).Error; err != nil {
+			return transactionPayment.PaymentInvoiceID, err
+		}
+	}
+
+	if paymentStatus != "" {
+		if err := helper.TransitionOrder(tx, transaction, paymentStatus, helper.OrderActor{ID: int(user.ID), Role: constants.USER_ROLE}, "paid with SboxPay"); err != nil {
+			return transactionPayment.PaymentInvoiceID, err
+		}
+	}
+
+	return transactionPayment.PaymentInvoiceID, nil
 }
 
 func (tr *TransactionRepository) Accept(trxID int, partnerID int) (models.Transaction, error) {
+	return tr.partnerTransition(trxID, partnerID, constants.ACCEPT_STATUS, "")
+}
+
+func (tr *TransactionRepository) Reject(trxID int, partnerID int, reason string) (models.Transaction, error) {
 	trx := models.Transaction{}
 
-	const PAID_STATUS = "PAID"
-	if err := tr.db.Where("partner_id = ? AND status = ?", partnerID, PAID_STATUS).First(&trx, trxID).Error; err != nil {
-		return trx, err
+	err := tr.db.Transaction(func(tx *gorm.DB) error {
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("partner_id = ?", partnerID).First(&trx, trxID).Error; err != nil {
+			return err
+		}
+
+		if err := helper.TransitionOrder(tx, &trx, constants.REJECT_STATUS, helper.OrderActor{ID: partnerID, Role: constants.PARTNER_ROLE}, reason); err != nil {
+			return err
+		}
+
+		return helper.RefundOrder(tx, trx, trx.TotalPrice)
+	})
+
+	if err != nil {
+		return models.Transaction{}, err
 	}
 
-	const ACCEPT_STATUS = "ACCEPT"
-	tr.db.Model(&trx).Update("status", ACCEPT_STATUS)
+	return trx, nil
+}
+
+func (tr *TransactionRepository) Prepare(trxID int, partnerID int) (models.Transaction, error) {
+	return tr.partnerTransition(trxID, partnerID, constants.PREPARING_STATUS, "")
+}
+
+func (tr *TransactionRepository) Ready(trxID int, partnerID int) (models.Transaction, error) {
+	return tr.partnerTransition(trxID, partnerID, constants.READY_STATUS, "")
+}
+
+func (tr *TransactionRepository) Deliver(trxID int, partnerID int) (models.Transaction, error) {
+	return tr.partnerTransition(trxID, partnerID, constants.ON_DELIVERY_STATUS, "")
+}
+
+func (tr *TransactionRepository) Send(trxID int, partnerID int) (models.Transaction, error) {
+	return tr.partnerTransition(trxID, partnerID, constants.SEND_STATUS, "")
+}
+
+// partnerTransition locks the order row so the partner never overrides a status the customer,
+// the payment callback or the scheduler set in the meantime.
+func (tr *TransactionRepository) partnerTransition(trxID, partnerID int, status, reason string) (models.Transaction, error) {
+	trx := models.Transaction{}
+
+	err := tr.db.Transaction(func(tx *gorm.DB) error {
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("partner_id = ?", partnerID).First(&trx, trxID).Error; err != nil {
+			return err
+		}
+
+		return helper.TransitionOrder(tx, &trx, status, helper.OrderActor{ID: partnerID, Role: constants.PARTNER_ROLE}, reason)
+	})
+
+	if err != nil {
+		return models.Transaction{}, err
+	}
 
 	return trx, nil
 }
 
-func (tr *TransactionRepository) Reject(trxID int, partnerID int) (models.Transaction, error) {
+func (tr *TransactionRepository) Confirm(trxID int, userID int) (models.Transaction, error) {
 	trx := models.Transaction{}
 
-	const PAID_STATUS = "PAID"
-	if err := tr.db.Where("partner_id = ? AND status = ?", partnerID, PAID_STATUS).First(&trx, trxID).Error; err != nil {
-		return trx, err
+	err := tr.db.Transaction(func(tx *gorm.DB) error {
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ?", userID).First(&trx, trxID).Error; err != nil {
+			return err
+		}
+
+		if err := helper.TransitionOrder(tx, &trx, constants.CONFIRM_STATUS, helper.OrderActor{ID: userID, Role: constants.USER_ROLE}, ""); err != nil {
+			return err
+		}
+
+		return helper.PayPartner(tx, trx)
+	})
+
+	if err != nil {
+		return models.Transaction{}, err
 	}
 
-	tr.db.Transaction(func(tx *gorm.DB) error {
+	return trx, nil
+}
+
+// Cancel lets the customer call off an order that the partner has not accepted yet.
+// A pending order gets its invoice expired and the SboxPay balance used on it back,
+// a paid order is refunded in full to the SboxPay balance. The order row stays locked
+// while the invoice is expired, so a payment callback arriving meanwhile waits and then
+// finds the order cancelled; an invoice the provider already took the payment for cannot
+// be expired and the cancel fails instead.
+func (tr *TransactionRepository) Cancel(trxID int, userID int, reason string) (models.Transaction, error) {
+	trx := models.Transaction{}
+
+	err := tr.db.Transaction(func(tx *gorm.DB) error {
 
-		const REJECT_STATUS = "REJECT"
-		if err := tx.Model(&trx).Update("status", REJECT_STATUS).Error; err != nil {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ?", userID).First(&trx, trxID).Error; err != nil {
 			return err
 		}
 
-		user := models.User{}
-		if err := tx.First(&user, trx.UserID).Error; err != nil {
+		if !helper.CanTransitionOrder(trx.Status, constants.CANCEL_STATUS) {
+			return helper.ErrInvalidTransition
+		}
+
+		if err := helper.ValidateCancellationTime(trx.DateTime); err != nil {
 			return err
 		}
 
-		if err := tx.Model(&user).Update("balance", trx.TotalPrice).Error; err != nil {
+		refund := trx.TotalPrice
+
+		if trx.Status == constants.PENDING_STATUS {
+			refund = trx.BalanceUsed
+
+			if trx.PaymentInvoiceID != "" {
+				if err := helper.ExpireInvoice(tr.provider, trx.PaymentInvoiceID); err != nil {
+					return err
+				}
+			}
+		}
+
+		if err := helper.TransitionOrder(tx, &trx, constants.CANCEL_STATUS, helper.OrderActor{ID: userID, Role: constants.USER_ROLE}, reason); err != nil {
 			return err
 		}
 
-		return nil
+		return helper.RefundOrder(tx, trx, refund)
 	})
 
+	if err != nil {
+		return models.Transaction{}, err
+	}
+
 	return trx, nil
 }
 
-func (tr *TransactionRepository) Send(trxID int, partnerID int) (models.Transaction, error) {
+// RenewPayment issues a new invoice, under a new external id, for a pending order whose invoice
+// expired before the order's payment time ran out. The earlier invoices stay in the order's history.
+func (tr *TransactionRepository) RenewPayment(trxID int, userID int) (models.Transaction, error) {
 	trx := models.Transaction{}
 
-	const ACCEPT_STATUS = "ACCEPT"
-	if err := tr.db.Where("partner_id = ? AND status = ?", partnerID, ACCEPT_STATUS).First(&trx, trxID).Error; err != nil {
+	if err := tr.db.Preload("User").Preload("DetailTransactions").Preload("Invoices").Preload("Taxes").Where("user_id = ?", userID).First(&trx, trxID).Error; err != nil {
 		return trx, err
 	}
 
-	const SEND_STATUS = "SEND"
-	tr.db.Model(&trx).Update("status", SEND_STATUS)
+	if trx.Status != constants.PENDING_STATUS || trx.PaymentInvoiceID == "" {
+		return models.Transaction{}, helper.PaymentError("the order is not waiting for a payment")
+	}
 
-	return trx, nil
-}
+	current, err := tr.provider.GetInvoice(trx.PaymentInvoiceID)
+	if err != nil {
+		return models.Transaction{}, err
+	}
 
-func (tr *TransactionRepository) Confirm(trxID int, userID int) (models.Transaction, error) {
-	trx := models.Transaction{}
+	if current.Status == payment.INVOICE_PAID {
+		return models.Transaction{}, helper.PaymentError("the invoice is already paid, the payment is being confirmed")
+	}
 
-	const SEND_STATUS = "SEND"
-	if err := tr.db.Where("user_id = ? AND status = ?", userID, SEND_STATUS).First(&trx, trxID).Error; err != nil {
-		return trx, err
+	if current.Status == payment.INVOICE_PENDING {
+		if time.Now().Before(current.ExpiryDate) {
+			return models.Transaction{}, helper.PaymentError("the payment link is still valid")
+		}
+
+		// past its expiry date but not expired yet, make sure it cannot be paid next to the new one
+		if err := helper.ExpireInvoice(tr.provider, trx.PaymentInvoiceID); err != nil {
+			return models.Transaction{}, err
+		}
 	}
 
-	tr.db.Transaction(func(tx *gorm.DB) error {
+	externalID := fmt.Sprintf("%s-%d", trx.InvoiceID, len(trx.Invoices)+1)
 
-		const CONFIRM_STATUS = "CONFIRM"
-		if err := tx.First(&trx, trxID).Update("status", CONFIRM_STATUS).Error; err != nil {
+	issued, err := helper.ReissueInvoice(tr.provider, trx, trx.User.Email, externalID)
+	if err != nil {
+		return models.Transaction{}, err
+	}
+
+	err = tr.db.Transaction(func(tx *gorm.DB) error {
+		// lock the order so a late callback cannot settle it while its invoice is swapped
+		locked := models.Transaction{}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&locked, trx.ID).Error; err != nil {
 			return err
 		}
 
-		partner := models.Partner{}
-		if err := tx.First(&partner, trx.PartnerID).Error; err != nil {
+		if locked.Status != constants.PENDING_STATUS || locked.PaymentInvoiceID != trx.PaymentInvoiceID {
+			return helper.PaymentError("the order is not waiting for a payment")
+		}
+
+		if err := tx.Model(&models.TransactionInvoice{}).Where("transaction_id = ? AND status = ?", trx.ID, payment.INVOICE_PENDING).Update("status", payment.INVOICE_EXPIRED).Error; err != nil {
 			return err
 		}
 
-		user := models.User{}
-		if err := tx.First(&user, "id = ?", partner.UserID).Model(&user).Update("balance", trx.TotalPrice).Error; err != nil {
+		invoice := models.TransactionInvoice{
+			TransactionID:    trx.ID,
+			ExternalID:       externalID,
+			PaymentInvoiceID: issued.ID,
+			PaymentUrl:       issued.URL,
+			Amount:           issued.Amount,
+			Status:           payment.INVOICE_PENDING,
+			ExpiresAt:        issued.ExpiryDate,
+		}
+
+		if err := tx.Create(&invoice).Error; err != nil {
 			return err
 		}
 
-		return nil
+		return tx.Model(&locked).Updates(models.Transaction{PaymentInvoiceID: issued.ID, PaymentUrl: issued.URL}).Error
 	})
 
+	if err != nil {
+		// the new invoice is not recorded anywhere, so it must not stay payable
+		if expireErr := helper.ExpireInvoice(tr.provider, issued.ID); expireErr != nil {
+			return models.Transaction{}, fmt.Errorf("%v, and expiring invoice %s failed: %v", err, issued.ID, expireErr)
+		}
+
+		return models.Transaction{}, err
+	}
+
+	if err := tr.db.Preload("Invoices", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id asc")
+	}).First(&trx, trx.ID).Error; err != nil {
+		return trx, err
+	}
+
 	return trx, nil
 }
 
 func (tr *TransactionRepository) GetAllForPartner(partnerID int) ([]models.Transaction, error) {
 	trx := []models.Transaction{}
 
-	const PENDING_STATUS = "PENDING"
-
-	if err := tr.db.Preload("User").Preload("Products").Where("partner_id = ? AND status <> ?", partnerID, PENDING_STATUS).Find(&trx).Error; err != nil {
+	if err := tr.db.Preload("User").Preload("DetailTransactions.Product").Where("partner_id = ? AND status <> ?", partnerID, constants.PENDING_STATUS).Find(&trx).Error; err != nil {
 		return nil, err
 	}
 
@@ -205,7 +576,7 @@ func (tr *TransactionRepository) GetAllForPartner(partnerID int) ([]models.Trans
 func (tr *TransactionRepository) GetAllForUser(userID int) ([]models.Transaction, error) {
 	trx := []models.Transaction{}
 
-	if err := tr.db.Preload("User").Preload("Products").Where("user_id = ?", userID).Find(&trx).Error; err != nil {
+	if err := tr.db.Preload("User").Preload("DetailTransactions.Product").Where("user_id = ?", userID).Find(&trx).Error; err != nil {
 		return nil, err
 	}
 
@@ -215,7 +586,9 @@ func (tr *TransactionRepository) GetAllForUser(userID int) ([]models.Transaction
 func (tr *TransactionRepository) GetOneForUser(trxID, userID int) (models.Transaction, error) {
 	trx := models.Transaction{}
 
-	if err := tr.db.Preload("User").Preload("Products").Where("user_id = ?", userID).First(&trx, trxID).Error; err != nil {
+	if err := tr.db.Preload("User").Preload("Partner").Preload("DetailTransactions.Product").Preload("Taxes").Preload("Invoices", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id asc")
+	}).Where("user_id = ?", userID).First(&trx, trxID).Error; err != nil {
 		return trx, err
 	}
 
@@ -225,15 +598,43 @@ func (tr *TransactionRepository) GetOneForUser(trxID, userID int) (models.Transa
 func (tr *TransactionRepository) GetOneForPartner(trxID, partnerID int) (models.Transaction, error) {
 	trx := models.Transaction{}
 
-	const PAID_STATUS = "PAID"
-
-	if err := tr.db.Preload("User").Preload("Products").Where("partner_id = ? AND status = ?", partnerID, PAID_STATUS).First(&trx, trxID).Error; err != nil {
+	if err := tr.db.Preload("User").Preload("DetailTransactions.Product").Preload("Taxes").Where("partner_id = ? AND status <> ?", partnerID, constants.PENDING_STATUS).First(&trx, trxID).Error; err != nil {
 		return trx, err
 	}
 
 	return trx, nil
 }
 
+func (tr *TransactionRepository) GetTimelineForUser(trxID, userID int) ([]models.TransactionStatusHistory, error) {
+	trx := models.Transaction{}
+
+	if err := tr.db.Where("user_id = ?", userID).First(&trx, trxID).Error; err != nil {
+		return nil, err
+	}
+
+	return tr.getTimeline(trx.ID)
+}
+
+func (tr *TransactionRepository) GetTimelineForPartner(trxID, partnerID int) ([]models.TransactionStatusHistory, error) {
+	trx := models.Transaction{}
+
+	if err := tr.db.Where("partner_id = ?", partnerID).First(&trx, trxID).Error; err != nil {
+		return nil, err
+	}
+
+	return tr.getTimeline(trx.ID)
+}
+
+func (tr *TransactionRepository) getTimeline(trxID uint) ([]models.TransactionStatusHistory, error) {
+	histories := []models.TransactionStatusHistory{}
+
+	if err := tr.db.Order("created_at asc, id asc").Where("transaction_id = ?", trxID).Find(&histories).Error; err != nil {
+		return nil, err
+	}
+
+	return histories, nil
+}
+
 func (tr *TransactionRepository) GetPartnerFromProduct(productID int) (models.Partner, error) {
 	product := models.Product{}
 
@@ -250,24 +651,76 @@ func (tr *TransactionRepository) GetPartnerFromProduct(productID int) (models.Pa
 	return partner, nil
 }
 
-func (tr *TransactionRepository) Callback(invId string, transaction models.Transaction, refund float64) (models.Transaction, error) {
-
-	var trx models.Transaction
-	var user models.User
+func (tr *TransactionRepository) GetPartner(partnerID int) (models.Partner, error) {
+	partner := models.Partner{}
 
-	if err := tr.db.First(&trx, "invoice_id = ?", invId).Error; err != nil {
-		return transaction, err
+	if err := tr.db.First(&partner, partnerID).Error; err != nil {
+		return partner, err
 	}
 
-	if err := tr.db.First(&user, trx.UserID).Error; err != nil {
-		return transaction, err
-	}
+	return partner, nil
+}
+
+func (tr *TransactionRepository) GetShippingTariff(partnerID int) (models.ShippingTariff, error) {
+	return helper.FindShippingTariff(tr.db, uint(partnerID))
+}
 
-	balance := user.Balance + refund
+// Callback applies the invoice status. invId may be the external id of any invoice issued for
+// the order. A paid invoice brings the rest of the price into escrow, an expired one is only
+// recorded, the order stays open for a new invoice until the scheduler expires it.
+func (tr *TransactionRepository) Callback(invId string, transaction models.Transaction) (models.Transaction, error) {
+
+	status := transaction.Status
+	transaction.Status = ""
 
 	err := tr.db.Transaction(func(tx *gorm.DB) error {
 
-		if err := tx.Model(&user).Update("balance", balance).Error; err != nil {
+		var trx models.Transaction
+		var invoice models.TransactionInvoice
+
+		// the rows are locked so a cancel or a timeout racing the payment waits for it
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&invoice, "external_id = ?", invId).Error
+		if err == nil {
+			err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&trx, invoice.TransactionID).Error
+		} else if err == gorm.ErrRecordNotFound {
+			// orders placed before invoices were recorded separately
+			err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&trx, "invoice_id = ?", invId).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		// a later callback about an invoice that was paid already only brings its fee and settlement
+		paidBefore := status == payment.INVOICE_PAID && (invoice.Status == payment.INVOICE_PAID || invoice.ID == 0 && !trx.PaidAt.IsZero())
+
+		if invoice.ID != 0 {
+			if err := tx.Model(&invoice).Update("status", status).Error; err != nil {
+				return err
+			}
+		}
+
+		if status == payment.INVOICE_EXPIRED {
+			return nil
+		}
+
+		if paidBefore {
+			return tx.Model(&trx).Updates(transaction).Error
+		}
+
+		if err := helper.TransitionOrder(tx, &trx, status, helper.SystemActor(), "payment callback"); err != nil {
+			return err
+		}
+
+		err = helper.PostLedger(tx, helper.LedgerTransfer{
+			From:        helper.SystemAccount(constants.GATEWAY_ACCOUNT),
+			To:          helper.SystemAccount(constants.ESCROW_ACCOUNT),
+			Amount:      trx.TotalPrice - trx.BalanceUsed,
+			Type:        constants.INVOICE_PAYMENT_ENTRY,
+			SourceType:  constants.TRANSACTION_SOURCE,
+			SourceID:    trx.ID,
+			Description: "invoice paid for order " + trx.InvoiceID,
+		})
+		if err != nil {
 			return err
 		}
 
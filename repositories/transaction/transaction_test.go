@@ -1,13 +1,17 @@
 package transaction_test
 
 import (
+	"errors"
 	"log"
 	"os"
 	"testing"
+	"time"
 
 	config "github.com/furqonzt99/snackbox/configs"
 	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/helper"
 	"github.com/furqonzt99/snackbox/models"
+	"github.com/furqonzt99/snackbox/payment"
 	"github.com/furqonzt99/snackbox/repositories/partner"
 	"github.com/furqonzt99/snackbox/repositories/product"
 	"github.com/furqonzt99/snackbox/repositories/transaction"
@@ -42,21 +46,43 @@ func TestOrder(t *testing.T) {
 	db.Migrator().DropTable(&models.Partner{})
 	db.Migrator().DropTable(&models.Product{})
 	db.Migrator().DropTable(&models.Rating{})
+	db.Migrator().DropTable(&models.TransactionInvoice{})
+	db.Migrator().DropTable(&models.TransactionTax{})
+	db.Migrator().DropTable(&models.TaxRule{})
 	db.Migrator().DropTable(&models.Transaction{})
+	db.Migrator().DropTable(&models.RefundRequest{})
+	db.Migrator().DropTable(&models.TransactionFee{})
+	db.Migrator().DropTable(&models.CommissionRule{})
+	db.Migrator().DropTable(&models.LedgerEntry{})
 	db.Migrator().DropTable(&models.DetailTransaction{})
+	db.Migrator().DropTable(&models.TransactionStatusHistory{})
+	db.Migrator().DropTable(&models.VoucherUsage{})
+	db.Migrator().DropTable(&models.Voucher{})
+	db.Migrator().DropTable(&models.PartnerCalendar{})
 	db.Migrator().DropTable(&models.Cashout{})
 
 	userRepo = user.NewUserRepo(db)
 	partnerRepo = partner.NewPartnerRepo(db)
 	productRepo = product.NewProductRepo(db)
-	transactionRepo = transaction.NewTransactionRepository(db)
+	transactionRepo = transaction.NewTransactionRepository(db, utils.InitPaymentProvider(configTest))
 
 	db.AutoMigrate(&models.User{})
 	db.AutoMigrate(&models.Partner{})
 	db.AutoMigrate(&models.Product{})
 	db.AutoMigrate(&models.Rating{})
 	db.AutoMigrate(&models.Transaction{})
+	db.AutoMigrate(&models.TransactionInvoice{})
+	db.AutoMigrate(&models.TaxRule{})
+	db.AutoMigrate(&models.TransactionTax{})
+	db.AutoMigrate(&models.LedgerEntry{})
+	db.AutoMigrate(&models.CommissionRule{})
+	db.AutoMigrate(&models.TransactionFee{})
+	db.AutoMigrate(&models.RefundRequest{})
 	db.AutoMigrate(&models.DetailTransaction{})
+	db.AutoMigrate(&models.TransactionStatusHistory{})
+	db.AutoMigrate(&models.Voucher{})
+	db.AutoMigrate(&models.VoucherUsage{})
+	db.AutoMigrate(&models.PartnerCalendar{})
 	db.AutoMigrate(&models.Cashout{})
 
 	//CREATE USER
@@ -130,8 +156,8 @@ func TestOrder(t *testing.T) {
 		mockTransaction.TotalPrice = 20000
 		mockTransaction.InvoiceID = "suka"
 
-		res, _ := transactionRepo.Order(mockTransaction, "test2@gmail.com", []int{1})
-		assert.Equal(t, float64(20000), res.TotalPrice)
+		res, _ := transactionRepo.Order(mockTransaction, "test2@gmail.com", []models.DetailTransaction{{ProductID: 1, Quantity: 1}})
+		assert.Equal(t, models.Money(20000), res.TotalPrice)
 	})
 
 	t.Run("create order invalid 1", func(t *testing.T) {
@@ -143,8 +169,8 @@ func TestOrder(t *testing.T) {
 
 		mockTransaction.InvoiceID = "suka"
 
-		res, _ := transactionRepo.Order(mockTransaction, "test2@gmail.com", []int{1})
-		assert.Equal(t, float64(30000), res.TotalPrice)
+		res, _ := transactionRepo.Order(mockTransaction, "test2@gmail.com", []models.DetailTransaction{{ProductID: 1, Quantity: 1}})
+		assert.Equal(t, models.Money(30000), res.TotalPrice)
 	})
 
 	t.Run("create order invalid 3", func(t *testing.T) {
@@ -154,8 +180,8 @@ func TestOrder(t *testing.T) {
 		mockTransaction.TotalPrice = 30000
 		mockTransaction.InvoiceID = "suka"
 
-		res, _ := transactionRepo.Order(mockTransaction, "test2@gmail.com", []int{0})
-		assert.Equal(t, float64(30000), res.TotalPrice)
+		res, _ := transactionRepo.Order(mockTransaction, "test2@gmail.com", []models.DetailTransaction{{ProductID: 0, Quantity: 1}})
+		assert.Equal(t, models.Money(30000), res.TotalPrice)
 	})
 
 	t.Run("create order", func(t *testing.T) {
@@ -165,8 +191,8 @@ func TestOrder(t *testing.T) {
 		mockTransaction.TotalPrice = 20000
 		mockTransaction.InvoiceID = "suka"
 
-		res, _ := transactionRepo.Order(mockTransaction, "test9@gmail.com", []int{1})
-		assert.Equal(t, float64(20000), res.TotalPrice)
+		res, _ := transactionRepo.Order(mockTransaction, "test9@gmail.com", []models.DetailTransaction{{ProductID: 1, Quantity: 1}})
+		assert.Equal(t, models.Money(20000), res.TotalPrice)
 	})
 
 	t.Run("create order success", func(t *testing.T) {
@@ -177,8 +203,8 @@ func TestOrder(t *testing.T) {
 		mockTransaction.InvoiceID = "suka"
 		mockTransaction.Quantity = 5
 
-		res, _ := transactionRepo.Order(mockTransaction, "test2@gmail.com", []int{1})
-		assert.Equal(t, float64(55000), res.TotalPrice)
+		res, _ := transactionRepo.Order(mockTransaction, "test2@gmail.com", []models.DetailTransaction{{ProductID: 1, Quantity: 1}})
+		assert.Equal(t, models.Money(55000), res.TotalPrice)
 	})
 
 }
@@ -192,20 +218,34 @@ func TestAccept(t *testing.T) {
 	db.Migrator().DropTable(&models.Product{})
 	db.Migrator().DropTable(&models.Rating{})
 	db.Migrator().DropTable(&models.Transaction{})
+	db.Migrator().DropTable(&models.RefundRequest{})
+	db.Migrator().DropTable(&models.TransactionFee{})
+	db.Migrator().DropTable(&models.CommissionRule{})
+	db.Migrator().DropTable(&models.LedgerEntry{})
 	db.Migrator().DropTable(&models.DetailTransaction{})
+	db.Migrator().DropTable(&models.TransactionStatusHistory{})
+	db.Migrator().DropTable(&models.VoucherUsage{})
+	db.Migrator().DropTable(&models.Voucher{})
 	db.Migrator().DropTable(&models.Cashout{})
 
 	userRepo = user.NewUserRepo(db)
 	partnerRepo = partner.NewPartnerRepo(db)
 	productRepo = product.NewProductRepo(db)
-	transactionRepo = transaction.NewTransactionRepository(db)
+	transactionRepo = transaction.NewTransactionRepository(db, utils.InitPaymentProvider(configTest))
 
 	db.AutoMigrate(&models.User{})
 	db.AutoMigrate(&models.Partner{})
 	db.AutoMigrate(&models.Product{})
 	db.AutoMigrate(&models.Rating{})
 	db.AutoMigrate(&models.Transaction{})
+	db.AutoMigrate(&models.LedgerEntry{})
+	db.AutoMigrate(&models.CommissionRule{})
+	db.AutoMigrate(&models.TransactionFee{})
+	db.AutoMigrate(&models.RefundRequest{})
 	db.AutoMigrate(&models.DetailTransaction{})
+	db.AutoMigrate(&models.TransactionStatusHistory{})
+	db.AutoMigrate(&models.Voucher{})
+	db.AutoMigrate(&models.VoucherUsage{})
 	db.AutoMigrate(&models.Cashout{})
 
 	//CREATE USER
@@ -278,6 +318,22 @@ func TestAccept(t *testing.T) {
 		res, _ := transactionRepo.Accept(2, 1)
 		assert.Equal(t, "", res.Status)
 	})
+
+	t.Run("test transition from a stale read", func(t *testing.T) {
+		trx := models.Transaction{PartnerID: 1, UserID: 2, Status: constants.PAID_STATUS}
+		db.Create(&trx)
+
+		stale := trx
+		db.Model(&trx).Update("status", constants.REJECT_STATUS)
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			return helper.TransitionOrder(tx, &stale, constants.ACCEPT_STATUS, helper.SystemActor(), "")
+		})
+		assert.True(t, errors.Is(err, helper.ErrInvalidTransition))
+
+		db.First(&trx, trx.ID)
+		assert.Equal(t, constants.REJECT_STATUS, trx.Status)
+	})
 }
 
 func TestReject(t *testing.T) {
@@ -289,20 +345,34 @@ func TestReject(t *testing.T) {
 	db.Migrator().DropTable(&models.Product{})
 	db.Migrator().DropTable(&models.Rating{})
 	db.Migrator().DropTable(&models.Transaction{})
+	db.Migrator().DropTable(&models.RefundRequest{})
+	db.Migrator().DropTable(&models.TransactionFee{})
+	db.Migrator().DropTable(&models.CommissionRule{})
+	db.Migrator().DropTable(&models.LedgerEntry{})
 	db.Migrator().DropTable(&models.DetailTransaction{})
+	db.Migrator().DropTable(&models.TransactionStatusHistory{})
+	db.Migrator().DropTable(&models.VoucherUsage{})
+	db.Migrator().DropTable(&models.Voucher{})
 	db.Migrator().DropTable(&models.Cashout{})
 
 	userRepo = user.NewUserRepo(db)
 	partnerRepo = partner.NewPartnerRepo(db)
 	productRepo = product.NewProductRepo(db)
-	transactionRepo = transaction.NewTransactionRepository(db)
+	transactionRepo = transaction.NewTransactionRepository(db, utils.InitPaymentProvider(configTest))
 
 	db.AutoMigrate(&models.User{})
 	db.AutoMigrate(&models.Partner{})
 	db.AutoMigrate(&models.Product{})
 	db.AutoMigrate(&models.Rating{})
 	db.AutoMigrate(&models.Transaction{})
+	db.AutoMigrate(&models.LedgerEntry{})
+	db.AutoMigrate(&models.CommissionRule{})
+	db.AutoMigrate(&models.TransactionFee{})
+	db.AutoMigrate(&models.RefundRequest{})
 	db.AutoMigrate(&models.DetailTransaction{})
+	db.AutoMigrate(&models.TransactionStatusHistory{})
+	db.AutoMigrate(&models.Voucher{})
+	db.AutoMigrate(&models.VoucherUsage{})
 	db.AutoMigrate(&models.Cashout{})
 
 	//CREATE USER
@@ -356,7 +426,7 @@ func TestReject(t *testing.T) {
 
 	t.Run("test reject", func(t *testing.T) {
 
-		res, _ := transactionRepo.Reject(1, 1)
+		res, _ := transactionRepo.Reject(1, 1, "")
 		assert.Equal(t, "REJECT", res.Status)
 	})
 
@@ -372,7 +442,7 @@ func TestReject(t *testing.T) {
 			Status:     "UNPAID",
 		}
 		db.Create(&dummyTransaction)
-		res, _ := transactionRepo.Reject(2, 1)
+		res, _ := transactionRepo.Reject(2, 1, "")
 		assert.Equal(t, "", res.Status)
 	})
 
@@ -390,7 +460,7 @@ func TestReject(t *testing.T) {
 			Status:     "ACCEPT",
 		}
 		db.Create(&dummyTransaction2)
-		res, _ := transactionRepo.Reject(3, 1)
+		res, _ := transactionRepo.Reject(3, 1, "")
 		assert.Equal(t, "", res.Status)
 	})
 
@@ -404,20 +474,34 @@ func TestSend(t *testing.T) {
 	db.Migrator().DropTable(&models.Product{})
 	db.Migrator().DropTable(&models.Rating{})
 	db.Migrator().DropTable(&models.Transaction{})
+	db.Migrator().DropTable(&models.RefundRequest{})
+	db.Migrator().DropTable(&models.TransactionFee{})
+	db.Migrator().DropTable(&models.CommissionRule{})
+	db.Migrator().DropTable(&models.LedgerEntry{})
 	db.Migrator().DropTable(&models.DetailTransaction{})
+	db.Migrator().DropTable(&models.TransactionStatusHistory{})
+	db.Migrator().DropTable(&models.VoucherUsage{})
+	db.Migrator().DropTable(&models.Voucher{})
 	db.Migrator().DropTable(&models.Cashout{})
 
 	userRepo = user.NewUserRepo(db)
 	partnerRepo = partner.NewPartnerRepo(db)
 	productRepo = product.NewProductRepo(db)
-	transactionRepo = transaction.NewTransactionRepository(db)
+	transactionRepo = transaction.NewTransactionRepository(db, utils.InitPaymentProvider(configTest))
 
 	db.AutoMigrate(&models.User{})
 	db.AutoMigrate(&models.Partner{})
 	db.AutoMigrate(&models.Product{})
 	db.AutoMigrate(&models.Rating{})
 	db.AutoMigrate(&models.Transaction{})
+	db.AutoMigrate(&models.LedgerEntry{})
+	db.AutoMigrate(&models.CommissionRule{})
+	db.AutoMigrate(&models.TransactionFee{})
+	db.AutoMigrate(&models.RefundRequest{})
 	db.AutoMigrate(&models.DetailTransaction{})
+	db.AutoMigrate(&models.TransactionStatusHistory{})
+	db.AutoMigrate(&models.Voucher{})
+	db.AutoMigrate(&models.VoucherUsage{})
 	db.AutoMigrate(&models.Cashout{})
 
 	//CREATE USER
@@ -501,20 +585,34 @@ func TestConfirm(t *testing.T) {
 	db.Migrator().DropTable(&models.Product{})
 	db.Migrator().DropTable(&models.Rating{})
 	db.Migrator().DropTable(&models.Transaction{})
+	db.Migrator().DropTable(&models.RefundRequest{})
+	db.Migrator().DropTable(&models.TransactionFee{})
+	db.Migrator().DropTable(&models.CommissionRule{})
+	db.Migrator().DropTable(&models.LedgerEntry{})
 	db.Migrator().DropTable(&models.DetailTransaction{})
+	db.Migrator().DropTable(&models.TransactionStatusHistory{})
+	db.Migrator().DropTable(&models.VoucherUsage{})
+	db.Migrator().DropTable(&models.Voucher{})
 	db.Migrator().DropTable(&models.Cashout{})
 
 	userRepo = user.NewUserRepo(db)
 	partnerRepo = partner.NewPartnerRepo(db)
 	productRepo = product.NewProductRepo(db)
-	transactionRepo = transaction.NewTransactionRepository(db)
+	transactionRepo = transaction.NewTransactionRepository(db, utils.InitPaymentProvider(configTest))
 
 	db.AutoMigrate(&models.User{})
 	db.AutoMigrate(&models.Partner{})
 	db.AutoMigrate(&models.Product{})
 	db.AutoMigrate(&models.Rating{})
 	db.AutoMigrate(&models.Transaction{})
+	db.AutoMigrate(&models.LedgerEntry{})
+	db.AutoMigrate(&models.CommissionRule{})
+	db.AutoMigrate(&models.TransactionFee{})
+	db.AutoMigrate(&models.RefundRequest{})
 	db.AutoMigrate(&models.DetailTransaction{})
+	db.AutoMigrate(&models.TransactionStatusHistory{})
+	db.AutoMigrate(&models.Voucher{})
+	db.AutoMigrate(&models.VoucherUsage{})
 	db.AutoMigrate(&models.Cashout{})
 
 	//CREATE USER
@@ -608,6 +706,114 @@ func TestConfirm(t *testing.T) {
 	})
 }
 
+func TestCancel(t *testing.T) {
+	configTest = config.GetConfig()
+	db = utils.InitDB(configTest)
+
+	db.Migrator().DropTable(&models.User{})
+	db.Migrator().DropTable(&models.Partner{})
+	db.Migrator().DropTable(&models.Transaction{})
+	db.Migrator().DropTable(&models.RefundRequest{})
+	db.Migrator().DropTable(&models.TransactionFee{})
+	db.Migrator().DropTable(&models.CommissionRule{})
+	db.Migrator().DropTable(&models.LedgerEntry{})
+	db.Migrator().DropTable(&models.TransactionStatusHistory{})
+	db.Migrator().DropTable(&models.VoucherUsage{})
+	db.Migrator().DropTable(&models.Voucher{})
+
+	userRepo = user.NewUserRepo(db)
+	partnerRepo = partner.NewPartnerRepo(db)
+	transactionRepo = transaction.NewTransactionRepository(db, utils.InitPaymentProvider(configTest))
+
+	db.AutoMigrate(&models.User{})
+	db.AutoMigrate(&models.Partner{})
+	db.AutoMigrate(&models.Transaction{})
+	db.AutoMigrate(&models.LedgerEntry{})
+	db.AutoMigrate(&models.CommissionRule{})
+	db.AutoMigrate(&models.TransactionFee{})
+	db.AutoMigrate(&models.RefundRequest{})
+	db.AutoMigrate(&models.TransactionStatusHistory{})
+	db.AutoMigrate(&models.Voucher{})
+	db.AutoMigrate(&models.VoucherUsage{})
+
+	//CREATE USER
+	dummyUser := models.User{
+		Email:    "test@gmail.com",
+		Password: "test1234",
+		Role:     "user",
+	}
+	userRepo.Register(dummyUser)
+
+	//CREATE TRANSACTION
+	dummyTransaction := models.Transaction{
+		PartnerID:  1,
+		UserID:     1,
+		Quantity:   1,
+		TotalPrice: 5000,
+		DateTime:   time.Now().AddDate(0, 0, 7),
+		Status:     constants.PAID_STATUS,
+	}
+	db.Create(&dummyTransaction)
+
+	t.Run("test cancel", func(t *testing.T) {
+		res, err := transactionRepo.Cancel(1, 1, "change of plan")
+		assert.Nil(t, err)
+		assert.Equal(t, constants.CANCEL_STATUS, res.Status)
+
+		var user models.User
+		db.First(&user, 1)
+		assert.Equal(t, models.Money(5000), user.Balance)
+	})
+
+	t.Run("test cancel twice", func(t *testing.T) {
+		_, err := transactionRepo.Cancel(1, 1, "")
+		assert.Equal(t, helper.ErrInvalidTransition, err)
+	})
+
+	t.Run("test cancel too late", func(t *testing.T) {
+		dummyTransaction2 := models.Transaction{
+			PartnerID: 1,
+			UserID:    1,
+			Quantity:  1,
+			DateTime:  time.Now().Add(time.Hour),
+			Status:    constants.PAID_STATUS,
+		}
+		db.Create(&dummyTransaction2)
+
+		_, err := transactionRepo.Cancel(2, 1, "")
+		assert.Equal(t, helper.ErrCancellationTooLate, err)
+	})
+
+	t.Run("test cancel other user", func(t *testing.T) {
+		res, err := transactionRepo.Cancel(2, 2, "")
+		assert.NotNil(t, err)
+		assert.Equal(t, "", res.Status)
+	})
+
+	t.Run("test cancel an order the provider took the payment for", func(t *testing.T) {
+		provider := payment.NewSandboxProvider("", "", 0)
+		issued, _ := provider.CreateInvoice(payment.CreateInvoiceParams{ExternalID: "SB-RACE", Amount: 5000, Duration: 3600})
+		time.Sleep(100 * time.Millisecond)
+
+		pending := models.Transaction{
+			PartnerID:        1,
+			UserID:           1,
+			TotalPrice:       5000,
+			DateTime:         time.Now().AddDate(0, 0, 7),
+			InvoiceID:        "SB-RACE",
+			PaymentInvoiceID: issued.ID,
+			Status:           constants.PENDING_STATUS,
+		}
+		db.Create(&pending)
+
+		_, err := transaction.NewTransactionRepository(db, provider).Cancel(int(pending.ID), 1, "")
+		assert.True(t, errors.Is(err, helper.ErrInvalidPayment))
+
+		db.First(&pending, pending.ID)
+		assert.Equal(t, constants.PENDING_STATUS, pending.Status)
+	})
+}
+
 func TestGetAllForPartner(t *testing.T) {
 	configTest = config.GetConfig()
 	db = utils.InitDB(configTest)
@@ -617,20 +823,34 @@ func TestGetAllForPartner(t *testing.T) {
 	db.Migrator().DropTable(&models.Product{})
 	db.Migrator().DropTable(&models.Rating{})
 	db.Migrator().DropTable(&models.Transaction{})
+	db.Migrator().DropTable(&models.RefundRequest{})
+	db.Migrator().DropTable(&models.TransactionFee{})
+	db.Migrator().DropTable(&models.CommissionRule{})
+	db.Migrator().DropTable(&models.LedgerEntry{})
 	db.Migrator().DropTable(&models.DetailTransaction{})
+	db.Migrator().DropTable(&models.TransactionStatusHistory{})
+	db.Migrator().DropTable(&models.VoucherUsage{})
+	db.Migrator().DropTable(&models.Voucher{})
 	db.Migrator().DropTable(&models.Cashout{})
 
 	userRepo = user.NewUserRepo(db)
 	partnerRepo = partner.NewPartnerRepo(db)
 	productRepo = product.NewProductRepo(db)
-	transactionRepo = transaction.NewTransactionRepository(db)
+	transactionRepo = transaction.NewTransactionRepository(db, utils.InitPaymentProvider(configTest))
 
 	db.AutoMigrate(&models.User{})
 	db.AutoMigrate(&models.Partner{})
 	db.AutoMigrate(&models.Product{})
 	db.AutoMigrate(&models.Rating{})
 	db.AutoMigrate(&models.Transaction{})
+	db.AutoMigrate(&models.LedgerEntry{})
+	db.AutoMigrate(&models.CommissionRule{})
+	db.AutoMigrate(&models.TransactionFee{})
+	db.AutoMigrate(&models.RefundRequest{})
 	db.AutoMigrate(&models.DetailTransaction{})
+	db.AutoMigrate(&models.TransactionStatusHistory{})
+	db.AutoMigrate(&models.Voucher{})
+	db.AutoMigrate(&models.VoucherUsage{})
 	db.AutoMigrate(&models.Cashout{})
 
 	//CREATE USER
@@ -689,6 +909,7 @@ func TestGetAllForPartner(t *testing.T) {
 	})
 	t.Run("test GetAllForPartner", func(t *testing.T) {
 		db.Migrator().DropTable(&models.Transaction{})
+		db.Migrator().DropTable(&models.LedgerEntry{})
 		res, _ := transactionRepo.GetAllForPartner(1)
 		assert.Equal(t, []models.Transaction([]models.Transaction(nil)), res)
 	})
@@ -703,20 +924,34 @@ func TestGetAllForUser(t *testing.T) {
 	db.Migrator().DropTable(&models.Product{})
 	db.Migrator().DropTable(&models.Rating{})
 	db.Migrator().DropTable(&models.Transaction{})
+	db.Migrator().DropTable(&models.RefundRequest{})
+	db.Migrator().DropTable(&models.TransactionFee{})
+	db.Migrator().DropTable(&models.CommissionRule{})
+	db.Migrator().DropTable(&models.LedgerEntry{})
 	db.Migrator().DropTable(&models.DetailTransaction{})
+	db.Migrator().DropTable(&models.TransactionStatusHistory{})
+	db.Migrator().DropTable(&models.VoucherUsage{})
+	db.Migrator().DropTable(&models.Voucher{})
 	db.Migrator().DropTable(&models.Cashout{})
 
 	userRepo = user.NewUserRepo(db)
 	partnerRepo = partner.NewPartnerRepo(db)
 	productRepo = product.NewProductRepo(db)
-	transactionRepo = transaction.NewTransactionRepository(db)
+	transactionRepo = transaction.NewTransactionRepository(db, utils.InitPaymentProvider(configTest))
 
 	db.AutoMigrate(&models.User{})
 	db.AutoMigrate(&models.Partner{})
 	db.AutoMigrate(&models.Product{})
 	db.AutoMigrate(&models.Rating{})
 	db.AutoMigrate(&models.Transaction{})
+	db.AutoMigrate(&models.LedgerEntry{})
+	db.AutoMigrate(&models.CommissionRule{})
+	db.AutoMigrate(&models.TransactionFee{})
+	db.AutoMigrate(&models.RefundRequest{})
 	db.AutoMigrate(&models.DetailTransaction{})
+	db.AutoMigrate(&models.TransactionStatusHistory{})
+	db.AutoMigrate(&models.Voucher{})
+	db.AutoMigrate(&models.VoucherUsage{})
 	db.AutoMigrate(&models.Cashout{})
 
 	//CREATE USER
@@ -775,6 +1010,7 @@ func TestGetAllForUser(t *testing.T) {
 	})
 	t.Run("test GetAllForUser", func(t *testing.T) {
 		db.Migrator().DropTable(&models.Transaction{})
+		db.Migrator().DropTable(&models.LedgerEntry{})
 		res, _ := transactionRepo.GetAllForUser(1)
 		assert.Equal(t, []models.Transaction([]models.Transaction(nil)), res)
 	})
@@ -789,20 +1025,34 @@ func TestGetOneForUser(t *testing.T) {
 	db.Migrator().DropTable(&models.Product{})
 	db.Migrator().DropTable(&models.Rating{})
 	db.Migrator().DropTable(&models.Transaction{})
+	db.Migrator().DropTable(&models.RefundRequest{})
+	db.Migrator().DropTable(&models.TransactionFee{})
+	db.Migrator().DropTable(&models.CommissionRule{})
+	db.Migrator().DropTable(&models.LedgerEntry{})
 	db.Migrator().DropTable(&models.DetailTransaction{})
+	db.Migrator().DropTable(&models.TransactionStatusHistory{})
+	db.Migrator().DropTable(&models.VoucherUsage{})
+	db.Migrator().DropTable(&models.Voucher{})
 	db.Migrator().DropTable(&models.Cashout{})
 
 	userRepo = user.NewUserRepo(db)
 	partnerRepo = partner.NewPartnerRepo(db)
 	productRepo = product.NewProductRepo(db)
-	transactionRepo = transaction.NewTransactionRepository(db)
+	transactionRepo = transaction.NewTransactionRepository(db, utils.InitPaymentProvider(configTest))
 
 	db.AutoMigrate(&models.User{})
 	db.AutoMigrate(&models.Partner{})
 	db.AutoMigrate(&models.Product{})
 	db.AutoMigrate(&models.Rating{})
 	db.AutoMigrate(&models.Transaction{})
+	db.AutoMigrate(&models.LedgerEntry{})
+	db.AutoMigrate(&models.CommissionRule{})
+	db.AutoMigrate(&models.TransactionFee{})
+	db.AutoMigrate(&models.RefundRequest{})
 	db.AutoMigrate(&models.DetailTransaction{})
+	db.AutoMigrate(&models.TransactionStatusHistory{})
+	db.AutoMigrate(&models.Voucher{})
+	db.AutoMigrate(&models.VoucherUsage{})
 	db.AutoMigrate(&models.Cashout{})
 
 	//CREATE USER
@@ -861,8 +1111,9 @@ func TestGetOneForUser(t *testing.T) {
 	})
 	t.Run("test TestGetOneForUser invalid", func(t *testing.T) {
 		db.Migrator().DropTable(&models.Transaction{})
+		db.Migrator().DropTable(&models.LedgerEntry{})
 		res, _ := transactionRepo.GetOneForUser(1, 2)
-		assert.Equal(t, float64(0), res.User.Balance)
+		assert.Equal(t, models.Money(0), res.User.Balance)
 	})
 }
 
@@ -875,20 +1126,34 @@ func TestGetPartnerFromProduct(t *testing.T) {
 	db.Migrator().DropTable(&models.Product{})
 	db.Migrator().DropTable(&models.Rating{})
 	db.Migrator().DropTable(&models.Transaction{})
+	db.Migrator().DropTable(&models.RefundRequest{})
+	db.Migrator().DropTable(&models.TransactionFee{})
+	db.Migrator().DropTable(&models.CommissionRule{})
+	db.Migrator().DropTable(&models.LedgerEntry{})
 	db.Migrator().DropTable(&models.DetailTransaction{})
+	db.Migrator().DropTable(&models.TransactionStatusHistory{})
+	db.Migrator().DropTable(&models.VoucherUsage{})
+	db.Migrator().DropTable(&models.Voucher{})
 	db.Migrator().DropTable(&models.Cashout{})
 
 	userRepo = user.NewUserRepo(db)
 	partnerRepo = partner.NewPartnerRepo(db)
 	productRepo = product.NewProductRepo(db)
-	transactionRepo = transaction.NewTransactionRepository(db)
+	transactionRepo = transaction.NewTransactionRepository(db, utils.InitPaymentProvider(configTest))
 
 	db.AutoMigrate(&models.User{})
 	db.AutoMigrate(&models.Partner{})
 	db.AutoMigrate(&models.Product{})
 	db.AutoMigrate(&models.Rating{})
 	db.AutoMigrate(&models.Transaction{})
+	db.AutoMigrate(&models.LedgerEntry{})
+	db.AutoMigrate(&models.CommissionRule{})
+	db.AutoMigrate(&models.TransactionFee{})
+	db.AutoMigrate(&models.RefundRequest{})
 	db.AutoMigrate(&models.DetailTransaction{})
+	db.AutoMigrate(&models.TransactionStatusHistory{})
+	db.AutoMigrate(&models.Voucher{})
+	db.AutoMigrate(&models.VoucherUsage{})
 	db.AutoMigrate(&models.Cashout{})
 
 	//CREATE USER
@@ -968,20 +1233,34 @@ func TestGetOneForPartner(t *testing.T) {
 	db.Migrator().DropTable(&models.Product{})
 	db.Migrator().DropTable(&models.Rating{})
 	db.Migrator().DropTable(&models.Transaction{})
+	db.Migrator().DropTable(&models.RefundRequest{})
+	db.Migrator().DropTable(&models.TransactionFee{})
+	db.Migrator().DropTable(&models.CommissionRule{})
+	db.Migrator().DropTable(&models.LedgerEntry{})
 	db.Migrator().DropTable(&models.DetailTransaction{})
+	db.Migrator().DropTable(&models.TransactionStatusHistory{})
+	db.Migrator().DropTable(&models.VoucherUsage{})
+	db.Migrator().DropTable(&models.Voucher{})
 	db.Migrator().DropTable(&models.Cashout{})
 
 	userRepo = user.NewUserRepo(db)
 	partnerRepo = partner.NewPartnerRepo(db)
 	productRepo = product.NewProductRepo(db)
-	transactionRepo = transaction.NewTransactionRepository(db)
+	transactionRepo = transaction.NewTransactionRepository(db, utils.InitPaymentProvider(configTest))
 
 	db.AutoMigrate(&models.User{})
 	db.AutoMigrate(&models.Partner{})
 	db.AutoMigrate(&models.Product{})
 	db.AutoMigrate(&models.Rating{})
 	db.AutoMigrate(&models.Transaction{})
+	db.AutoMigrate(&models.LedgerEntry{})
+	db.AutoMigrate(&models.CommissionRule{})
+	db.AutoMigrate(&models.TransactionFee{})
+	db.AutoMigrate(&models.RefundRequest{})
 	db.AutoMigrate(&models.DetailTransaction{})
+	db.AutoMigrate(&models.TransactionStatusHistory{})
+	db.AutoMigrate(&models.Voucher{})
+	db.AutoMigrate(&models.VoucherUsage{})
 	db.AutoMigrate(&models.Cashout{})
 
 	//CREATE USER
@@ -1038,8 +1317,21 @@ func TestGetOneForPartner(t *testing.T) {
 		res, _ := transactionRepo.GetOneForPartner(1, 1)
 		assert.Equal(t, "PAID", res.Status)
 	})
+	t.Run("test GetOneForPartner after accept", func(t *testing.T) {
+		db.Model(&models.Transaction{}).Where("id = ?", 1).Update("status", "ACCEPT")
+
+		res, _ := transactionRepo.GetOneForPartner(1, 1)
+		assert.Equal(t, "ACCEPT", res.Status)
+	})
+	t.Run("test GetOneForPartner pending", func(t *testing.T) {
+		db.Model(&models.Transaction{}).Where("id = ?", 1).Update("status", "PENDING")
+
+		res, _ := transactionRepo.GetOneForPartner(1, 1)
+		assert.Equal(t, uint(0), res.ID)
+	})
 	t.Run("test GetOneForPartner invalid", func(t *testing.T) {
 		db.Migrator().DropTable(&models.Transaction{})
+		db.Migrator().DropTable(&models.LedgerEntry{})
 		res, _ := transactionRepo.GetOneForPartner(1, 1)
 		assert.Equal(t, uint(0), res.ID)
 	})
@@ -1053,21 +1345,37 @@ func TestCallback(t *testing.T) {
 	db.Migrator().DropTable(&models.Partner{})
 	db.Migrator().DropTable(&models.Product{})
 	db.Migrator().DropTable(&models.Rating{})
+	db.Migrator().DropTable(&models.TransactionInvoice{})
 	db.Migrator().DropTable(&models.Transaction{})
+	db.Migrator().DropTable(&models.RefundRequest{})
+	db.Migrator().DropTable(&models.TransactionFee{})
+	db.Migrator().DropTable(&models.CommissionRule{})
+	db.Migrator().DropTable(&models.LedgerEntry{})
 	db.Migrator().DropTable(&models.DetailTransaction{})
+	db.Migrator().DropTable(&models.TransactionStatusHistory{})
+	db.Migrator().DropTable(&models.VoucherUsage{})
+	db.Migrator().DropTable(&models.Voucher{})
 	db.Migrator().DropTable(&models.Cashout{})
 
 	userRepo = user.NewUserRepo(db)
 	partnerRepo = partner.NewPartnerRepo(db)
 	productRepo = product.NewProductRepo(db)
-	transactionRepo = transaction.NewTransactionRepository(db)
+	transactionRepo = transaction.NewTransactionRepository(db, utils.InitPaymentProvider(configTest))
 
 	db.AutoMigrate(&models.User{})
 	db.AutoMigrate(&models.Partner{})
 	db.AutoMigrate(&models.Product{})
 	db.AutoMigrate(&models.Rating{})
 	db.AutoMigrate(&models.Transaction{})
+	db.AutoMigrate(&models.TransactionInvoice{})
+	db.AutoMigrate(&models.LedgerEntry{})
+	db.AutoMigrate(&models.CommissionRule{})
+	db.AutoMigrate(&models.TransactionFee{})
+	db.AutoMigrate(&models.RefundRequest{})
 	db.AutoMigrate(&models.DetailTransaction{})
+	db.AutoMigrate(&models.TransactionStatusHistory{})
+	db.AutoMigrate(&models.Voucher{})
+	db.AutoMigrate(&models.VoucherUsage{})
 	db.AutoMigrate(&models.Cashout{})
 
 	//CREATE USER
@@ -1149,32 +1457,144 @@ func TestCallback(t *testing.T) {
 	t.Run("test Callback success", func(t *testing.T) {
 		transaction := models.Transaction{}
 		transaction.UserID = 2
-		res, _ := transactionRepo.Callback("11", transaction, 500)
+		res, _ := transactionRepo.Callback("11", transaction)
 		assert.Equal(t, "", res.Partner.BussinessName)
 	})
 	t.Run("test Callback transaction not found", func(t *testing.T) {
 		transaction := models.Transaction{}
 		transaction.UserID = 2
-		res, _ := transactionRepo.Callback("99", transaction, 500)
+		res, _ := transactionRepo.Callback("99", transaction)
 		assert.Equal(t, "", res.Partner.BussinessName)
 	})
 
 	t.Run("test Callback user not found", func(t *testing.T) { //////////////////////////<<<<<<
 		transaction := models.Transaction{}
 		transaction.UserID = 2
-		res, _ := transactionRepo.Callback("23abc", dummyTransaction3, 500)
+		res, _ := transactionRepo.Callback("23abc", dummyTransaction3)
 		assert.Equal(t, "", res.Partner.BussinessName)
 	})
 
 	t.Run("test Callback", func(t *testing.T) {
 		transaction := models.Transaction{}
 		transaction.UserID = 9
-		res, _ := transactionRepo.Callback("11", transaction, 500)
+		res, _ := transactionRepo.Callback("11", transaction)
 		assert.Equal(t, "", res.Partner.BussinessName)
 	})
 
 }
 
+func TestRenewPayment(t *testing.T) {
+	configTest = config.GetConfig()
+	db = utils.InitDB(configTest)
+
+	constants.PAYMENT_TIMEOUT_HOURS = 24
+	constants.INVOICE_DURATION_HOURS = 6
+
+	db.Migrator().DropTable(&models.User{})
+	db.Migrator().DropTable(&models.Transaction{})
+	db.Migrator().DropTable(&models.TransactionInvoice{})
+	db.Migrator().DropTable(&models.DetailTransaction{})
+	db.Migrator().DropTable(&models.TransactionStatusHistory{})
+	db.Migrator().DropTable(&models.LedgerEntry{})
+	db.Migrator().DropTable(&models.TransactionTax{})
+
+	provider := payment.NewSandboxProvider("", "", time.Hour)
+	transactionRepo = transaction.NewTransactionRepository(db, provider)
+
+	db.AutoMigrate(&models.User{})
+	db.AutoMigrate(&models.Transaction{})
+	db.AutoMigrate(&models.TransactionInvoice{})
+	db.AutoMigrate(&models.DetailTransaction{})
+	db.AutoMigrate(&models.TransactionStatusHistory{})
+	db.AutoMigrate(&models.LedgerEntry{})
+	db.AutoMigrate(&models.TransactionTax{})
+
+	dummyUser := models.User{Email: "test2@gmail.com", Password: "test1234", Role: "user"}
+	db.Create(&dummyUser)
+
+	newOrder := func(invoiceID string, duration int) models.Transaction {
+		issued, _ := provider.CreateInvoice(payment.CreateInvoiceParams{ExternalID: invoiceID, Amount: 20000, Duration: duration})
+
+		trx := models.Transaction{
+			UserID:           dummyUser.ID,
+			PartnerID:        1,
+			TotalPrice:       20000,
+			InvoiceID:        invoiceID,
+			PaymentInvoiceID: issued.ID,
+			PaymentUrl:       issued.URL,
+			Status:           constants.PENDING_STATUS,
+		}
+		db.Create(&trx)
+		db.Create(&models.TransactionInvoice{TransactionID: trx.ID, ExternalID: invoiceID, PaymentInvoiceID: issued.ID, PaymentUrl: issued.URL, Amount: 20000, Status: payment.INVOICE_PENDING, ExpiresAt: issued.ExpiryDate})
+
+		return trx
+	}
+
+	expired := newOrder("SB-EXPIRED", 0)
+	valid := newOrder("SB-VALID", 3600)
+
+	t.Run("renew an expired invoice", func(t *testing.T) {
+		res, err := transactionRepo.RenewPayment(int(expired.ID), int(dummyUser.ID))
+		assert.Nil(t, err)
+		assert.Len(t, res.Invoices, 2)
+		assert.Equal(t, payment.INVOICE_EXPIRED, res.Invoices[0].Status)
+		assert.Equal(t, "SB-EXPIRED-2", res.Invoices[1].ExternalID)
+		assert.Equal(t, payment.INVOICE_PENDING, res.Invoices[1].Status)
+		assert.Equal(t, models.Money(20000), res.Invoices[1].Amount)
+		assert.Equal(t, res.Invoices[1].PaymentInvoiceID, res.PaymentInvoiceID)
+		assert.NotEqual(t, expired.PaymentInvoiceID, res.PaymentInvoiceID)
+
+		old, _ := provider.GetInvoice(expired.PaymentInvoiceID)
+		assert.Equal(t, payment.INVOICE_EXPIRED, old.Status)
+	})
+
+	t.Run("renew while the invoice is still valid", func(t *testing.T) {
+		_, err := transactionRepo.RenewPayment(int(valid.ID), int(dummyUser.ID))
+		assert.True(t, errors.Is(err, helper.ErrInvalidPayment))
+	})
+
+	t.Run("renew the order of another user", func(t *testing.T) {
+		_, err := transactionRepo.RenewPayment(int(expired.ID), 99)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("expired callback keeps the order waiting", func(t *testing.T) {
+		_, err := transactionRepo.Callback("SB-EXPIRED", models.Transaction{Status: payment.INVOICE_EXPIRED})
+		assert.Nil(t, err)
+
+		trx := models.Transaction{}
+		db.First(&trx, expired.ID)
+		assert.Equal(t, constants.PENDING_STATUS, trx.Status)
+	})
+
+	t.Run("callback of a renewed invoice pays the order", func(t *testing.T) {
+		_, err := transactionRepo.Callback("SB-EXPIRED-2", models.Transaction{Status: constants.PAID_STATUS, PaymentMethod: "BANK_TRANSFER"})
+		assert.Nil(t, err)
+
+		trx := models.Transaction{}
+		db.Preload("Invoices").First(&trx, expired.ID)
+		assert.Equal(t, constants.PAID_STATUS, trx.Status)
+		assert.Equal(t, payment.INVOICE_PAID, trx.Invoices[1].Status)
+	})
+
+	t.Run("later callback of a paid invoice records its settlement", func(t *testing.T) {
+		_, err := transactionRepo.Callback("SB-EXPIRED-2", models.Transaction{Status: payment.INVOICE_PAID, PaidAmount: 20000, GatewayFee: 4440, SettlementStatus: constants.SETTLEMENT_SETTLED})
+		assert.Nil(t, err)
+
+		trx := models.Transaction{}
+		db.First(&trx, expired.ID)
+		assert.Equal(t, constants.PAID_STATUS, trx.Status)
+		assert.Equal(t, models.Money(20000), trx.PaidAmount)
+		assert.Equal(t, models.Money(4440), trx.GatewayFee)
+		assert.Equal(t, constants.SETTLEMENT_SETTLED, trx.SettlementStatus)
+	})
+
+	t.Run("renew a paid order", func(t *testing.T) {
+		_, err := transactionRepo.RenewPayment(int(expired.ID), int(dummyUser.ID))
+		assert.True(t, errors.Is(err, helper.ErrInvalidPayment))
+	})
+}
+
 func TestGetDistance(t *testing.T) {
 	configTest = config.GetConfig()
 	db = utils.InitDB(configTest)
@@ -1184,20 +1604,34 @@ func TestGetDistance(t *testing.T) {
 	db.Migrator().DropTable(&models.Product{})
 	db.Migrator().DropTable(&models.Rating{})
 	db.Migrator().DropTable(&models.Transaction{})
+	db.Migrator().DropTable(&models.RefundRequest{})
+	db.Migrator().DropTable(&models.TransactionFee{})
+	db.Migrator().DropTable(&models.CommissionRule{})
+	db.Migrator().DropTable(&models.LedgerEntry{})
 	db.Migrator().DropTable(&models.DetailTransaction{})
+	db.Migrator().DropTable(&models.TransactionStatusHistory{})
+	db.Migrator().DropTable(&models.VoucherUsage{})
+	db.Migrator().DropTable(&models.Voucher{})
 	db.Migrator().DropTable(&models.Cashout{})
 
 	userRepo = user.NewUserRepo(db)
 	partnerRepo = partner.NewPartnerRepo(db)
 	productRepo = product.NewProductRepo(db)
-	transactionRepo = transaction.NewTransactionRepository(db)
+	transactionRepo = transaction.NewTransactionRepository(db, utils.InitPaymentProvider(configTest))
 
 	db.AutoMigrate(&models.User{})
 	db.AutoMigrate(&models.Partner{})
 	db.AutoMigrate(&models.Product{})
 	db.AutoMigrate(&models.Rating{})
 	db.AutoMigrate(&models.Transaction{})
+	db.AutoMigrate(&models.LedgerEntry{})
+	db.AutoMigrate(&models.CommissionRule{})
+	db.AutoMigrate(&models.TransactionFee{})
+	db.AutoMigrate(&models.RefundRequest{})
 	db.AutoMigrate(&models.DetailTransaction{})
+	db.AutoMigrate(&models.TransactionStatusHistory{})
+	db.AutoMigrate(&models.Voucher{})
+	db.AutoMigrate(&models.VoucherUsage{})
 	db.AutoMigrate(&models.Cashout{})
 
 	//CREATE USER
@@ -0,0 +1,106 @@
+package voucher
+
+import (
+	"github.com/furqonzt99/snackbox/models"
+	"gorm.io/gorm"
+)
+
+type VoucherInterface interface {
+	Create(voucher models.Voucher) (models.Voucher, error)
+	GetAll() ([]models.Voucher, error)
+	Get(voucherID int) (models.Voucher, error)
+	Update(voucherID int, voucher models.Voucher) (models.Voucher, error)
+	Delete(voucherID int) error
+}
+
+type VoucherRepository struct {
+	db *gorm.DB
+}
+
+func NewVoucherRepository(db *gorm.DB) *VoucherRepository {
+	return &VoucherRepository{db: db}
+}
+
+func (vr *VoucherRepository) Create(voucher models.Voucher) (models.Voucher, error) {
+	if err := vr.db.Create(&voucher).Error; err != nil {
+		return voucher, err
+	}
+
+	return vr.Get(int(voucher.ID))
+}
+
+func (vr *VoucherRepository) GetAll() ([]models.Voucher, error) {
+	vouchers := []models.Voucher{}
+
+	if err := vr.db.Preload("Partners").Preload("Categories").Order("id desc").Find(&vouchers).Error; err != nil {
+		return vouchers, err
+	}
+
+	return vouchers, nil
+}
+
+func (vr *VoucherRepository) Get(voucherID int) (models.Voucher, error) {
+	voucher := models.Voucher{}
+
+	if err := vr.db.Preload("Partners").Preload("Categories").First(&voucher, voucherID).Error; err != nil {
+		return voucher, err
+	}
+
+	return voucher, nil
+}
+
+// Update replaces every setting of the voucher, restrictions included. The usage count is kept.
+func (vr *VoucherRepository) Update(voucherID int, voucher models.Voucher) (models.Voucher, error) {
+	current := models.Voucher{}
+
+	if err := vr.db.First(&current, voucherID).Error; err != nil {
+		return current, err
+	}
+
+	err := vr.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&current).Select("code", "type", "amount", "percentage", "max_discount", "min_spend", "start_at", "end_at", "quota", "per_user_limit").Updates(voucher).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("voucher_id = ?", current.ID).Delete(&models.VoucherPartner{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("voucher_id = ?", current.ID).Delete(&models.VoucherCategory{}).Error; err != nil {
+			return err
+		}
+
+		for _, partner := range voucher.Partners {
+			partner.VoucherID = current.ID
+			if err := tx.Create(&partner).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, category := range voucher.Categories {
+			category.VoucherID = current.ID
+			if err := tx.Create(&category).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return current, err
+	}
+
+	return vr.Get(voucherID)
+}
+
+// Delete retires the voucher. Orders that already redeemed it keep their discount.
+func (vr *VoucherRepository) Delete(voucherID int) error {
+	voucher := models.Voucher{}
+
+	if err := vr.db.First(&voucher, voucherID).Error; err != nil {
+		return err
+	}
+
+	return vr.db.Delete(&voucher).Error
+}
@@ -0,0 +1,124 @@
+package voucher_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	config "github.com/furqonzt99/snackbox/configs"
+	"github.com/furqonzt99/snackbox/helper"
+	"github.com/furqonzt99/snackbox/models"
+	"github.com/furqonzt99/snackbox/repositories/voucher"
+	"github.com/furqonzt99/snackbox/utils"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+var configTest *config.AppConfig
+var db *gorm.DB
+var voucherRepo *voucher.VoucherRepository
+
+func TestVoucher(t *testing.T) {
+	configTest = config.GetConfig()
+	db = utils.InitDB(configTest)
+
+	db.Migrator().DropTable(&models.VoucherUsage{})
+	db.Migrator().DropTable(&models.VoucherCategory{})
+	db.Migrator().DropTable(&models.VoucherPartner{})
+	db.Migrator().DropTable(&models.Voucher{})
+
+	voucherRepo = voucher.NewVoucherRepository(db)
+
+	db.AutoMigrate(&models.Voucher{})
+	db.AutoMigrate(&models.VoucherPartner{})
+	db.AutoMigrate(&models.VoucherCategory{})
+	db.AutoMigrate(&models.VoucherUsage{})
+
+	items := []models.DetailTransaction{
+		{Type: "snackbox", Price: 10000, Quantity: 10},
+		{Type: "ricebox", Price: 25000, Quantity: 2},
+	}
+	shipping := helper.ShippingBreakdown{BaseCost: 50000, Surcharge: 20000, Total: 70000}
+
+	t.Run("create voucher", func(t *testing.T) {
+		res, err := voucherRepo.Create(models.Voucher{
+			Code:         "HEMAT10",
+			Type:         "PERCENTAGE",
+			Percentage:   10,
+			MaxDiscount:  8000,
+			StartAt:      time.Now().Add(-time.Hour),
+			EndAt:        time.Now().Add(time.Hour),
+			Quota:        2,
+			PerUserLimit: 1,
+			Categories:   []models.VoucherCategory{{Category: "snackbox"}},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(res.Categories))
+	})
+
+	t.Run("create voucher duplicate code", func(t *testing.T) {
+		_, err := voucherRepo.Create(models.Voucher{Code: "HEMAT10", Type: "FLAT", Amount: 1000})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("apply voucher on eligible products only", func(t *testing.T) {
+		_, discount, err := helper.ApplyVoucher(db, "HEMAT10", models.Transaction{Model: gorm.Model{ID: 1}, UserID: 1, PartnerID: 1}, items, shipping)
+		assert.Nil(t, err)
+		// 10% of the snackbox lines is 10000, capped at 8000
+		assert.Equal(t, models.Money(8000), discount)
+
+		res, _ := voucherRepo.Get(1)
+		assert.Equal(t, 1, res.Used)
+	})
+
+	t.Run("apply voucher over the per user limit", func(t *testing.T) {
+		_, _, err := helper.ApplyVoucher(db, "HEMAT10", models.Transaction{Model: gorm.Model{ID: 2}, UserID: 1, PartnerID: 1}, items, shipping)
+		assert.True(t, errors.Is(err, helper.ErrInvalidVoucher))
+	})
+
+	t.Run("apply voucher over the quota", func(t *testing.T) {
+		helper.ApplyVoucher(db, "HEMAT10", models.Transaction{Model: gorm.Model{ID: 3}, UserID: 2, PartnerID: 1}, items, shipping)
+
+		_, _, err := helper.ApplyVoucher(db, "HEMAT10", models.Transaction{Model: gorm.Model{ID: 4}, UserID: 3, PartnerID: 1}, items, shipping)
+		assert.Equal(t, "invalid voucher: quota has run out", err.Error())
+	})
+
+	t.Run("release voucher", func(t *testing.T) {
+		assert.Nil(t, helper.ReleaseVoucher(db, 3))
+
+		res, _ := voucherRepo.Get(1)
+		assert.Equal(t, 1, res.Used)
+	})
+
+	t.Run("update voucher to a partner restricted free shipping voucher", func(t *testing.T) {
+		res, err := voucherRepo.Update(1, models.Voucher{
+			Code:     "ONGKIR",
+			Type:     "FREE_SHIPPING",
+			MinSpend: 100000,
+			StartAt:  time.Now().Add(-time.Hour),
+			EndAt:    time.Now().Add(time.Hour),
+			Partners: []models.VoucherPartner{{PartnerID: 2}},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(res.Categories))
+		assert.Equal(t, 1, res.Used)
+
+		_, _, err = helper.ApplyVoucher(db, "ONGKIR", models.Transaction{Model: gorm.Model{ID: 5}, UserID: 4, PartnerID: 1}, items, shipping)
+		assert.Equal(t, "invalid voucher: not valid for this partner", err.Error())
+
+		// the large order surcharge is still paid
+		_, discount, err := helper.ApplyVoucher(db, "ONGKIR", models.Transaction{Model: gorm.Model{ID: 6}, UserID: 4, PartnerID: 2}, items, shipping)
+		assert.Nil(t, err)
+		assert.Equal(t, models.Money(50000), discount)
+	})
+
+	t.Run("apply unknown voucher", func(t *testing.T) {
+		_, _, err := helper.ApplyVoucher(db, "NOPE", models.Transaction{Model: gorm.Model{ID: 7}, UserID: 1, PartnerID: 1}, items, shipping)
+		assert.True(t, errors.Is(err, helper.ErrInvalidVoucher))
+	})
+
+	t.Run("delete voucher", func(t *testing.T) {
+		assert.Nil(t, voucherRepo.Delete(1))
+		assert.NotNil(t, voucherRepo.Delete(1))
+	})
+}
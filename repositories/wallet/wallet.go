@@ -0,0 +1,190 @@
+package wallet
+
+import (
+	"time"
+
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/helper"
+	"github.com/furqonzt99/snackbox/models"
+	"github.com/furqonzt99/snackbox/payment"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WalletMismatch is a user whose stored balance differs from the sum of their wallet ledger.
+type WalletMismatch struct {
+	UserID        uint
+	Balance       models.Money
+	LedgerBalance models.Money
+}
+
+type WalletInterface interface {
+	GetLedger(userID, offset, limit int) ([]models.LedgerEntry, error)
+	Adjust(adjustment models.WalletAdjustment) (models.WalletAdjustment, error)
+	Reconcile() ([]WalletMismatch, error)
+	Topup(topup models.WalletTopup, email string) (models.WalletTopup, error)
+	GetTopups(userID int) ([]models.WalletTopup, error)
+	TopupCallback(externalID string, topup models.WalletTopup) (models.WalletTopup, error)
+}
+
+type WalletRepository struct {
+	db       *gorm.DB
+	provider payment.PaymentProvider
+}
+
+func NewWalletRepository(db *gorm.DB, provider payment.PaymentProvider) *WalletRepository {
+	return &WalletRepository{db: db, provider: provider}
+}
+
+// GetLedger pages through the wallet entries of the user, newest first.
+func (wr *WalletRepository) GetLedger(userID, offset, limit int) ([]models.LedgerEntry, error) {
+	entries := []models.LedgerEntry{}
+
+	if err := wr.db.Where("account = ? AND user_id = ?", constants.WALLET_ACCOUNT, userID).
+		Order("id desc").Offset(offset).Limit(limit).Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// Adjust books a correction on the user's wallet, a negative amount taking money off it.
+func (wr *WalletRepository) Adjust(adjustment models.WalletAdjustment) (models.WalletAdjustment, error) {
+	err := wr.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&adjustment).Error; err != nil {
+			return err
+		}
+
+		return helper.PostLedger(tx, helper.LedgerTransfer{
+			From:        helper.SystemAccount(constants.ADJUSTMENT_ACCOUNT),
+			To:          helper.WalletAccount(adjustment.UserID),
+			Amount:      adjustment.Amount,
+			Type:        constants.ADJUSTMENT_ENTRY,
+			SourceType:  constants.ADJUSTMENT_SOURCE,
+			SourceID:    adjustment.ID,
+			Description: adjustment.Reason,
+		})
+	})
+
+	if err != nil {
+		return adjustment, err
+	}
+
+	return adjustment, nil
+}
+
+// Reconcile compares every stored balance with the wallet ledger.
+func (wr *WalletRepository) Reconcile() ([]WalletMismatch, error) {
+	mismatches := []WalletMismatch{}
+
+	ledger := wr.db.Model(&models.LedgerEntry{}).
+		Select("user_id, SUM(amount) AS balance").
+		Where("account = ?", constants.WALLET_ACCOUNT).
+		Group("user_id")
+
+	err := wr.db.Table("users").
+		Select("users.id AS user_id, users.balance, COALESCE(ledger.balance, 0) AS ledger_balance").
+		Joins("LEFT JOIN (?) AS ledger ON ledger.user_id = users.id", ledger).
+		Where("users.deleted_at IS NULL AND users.balance <> COALESCE(ledger.balance, 0)").
+		Scan(&mismatches).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return mismatches, nil
+}
+
+// Topup issues a payment invoice for the amount. The wallet is credited once the invoice is paid.
+func (wr *WalletRepository) Topup(topup models.WalletTopup, email string) (models.WalletTopup, error) {
+	topup.ExternalID = helper.NewTopupID()
+	topup.Status = constants.PENDING_STATUS
+
+	err := wr.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&topup).Error; err != nil {
+			return err
+		}
+
+		invoice, err := wr.provider.CreateInvoice(payment.CreateInvoiceParams{
+			ExternalID:  topup.ExternalID,
+			Amount:      topup.Amount,
+			Description: "SboxPay top-up " + topup.ExternalID + " for " + email,
+			PayerEmail:  email,
+			Items: []payment.InvoiceItem{
+				{Name: "SboxPay Top-up", Price: topup.Amount, Quantity: 1},
+			},
+			Duration: constants.PAYMENT_TIMEOUT_HOURS * 3600,
+		})
+		if err != nil {
+			return err
+		}
+
+		topup.PaymentInvoiceID = invoice.ID
+		topup.PaymentUrl = invoice.URL
+
+		return tx.Model(&topup).Updates(models.WalletTopup{PaymentInvoiceID: invoice.ID, PaymentUrl: invoice.URL}).Error
+	})
+
+	if err != nil {
+		return topup, err
+	}
+
+	return topup, nil
+}
+
+func (wr *WalletRepository) GetTopups(userID int) ([]models.WalletTopup, error) {
+	topups := []models.WalletTopup{}
+
+	if err := wr.db.Where("user_id = ?", userID).Order("id desc").Find(&topups).Error; err != nil {
+		return nil, err
+	}
+
+	return topups, nil
+}
+
+// TopupCallback applies the invoice status to a pending top-up, crediting the wallet when it was paid.
+// Callbacks for a top-up that is no longer pending change nothing, so the wallet is credited exactly once.
+func (wr *WalletRepository) TopupCallback(externalID string, topup models.WalletTopup) (models.WalletTopup, error) {
+	topupDB := models.WalletTopup{}
+
+	err := wr.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&topupDB, "external_id = ?", externalID).Error; err != nil {
+			return err
+		}
+
+		if topupDB.Status != constants.PENDING_STATUS {
+			return nil
+		}
+
+		if topup.Status == constants.PAID_STATUS {
+			if topup.PaidAt.IsZero() {
+				topup.PaidAt = time.Now()
+			}
+
+			err := helper.PostLedger(tx, helper.LedgerTransfer{
+				From:        helper.SystemAccount(constants.GATEWAY_ACCOUNT),
+				To:          helper.WalletAccount(topupDB.UserID),
+				Amount:      topupDB.Amount,
+				Type:        constants.TOPUP_ENTRY,
+				SourceType:  constants.TOPUP_SOURCE,
+				SourceID:    topupDB.ID,
+				Description: "top-up " + topupDB.ExternalID,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&topupDB).Updates(models.WalletTopup{
+			Status:         topup.Status,
+			PaymentMethod:  topup.PaymentMethod,
+			PaymentChannel: topup.PaymentChannel,
+			PaidAt:         topup.PaidAt,
+		}).Error
+	})
+
+	if err != nil {
+		return topupDB, err
+	}
+
+	return topupDB, nil
+}
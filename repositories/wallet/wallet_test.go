@@ -0,0 +1,138 @@
+package wallet_test
+
+import (
+	"testing"
+	"time"
+
+	config "github.com/furqonzt99/snackbox/configs"
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/helper"
+	"github.com/furqonzt99/snackbox/models"
+	"github.com/furqonzt99/snackbox/payment"
+	"github.com/furqonzt99/snackbox/repositories/wallet"
+	"github.com/furqonzt99/snackbox/utils"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+var configTest *config.AppConfig
+var db *gorm.DB
+var walletRepo *wallet.WalletRepository
+
+func TestWallet(t *testing.T) {
+	configTest = config.GetConfig()
+	db = utils.InitDB(configTest)
+
+	db.Migrator().DropTable(&models.WalletTopup{})
+	db.Migrator().DropTable(&models.WalletAdjustment{})
+	db.Migrator().DropTable(&models.LedgerEntry{})
+	db.Migrator().DropTable(&models.User{})
+
+	walletRepo = wallet.NewWalletRepository(db, payment.NewSandboxProvider("", "", time.Hour))
+
+	db.AutoMigrate(&models.User{})
+	db.AutoMigrate(&models.LedgerEntry{})
+	db.AutoMigrate(&models.WalletAdjustment{})
+	db.AutoMigrate(&models.WalletTopup{})
+
+	db.Create(&models.User{Name: "tester", Email: "test@gmail.com", Balance: 20000})
+	db.Create(&models.User{Name: "partner", Email: "partner@gmail.com"})
+
+	t.Run("open wallet balances", func(t *testing.T) {
+		assert.Nil(t, helper.OpenWalletBalances(db))
+		assert.Nil(t, helper.OpenWalletBalances(db))
+
+		entries, _ := walletRepo.GetLedger(1, 0, 10)
+		assert.Equal(t, 1, len(entries))
+		assert.Equal(t, constants.OPENING_BALANCE_ENTRY, entries[0].Type)
+		assert.Equal(t, models.Money(20000), entries[0].Balance)
+	})
+
+	t.Run("adjust balance", func(t *testing.T) {
+		res, err := walletRepo.Adjust(models.WalletAdjustment{UserID: 1, AdminID: 3, Amount: -5000, Reason: "double refund"})
+		assert.Nil(t, err)
+		assert.Equal(t, uint(1), res.ID)
+
+		user := models.User{}
+		db.First(&user, 1)
+		assert.Equal(t, models.Money(15000), user.Balance)
+	})
+
+	t.Run("adjust balance below zero", func(t *testing.T) {
+		_, err := walletRepo.Adjust(models.WalletAdjustment{UserID: 2, AdminID: 3, Amount: -5000, Reason: "mistake"})
+		assert.Equal(t, helper.ErrInsufficientBalance, err)
+	})
+
+	t.Run("transfer is posted once per source", func(t *testing.T) {
+		transfer := helper.LedgerTransfer{
+			From:       helper.SystemAccount(constants.ESCROW_ACCOUNT),
+			To:         helper.WalletAccount(2),
+			Amount:     30000,
+			Type:       constants.PARTNER_EARNING_ENTRY,
+			SourceType: constants.TRANSACTION_SOURCE,
+			SourceID:   7,
+		}
+
+		assert.Nil(t, helper.PostLedger(db, transfer))
+		assert.Nil(t, helper.PostLedger(db, transfer))
+
+		user := models.User{}
+		db.First(&user, 2)
+		assert.Equal(t, models.Money(30000), user.Balance)
+
+		var total float64
+		db.Model(&models.LedgerEntry{}).Select("SUM(amount)").Scan(&total)
+		assert.Equal(t, float64(0), total)
+	})
+
+	t.Run("ledger with running balance", func(t *testing.T) {
+		entries, err := walletRepo.GetLedger(1, 0, 10)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(entries))
+		assert.Equal(t, models.Money(-5000), entries[0].Amount)
+		assert.Equal(t, models.Money(15000), entries[0].Balance)
+
+		entries, _ = walletRepo.GetLedger(1, 1, 10)
+		assert.Equal(t, 1, len(entries))
+	})
+
+	t.Run("topup", func(t *testing.T) {
+		res, err := walletRepo.Topup(models.WalletTopup{UserID: 1, Amount: 50000}, "test@gmail.com")
+		assert.Nil(t, err)
+		assert.True(t, helper.IsTopupID(res.ExternalID))
+		assert.Equal(t, constants.PENDING_STATUS, res.Status)
+		assert.NotEqual(t, "", res.PaymentUrl)
+
+		topups, _ := walletRepo.GetTopups(1)
+		assert.Equal(t, 1, len(topups))
+	})
+
+	t.Run("topup callback credits once", func(t *testing.T) {
+		topups, _ := walletRepo.GetTopups(1)
+
+		paid := models.WalletTopup{Status: constants.PAID_STATUS, PaymentMethod: "BANK_TRANSFER", PaidAt: time.Now()}
+
+		res, err := walletRepo.TopupCallback(topups[0].ExternalID, paid)
+		assert.Nil(t, err)
+		assert.Equal(t, constants.PAID_STATUS, res.Status)
+
+		_, err = walletRepo.TopupCallback(topups[0].ExternalID, paid)
+		assert.Nil(t, err)
+
+		user := models.User{}
+		db.First(&user, 1)
+		assert.Equal(t, models.Money(65000), user.Balance)
+	})
+
+	t.Run("reconcile", func(t *testing.T) {
+		mismatches, err := walletRepo.Reconcile()
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(mismatches))
+
+		db.Model(&models.User{}).Where("id = ?", 2).Update("balance", 99000)
+
+		mismatches, _ = walletRepo.Reconcile()
+		assert.Equal(t, 1, len(mismatches))
+		assert.Equal(t, models.Money(30000), mismatches[0].LedgerBalance)
+	})
+}
@@ -0,0 +1,174 @@
+package webhook
+
+import (
+	"errors"
+	"time"
+
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrNotReplayable = errors.New("only failed webhook events can be replayed")
+
+type WebhookInterface interface {
+	Receive(event models.WebhookEvent) (models.WebhookEvent, bool, error)
+	GetAll(source, status string) ([]models.WebhookEvent, error)
+	Get(id int) (models.WebhookEvent, error)
+	GetDue(now time.Time, limit int) ([]models.WebhookEvent, error)
+	Claim(id int, now time.Time, lease time.Duration) (models.WebhookEvent, bool, error)
+	Processed(id int, now time.Time) error
+	Failed(id int, message string, retryAt time.Time) error
+	Replay(id int) (models.WebhookEvent, error)
+}
+
+type WebhookRepository struct {
+	db *gorm.DB
+}
+
+func NewWebhookRepository(db *gorm.DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+// Receive stores the event unless one with the same source and event id was stored before.
+// It reports false and returns the stored event for such a redelivery.
+func (wr *WebhookRepository) Receive(event models.WebhookEvent) (models.WebhookEvent, bool, error) {
+	if event.Status == "" {
+		event.Status = constants.WEBHOOK_PENDING
+	}
+	if event.NextAttemptAt.IsZero() {
+		event.NextAttemptAt = time.Now()
+	}
+
+	res := wr.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&event)
+	if res.Error != nil {
+		return event, false, res.Error
+	}
+
+	if res.RowsAffected == 1 {
+		return event, true, nil
+	}
+
+	stored := models.WebhookEvent{}
+	if err := wr.db.Where("source = ? AND event_id = ?", event.Source, event.EventID).First(&stored).Error; err != nil {
+		return event, false, err
+	}
+
+	return stored, false, nil
+}
+
+func (wr *WebhookRepository) GetAll(source, status string) ([]models.WebhookEvent, error) {
+	events := []models.WebhookEvent{}
+
+	query := wr.db.Order("id desc")
+	if source != "" {
+		query = query.Where("source = ?", source)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Find(&events).Error; err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+func (wr *WebhookRepository) Get(id int) (models.WebhookEvent, error) {
+	event := models.WebhookEvent{}
+
+	if err := wr.db.First(&event, id).Error; err != nil {
+		return event, err
+	}
+
+	return event, nil
+}
+
+// GetDue returns the events waiting for a (next) attempt, including those whose
+// processing lease ran out because the replica working on them went away.
+func (wr *WebhookRepository) GetDue(now time.Time, limit int) ([]models.WebhookEvent, error) {
+	events := []models.WebhookEvent{}
+
+	if err := wr.db.Where("status IN ? AND next_attempt_at <= ?", []string{constants.WEBHOOK_PENDING, constants.WEBHOOK_PROCESSING}, now).
+		Order("next_attempt_at").Limit(limit).Find(&events).Error; err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// Claim marks the event as being processed for the length of the lease and counts the attempt.
+// It reports false when the event is not due, or another worker claimed it first.
+func (wr *WebhookRepository) Claim(id int, now time.Time, lease time.Duration) (models.WebhookEvent, bool, error) {
+	event := models.WebhookEvent{}
+
+	res := wr.db.Model(&models.WebhookEvent{}).
+		Where("id = ? AND status IN ? AND next_attempt_at <= ?", id, []string{constants.WEBHOOK_PENDING, constants.WEBHOOK_PROCESSING}, now).
+		Updates(map[string]interface{}{
+			"status":          constants.WEBHOOK_PROCESSING,
+			"next_attempt_at": now.Add(lease),
+			"attempts":        gorm.Expr("attempts + 1"),
+		})
+	if res.Error != nil {
+		return event, false, res.Error
+	}
+
+	if res.RowsAffected != 1 {
+		return event, false, nil
+	}
+
+	if err := wr.db.First(&event, id).Error; err != nil {
+		return event, false, err
+	}
+
+	return event, true, nil
+}
+
+func (wr *WebhookRepository) Processed(id int, now time.Time) error {
+	return wr.db.Model(&models.WebhookEvent{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":       constants.WEBHOOK_PROCESSED,
+		"error":        "",
+		"processed_at": now,
+	}).Error
+}
+
+// Failed records the error and schedules the next attempt at retryAt, or gives up on the event when retryAt is zero.
+func (wr *WebhookRepository) Failed(id int, message string, retryAt time.Time) error {
+	updates := map[string]interface{}{
+		"status": constants.WEBHOOK_PENDING,
+		"error":  message,
+	}
+
+	if retryAt.IsZero() {
+		updates["status"] = constants.WEBHOOK_FAILED
+	} else {
+		updates["next_attempt_at"] = retryAt
+	}
+
+	return wr.db.Model(&models.WebhookEvent{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// Replay queues a failed event again with a fresh set of attempts.
+func (wr *WebhookRepository) Replay(id int) (models.WebhookEvent, error) {
+	event, err := wr.Get(id)
+	if err != nil {
+		return event, err
+	}
+
+	if event.Status != constants.WEBHOOK_FAILED {
+		return event, ErrNotReplayable
+	}
+
+	err = wr.db.Model(&event).Updates(map[string]interface{}{
+		"status":          constants.WEBHOOK_PENDING,
+		"attempts":        0,
+		"next_attempt_at": time.Now(),
+	}).Error
+	if err != nil {
+		return event, err
+	}
+
+	return wr.Get(id)
+}
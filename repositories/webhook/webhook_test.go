@@ -0,0 +1,106 @@
+package webhook_test
+
+import (
+	"testing"
+	"time"
+
+	config "github.com/furqonzt99/snackbox/configs"
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/models"
+	"github.com/furqonzt99/snackbox/repositories/webhook"
+	"github.com/furqonzt99/snackbox/utils"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+var configTest *config.AppConfig
+var db *gorm.DB
+var webhookRepo *webhook.WebhookRepository
+
+func TestWebhook(t *testing.T) {
+	configTest = config.GetConfig()
+	db = utils.InitDB(configTest)
+
+	db.Migrator().DropTable(&models.WebhookEvent{})
+
+	webhookRepo = webhook.NewWebhookRepository(db)
+
+	db.AutoMigrate(&models.WebhookEvent{})
+
+	now := time.Now()
+
+	t.Run("receive event", func(t *testing.T) {
+		res, created, err := webhookRepo.Receive(models.WebhookEvent{
+			Source:  constants.CASHOUT_WEBHOOK,
+			EventID: "disb-1:FAILED",
+			Body:    `{"external_id":"C1","status":"FAILED"}`,
+		})
+		assert.Nil(t, err)
+		assert.True(t, created)
+		assert.Equal(t, constants.WEBHOOK_PENDING, res.Status)
+	})
+
+	t.Run("receive redelivered event", func(t *testing.T) {
+		res, created, err := webhookRepo.Receive(models.WebhookEvent{
+			Source:  constants.CASHOUT_WEBHOOK,
+			EventID: "disb-1:FAILED",
+			Body:    `{"external_id":"C1","status":"FAILED"}`,
+		})
+		assert.Nil(t, err)
+		assert.False(t, created)
+		assert.Equal(t, uint(1), res.ID)
+	})
+
+	t.Run("claim event", func(t *testing.T) {
+		res, claimed, err := webhookRepo.Claim(1, now.Add(time.Second), time.Minute)
+		assert.Nil(t, err)
+		assert.True(t, claimed)
+		assert.Equal(t, constants.WEBHOOK_PROCESSING, res.Status)
+		assert.Equal(t, 1, res.Attempts)
+
+		_, claimed, _ = webhookRepo.Claim(1, now.Add(time.Second), time.Minute)
+		assert.False(t, claimed)
+	})
+
+	t.Run("failed event is due again later", func(t *testing.T) {
+		assert.Nil(t, webhookRepo.Failed(1, "record not found", now.Add(time.Hour)))
+
+		due, _ := webhookRepo.GetDue(now.Add(time.Minute), 10)
+		assert.Equal(t, 0, len(due))
+
+		due, _ = webhookRepo.GetDue(now.Add(2*time.Hour), 10)
+		assert.Equal(t, 1, len(due))
+	})
+
+	t.Run("replay pending event", func(t *testing.T) {
+		_, err := webhookRepo.Replay(1)
+		assert.Equal(t, webhook.ErrNotReplayable, err)
+	})
+
+	t.Run("replay failed event", func(t *testing.T) {
+		assert.Nil(t, webhookRepo.Failed(1, "record not found", time.Time{}))
+
+		failed, _ := webhookRepo.GetAll(constants.CASHOUT_WEBHOOK, constants.WEBHOOK_FAILED)
+		assert.Equal(t, 1, len(failed))
+
+		res, err := webhookRepo.Replay(1)
+		assert.Nil(t, err)
+		assert.Equal(t, constants.WEBHOOK_PENDING, res.Status)
+		assert.Equal(t, 0, res.Attempts)
+	})
+
+	t.Run("processed event", func(t *testing.T) {
+		_, claimed, _ := webhookRepo.Claim(1, time.Now().Add(time.Second), time.Minute)
+		assert.True(t, claimed)
+
+		assert.Nil(t, webhookRepo.Processed(1, now))
+
+		res, _ := webhookRepo.Get(1)
+		assert.Equal(t, constants.WEBHOOK_PROCESSED, res.Status)
+	})
+
+	t.Run("get unknown event", func(t *testing.T) {
+		_, err := webhookRepo.Get(99)
+		assert.NotNil(t, err)
+	})
+}
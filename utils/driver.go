@@ -2,6 +2,8 @@ package utils
 
 import (
 	config "github.com/furqonzt99/snackbox/configs"
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/helper"
 	"github.com/furqonzt99/snackbox/models"
 	"github.com/furqonzt99/snackbox/seeder"
 	"gorm.io/driver/mysql"
@@ -23,32 +25,164 @@ func InitDB(config *config.AppConfig) *gorm.DB {
 
 func InitialMigrate(db *gorm.DB) {
 	if config.Mode == "development" {
+		db.Migrator().DropTable(&models.ReconciliationDiscrepancy{})
+		db.Migrator().DropTable(&models.ReconciliationRun{})
+		db.Migrator().DropTable(&models.BankAccount{})
+		db.Migrator().DropTable(&models.WebhookEvent{})
+		db.Migrator().DropTable(&models.RefundRequest{})
+		db.Migrator().DropTable(&models.TransactionFee{})
+		db.Migrator().DropTable(&models.CommissionRule{})
+		db.Migrator().DropTable(&models.WalletTopup{})
+		db.Migrator().DropTable(&models.WalletAdjustment{})
+		db.Migrator().DropTable(&models.LedgerEntry{})
+		db.Migrator().DropTable(&models.SchedulerLock{})
+		db.Migrator().DropTable(&models.VoucherUsage{})
+		db.Migrator().DropTable(&models.VoucherCategory{})
+		db.Migrator().DropTable(&models.VoucherPartner{})
+		db.Migrator().DropTable(&models.Voucher{})
+		db.Migrator().DropTable(&models.ShippingSurcharge{})
+		db.Migrator().DropTable(&models.ShippingZone{})
+		db.Migrator().DropTable(&models.ShippingTariffTier{})
+		db.Migrator().DropTable(&models.ShippingTariff{})
+		db.Migrator().DropTable(&models.SubscriptionItem{})
+		db.Migrator().DropTable(&models.Subscription{})
+		db.Migrator().DropTable(&models.CartItem{})
+		db.Migrator().DropTable(&models.TransactionStatusHistory{})
+		db.Migrator().DropTable(&models.TransactionInvoice{})
+		db.Migrator().DropTable(&models.TransactionTax{})
+		db.Migrator().DropTable(&models.TaxRule{})
 		db.Migrator().DropTable(&models.DetailTransaction{})
 		db.Migrator().DropTable(&models.Transaction{})
 		db.Migrator().DropTable(&models.Product{})
 		db.Migrator().DropTable(&models.Rating{})
 		db.Migrator().DropTable(&models.Cashout{})
+		db.Migrator().DropTable(&models.PartnerCalendar{})
 		db.Migrator().DropTable(&models.Partner{})
 		db.Migrator().DropTable(&models.User{})
 
 		db.AutoMigrate(&models.User{})
 		db.AutoMigrate(&models.Product{})
 		db.AutoMigrate(&models.Transaction{})
+		db.AutoMigrate(&models.DetailTransaction{})
+		db.AutoMigrate(&models.TransactionStatusHistory{})
+		db.AutoMigrate(&models.TransactionInvoice{})
+		db.AutoMigrate(&models.TaxRule{})
+		db.AutoMigrate(&models.TransactionTax{})
 		db.AutoMigrate(&models.Partner{})
 		db.AutoMigrate(&models.Rating{})
 		db.AutoMigrate(&models.Cashout{})
+		db.AutoMigrate(&models.CartItem{})
+		db.AutoMigrate(&models.SchedulerLock{})
+		db.AutoMigrate(&models.PartnerCalendar{})
+		db.AutoMigrate(&models.ShippingTariff{})
+		db.AutoMigrate(&models.ShippingTariffTier{})
+		db.AutoMigrate(&models.ShippingZone{})
+		db.AutoMigrate(&models.ShippingSurcharge{})
+		db.AutoMigrate(&models.Voucher{})
+		db.AutoMigrate(&models.VoucherPartner{})
+		db.AutoMigrate(&models.VoucherCategory{})
+		db.AutoMigrate(&models.VoucherUsage{})
+		db.AutoMigrate(&models.Subscription{})
+		db.AutoMigrate(&models.SubscriptionItem{})
+		db.AutoMigrate(&models.WebhookEvent{})
+		db.AutoMigrate(&models.LedgerEntry{})
+		db.AutoMigrate(&models.WalletAdjustment{})
+		db.AutoMigrate(&models.WalletTopup{})
+		db.AutoMigrate(&models.CommissionRule{})
+		db.AutoMigrate(&models.TransactionFee{})
+		db.AutoMigrate(&models.RefundRequest{})
+		db.AutoMigrate(&models.BankAccount{})
+		db.AutoMigrate(&models.ReconciliationRun{})
+		db.AutoMigrate(&models.ReconciliationDiscrepancy{})
 
 		seeder.AdminSeeder(db)
 		seeder.UserSeeder(db)
 		seeder.PartnerSeeder(db)
 		seeder.ProductSeeder(db)
 	} else {
+		// convert the amounts stored as DOUBLE before AutoMigrate sees the new column types
+		if err := MigrateMoneyColumns(db); err != nil {
+			panic(err)
+		}
+
 		db.AutoMigrate(&models.User{})
 		db.AutoMigrate(&models.Product{})
 		db.AutoMigrate(&models.Transaction{})
+		db.AutoMigrate(&models.DetailTransaction{})
+		db.AutoMigrate(&models.TransactionStatusHistory{})
+		db.AutoMigrate(&models.TransactionInvoice{})
+		db.AutoMigrate(&models.TaxRule{})
+		db.AutoMigrate(&models.TransactionTax{})
 		db.AutoMigrate(&models.Partner{})
 		db.AutoMigrate(&models.Rating{})
 		db.AutoMigrate(&models.Cashout{})
+		db.AutoMigrate(&models.CartItem{})
+		db.AutoMigrate(&models.SchedulerLock{})
+		db.AutoMigrate(&models.PartnerCalendar{})
+		db.AutoMigrate(&models.ShippingTariff{})
+		db.AutoMigrate(&models.ShippingTariffTier{})
+		db.AutoMigrate(&models.ShippingZone{})
+		db.AutoMigrate(&models.ShippingSurcharge{})
+		db.AutoMigrate(&models.Voucher{})
+		db.AutoMigrate(&models.VoucherPartner{})
+		db.AutoMigrate(&models.VoucherCategory{})
+		db.AutoMigrate(&models.VoucherUsage{})
+		db.AutoMigrate(&models.Subscription{})
+		db.AutoMigrate(&models.SubscriptionItem{})
+		db.AutoMigrate(&models.WebhookEvent{})
+		db.AutoMigrate(&models.LedgerEntry{})
+		db.AutoMigrate(&models.WalletAdjustment{})
+		db.AutoMigrate(&models.WalletTopup{})
+		db.AutoMigrate(&models.CommissionRule{})
+		db.AutoMigrate(&models.TransactionFee{})
+		db.AutoMigrate(&models.RefundRequest{})
+		db.AutoMigrate(&models.BankAccount{})
+		db.AutoMigrate(&models.ReconciliationRun{})
+		db.AutoMigrate(&models.ReconciliationDiscrepancy{})
+
+		// vouchers created when the rupiah amount and the percentage shared one value column
+		if err := MigrateVoucherValues(db); err != nil {
+			panic(err)
+		}
+
+		// earnings booked before the hold period were paid straight into the wallet
+		if err := db.Model(&models.TransactionFee{}).Where("available_at IS NULL").
+			Updates(map[string]interface{}{"available_at": gorm.Expr("created_at"), "released_at": gorm.Expr("created_at")}).Error; err != nil {
+			panic(err)
+		}
+
+		// fees booked before the exclusive tax was kept apart
+		if err := db.Exec("UPDATE transaction_fees tf JOIN transactions t ON t.id = tf.transaction_id SET tf.tax = t.tax WHERE tf.tax = 0 AND t.tax > 0").Error; err != nil {
+			panic(err)
+		}
+
+		// book the balances users had before the ledger as opening entries
+		if err := helper.OpenWalletBalances(db); err != nil {
+			panic(err)
+		}
+
+		// record the single invoice of orders placed before invoices were kept per order
+		if err := db.Exec("INSERT INTO transaction_invoices (created_at, updated_at, transaction_id, external_id, payment_invoice_id, payment_url, amount, status, expires_at) "+
+			"SELECT t.created_at, t.updated_at, t.id, t.invoice_id, t.payment_invoice_id, t.payment_url, t.total_price - t.balance_used, "+
+			"CASE WHEN t.paid_at IS NOT NULL THEN 'PAID' WHEN t.status = 'PENDING' THEN 'PENDING' ELSE 'EXPIRED' END, DATE_ADD(t.created_at, INTERVAL ? HOUR) "+
+			"FROM transactions t LEFT JOIN transaction_invoices ti ON ti.transaction_id = t.id WHERE t.payment_invoice_id <> '' AND ti.id IS NULL", constants.PAYMENT_TIMEOUT_HOURS).Error; err != nil {
+			panic(err)
+		}
+
+		// orders paid before the gateway's fee was recorded: the fee is unknown and the money long settled
+		if err := db.Exec("UPDATE transactions t JOIN transaction_invoices ti ON ti.transaction_id = t.id AND ti.status = 'PAID' "+
+			"SET t.paid_amount = ti.amount, t.settlement_status = ? WHERE t.paid_amount = 0", constants.SETTLEMENT_SETTLED).Error; err != nil {
+			panic(err)
+		}
+
+		// cashouts completed before the settlement time was recorded
+		if err := db.Model(&models.Cashout{}).Where("status = ? AND settled_at IS NULL", constants.CASHOUT_COMPLETED).
+			UpdateColumn("settled_at", gorm.Expr("updated_at")).Error; err != nil {
+			panic(err)
+		}
+
+		// fill line item snapshots for orders placed before they were stored
+		db.Exec("UPDATE detail_transactions dt JOIN transactions t ON t.id = dt.transaction_id JOIN products p ON p.id = dt.product_id SET dt.title = p.title, dt.type = p.type, dt.price = p.price, dt.quantity = t.quantity WHERE dt.quantity = 0")
 	}
 
 }
@@ -0,0 +1,120 @@
+package utils
+
+import (
+	"strings"
+
+	"github.com/furqonzt99/snackbox/constants"
+	"github.com/furqonzt99/snackbox/models"
+	"github.com/labstack/gommon/log"
+	"gorm.io/gorm"
+)
+
+// moneyColumns lists the amounts that were stored as DOUBLE before models.Money.
+var moneyColumns = []struct {
+	model   interface{}
+	columns []string
+}{
+	{&models.User{}, []string{"balance"}},
+	{&models.Product{}, []string{"price"}},
+	{&models.Transaction{}, []string{"total_price", "shipping_cost", "balance_used", "discount"}},
+	{&models.DetailTransaction{}, []string{"price"}},
+	{&models.Cashout{}, []string{"amount"}},
+	{&models.LedgerEntry{}, []string{"amount", "balance"}},
+	{&models.WalletAdjustment{}, []string{"amount"}},
+	{&models.WalletTopup{}, []string{"amount"}},
+	{&models.ShippingTariff{}, []string{"base_cost", "free_shipping_min_subtotal"}},
+	{&models.ShippingTariffTier{}, []string{"cost_per_km"}},
+	{&models.ShippingZone{}, []string{"flat_cost"}},
+	{&models.ShippingSurcharge{}, []string{"amount"}},
+	{&models.Voucher{}, []string{"max_discount", "min_spend"}},
+	{&models.VoucherUsage{}, []string{"discount"}},
+}
+
+// MigrateMoneyColumns turns the DOUBLE money columns into BIGINT rupiah. Amounts are whole
+// rupiah in practice; every row with a stray fraction is logged with its value before it is
+// rounded, so a wallet that drifted by it can be traced in the wallet reconciliation.
+// Converted columns are skipped.
+func MigrateMoneyColumns(db *gorm.DB) error {
+	for _, money := range moneyColumns {
+		if !db.Migrator().HasTable(money.model) {
+			continue
+		}
+
+		columnTypes, err := db.Migrator().ColumnTypes(money.model)
+		if err != nil {
+			return err
+		}
+
+		types := map[string]string{}
+		for _, columnType := range columnTypes {
+			types[columnType.Name()] = strings.ToLower(columnType.DatabaseTypeName())
+		}
+
+		for _, column := range money.columns {
+			switch types[column] {
+			case "double", "float", "decimal":
+			default:
+				continue
+			}
+
+			fractional := db.Model(money.model).Unscoped().Where(column + " <> ROUND(" + column + ")")
+			if err := logFractions(fractional, money.model, column); err != nil {
+				return err
+			}
+
+			if err := fractional.UpdateColumn(column, gorm.Expr("ROUND("+column+")")).Error; err != nil {
+				return err
+			}
+
+			if err := db.Migrator().AlterColumn(money.model, column); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// MigrateVoucherValues moves the value column vouchers had before amounts and percentages were
+// kept apart into percentage for PERCENTAGE vouchers and into amount for the others, then drops
+// it. Run it after AutoMigrate has added the new columns.
+func MigrateVoucherValues(db *gorm.DB) error {
+	if !db.Migrator().HasColumn(&models.Voucher{}, "value") {
+		return nil
+	}
+
+	if err := db.Model(&models.Voucher{}).Unscoped().Where("type = ?", constants.PERCENTAGE_VOUCHER).
+		UpdateColumn("percentage", gorm.Expr("value")).Error; err != nil {
+		return err
+	}
+
+	fractional := db.Model(&models.Voucher{}).Unscoped().Where("type <> ? AND value <> ROUND(value)", constants.PERCENTAGE_VOUCHER)
+	if err := logFractions(fractional, &models.Voucher{}, "value"); err != nil {
+		return err
+	}
+
+	if err := db.Model(&models.Voucher{}).Unscoped().Where("type <> ?", constants.PERCENTAGE_VOUCHER).
+		UpdateColumn("amount", gorm.Expr("ROUND(value)")).Error; err != nil {
+		return err
+	}
+
+	return db.Migrator().DropColumn(&models.Voucher{}, "value")
+}
+
+// logFractions logs every row of query whose column holds a fraction of a rupiah, before it is rounded.
+func logFractions(query *gorm.DB, model interface{}, column string) error {
+	rows := []struct {
+		ID    uint
+		Value float64
+	}{}
+
+	if err := query.Session(&gorm.Session{}).Select("id, " + column + " AS value").Scan(&rows).Error; err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		log.Warnf("money migration: rounding %s %v of %T %d to whole rupiah", column, row.Value, model, row.ID)
+	}
+
+	return nil
+}
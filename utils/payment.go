@@ -0,0 +1,20 @@
+package utils
+
+import (
+	"time"
+
+	config "github.com/furqonzt99/snackbox/configs"
+	"github.com/furqonzt99/snackbox/payment"
+	"github.com/labstack/gommon/log"
+)
+
+// InitPaymentProvider picks the payment gateway from PAYMENT_PROVIDER, Xendit unless it is "sandbox".
+func InitPaymentProvider(config *config.AppConfig) payment.PaymentProvider {
+	if config.Payment.Provider == "sandbox" {
+		log.Info("payments go through the sandbox provider")
+
+		return payment.NewSandboxProvider(config.Payment.CallbackURL, config.Payment.CallbackToken, time.Duration(config.Payment.DelaySeconds)*time.Second)
+	}
+
+	return payment.NewXenditProvider(config.Payment.XenditSecretKey)
+}